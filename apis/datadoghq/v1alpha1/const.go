@@ -10,11 +10,32 @@ const (
 	AgentDeploymentNameLabelKey = "agent.datadoghq.com/name"
 	// AgentDeploymentComponentLabelKey label key use to know with component is it
 	AgentDeploymentComponentLabelKey = "agent.datadoghq.com/component"
+	// AgentProfileLabelKey label key use to link an Agent DaemonSet and its pods to a DatadogAgentProfile
+	AgentProfileLabelKey = "agent.datadoghq.com/profile"
+	// AgentSidecarProfileLabelKey label key use to select the pods in which the Agent sidecar is injected
+	AgentSidecarProfileLabelKey = "agent.datadoghq.com/sidecar"
 	// MD5AgentDeploymentAnnotationKey annotation key used on a Resource in order to identify which AgentDeployment have been used to generate it.
 	MD5AgentDeploymentAnnotationKey = "agent.datadoghq.com/agentspechash"
+	// RevisionHashLabelKey label key use to link a ControllerRevision to the hash of the component it was rendered from
+	RevisionHashLabelKey = "agent.datadoghq.com/revision-hash"
+	// RevisionHealthyAnnotationKey annotation key set on a ControllerRevision once all the pods of the revision were available
+	RevisionHealthyAnnotationKey = "agent.datadoghq.com/healthy"
+	// RevisionRolledBackToAnnotationKey annotation key set on a failed ControllerRevision with the hash of the revision it was rolled back to
+	RevisionRolledBackToAnnotationKey = "agent.datadoghq.com/rolled-back-to"
+	// RevisionRollbackReasonAnnotationKey annotation key set on a failed ControllerRevision with the reason of the rollback
+	RevisionRollbackReasonAnnotationKey = "agent.datadoghq.com/rollback-reason"
+	// PrometheusRuleNameLabelKey label key use to link a DatadogMonitor to the PrometheusRule it was generated from,
+	// the names longer than a label value are truncated and suffixed with their hash
+	PrometheusRuleNameLabelKey = "monitor.datadoghq.com/prometheusrule"
+	// PrometheusRuleNameAnnotationKey annotation key set on a generated DatadogMonitor with the full name of its PrometheusRule
+	PrometheusRuleNameAnnotationKey = "monitor.datadoghq.com/prometheusrule-name"
+	// DatadogMonitorTranslationErrorAnnotationKey annotation key set on a generated DatadogMonitor when its source alert cannot be translated into a Datadog query
+	DatadogMonitorTranslationErrorAnnotationKey = "monitor.datadoghq.com/translation-error"
 
 	// DefaultAgentResourceSuffix use as suffix for agent resource naming
 	DefaultAgentResourceSuffix = "agent"
+	// DefaultAgentWindowsResourceSuffix use as suffix for windows agent resource naming
+	DefaultAgentWindowsResourceSuffix = "agent-windows"
 	// DefaultClusterAgentResourceSuffix use as suffix for cluster-agent resource naming
 	DefaultClusterAgentResourceSuffix = "cluster-agent"
 	// DefaultClusterChecksRunnerResourceSuffix use as suffix for cluster-checks-runner resource naming
@@ -39,6 +60,10 @@ const (
 	DefaultAdmissionControllerTargetPort = 8000
 	// DefaultDogstatsdPort default dogstatsd port
 	DefaultDogstatsdPort = 8125
+	// DefaultOTLPGRPCPort default OTLP/gRPC receiver port
+	DefaultOTLPGRPCPort = 4317
+	// DefaultOTLPHTTPPort default OTLP/HTTP receiver port
+	DefaultOTLPHTTPPort = 4318
 )
 
 // Datadog env var names
@@ -121,6 +146,15 @@ const (
 	DDExternalMetricsProviderAPIKey              = "DD_EXTERNAL_METRICS_PROVIDER_API_KEY"
 	DDExternalMetricsProviderAppKey              = "DD_EXTERNAL_METRICS_PROVIDER_APP_KEY"
 	DDAuthTokenFilePath                          = "DD_AUTH_TOKEN_FILE_PATH"
+	DDOTLPGRPCEndpoint                           = "DD_OTLP_CONFIG_RECEIVER_PROTOCOLS_GRPC_ENDPOINT"
+	DDRemoteConfigurationEnabled                 = "DD_REMOTE_CONFIGURATION_ENABLED"
+	DDOTLPGRPCTransport                          = "DD_OTLP_CONFIG_RECEIVER_PROTOCOLS_GRPC_TRANSPORT"
+	DDOTLPHTTPEndpoint                           = "DD_OTLP_CONFIG_RECEIVER_PROTOCOLS_HTTP_ENDPOINT"
+	DDEKSFargate                                 = "DD_EKS_FARGATE"
+	DDKubeletNodeName                            = "DD_KUBERNETES_KUBELET_NODENAME"
+	DDClusterAgentURL                            = "DD_CLUSTER_AGENT_URL"
+	DDDogstatsdPipeName                          = "DD_DOGSTATSD_PIPE_NAME"
+	DDAPMWindowsPipeName                         = "DD_APM_WINDOWS_PIPE_NAME"
 
 	// KubernetesEnvvarName Env var used by the Datadog Agent container entrypoint
 	// to add kubelet config provider and listener
@@ -132,6 +166,10 @@ const (
 	LogDatadogVolumePath                 = "/var/log/datadog"
 	APMSocketVolumeName                  = "apmsocket"
 	APMSocketVolumePath                  = "/var/run/datadog/apm"
+	OTLPSocketVolumeName                 = "otlpsocket"
+	OTLPSocketVolumePath                 = "/var/run/datadog/otlp"
+	RemoteConfigVolumeName               = "remoteconfig"
+	RemoteConfigVolumePath               = "/opt/datadog-agent/run"
 	InstallInfoVolumeName                = "installinfo"
 	InstallInfoVolumeSubPath             = "install_info"
 	InstallInfoVolumePath                = "/etc/datadog-agent/install_info"
@@ -194,6 +232,17 @@ const (
 
 	HostCriSocketPathPrefix = "/host"
 
+	// Windows Agent volume paths and named pipes
+
+	WindowsConfigVolumePath            = "C:/ProgramData/Datadog"
+	WindowsAgentCustomConfigVolumePath = "C:/ProgramData/Datadog/datadog.yaml"
+	WindowsInstallInfoVolumePath       = "C:/ProgramData/Datadog/install_info"
+	WindowsLogPodVolumePath            = "C:/var/log/pods"
+	WindowsLogContainerVolumePath      = "C:/ProgramData/docker/containers"
+	WindowsCriSocketPipePath           = `\\.\pipe\containerd-containerd`
+	WindowsDogstatsdPipeName           = "datadog-dogstatsd"
+	WindowsAPMPipeName                 = "datadog-apm"
+
 	SecurityAgentRuntimeCustomPoliciesVolumeName     = "customruntimepolicies"
 	SecurityAgentRuntimePoliciesDirVolumeName        = "runtimepoliciesdir"
 	SecurityAgentRuntimePoliciesDirVolumePath        = "/etc/datadog-agent/runtime-security.d"
@@ -52,6 +52,8 @@ const (
 	defaultSecuritySyscallMonitorEnabled    bool   = false
 	defaultHostApmSocketName                string = "apm.sock"
 	defaultHostApmSocketPath                string = "/var/run/datadog"
+	defaultHostOTLPSocketName               string = "otlp.sock"
+	defaultHostOTLPSocketPath               string = "/var/run/datadog"
 	defaultLogEnabled                       bool   = false
 	defaultLogsConfigContainerCollectAll    bool   = false
 	defaultLogsContainerCollectUsingFiles   bool   = true
@@ -78,10 +80,35 @@ const (
 	defaultRollingUpdateSlowStartIntervalDuration               = 1 * time.Minute
 	defaultRollingUpdateSlowStartAdditiveIncrease               = "5"
 	defaultReconcileFrequency                                   = 10 * time.Second
+	defaultStagedRolloutCanaryReplicas                          = 1
+	defaultStagedRolloutBatchSize                               = "25%"
+	defaultStagedRolloutPauseDuration                           = 1 * time.Minute
+	defaultStagedRolloutMaxRestarts                      int32  = 2
+	defaultRollbackCrashLoopBackOffThreshold                    = "50%"
+	defaultRollbackUnreadyThreshold                             = "50%"
+	defaultRollbackUnreadyTimeout                               = 10 * time.Minute
+	defaultRollbackRevisionHistoryLimit                  int32  = 5
 	defaultRbacCreate                                           = true
 	defaultMutateUnlabelled                                     = false
 	DefaultAdmissionServiceName                                 = "datadog-admission-controller"
+	DefaultVersionCatalogName                                   = "datadog-version-catalog"
+	defaultAutoscalingMinReplicas                               = 1
+	defaultAutoscalingMaxReplicas                               = 5
+	defaultAutoscalingTargetCPUUtilizationPercentage            = 80
 	defaultAdmissionControllerEnabled                           = false
+	defaultOTLPEnabled                                          = false
+	defaultOTLPReceiverEnabled                                  = true
+	defaultOTLPUDSEnabled                                       = false
+	defaultRemoteConfigurationEnabled                           = false
+	defaultSidecarInjectionEnabled                              = false
+	defaultSidecarInjectionProfile                              = "fargate"
+	defaultSidecarInjectionNodeSelectorKey                      = "eks.amazonaws.com/compute-type"
+	defaultSidecarInjectionNodeSelectorValue                    = "fargate"
+	defaultWindowsAgentEnabled                                  = false
+	defaultSecurityContextConstraintsCreate                     = false
+	defaultContainerdSocketPath                                 = "/var/run/containerd/containerd.sock"
+	defaultCRIOSocketPath                                       = "/var/run/crio/crio.sock"
+	defaultOpenShiftKubeletTLSVerify                            = false
 
 	// Liveness probe default config
 	defaultLivenessProbeInitialDelaySeconds int32 = 15
@@ -114,6 +141,10 @@ func DefaultDatadogAgent(dda *DatadogAgent) *DatadogAgentStatus {
 	// Creds
 	defaultCredentials(&dda.Spec, dso)
 
+	// Platform
+	// default the platform first, the fields it sets are not changed by the generic defaulting
+	platformOverride := DefaultPlatform(&dda.Spec)
+
 	// Override spec given featureset
 	FeatureOverride(&dda.Spec, dso.DefaultOverride)
 
@@ -126,13 +157,189 @@ func DefaultDatadogAgent(dda *DatadogAgent) *DatadogAgentStatus {
 
 	// Agent
 	dso.DefaultOverride.Agent = *DefaultDatadogAgentSpecAgent(&dda.Spec.Agent)
+	mergePlatformOverride(&dso.DefaultOverride.Agent, platformOverride)
 
 	// CLC
 	dso.DefaultOverride.ClusterChecksRunner = *DefaultDatadogAgentSpecClusterChecksRunner(&dda.Spec.ClusterChecksRunner)
 
+	// Rollback
+	if dda.Spec.Rollback != nil {
+		if rollback := DefaultRollbackConfig(dda.Spec.Rollback); !apiutils.IsEqualStruct(*rollback, RollbackConfig{}) {
+			dso.DefaultOverride.Rollback = rollback
+		}
+	}
+
+	// Update policy
+	if dda.Spec.UpdatePolicy != nil {
+		if policy := DefaultUpdatePolicy(dda.Spec.UpdatePolicy); !apiutils.IsEqualStruct(*policy, UpdatePolicy{}) {
+			dso.DefaultOverride.UpdatePolicy = policy
+		}
+	}
+
+	// Image policy
+	if dda.Spec.ImagePolicy != nil {
+		if policy := DefaultImagePolicy(dda.Spec.ImagePolicy); !apiutils.IsEqualStruct(*policy, ImagePolicy{}) {
+			dso.DefaultOverride.ImagePolicy = policy
+		}
+	}
+
 	return dso
 }
 
+// DefaultPlatform applies the defaults of the platform the Agents are deployed on, only to the fields not set in the spec
+// return the defaulted NodeAgentConfig
+func DefaultPlatform(spec *DatadogAgentSpec) *NodeAgentConfig {
+	configOverride := &NodeAgentConfig{}
+
+	if spec.Agent.Enabled != nil && !*spec.Agent.Enabled {
+		return configOverride
+	}
+
+	var criSocketPath string
+	switch spec.Platform {
+	case PlatformGKEAutopilot:
+		criSocketPath = defaultContainerdSocketPath
+	case PlatformOpenShift:
+		criSocketPath = defaultCRIOSocketPath
+	default:
+		return configOverride
+	}
+
+	if spec.Agent.Config == nil {
+		spec.Agent.Config = &NodeAgentConfig{}
+	}
+	config := spec.Agent.Config
+
+	if config.CriSocket == nil {
+		config.CriSocket = &CRISocketConfig{}
+	}
+	if config.CriSocket.CriSocketPath == nil {
+		config.CriSocket.CriSocketPath = apiutils.NewStringPointer(criSocketPath)
+		configOverride.CriSocket = &CRISocketConfig{CriSocketPath: config.CriSocket.CriSocketPath}
+	}
+
+	if spec.Platform == PlatformOpenShift {
+		// The Kubelet serving certificate is not signed by the cluster CA mounted in the pods
+		if config.Kubelet == nil {
+			config.Kubelet = &KubeletConfig{}
+		}
+		if config.Kubelet.TLSVerify == nil {
+			config.Kubelet.TLSVerify = apiutils.NewBoolPointer(defaultOpenShiftKubeletTLSVerify)
+			configOverride.Kubelet = &KubeletConfig{TLSVerify: config.Kubelet.TLSVerify}
+		}
+
+		// The spc_t SELinux type allows the Agent to access the container runtime socket and the host files
+		if config.SecurityContext == nil {
+			config.SecurityContext = &corev1.PodSecurityContext{
+				SELinuxOptions: &corev1.SELinuxOptions{
+					User:  "system_u",
+					Role:  "system_r",
+					Type:  "spc_t",
+					Level: "s0",
+				},
+			}
+			configOverride.SecurityContext = config.SecurityContext
+		}
+	}
+
+	return configOverride
+}
+
+// mergePlatformOverride adds the platform defaults to the Agent defaults override
+func mergePlatformOverride(agentOverride *DatadogAgentSpecAgentSpec, configOverride *NodeAgentConfig) {
+	if apiutils.IsEqualStruct(*configOverride, NodeAgentConfig{}) {
+		return
+	}
+	if agentOverride.Config == nil {
+		agentOverride.Config = &NodeAgentConfig{}
+	}
+
+	if configOverride.CriSocket != nil {
+		if agentOverride.Config.CriSocket == nil {
+			agentOverride.Config.CriSocket = &CRISocketConfig{}
+		}
+		agentOverride.Config.CriSocket.CriSocketPath = configOverride.CriSocket.CriSocketPath
+	}
+	if configOverride.Kubelet != nil {
+		agentOverride.Config.Kubelet = configOverride.Kubelet
+	}
+	if configOverride.SecurityContext != nil {
+		agentOverride.Config.SecurityContext = configOverride.SecurityContext
+	}
+}
+
+// DefaultImagePolicy used to default an ImagePolicy
+// return the defaulted ImagePolicy
+func DefaultImagePolicy(policy *ImagePolicy) *ImagePolicy {
+	policyOverride := &ImagePolicy{}
+
+	if policy.RequireDigest == nil {
+		policy.RequireDigest = apiutils.NewBoolPointer(false)
+		policyOverride.RequireDigest = policy.RequireDigest
+	}
+
+	return policyOverride
+}
+
+// DefaultUpdatePolicy used to default an UpdatePolicy
+// return the defaulted UpdatePolicy
+func DefaultUpdatePolicy(policy *UpdatePolicy) *UpdatePolicy {
+	policyOverride := &UpdatePolicy{}
+
+	if policy.Channel == "" {
+		policy.Channel = UpdateChannelPinned
+		policyOverride.Channel = policy.Channel
+	}
+
+	if policy.VersionCatalog == "" {
+		policy.VersionCatalog = DefaultVersionCatalogName
+		policyOverride.VersionCatalog = policy.VersionCatalog
+	}
+
+	return policyOverride
+}
+
+// DefaultRollbackConfig used to default a RollbackConfig
+// return the defaulted RollbackConfig
+func DefaultRollbackConfig(rollback *RollbackConfig) *RollbackConfig {
+	rollbackOverride := &RollbackConfig{}
+
+	if rollback.Enabled == nil {
+		rollback.Enabled = apiutils.NewBoolPointer(false)
+		rollbackOverride.Enabled = rollback.Enabled
+	}
+
+	if rollback.CrashLoopBackOffThreshold == nil {
+		rollback.CrashLoopBackOffThreshold = &intstr.IntOrString{
+			Type:   intstr.String,
+			StrVal: defaultRollbackCrashLoopBackOffThreshold,
+		}
+		rollbackOverride.CrashLoopBackOffThreshold = rollback.CrashLoopBackOffThreshold
+	}
+
+	if rollback.UnreadyThreshold == nil {
+		rollback.UnreadyThreshold = &intstr.IntOrString{
+			Type:   intstr.String,
+			StrVal: defaultRollbackUnreadyThreshold,
+		}
+		rollbackOverride.UnreadyThreshold = rollback.UnreadyThreshold
+	}
+
+	if rollback.UnreadyTimeout == nil {
+		rollback.UnreadyTimeout = &metav1.Duration{
+			Duration: defaultRollbackUnreadyTimeout,
+		}
+		rollbackOverride.UnreadyTimeout = rollback.UnreadyTimeout
+	}
+
+	if rollback.RevisionHistoryLimit == nil {
+		rollback.RevisionHistoryLimit = apiutils.NewInt32Pointer(defaultRollbackRevisionHistoryLimit)
+		rollbackOverride.RevisionHistoryLimit = rollback.RevisionHistoryLimit
+	}
+
+	return rollbackOverride
+}
+
 func defaultCredentials(ddaSpec *DatadogAgentSpec, dso *DatadogAgentStatus) {
 	if ddaSpec.Credentials == nil {
 		ddaSpec.Credentials = &AgentCredentials{}
@@ -254,6 +461,24 @@ func DefaultDatadogAgentSpecAgent(agent *DatadogAgentSpecAgentSpec) *DatadogAgen
 		agentOverride.NetworkPolicy = net
 	}
 
+	if agent.SidecarInjection != nil {
+		if sidecar := DefaultAgentSidecarInjection(agent.SidecarInjection); !apiutils.IsEqualStruct(*sidecar, AgentSidecarInjectionConfig{}) {
+			agentOverride.SidecarInjection = sidecar
+		}
+	}
+
+	if agent.Windows != nil {
+		if windows := DefaultAgentWindows(agent.Windows); !apiutils.IsEqualStruct(*windows, WindowsAgentConfig{}) {
+			agentOverride.Windows = windows
+		}
+	}
+
+	if agent.SecurityContextConstraints != nil {
+		if scc := DefaultAgentSecurityContextConstraints(agent.SecurityContextConstraints); !apiutils.IsEqualStruct(*scc, SecurityContextConstraintsConfig{}) {
+			agentOverride.SecurityContextConstraints = scc
+		}
+	}
+
 	return agentOverride
 }
 
@@ -562,9 +787,56 @@ func DefaultDatadogAgentSpecDatadogAgentStrategy(agent *DatadogAgentSpecAgentSpe
 		strategyOverride.ReconcileFrequency = agent.DeploymentStrategy.ReconcileFrequency
 	}
 
+	if agent.DeploymentStrategy.StagedRollout != nil {
+		if rollout := DefaultDaemonSetStagedRollout(agent.DeploymentStrategy.StagedRollout); !apiutils.IsEqualStruct(*rollout, DaemonSetStagedRolloutSpec{}) {
+			strategyOverride.StagedRollout = rollout
+		}
+	}
+
 	return strategyOverride
 }
 
+// DefaultDaemonSetStagedRollout used to default a DaemonSetStagedRolloutSpec
+// return the defaulted DaemonSetStagedRolloutSpec
+func DefaultDaemonSetStagedRollout(rollout *DaemonSetStagedRolloutSpec) *DaemonSetStagedRolloutSpec {
+	rolloutOverride := &DaemonSetStagedRolloutSpec{}
+
+	if rollout.Enabled == nil {
+		rollout.Enabled = apiutils.NewBoolPointer(false)
+		rolloutOverride.Enabled = rollout.Enabled
+	}
+
+	if rollout.CanaryReplicas == nil {
+		rollout.CanaryReplicas = &intstr.IntOrString{
+			Type:   intstr.Int,
+			IntVal: defaultStagedRolloutCanaryReplicas,
+		}
+		rolloutOverride.CanaryReplicas = rollout.CanaryReplicas
+	}
+
+	if rollout.BatchSize == nil {
+		rollout.BatchSize = &intstr.IntOrString{
+			Type:   intstr.String,
+			StrVal: defaultStagedRolloutBatchSize,
+		}
+		rolloutOverride.BatchSize = rollout.BatchSize
+	}
+
+	if rollout.PauseDuration == nil {
+		rollout.PauseDuration = &metav1.Duration{
+			Duration: defaultStagedRolloutPauseDuration,
+		}
+		rolloutOverride.PauseDuration = rollout.PauseDuration
+	}
+
+	if rollout.MaxRestarts == nil {
+		rollout.MaxRestarts = apiutils.NewInt32Pointer(defaultStagedRolloutMaxRestarts)
+		rolloutOverride.MaxRestarts = rollout.MaxRestarts
+	}
+
+	return rolloutOverride
+}
+
 // DefaultDatadogAgentSpecAgentApm used to default an APMSpec
 // return the defaulted APMSpec
 func DefaultDatadogAgentSpecAgentApm(agent *DatadogAgentSpecAgentSpec) *APMSpec {
@@ -854,6 +1126,14 @@ func DefaultFeatures(dda *DatadogAgent) *DatadogFeatures {
 		featureOverride.NetworkMonitoring = net
 	}
 
+	if otlp := DefaultDatadogFeatureOTLP(ft); !apiutils.IsEqualStruct(*otlp, OTLPConfig{}) {
+		featureOverride.OTLP = otlp
+	}
+
+	if rc := DefaultDatadogFeatureRemoteConfiguration(ft); !apiutils.IsEqualStruct(*rc, RemoteConfigurationConfig{}) {
+		featureOverride.RemoteConfiguration = rc
+	}
+
 	return featureOverride
 }
 
@@ -961,6 +1241,88 @@ func DefaultDatadogFeatureNetworkMonitoring(ft *DatadogFeatures) *NetworkMonitor
 	return netOverride
 }
 
+// DefaultDatadogFeatureOTLP used to default an OTLPConfig
+// return the defaulted OTLPConfig
+func DefaultDatadogFeatureOTLP(ft *DatadogFeatures) *OTLPConfig {
+	if ft.OTLP == nil {
+		ft.OTLP = &OTLPConfig{Enabled: apiutils.NewBoolPointer(defaultOTLPEnabled)}
+	}
+
+	if ft.OTLP.Enabled == nil {
+		ft.OTLP.Enabled = apiutils.NewBoolPointer(defaultOTLPEnabled)
+	}
+
+	otlpOverride := &OTLPConfig{Enabled: ft.OTLP.Enabled}
+
+	if !apiutils.BoolValue(ft.OTLP.Enabled) {
+		return otlpOverride
+	}
+
+	if ft.OTLP.GRPC == nil {
+		ft.OTLP.GRPC = &OTLPProtocolConfig{}
+	}
+	if grpcOverride := defaultOTLPProtocol(ft.OTLP.GRPC, DefaultOTLPGRPCPort); !apiutils.IsEqualStruct(*grpcOverride, OTLPProtocolConfig{}) {
+		otlpOverride.GRPC = grpcOverride
+	}
+
+	if ft.OTLP.HTTP == nil {
+		ft.OTLP.HTTP = &OTLPProtocolConfig{}
+	}
+	if httpOverride := defaultOTLPProtocol(ft.OTLP.HTTP, DefaultOTLPHTTPPort); !apiutils.IsEqualStruct(*httpOverride, OTLPProtocolConfig{}) {
+		otlpOverride.HTTP = httpOverride
+	}
+
+	if ft.OTLP.UnixDomainSocket == nil {
+		ft.OTLP.UnixDomainSocket = &OTLPUnixDomainSocketConfig{Enabled: apiutils.NewBoolPointer(defaultOTLPUDSEnabled)}
+		otlpOverride.UnixDomainSocket = ft.OTLP.UnixDomainSocket
+	} else {
+		udsOverride := &OTLPUnixDomainSocketConfig{}
+		if ft.OTLP.UnixDomainSocket.Enabled == nil {
+			ft.OTLP.UnixDomainSocket.Enabled = apiutils.NewBoolPointer(defaultOTLPUDSEnabled)
+			udsOverride.Enabled = ft.OTLP.UnixDomainSocket.Enabled
+		}
+		if apiutils.BoolValue(ft.OTLP.UnixDomainSocket.Enabled) && ft.OTLP.UnixDomainSocket.HostFilepath == nil {
+			socketPath := path.Join(defaultHostOTLPSocketPath, defaultHostOTLPSocketName)
+			ft.OTLP.UnixDomainSocket.HostFilepath = &socketPath
+			udsOverride.HostFilepath = ft.OTLP.UnixDomainSocket.HostFilepath
+		}
+		if !apiutils.IsEqualStruct(*udsOverride, OTLPUnixDomainSocketConfig{}) {
+			otlpOverride.UnixDomainSocket = udsOverride
+		}
+	}
+
+	return otlpOverride
+}
+
+func defaultOTLPProtocol(protocol *OTLPProtocolConfig, defaultPort int32) *OTLPProtocolConfig {
+	protocolOverride := &OTLPProtocolConfig{}
+
+	if protocol.Enabled == nil {
+		protocol.Enabled = apiutils.NewBoolPointer(defaultOTLPReceiverEnabled)
+		protocolOverride.Enabled = protocol.Enabled
+	}
+
+	if apiutils.BoolValue(protocol.Enabled) && protocol.Port == nil {
+		protocol.Port = apiutils.NewInt32Pointer(defaultPort)
+		protocolOverride.Port = protocol.Port
+	}
+
+	return protocolOverride
+}
+
+// DefaultDatadogFeatureRemoteConfiguration used to default the RemoteConfiguration config
+func DefaultDatadogFeatureRemoteConfiguration(ft *DatadogFeatures) *RemoteConfigurationConfig {
+	if ft.RemoteConfiguration == nil {
+		ft.RemoteConfiguration = &RemoteConfigurationConfig{}
+	}
+
+	if ft.RemoteConfiguration.Enabled == nil {
+		ft.RemoteConfiguration.Enabled = apiutils.NewBoolPointer(defaultRemoteConfigurationEnabled)
+	}
+
+	return &RemoteConfigurationConfig{Enabled: ft.RemoteConfiguration.Enabled}
+}
+
 // DefaultDatadogAgentSpecClusterAgent used to default an DatadogAgentSpecClusterAgentSpec
 // Mutate the internal DatadogAgentSpecClusterAgent throughout the method
 // return the defaulted DatadogAgentSpecClusterAgentSpec to update the status
@@ -1004,9 +1366,47 @@ func DefaultDatadogAgentSpecClusterAgent(clusterAgent *DatadogAgentSpecClusterAg
 		clusterAgentOverride.NetworkPolicy = net
 	}
 
+	if clusterAgent.Autoscaling != nil {
+		if autoscaling := DefaultAutoscalingConfig(clusterAgent.Autoscaling); !apiutils.IsEqualStruct(*autoscaling, AutoscalingConfig{}) {
+			clusterAgentOverride.Autoscaling = autoscaling
+		}
+	}
+
 	return clusterAgentOverride
 }
 
+// DefaultAutoscalingConfig used to default an AutoscalingConfig
+// return the defaulted AutoscalingConfig
+func DefaultAutoscalingConfig(autoscaling *AutoscalingConfig) *AutoscalingConfig {
+	autoscalingOverride := &AutoscalingConfig{}
+
+	if autoscaling.Enabled == nil {
+		autoscaling.Enabled = apiutils.NewBoolPointer(false)
+		autoscalingOverride.Enabled = autoscaling.Enabled
+	}
+
+	if autoscaling.MinReplicas == nil {
+		autoscaling.MinReplicas = apiutils.NewInt32Pointer(defaultAutoscalingMinReplicas)
+		autoscalingOverride.MinReplicas = autoscaling.MinReplicas
+	}
+
+	if autoscaling.MaxReplicas == nil {
+		maxReplicas := int32(defaultAutoscalingMaxReplicas)
+		if *autoscaling.MinReplicas > maxReplicas {
+			maxReplicas = *autoscaling.MinReplicas
+		}
+		autoscaling.MaxReplicas = apiutils.NewInt32Pointer(maxReplicas)
+		autoscalingOverride.MaxReplicas = autoscaling.MaxReplicas
+	}
+
+	if autoscaling.TargetCPUUtilizationPercentage == nil && autoscaling.TargetMemoryUtilizationPercentage == nil && autoscaling.TargetClusterChecksPerRunner == nil {
+		autoscaling.TargetCPUUtilizationPercentage = apiutils.NewInt32Pointer(defaultAutoscalingTargetCPUUtilizationPercentage)
+		autoscalingOverride.TargetCPUUtilizationPercentage = autoscaling.TargetCPUUtilizationPercentage
+	}
+
+	return autoscalingOverride
+}
+
 // DefaultDatadogAgentSpecClusterAgentConfig used to default an ClusterAgentConfig
 // return the defaulted ClusterAgentConfig
 func DefaultDatadogAgentSpecClusterAgentConfig(dca *DatadogAgentSpecClusterAgentSpec) *ClusterAgentConfig {
@@ -1169,6 +1569,12 @@ func DefaultDatadogAgentSpecClusterChecksRunner(clusterChecksRunner *DatadogAgen
 		clcOverride.NetworkPolicy = net
 	}
 
+	if clusterChecksRunner.Autoscaling != nil {
+		if autoscaling := DefaultAutoscalingConfig(clusterChecksRunner.Autoscaling); !apiutils.IsEqualStruct(*autoscaling, AutoscalingConfig{}) {
+			clcOverride.Autoscaling = autoscaling
+		}
+	}
+
 	return clcOverride
 }
 
@@ -1282,6 +1688,56 @@ func DefaultAgentNetworkPolicy(agent *DatadogAgentSpecAgentSpec) *NetworkPolicyS
 	return DefaultNetworkPolicy(agent.NetworkPolicy)
 }
 
+// DefaultAgentSidecarInjection defaults the Agent sidecar injection configuration
+func DefaultAgentSidecarInjection(sidecar *AgentSidecarInjectionConfig) *AgentSidecarInjectionConfig {
+	sidecarOverride := &AgentSidecarInjectionConfig{}
+
+	if sidecar.Enabled == nil {
+		sidecar.Enabled = apiutils.NewBoolPointer(defaultSidecarInjectionEnabled)
+		sidecarOverride.Enabled = sidecar.Enabled
+	}
+
+	if !apiutils.BoolValue(sidecar.Enabled) {
+		return sidecarOverride
+	}
+
+	if sidecar.NodeSelector == nil {
+		sidecar.NodeSelector = map[string]string{defaultSidecarInjectionNodeSelectorKey: defaultSidecarInjectionNodeSelectorValue}
+		sidecarOverride.NodeSelector = sidecar.NodeSelector
+	}
+
+	if sidecar.Profile == nil {
+		sidecar.Profile = apiutils.NewStringPointer(defaultSidecarInjectionProfile)
+		sidecarOverride.Profile = sidecar.Profile
+	}
+
+	return sidecarOverride
+}
+
+// DefaultAgentWindows defaults the Windows Agent configuration
+func DefaultAgentWindows(windows *WindowsAgentConfig) *WindowsAgentConfig {
+	windowsOverride := &WindowsAgentConfig{}
+
+	if windows.Enabled == nil {
+		windows.Enabled = apiutils.NewBoolPointer(defaultWindowsAgentEnabled)
+		windowsOverride.Enabled = windows.Enabled
+	}
+
+	return windowsOverride
+}
+
+// DefaultAgentSecurityContextConstraints defaults the OpenShift SecurityContextConstraints configuration
+func DefaultAgentSecurityContextConstraints(scc *SecurityContextConstraintsConfig) *SecurityContextConstraintsConfig {
+	sccOverride := &SecurityContextConstraintsConfig{}
+
+	if scc.Create == nil {
+		scc.Create = apiutils.NewBoolPointer(defaultSecurityContextConstraintsCreate)
+		sccOverride.Create = scc.Create
+	}
+
+	return sccOverride
+}
+
 // DefaultClusterAgentNetworkPolicy defaults the Network Policy for the Datadog Cluster Agent
 func DefaultClusterAgentNetworkPolicy(dca *DatadogAgentSpecClusterAgentSpec) *NetworkPolicySpec {
 	if dca.NetworkPolicy == nil {
@@ -128,7 +128,9 @@ func TestDefaultFeatures(t *testing.T) {
 				LogCollection: &LogCollectionConfig{
 					Enabled: apiutils.NewBoolPointer(false),
 				},
-				NetworkMonitoring: &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(false)},
+				NetworkMonitoring:   &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(false)},
+				OTLP:                &OTLPConfig{Enabled: apiutils.NewBoolPointer(false)},
+				RemoteConfiguration: &RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(false)},
 			},
 			internalDefaulted: DatadogFeatures{
 				OrchestratorExplorer: &OrchestratorExplorerConfig{
@@ -143,7 +145,9 @@ func TestDefaultFeatures(t *testing.T) {
 				LogCollection: &LogCollectionConfig{
 					Enabled: apiutils.NewBoolPointer(false),
 				},
-				NetworkMonitoring: &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(false)},
+				NetworkMonitoring:   &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(false)},
+				OTLP:                &OTLPConfig{Enabled: apiutils.NewBoolPointer(false)},
+				RemoteConfiguration: &RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(false)},
 			},
 		},
 		{
@@ -173,7 +177,9 @@ func TestDefaultFeatures(t *testing.T) {
 				PrometheusScrape: &PrometheusScrapeConfig{
 					Enabled: apiutils.NewBoolPointer(false),
 				},
-				NetworkMonitoring: &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(false)},
+				NetworkMonitoring:   &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(false)},
+				OTLP:                &OTLPConfig{Enabled: apiutils.NewBoolPointer(false)},
+				RemoteConfiguration: &RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(false)},
 			},
 			internalDefaulted: DatadogFeatures{
 				OrchestratorExplorer: &OrchestratorExplorerConfig{
@@ -192,7 +198,9 @@ func TestDefaultFeatures(t *testing.T) {
 				PrometheusScrape: &PrometheusScrapeConfig{
 					Enabled: apiutils.NewBoolPointer(false),
 				},
-				NetworkMonitoring: &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(false)},
+				NetworkMonitoring:   &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(false)},
+				OTLP:                &OTLPConfig{Enabled: apiutils.NewBoolPointer(false)},
+				RemoteConfiguration: &RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(false)},
 			},
 		},
 		{
@@ -210,6 +218,11 @@ func TestDefaultFeatures(t *testing.T) {
 					ServiceEndpoints: apiutils.NewBoolPointer(true),
 				},
 				NetworkMonitoring: &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(true)},
+				OTLP: &OTLPConfig{
+					Enabled: apiutils.NewBoolPointer(true),
+					HTTP:    &OTLPProtocolConfig{Enabled: apiutils.NewBoolPointer(false)},
+				},
+				RemoteConfiguration: &RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(false)},
 			},
 			overrideExpected: &DatadogFeatures{
 				OrchestratorExplorer: &OrchestratorExplorerConfig{
@@ -227,6 +240,12 @@ func TestDefaultFeatures(t *testing.T) {
 					Enabled: apiutils.NewBoolPointer(false), // defaultPrometheusScrapeEnabled
 				},
 				NetworkMonitoring: &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(true)},
+				OTLP: &OTLPConfig{
+					Enabled:          apiutils.NewBoolPointer(true),
+					GRPC:             &OTLPProtocolConfig{Enabled: apiutils.NewBoolPointer(true), Port: apiutils.NewInt32Pointer(4317)},
+					UnixDomainSocket: &OTLPUnixDomainSocketConfig{Enabled: apiutils.NewBoolPointer(false)},
+				},
+				RemoteConfiguration: &RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(false)},
 			},
 			internalDefaulted: DatadogFeatures{
 				OrchestratorExplorer: &OrchestratorExplorerConfig{
@@ -245,6 +264,13 @@ func TestDefaultFeatures(t *testing.T) {
 					ServiceEndpoints: apiutils.NewBoolPointer(true),
 				},
 				NetworkMonitoring: &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(true)},
+				OTLP: &OTLPConfig{
+					Enabled:          apiutils.NewBoolPointer(true),
+					GRPC:             &OTLPProtocolConfig{Enabled: apiutils.NewBoolPointer(true), Port: apiutils.NewInt32Pointer(4317)},
+					HTTP:             &OTLPProtocolConfig{Enabled: apiutils.NewBoolPointer(false)},
+					UnixDomainSocket: &OTLPUnixDomainSocketConfig{Enabled: apiutils.NewBoolPointer(false)},
+				},
+				RemoteConfiguration: &RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(false)},
 			},
 		},
 	}
@@ -1064,3 +1090,93 @@ func Test_defaultCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultPlatform(t *testing.T) {
+	customSecurityContext := &corev1.PodSecurityContext{RunAsUser: apiutils.NewInt64Pointer(0)}
+
+	tests := []struct {
+		name     string
+		spec     *DatadogAgentSpec
+		override *NodeAgentConfig
+		want     *NodeAgentConfig
+	}{
+		{
+			name:     "generic platform",
+			spec:     &DatadogAgentSpec{},
+			override: &NodeAgentConfig{},
+			want:     nil,
+		},
+		{
+			name:     "gke-autopilot",
+			spec:     &DatadogAgentSpec{Platform: PlatformGKEAutopilot},
+			override: &NodeAgentConfig{CriSocket: &CRISocketConfig{CriSocketPath: apiutils.NewStringPointer(defaultContainerdSocketPath)}},
+			want:     &NodeAgentConfig{CriSocket: &CRISocketConfig{CriSocketPath: apiutils.NewStringPointer(defaultContainerdSocketPath)}},
+		},
+		{
+			name: "openshift",
+			spec: &DatadogAgentSpec{Platform: PlatformOpenShift},
+			override: &NodeAgentConfig{
+				CriSocket: &CRISocketConfig{CriSocketPath: apiutils.NewStringPointer(defaultCRIOSocketPath)},
+				Kubelet:   &KubeletConfig{TLSVerify: apiutils.NewBoolPointer(false)},
+				SecurityContext: &corev1.PodSecurityContext{
+					SELinuxOptions: &corev1.SELinuxOptions{User: "system_u", Role: "system_r", Type: "spc_t", Level: "s0"},
+				},
+			},
+			want: &NodeAgentConfig{
+				CriSocket: &CRISocketConfig{CriSocketPath: apiutils.NewStringPointer(defaultCRIOSocketPath)},
+				Kubelet:   &KubeletConfig{TLSVerify: apiutils.NewBoolPointer(false)},
+				SecurityContext: &corev1.PodSecurityContext{
+					SELinuxOptions: &corev1.SELinuxOptions{User: "system_u", Role: "system_r", Type: "spc_t", Level: "s0"},
+				},
+			},
+		},
+		{
+			name: "openshift with user settings",
+			spec: &DatadogAgentSpec{
+				Platform: PlatformOpenShift,
+				Agent: DatadogAgentSpecAgentSpec{
+					Config: &NodeAgentConfig{
+						CriSocket:       &CRISocketConfig{CriSocketPath: apiutils.NewStringPointer("/run/containerd.sock")},
+						Kubelet:         &KubeletConfig{TLSVerify: apiutils.NewBoolPointer(true)},
+						SecurityContext: customSecurityContext,
+					},
+				},
+			},
+			override: &NodeAgentConfig{},
+			want: &NodeAgentConfig{
+				CriSocket:       &CRISocketConfig{CriSocketPath: apiutils.NewStringPointer("/run/containerd.sock")},
+				Kubelet:         &KubeletConfig{TLSVerify: apiutils.NewBoolPointer(true)},
+				SecurityContext: customSecurityContext,
+			},
+		},
+		{
+			name: "agent disabled",
+			spec: &DatadogAgentSpec{
+				Platform: PlatformOpenShift,
+				Agent:    DatadogAgentSpecAgentSpec{Enabled: apiutils.NewBoolPointer(false)},
+			},
+			override: &NodeAgentConfig{},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DefaultPlatform(tt.spec)
+			assert.True(t, apiutils.IsEqualStruct(got, tt.override), "DefaultPlatform override \ndiff = %s", cmp.Diff(tt.override, got))
+			assert.True(t, apiutils.IsEqualStruct(tt.spec.Agent.Config, tt.want), "DefaultPlatform spec \ndiff = %s", cmp.Diff(tt.want, tt.spec.Agent.Config))
+		})
+	}
+}
+
+func TestDefaultDatadogAgent_PlatformOverride(t *testing.T) {
+	dda := &DatadogAgent{Spec: DatadogAgentSpec{Platform: PlatformOpenShift}}
+	dso := DefaultDatadogAgent(dda)
+
+	assert.Equal(t, defaultCRIOSocketPath, *dda.Spec.Agent.Config.CriSocket.CriSocketPath)
+	assert.Equal(t, defaultCRIOSocketPath, *dso.DefaultOverride.Agent.Config.CriSocket.CriSocketPath)
+	assert.False(t, *dso.DefaultOverride.Agent.Config.Kubelet.TLSVerify)
+	assert.Equal(t, "spc_t", dso.DefaultOverride.Agent.Config.SecurityContext.SELinuxOptions.Type)
+	// The generic defaults are still applied
+	assert.NotNil(t, dso.DefaultOverride.Agent.Config.LivenessProbe)
+}
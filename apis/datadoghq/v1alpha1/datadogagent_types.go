@@ -27,6 +27,10 @@ type DatadogFeatures struct {
 	NetworkMonitoring *NetworkMonitoringConfig `json:"networkMonitoring,omitempty"`
 	// LogCollection configuration.
 	LogCollection *LogCollectionConfig `json:"logCollection,omitempty"`
+	// OTLP ingest configuration.
+	OTLP *OTLPConfig `json:"otlp,omitempty"`
+	// RemoteConfiguration configuration.
+	RemoteConfiguration *RemoteConfigurationConfig `json:"remoteConfiguration,omitempty"`
 }
 
 // DatadogAgentSpec defines the desired state of DatadogAgent.
@@ -67,6 +71,146 @@ type DatadogAgentSpec struct {
 	// Use docker.io/datadog for DockerHub
 	// +optional
 	Registry *string `json:"registry,omitempty"`
+
+	// Rollback configures the automatic rollback of the Agent, Cluster Agent and Cluster Checks Runner
+	// to their last healthy revision when an update fails.
+	// +optional
+	Rollback *RollbackConfig `json:"rollback,omitempty"`
+
+	// UpdatePolicy configures the automatic update of the default Agent and Cluster Agent versions
+	// from a version catalog. It only applies to the images whose tag is not set in the DatadogAgent.
+	// +optional
+	UpdatePolicy *UpdatePolicy `json:"updatePolicy,omitempty"`
+
+	// ImagePolicy configures the registry mirrors and the digest pinning applied to all the images
+	// rendered by the operator.
+	// +optional
+	ImagePolicy *ImagePolicy `json:"imagePolicy,omitempty"`
+
+	// Platform the Agents are deployed on: generic, gke-autopilot or openshift. The platform applies a set of defaults
+	// suited to the distribution, and the features that cannot run on it are rejected.
+	// When not set, no platform specific configuration is applied.
+	// +optional
+	Platform Platform `json:"platform,omitempty"`
+}
+
+// Platform defines the Kubernetes distribution the Agents are deployed on.
+// +kubebuilder:validation:Enum=generic;gke-autopilot;openshift
+type Platform string
+
+const (
+	// PlatformGeneric applies no platform specific configuration.
+	PlatformGeneric Platform = "generic"
+	// PlatformGKEAutopilot restricts the configuration to the workloads allowed on GKE Autopilot: no privileged
+	// containers, no host network or PID namespace and no hostPath writes.
+	PlatformGKEAutopilot Platform = "gke-autopilot"
+	// PlatformOpenShift configures the Agents for OpenShift: CRI-O runtime socket and SELinux context.
+	PlatformOpenShift Platform = "openshift"
+)
+
+// ImagePolicy contains the configuration of the image references rendered by the operator.
+// +k8s:openapi-gen=true
+type ImagePolicy struct {
+	// Mirrors maps source registries or repositories to mirrors. When several sources match an image,
+	// the longest one is used.
+	// +optional
+	// +listType=map
+	// +listMapKey=source
+	Mirrors []ImageMirror `json:"mirrors,omitempty"`
+
+	// DigestCatalog is the name of the ConfigMap, in the namespace of the DatadogAgent, listing the digests of
+	// the images by reference. It can be the version catalog of the update policy.
+	// +optional
+	DigestCatalog string `json:"digestCatalog,omitempty"`
+
+	// RequireDigest fails the reconciliation when an image cannot be pinned to a digest.
+	// Default value is false.
+	// +optional
+	RequireDigest *bool `json:"requireDigest,omitempty"`
+}
+
+// ImageMirror maps a source registry or repository to a mirror.
+// +k8s:openapi-gen=true
+type ImageMirror struct {
+	// Source registry or repository, for example gcr.io/datadoghq or gcr.io/datadoghq/agent.
+	Source string `json:"source"`
+
+	// Mirror registry or repository replacing the source, for example registry.example.com/datadog.
+	Mirror string `json:"mirror"`
+}
+
+// UpdateChannel defines which versions of the catalog the Agents are updated to.
+// +kubebuilder:validation:Enum=pinned;patch;minor
+type UpdateChannel string
+
+const (
+	// UpdateChannelPinned keeps the version deployed first, the Agents are never updated.
+	UpdateChannelPinned UpdateChannel = "pinned"
+	// UpdateChannelPatch updates the Agents to the latest patch version of their minor version.
+	UpdateChannelPatch UpdateChannel = "patch"
+	// UpdateChannelMinor updates the Agents to the latest minor version of their major version.
+	UpdateChannelMinor UpdateChannel = "minor"
+)
+
+// UpdatePolicy contains the configuration of the automatic updates of the Agent versions.
+// +k8s:openapi-gen=true
+type UpdatePolicy struct {
+	// Channel defines which versions the Agents are updated to: pinned, patch or minor.
+	// Default value is pinned.
+	// +optional
+	Channel UpdateChannel `json:"channel,omitempty"`
+
+	// VersionCatalog is the name of the ConfigMap, in the namespace of the DatadogAgent, listing the available versions.
+	// Default value is datadog-version-catalog.
+	// +optional
+	VersionCatalog string `json:"versionCatalog,omitempty"`
+
+	// MaintenanceWindow restricts the updates to a time window. The Agents can be updated at any time if not set.
+	// +optional
+	MaintenanceWindow *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
+}
+
+// MaintenanceWindow defines a recurring time window.
+// +k8s:openapi-gen=true
+type MaintenanceWindow struct {
+	// Days of the week of the window, for example Saturday. The window is open every day if not set.
+	// +optional
+	// +listType=set
+	Days []string `json:"days,omitempty"`
+
+	// Start time of the window, in UTC, with the HH:MM format.
+	Start string `json:"start"`
+
+	// Duration of the window.
+	Duration metav1.Duration `json:"duration"`
+}
+
+// RollbackConfig contains the configuration of the automatic rollback of failed updates.
+// The rendered pod template of each component is stored in a ControllerRevision. While the pods of a new
+// revision are rolled out, the revision is rolled back to the last healthy one if the failure criteria are met.
+// +k8s:openapi-gen=true
+type RollbackConfig struct {
+	// Enable the automatic rollback of failed updates.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// The number of updated pods in CrashLoopBackOff triggering a rollback.
+	// Value can be an absolute number (ex: 2) or a percentage of the updated pods (ex: 50%).
+	// Default value is 50%.
+	// +optional
+	CrashLoopBackOffThreshold *intstr.IntOrString `json:"crashLoopBackOffThreshold,omitempty"`
+	// The number of updated pods not ready for more than UnreadyTimeout triggering a rollback.
+	// Value can be an absolute number (ex: 2) or a percentage of the updated pods (ex: 50%).
+	// Default value is 50%.
+	// +optional
+	UnreadyThreshold *intstr.IntOrString `json:"unreadyThreshold,omitempty"`
+	// The duration after which an updated pod that is not ready is considered as failed.
+	// Default value is 10min.
+	// +optional
+	UnreadyTimeout *metav1.Duration `json:"unreadyTimeout,omitempty"`
+	// The number of revisions kept for each component.
+	// Default value is 5.
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
 }
 
 // DatadogCredentials is a generic structure that holds credentials to access Datadog.
@@ -168,11 +312,11 @@ type DatadogAgentSpecAgentSpec struct {
 	AdditionalLabels map[string]string `json:"additionalLabels,omitempty"`
 
 	// KeepLabels allows the specification of labels not managed by the Operator that will be kept on Agent DaemonSet.
-	// All labels containing 'datadoghq.com' are always included. This field uses glob syntax.
+	// All labels containing 'datadoghq.com', and the ones set by Kubernetes, are always included. This field uses glob syntax.
 	KeepLabels string `json:"keepLabels,omitempty"`
 
 	// KeepAnnotations allows the specification of annotations not managed by the Operator that will be kept on Agent DaemonSet.
-	// All annotations containing 'datadoghq.com' are always included. This field uses glob syntax.
+	// All annotations containing 'datadoghq.com', and the ones set by Kubernetes, are always included. This field uses glob syntax.
 	KeepAnnotations string `json:"keepAnnotations,omitempty"`
 
 	// If specified, indicates the pod's priority. "system-node-critical" and "system-cluster-critical"
@@ -251,6 +395,25 @@ type DatadogAgentSpecAgentSpec struct {
 	// Options to customize the internal traffic policy service
 	// +optional
 	LocalService *LocalService `json:"localService,omitempty"`
+
+	// SidecarInjection configures the injection of the Agent as a sidecar container in the pods running on
+	// serverless nodes (EKS Fargate, virtual-kubelet), where the Agent DaemonSet cannot run.
+	// +optional
+	SidecarInjection *AgentSidecarInjectionConfig `json:"sidecarInjection,omitempty"`
+
+	// Windows configures a second Agent DaemonSet running on the Windows nodes of the cluster.
+	// +optional
+	Windows *WindowsAgentConfig `json:"windows,omitempty"`
+
+	// SecurityContextConstraints configures the OpenShift SecurityContextConstraints created for the Agent.
+	// +optional
+	SecurityContextConstraints *SecurityContextConstraintsConfig `json:"securityContextConstraints,omitempty"`
+
+	// DisjointNodes declares that the Agents of this DatadogAgent run on nodes not shared with the other DatadogAgents of
+	// the cluster, for instance nodes selected with taints and tolerations. The node and host port conflicts with the
+	// other DatadogAgents are not checked.
+	// +optional
+	DisjointNodes *bool `json:"disjointNodes,omitempty"`
 }
 
 // RbacConfig contains RBAC configuration.
@@ -275,6 +438,39 @@ type DaemonSetDeploymentStrategy struct {
 	Canary *edsdatadoghqv1alpha1.ExtendedDaemonSetSpecStrategyCanary `json:"canary,omitempty"`
 	// The reconcile frequency of the ExtendDaemonSet.
 	ReconcileFrequency *metav1.Duration `json:"reconcileFrequency,omitempty"`
+	// Configure a staged rollout of the DaemonSet driven by the Operator, when the ExtendedDaemonSet is not used.
+	StagedRollout *DaemonSetStagedRolloutSpec `json:"stagedRollout,omitempty"`
+}
+
+// DaemonSetStagedRolloutSpec contains the configuration of the staged rollout of the Agent DaemonSet.
+// The DaemonSet uses the OnDelete update strategy, and the Operator deletes the outdated Agent pods
+// batch by batch, once the pods of the previous batch are ready and healthy.
+// +k8s:openapi-gen=true
+type DaemonSetStagedRolloutSpec struct {
+	// Enable the staged rollout of the DaemonSet.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// The number of pods updated in the first batch, used as canary.
+	// Value can be an absolute number (ex: 1) or a percentage of total
+	// number of DaemonSet pods (ex: 5%).
+	// Default value is 1.
+	// +optional
+	CanaryReplicas *intstr.IntOrString `json:"canaryReplicas,omitempty"`
+	// The number of pods updated in each of the following batches.
+	// Value can be an absolute number (ex: 5) or a percentage of total
+	// number of DaemonSet pods (ex: 10%). Absolute number is calculated from percentage by rounding up.
+	// Default value is 25%.
+	// +optional
+	BatchSize *intstr.IntOrString `json:"batchSize,omitempty"`
+	// The duration to wait, once the pods of a batch are ready, before updating the next batch.
+	// Default value is 1min.
+	// +optional
+	PauseDuration *metav1.Duration `json:"pauseDuration,omitempty"`
+	// The maximum number of container restarts allowed for an updated pod. The rollout is halted
+	// when an updated pod restarts more often, until a new configuration is applied.
+	// Default value is 2.
+	// +optional
+	MaxRestarts *int32 `json:"maxRestarts,omitempty"`
 }
 
 // DaemonSetRollingUpdateSpec contains configuration fields of the rolling update strategy.
@@ -419,6 +615,8 @@ type LogCollectionConfig struct {
 	// This path (always mounted from the host) is used by Datadog Agent to store information about processed log files.
 	// If the Datadog Agent is restarted, it starts tailing the log files immediately.
 	// Default to `/var/lib/datadog-agent/logs`
+	// Ignored on the gke-autopilot platform, which doesn't allow writing to the host: the information is stored in an
+	// emptyDir volume and lost when the Agent pod is recreated.
 	//
 	// +optional
 	TempStoragePath *string `json:"tempStoragePath,omitempty"`
@@ -557,6 +755,72 @@ type NetworkMonitoringConfig struct {
 	Enabled *bool `json:"enabled,omitempty"`
 }
 
+// OTLPConfig contains the configuration of the OpenTelemetry Protocol (OTLP) ingest in the Agent.
+// See also: https://docs.datadoghq.com/tracing/setup_overview/open_standards/#otlp-ingest-in-datadog-agent
+// +k8s:openapi-gen=true
+type OTLPConfig struct {
+	// Enable this option to activate the OTLP ingest in the Agent.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// GRPC configures the OTLP/gRPC receiver.
+	// +optional
+	GRPC *OTLPProtocolConfig `json:"grpc,omitempty"`
+
+	// HTTP configures the OTLP/HTTP receiver.
+	// +optional
+	HTTP *OTLPProtocolConfig `json:"http,omitempty"`
+
+	// UnixDomainSocket configures the OTLP/gRPC receiver to listen on a Unix Domain Socket
+	// instead of its TCP port.
+	// +optional
+	UnixDomainSocket *OTLPUnixDomainSocketConfig `json:"unixDomainSocket,omitempty"`
+}
+
+// OTLPProtocolConfig contains the configuration of an OTLP receiver.
+// +k8s:openapi-gen=true
+type OTLPProtocolConfig struct {
+	// Enable the receiver.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Port of the receiver in the Agent pod.
+	// (default value: 4317 for gRPC, 4318 for HTTP)
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// Number of port to expose on the host.
+	// If specified, this must be a valid port number, 0 < x < 65536.
+	// If HostNetwork is specified, this must match Port.
+	// +optional
+	HostPort *int32 `json:"hostPort,omitempty"`
+}
+
+// OTLPUnixDomainSocketConfig contains the configuration of the OTLP/gRPC receiver over Unix Domain Socket.
+// +k8s:openapi-gen=true
+type OTLPUnixDomainSocketConfig struct {
+	// Enable the OTLP/gRPC receiver over Unix Domain Socket.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Define the host OTLP socket filepath used when the OTLP/gRPC receiver over Unix Domain Socket is enabled.
+	// (default value: /var/run/datadog/otlp.sock)
+	// +optional
+	HostFilepath *string `json:"hostFilepath,omitempty"`
+}
+
+// RemoteConfigurationConfig contains the configuration of the Remote Configuration of the Agent, Cluster Agent
+// and Security Agent, which allows managing APM sampling and CWS rules from the Datadog UI.
+// The API key must have the Remote Configuration scope.
+// See also: https://docs.datadoghq.com/agent/guide/how_rc_works
+// +k8s:openapi-gen=true
+type RemoteConfigurationConfig struct {
+	// Enable this option to activate the Remote Configuration.
+	// Requires the Agent and Cluster Agent 7.41.0 or later.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
 // SystemProbeSpec contains the SystemProbe Agent configuration.
 // +k8s:openapi-gen=true
 type SystemProbeSpec struct {
@@ -970,8 +1234,14 @@ type DatadogAgentSpecClusterAgentSpec struct {
 	Rbac *RbacConfig `json:"rbac,omitempty"`
 
 	// Number of the Cluster Agent replicas.
+	// When set, the Operator owns the number of replicas and reverts manual scaling, leave it unset to scale the Deployment manually.
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// Autoscaling configures a HorizontalPodAutoscaler for the Cluster Agent Deployment.
+	// When enabled, the replicas are managed by the HorizontalPodAutoscaler and Replicas is ignored.
+	// +optional
+	Autoscaling *AutoscalingConfig `json:"autoscaling,omitempty"`
+
 	// AdditionalAnnotations provide annotations that will be added to the Cluster Agent Pods.
 	AdditionalAnnotations map[string]string `json:"additionalAnnotations,omitempty"`
 
@@ -979,11 +1249,11 @@ type DatadogAgentSpecClusterAgentSpec struct {
 	AdditionalLabels map[string]string `json:"additionalLabels,omitempty"`
 
 	// KeepLabels allows the specification of labels not managed by the Operator that will be kept on ClusterAgent Deployment.
-	// All labels containing 'datadoghq.com' are always included. This field uses glob syntax.
+	// All labels containing 'datadoghq.com', and the ones set by Kubernetes, are always included. This field uses glob syntax.
 	KeepLabels string `json:"keepLabels,omitempty"`
 
 	// KeepAnnotations allows the specification of annotations not managed by the Operator that will be kept on ClusterAgent Deployment.
-	// All annotations containing 'datadoghq.com' are always included. This field uses glob syntax.
+	// All annotations containing 'datadoghq.com', and the ones set by Kubernetes, are always included. This field uses glob syntax.
 	KeepAnnotations string `json:"keepAnnotations,omitempty"`
 
 	// If specified, indicates the pod's priority. "system-node-critical" and "system-cluster-critical"
@@ -1001,6 +1271,16 @@ type DatadogAgentSpecClusterAgentSpec struct {
 	// +listType=atomic
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// TopologySpreadConstraints describes how the Cluster Agent pods are spread across the topology domains.
+	// If not set, the pods are spread across the zones (topology.kubernetes.io/zone) when possible.
+	// +optional
+	// +listType=atomic
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the Cluster Agent pods.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetConfig `json:"podDisruptionBudget,omitempty"`
+
 	// NodeSelector is a selector which must be true for the pod to fit on a node.
 	// Selector which must match a node's labels for the pod to be scheduled on that node.
 	// More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/
@@ -1203,8 +1483,14 @@ type DatadogAgentSpecClusterChecksRunnerSpec struct {
 	Rbac *RbacConfig `json:"rbac,omitempty"`
 
 	// Number of the Cluster Checks Runner replicas.
+	// When set, the Operator owns the number of replicas and reverts manual scaling, leave it unset to scale the Deployment manually.
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// Autoscaling configures a HorizontalPodAutoscaler for the Cluster Checks Runner Deployment.
+	// When enabled, the replicas are managed by the HorizontalPodAutoscaler and Replicas is ignored.
+	// +optional
+	Autoscaling *AutoscalingConfig `json:"autoscaling,omitempty"`
+
 	// AdditionalAnnotations provide annotations that will be added to the cluster checks runner Pods.
 	AdditionalAnnotations map[string]string `json:"additionalAnnotations,omitempty"`
 
@@ -1226,6 +1512,16 @@ type DatadogAgentSpecClusterChecksRunnerSpec struct {
 	// +listType=atomic
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// TopologySpreadConstraints describes how the Cluster Checks Runner pods are spread across the topology domains.
+	// If not set, the pods are spread across the zones (topology.kubernetes.io/zone) when possible.
+	// +optional
+	// +listType=atomic
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PodDisruptionBudget configures the PodDisruptionBudget of the Cluster Checks Runner pods.
+	// +optional
+	PodDisruptionBudget *PodDisruptionBudgetConfig `json:"podDisruptionBudget,omitempty"`
+
 	// NodeSelector is a selector which must be true for the pod to fit on a node.
 	// Selector which must match a node's labels for the pod to be scheduled on that node.
 	// More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/
@@ -1237,6 +1533,53 @@ type DatadogAgentSpecClusterChecksRunnerSpec struct {
 	NetworkPolicy *NetworkPolicySpec `json:"networkPolicy,omitempty"`
 }
 
+// AutoscalingConfig contains the configuration of a HorizontalPodAutoscaler managed by the operator.
+// +k8s:openapi-gen=true
+type AutoscalingConfig struct {
+	// Enable the HorizontalPodAutoscaler.
+	// Default value is false.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MinReplicas is the lower limit of the number of replicas.
+	// Default value is 1.
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit of the number of replicas.
+	// Default value is 5.
+	// +optional
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// TargetCPUUtilizationPercentage is the target average CPU utilization of the pods, in percentage of their requests.
+	// Default value is 80 when no target is set.
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage is the target average memory utilization of the pods, in percentage of their requests.
+	// +optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+
+	// TargetClusterChecksPerRunner is the target average number of cluster checks dispatched to each runner.
+	// Only supported by the Cluster Checks Runner, it requires the external metrics provider of the Cluster Agent.
+	// +optional
+	TargetClusterChecksPerRunner *int32 `json:"targetClusterChecksPerRunner,omitempty"`
+}
+
+// PodDisruptionBudgetConfig contains the configuration of a PodDisruptionBudget managed by the operator.
+// +k8s:openapi-gen=true
+type PodDisruptionBudgetConfig struct {
+	// MinAvailable is the number, or percentage, of pods that must remain available during a voluntary disruption.
+	// Default value is 50%, rounded up, when MaxUnavailable is not set.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is the number, or percentage, of pods that can be unavailable during a voluntary disruption.
+	// Cannot be set with MinAvailable.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
 // ImageConfig Datadog Agent container image config.
 // +k8s:openapi-gen=true
 type ImageConfig struct {
@@ -1253,6 +1596,12 @@ type ImageConfig struct {
 	// +optional
 	Tag string `json:"tag,omitempty"`
 
+	// Define the image digest to pin the image to, for example sha256:<hex>.
+	// Takes precedence over the digests of the image policy catalog.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	Digest string `json:"digest,omitempty"`
+
 	// Define whether the Agent image should support JMX.
 	// +optional
 	JmxEnabled bool `json:"jmxEnabled,omitempty"`
@@ -1307,6 +1656,65 @@ type LocalService struct {
 	ForceLocalServiceEnable *bool `json:"forceLocalServiceEnable,omitempty"`
 }
 
+// AgentSidecarInjectionConfig contains the configuration of the Agent sidecar injection.
+// A pod is injected when it is created in one of the Namespaces and either its nodeSelector contains the NodeSelector labels,
+// or it has the `agent.datadoghq.com/sidecar` label set to the Profile.
+// +k8s:openapi-gen=true
+type AgentSidecarInjectionConfig struct {
+	// Enable the injection of the Agent sidecar by the operator admission webhook.
+	// The operator must be started with the `-sidecarInjectionEnabled` flag to serve the webhook.
+	// Default: false
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// NodeSelector selects the pods scheduled on serverless nodes by their nodeSelector.
+	// Default: `eks.amazonaws.com/compute-type: fargate`
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Profile selects the pods with the `agent.datadoghq.com/sidecar` label set to this value.
+	// Default: `fargate`
+	// +optional
+	Profile *string `json:"profile,omitempty"`
+
+	// Namespaces where the Agent sidecar is injected. The service accounts of these namespaces are granted the access to the Kubelet API.
+	// Default: the DatadogAgent namespace
+	// +optional
+	// +listType=set
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Resources of the Agent sidecar container, the Agent DaemonSet container resources are used by default.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// WindowsAgentConfig contains the configuration of the Agent DaemonSet deployed on the Windows nodes.
+// The Windows Agent shares the Agent configuration, the Linux-only components (System Probe, Security Agent,
+// Process Agent) are not deployed.
+// +k8s:openapi-gen=true
+type WindowsAgentConfig struct {
+	// Enable the deployment of the Windows Agent DaemonSet.
+	// Default: false
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Image of the Windows Agent, the Agent image is used by default as it is published for Windows.
+	// +optional
+	Image *ImageConfig `json:"image,omitempty"`
+}
+
+// SecurityContextConstraintsConfig contains the configuration of the OpenShift SecurityContextConstraints
+// allowing the Agent pods to run. The SecurityContextConstraints only grants the privileges needed by
+// the enabled features, it is updated when the features change.
+// +k8s:openapi-gen=true
+type SecurityContextConstraintsConfig struct {
+	// Create the SecurityContextConstraints used by the Agent service account.
+	// Requires the operator to be started with the `supportOpenShiftSCC` option.
+	// Default: false
+	// +optional
+	Create *bool `json:"create,omitempty"`
+}
+
 // DatadogAgentState type representing the deployment state of the different Agent components.
 type DatadogAgentState string
 
@@ -1335,6 +1743,10 @@ type DatadogAgentStatus struct {
 	// +optional
 	Agent *DaemonSetStatus `json:"agent,omitempty"`
 
+	// The actual state of the Windows Agent as a daemonset.
+	// +optional
+	AgentWindows *DaemonSetStatus `json:"agentWindows,omitempty"`
+
 	// The actual state of the Cluster Agent as a deployment.
 	// +optional
 	ClusterAgent *DeploymentStatus `json:"clusterAgent,omitempty"`
@@ -1347,6 +1759,38 @@ type DatadogAgentStatus struct {
 	// +listType=map
 	// +listMapKey=type
 	Conditions []DatadogAgentCondition `json:"conditions,omitempty"`
+
+	// Versions reports the versions selected by the update policy, when configured.
+	// +optional
+	Versions *VersionsStatus `json:"versions,omitempty"`
+}
+
+// VersionsStatus reports the versions of the Agents managed by the update policy.
+// +k8s:openapi-gen=true
+type VersionsStatus struct {
+	// Agent versions of the Agent and of the Cluster Checks Runner.
+	// +optional
+	Agent *ComponentVersionStatus `json:"agent,omitempty"`
+
+	// ClusterAgent versions of the Cluster Agent.
+	// +optional
+	ClusterAgent *ComponentVersionStatus `json:"clusterAgent,omitempty"`
+}
+
+// ComponentVersionStatus reports the versions of a component managed by the update policy.
+// +k8s:openapi-gen=true
+type ComponentVersionStatus struct {
+	// Current is the version deployed.
+	Current string `json:"current,omitempty"`
+
+	// Target is the version the channel updates to, during the next maintenance window.
+	Target string `json:"target,omitempty"`
+
+	// Available is the latest version of the catalog, whatever the channel.
+	Available string `json:"available,omitempty"`
+
+	// LastUpdateTime is the last time the current version changed.
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 }
 
 // DaemonSetStatus defines the observed state of Agent running as DaemonSet.
@@ -1365,6 +1809,41 @@ type DaemonSetStatus struct {
 
 	// DaemonsetName corresponds to the name of the created DaemonSet.
 	DaemonsetName string `json:"daemonsetName,omitempty"`
+
+	// StagedRollout reports the progress of the staged rollout of the DaemonSet, when enabled.
+	// +optional
+	StagedRollout *StagedRolloutStatus `json:"stagedRollout,omitempty"`
+}
+
+// StagedRolloutPhase is the phase of a staged rollout.
+type StagedRolloutPhase string
+
+const (
+	// StagedRolloutPhaseProgressing the outdated pods are being updated batch by batch.
+	StagedRolloutPhaseProgressing StagedRolloutPhase = "Progressing"
+	// StagedRolloutPhaseHalted an updated pod is not healthy, the rollout is halted until a new configuration is applied.
+	StagedRolloutPhaseHalted StagedRolloutPhase = "Halted"
+	// StagedRolloutPhaseCompleted all the pods run the current revision of the DaemonSet.
+	StagedRolloutPhaseCompleted StagedRolloutPhase = "Completed"
+)
+
+// StagedRolloutStatus reports the progress of the staged rollout of the Agent DaemonSet.
+// +k8s:openapi-gen=true
+type StagedRolloutStatus struct {
+	// Phase of the rollout.
+	Phase StagedRolloutPhase `json:"phase,omitempty"`
+	// Revision is the DaemonSet revision (controller-revision-hash) being rolled out.
+	Revision string `json:"revision,omitempty"`
+	// Batch is the number of batches of pods deleted for the current revision.
+	Batch int32 `json:"batch,omitempty"`
+	// UpdatedPods is the number of pods running the current revision.
+	UpdatedPods int32 `json:"updatedPods,omitempty"`
+	// TotalPods is the total number of pods of the DaemonSet.
+	TotalPods int32 `json:"totalPods,omitempty"`
+	// LastBatchTime is the time the last batch of pods was deleted.
+	LastBatchTime *metav1.Time `json:"lastBatchTime,omitempty"`
+	// Message gives details about the state of the rollout.
+	Message string `json:"message,omitempty"`
 }
 
 // DeploymentStatus type representing the Cluster Agent Deployment status.
@@ -1440,6 +1919,14 @@ const (
 	DatadogAgentConditionTypeReconcileError DatadogAgentConditionType = "ReconcileError"
 	// DatadogAgentConditionTypeSecretError the required Secret doesn't exist.
 	DatadogAgentConditionTypeSecretError DatadogAgentConditionType = "SecretError"
+	// DatadogAgentConditionTypeResourceConflict fields of the managed resources were managed by another field manager and have been overridden.
+	DatadogAgentConditionTypeResourceConflict DatadogAgentConditionType = "ResourceConflict"
+	// DatadogAgentConditionTypeRolledBack a component was rolled back to its last healthy revision after a failed update.
+	DatadogAgentConditionTypeRolledBack DatadogAgentConditionType = "RolledBack"
+	// DatadogAgentConditionTypePodSecurityViolation pods of the DatadogAgent would be rejected by the Pod Security admission of the namespace.
+	DatadogAgentConditionTypePodSecurityViolation DatadogAgentConditionType = "PodSecurityViolation"
+	// DatadogAgentConditionTypeConflict the DatadogAgent conflicts with an older DatadogAgent and is not reconciled.
+	DatadogAgentConditionTypeConflict DatadogAgentConditionType = "Conflict"
 
 	// DatadogMetricsActive forwarding metrics and events to Datadog is active.
 	DatadogMetricsActive DatadogAgentConditionType = "ActiveDatadogMetrics"
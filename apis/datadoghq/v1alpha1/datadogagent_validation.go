@@ -7,11 +7,36 @@ package v1alpha1
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-operator/apis/utils"
+	"github.com/DataDog/datadog-operator/pkg/defaulting"
+	pkgutils "github.com/DataDog/datadog-operator/pkg/utils"
 	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
+const (
+	// MaintenanceWindowStartFormat is the time format of MaintenanceWindow.Start
+	MaintenanceWindowStartFormat = "15:04"
+	// RemoteConfigurationMinAgentVersion is the minimum version of the Agent supporting the Remote Configuration
+	RemoteConfigurationMinAgentVersion = "7.41.0-0"
+	// RemoteConfigurationMinClusterAgentVersion is the minimum version of the Cluster Agent supporting the Remote Configuration
+	RemoteConfigurationMinClusterAgentVersion = "7.41.0-0"
+)
+
+// Weekdays maps the days of a MaintenanceWindow to time.Weekday
+var Weekdays = map[string]time.Weekday{
+	time.Sunday.String():    time.Sunday,
+	time.Monday.String():    time.Monday,
+	time.Tuesday.String():   time.Tuesday,
+	time.Wednesday.String(): time.Wednesday,
+	time.Thursday.String():  time.Thursday,
+	time.Friday.String():    time.Friday,
+	time.Saturday.String():  time.Saturday,
+}
+
 // IsValidDatadogAgent use to check if a DatadogAgentSpec is valid
 func IsValidDatadogAgent(spec *DatadogAgentSpec) error {
 	var errs []error
@@ -28,6 +53,12 @@ func IsValidDatadogAgent(spec *DatadogAgentSpec) error {
 				errs = append(errs, fmt.Errorf("invalid spec.agent.systemProbe.customConfig, err: %w", err))
 			}
 		}
+
+		if spec.Agent.SidecarInjection != nil {
+			if err = IsValidAgentSidecarInjectionConfig(spec.Agent.SidecarInjection); err != nil {
+				errs = append(errs, fmt.Errorf("invalid spec.agent.sidecarInjection, err: %w", err))
+			}
+		}
 	}
 
 	if utils.BoolValue(spec.ClusterAgent.Enabled) {
@@ -36,6 +67,20 @@ func IsValidDatadogAgent(spec *DatadogAgentSpec) error {
 				errs = append(errs, fmt.Errorf("invalid spec.clusterAgent.customConfig, err: %w", err))
 			}
 		}
+
+		if spec.ClusterAgent.Autoscaling != nil {
+			if err = IsValidAutoscalingConfig(spec.ClusterAgent.Autoscaling); err != nil {
+				errs = append(errs, fmt.Errorf("invalid spec.clusterAgent.autoscaling, err: %w", err))
+			} else if spec.ClusterAgent.Autoscaling.TargetClusterChecksPerRunner != nil {
+				errs = append(errs, fmt.Errorf("invalid spec.clusterAgent.autoscaling, err: 'targetClusterChecksPerRunner' is only supported by the Cluster Checks Runner"))
+			}
+		}
+
+		if spec.ClusterAgent.PodDisruptionBudget != nil {
+			if err = IsValidPodDisruptionBudgetConfig(spec.ClusterAgent.PodDisruptionBudget); err != nil {
+				errs = append(errs, fmt.Errorf("invalid spec.clusterAgent.podDisruptionBudget, err: %w", err))
+			}
+		}
 	}
 
 	if utils.BoolValue(spec.ClusterChecksRunner.Enabled) {
@@ -44,6 +89,32 @@ func IsValidDatadogAgent(spec *DatadogAgentSpec) error {
 				errs = append(errs, fmt.Errorf("invalid spec.clusterChecksRunner.customConfig, err: %w", err))
 			}
 		}
+
+		if spec.ClusterChecksRunner.Autoscaling != nil {
+			if err = IsValidAutoscalingConfig(spec.ClusterChecksRunner.Autoscaling); err != nil {
+				errs = append(errs, fmt.Errorf("invalid spec.clusterChecksRunner.autoscaling, err: %w", err))
+			} else if spec.ClusterChecksRunner.Autoscaling.TargetClusterChecksPerRunner != nil && !isExternalMetricsEnabled(spec.ClusterAgent.Config) {
+				errs = append(errs, fmt.Errorf("invalid spec.clusterChecksRunner.autoscaling, err: 'targetClusterChecksPerRunner' requires spec.clusterAgent.config.externalMetrics.enabled"))
+			}
+		}
+
+		if spec.ClusterChecksRunner.PodDisruptionBudget != nil {
+			if err = IsValidPodDisruptionBudgetConfig(spec.ClusterChecksRunner.PodDisruptionBudget); err != nil {
+				errs = append(errs, fmt.Errorf("invalid spec.clusterChecksRunner.podDisruptionBudget, err: %w", err))
+			}
+		}
+	}
+
+	if spec.Features.OTLP != nil && utils.BoolValue(spec.Features.OTLP.Enabled) {
+		if err = IsValidOTLPConfig(spec.Features.OTLP); err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec.features.otlp, err: %w", err))
+		}
+	}
+
+	if spec.Features.RemoteConfiguration != nil && utils.BoolValue(spec.Features.RemoteConfiguration.Enabled) {
+		if err = IsValidRemoteConfiguration(spec); err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec.features.remoteConfiguration, err: %w", err))
+		}
 	}
 
 	if spec.Features.KubeStateMetricsCore != nil {
@@ -54,9 +125,198 @@ func IsValidDatadogAgent(spec *DatadogAgentSpec) error {
 		}
 	}
 
+	if spec.UpdatePolicy != nil && spec.UpdatePolicy.MaintenanceWindow != nil {
+		if err = IsValidMaintenanceWindow(spec.UpdatePolicy.MaintenanceWindow); err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec.updatePolicy.maintenanceWindow, err: %w", err))
+		}
+	}
+
+	if spec.ImagePolicy != nil {
+		if err = IsValidImagePolicy(spec.ImagePolicy); err != nil {
+			errs = append(errs, fmt.Errorf("invalid spec.imagePolicy, err: %w", err))
+		}
+	}
+
+	if spec.Platform == PlatformGKEAutopilot {
+		errs = append(errs, isValidGKEAutopilotSpec(spec)...)
+	}
+
 	return utilserrors.NewAggregate(errs)
 }
 
+// isValidGKEAutopilotSpec rejects the features that cannot run on GKE Autopilot, which forbids privileged containers,
+// the host network and PID namespaces and the hostPath writes
+func isValidGKEAutopilotSpec(spec *DatadogAgentSpec) []error {
+	var errs []error
+	notSupported := func(field, reason string) {
+		errs = append(errs, fmt.Errorf("invalid %s, err: not supported on the %s platform, %s", field, PlatformGKEAutopilot, reason))
+	}
+
+	if spec.Features.NetworkMonitoring != nil && utils.BoolValue(spec.Features.NetworkMonitoring.Enabled) {
+		notSupported("spec.features.networkMonitoring", "it requires the System Probe")
+	}
+
+	if spec.Agent.Enabled != nil && !*spec.Agent.Enabled {
+		return errs
+	}
+
+	agent := spec.Agent
+	if agent.SystemProbe != nil && utils.BoolValue(agent.SystemProbe.Enabled) {
+		notSupported("spec.agent.systemProbe", "it requires privileged capabilities")
+	}
+	if agent.Security != nil && (utils.BoolValue(agent.Security.Compliance.Enabled) || utils.BoolValue(agent.Security.Runtime.Enabled)) {
+		notSupported("spec.agent.security", "the Security Agent requires privileged capabilities")
+	}
+	if agent.HostNetwork {
+		notSupported("spec.agent.hostNetwork", "the host network namespace is not allowed")
+	}
+	if agent.HostPID {
+		notSupported("spec.agent.hostPID", "the host PID namespace is not allowed")
+	}
+	if agent.Config != nil && agent.Config.Dogstatsd != nil && agent.Config.Dogstatsd.UnixDomainSocket != nil && utils.BoolValue(agent.Config.Dogstatsd.UnixDomainSocket.Enabled) {
+		notSupported("spec.agent.config.dogstatsd.unixDomainSocket", "the socket cannot be created on the host")
+	}
+	if agent.Apm != nil && agent.Apm.UnixDomainSocket != nil && utils.BoolValue(agent.Apm.UnixDomainSocket.Enabled) {
+		notSupported("spec.agent.apm.unixDomainSocket", "the socket cannot be created on the host")
+	}
+	if spec.Features.OTLP != nil && utils.BoolValue(spec.Features.OTLP.Enabled) && spec.Features.OTLP.UnixDomainSocket != nil && utils.BoolValue(spec.Features.OTLP.UnixDomainSocket.Enabled) {
+		notSupported("spec.features.otlp.unixDomainSocket", "the socket cannot be created on the host")
+	}
+	if agent.Windows != nil && utils.BoolValue(agent.Windows.Enabled) {
+		notSupported("spec.agent.windows", "the Windows nodes are not available")
+	}
+
+	return errs
+}
+
+// IsValidAutoscalingConfig used to check if an AutoscalingConfig is properly set
+func IsValidAutoscalingConfig(autoscaling *AutoscalingConfig) error {
+	if autoscaling.MinReplicas != nil && *autoscaling.MinReplicas < 1 {
+		return fmt.Errorf("'minReplicas' should be at least 1")
+	}
+	if autoscaling.MinReplicas != nil && autoscaling.MaxReplicas != nil && *autoscaling.MaxReplicas < *autoscaling.MinReplicas {
+		return fmt.Errorf("'maxReplicas' should be greater than or equal to 'minReplicas'")
+	}
+
+	return nil
+}
+
+// IsValidPodDisruptionBudgetConfig used to check if a PodDisruptionBudgetConfig is properly set
+func IsValidPodDisruptionBudgetConfig(pdb *PodDisruptionBudgetConfig) error {
+	if pdb.MinAvailable != nil && pdb.MaxUnavailable != nil {
+		return fmt.Errorf("'minAvailable' and 'maxUnavailable' cannot be both set")
+	}
+
+	return nil
+}
+
+// IsValidAgentSidecarInjectionConfig used to check if an AgentSidecarInjectionConfig is properly set
+func IsValidAgentSidecarInjectionConfig(sidecar *AgentSidecarInjectionConfig) error {
+	if sidecar.NodeSelector != nil && len(sidecar.NodeSelector) == 0 {
+		return fmt.Errorf("'nodeSelector' cannot be empty, it would select all the pods")
+	}
+	if sidecar.Profile != nil && *sidecar.Profile == "" {
+		return fmt.Errorf("'profile' cannot be empty")
+	}
+	for _, namespace := range sidecar.Namespaces {
+		if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(msgs, ", "))
+		}
+	}
+
+	return nil
+}
+
+// IsValidOTLPConfig used to check if an OTLPConfig is properly set
+func IsValidOTLPConfig(otlp *OTLPConfig) error {
+	grpcDisabled := otlp.GRPC != nil && otlp.GRPC.Enabled != nil && !*otlp.GRPC.Enabled
+	httpDisabled := otlp.HTTP != nil && otlp.HTTP.Enabled != nil && !*otlp.HTTP.Enabled
+	if grpcDisabled && httpDisabled {
+		return fmt.Errorf("at least one of 'grpc' and 'http' should be enabled")
+	}
+	if grpcDisabled && otlp.UnixDomainSocket != nil && utils.BoolValue(otlp.UnixDomainSocket.Enabled) {
+		return fmt.Errorf("'unixDomainSocket' requires 'grpc' to be enabled")
+	}
+
+	return nil
+}
+
+// IsValidRemoteConfiguration used to check if the components support the Remote Configuration
+func IsValidRemoteConfiguration(spec *DatadogAgentSpec) error {
+	// Check against the image tag + "-0", otherwise prerelease versions are not compared.
+	if utils.BoolValue(spec.Agent.Enabled) {
+		tag := strings.TrimSuffix(getImageTag(spec.Agent.Image, defaulting.AgentLatestVersion), defaulting.JMXTagSuffix)
+		if !isSupportedVersion(tag, RemoteConfigurationMinAgentVersion) {
+			return fmt.Errorf("the Agent version %s doesn't support the Remote Configuration, the minimum version is %s", tag, strings.TrimSuffix(RemoteConfigurationMinAgentVersion, "-0"))
+		}
+	}
+	if utils.BoolValue(spec.ClusterAgent.Enabled) {
+		tag := getImageTag(spec.ClusterAgent.Image, defaulting.ClusterAgentLatestVersion)
+		if !isSupportedVersion(tag, RemoteConfigurationMinClusterAgentVersion) {
+			return fmt.Errorf("the Cluster Agent version %s doesn't support the Remote Configuration, the minimum version is %s", tag, strings.TrimSuffix(RemoteConfigurationMinClusterAgentVersion, "-0"))
+		}
+	}
+
+	return nil
+}
+
+// getImageTag returns the tag of an image, or the default tag if it is not set yet
+func getImageTag(image *ImageConfig, defaultTag string) string {
+	switch {
+	case image == nil:
+		return defaultTag
+	case defaulting.IsImageNameContainsTag(image.Name):
+		return pkgutils.GetTagFromImageName(image.Name)
+	case image.Tag != "":
+		return image.Tag
+	default:
+		return defaultTag
+	}
+}
+
+func isSupportedVersion(tag, minVersion string) bool {
+	return tag == "latest" || pkgutils.IsAboveMinVersion(tag, minVersion)
+}
+
+func isExternalMetricsEnabled(config *ClusterAgentConfig) bool {
+	return config != nil && config.ExternalMetrics != nil && utils.BoolValue(config.ExternalMetrics.Enabled)
+}
+
+// IsValidImagePolicy used to check if an ImagePolicy is properly set
+func IsValidImagePolicy(policy *ImagePolicy) error {
+	for _, mirror := range policy.Mirrors {
+		if mirror.Source == "" || mirror.Mirror == "" {
+			return fmt.Errorf("'source' and 'mirror' should be set in mirrors")
+		}
+		for _, ref := range []string{mirror.Source, mirror.Mirror} {
+			// A colon is only allowed in the registry host, for its port
+			hasTag := strings.Contains(ref, "/") && strings.Contains(ref[strings.LastIndex(ref, "/"):], ":")
+			if hasTag || strings.Contains(ref, "@") || strings.HasSuffix(ref, "/") {
+				return fmt.Errorf("%q should be a registry or a repository, without tag, digest or trailing slash", ref)
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsValidMaintenanceWindow used to check if a MaintenanceWindow is properly set
+func IsValidMaintenanceWindow(window *MaintenanceWindow) error {
+	if _, err := time.Parse(MaintenanceWindowStartFormat, window.Start); err != nil {
+		return fmt.Errorf("'start' should use the HH:MM format: %w", err)
+	}
+	if window.Duration.Duration <= 0 {
+		return fmt.Errorf("'duration' should be positive")
+	}
+	for _, day := range window.Days {
+		if _, found := Weekdays[day]; !found {
+			return fmt.Errorf("unknown day %q", day)
+		}
+	}
+
+	return nil
+}
+
 // IsValidCustomConfigSpec used to check if a CustomConfigSpec is properly set
 func IsValidCustomConfigSpec(ccs *CustomConfigSpec) error {
 	if ccs.ConfigData != nil && ccs.ConfigMap != nil {
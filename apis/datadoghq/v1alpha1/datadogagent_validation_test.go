@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+func TestIsValidRemoteConfiguration(t *testing.T) {
+	newSpec := func(agentImage, clusterAgentImage *ImageConfig) *DatadogAgentSpec {
+		return &DatadogAgentSpec{
+			Features: DatadogFeatures{
+				RemoteConfiguration: &RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(true)},
+			},
+			Agent:        DatadogAgentSpecAgentSpec{Enabled: apiutils.NewBoolPointer(true), Image: agentImage},
+			ClusterAgent: DatadogAgentSpecClusterAgentSpec{Enabled: apiutils.NewBoolPointer(true), Image: clusterAgentImage},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		spec    *DatadogAgentSpec
+		wantErr string
+	}{
+		{
+			name: "supported versions",
+			spec: newSpec(&ImageConfig{Name: "agent", Tag: "7.41.0"}, &ImageConfig{Name: "gcr.io/datadoghq/cluster-agent:7.42.1"}),
+		},
+		{
+			name: "JMX and latest images",
+			spec: newSpec(&ImageConfig{Name: "agent", Tag: "7.41.0-jmx"}, &ImageConfig{Name: "cluster-agent", Tag: "latest"}),
+		},
+		{
+			name:    "default Agent version",
+			spec:    newSpec(nil, &ImageConfig{Name: "cluster-agent", Tag: "7.41.0"}),
+			wantErr: "the Agent version 7.33.0 doesn't support the Remote Configuration, the minimum version is 7.41.0",
+		},
+		{
+			name:    "unsupported Cluster Agent version",
+			spec:    newSpec(&ImageConfig{Name: "agent", Tag: "7.41.0"}, &ImageConfig{Name: "cluster-agent", Tag: "1.17.0"}),
+			wantErr: "the Cluster Agent version 1.17.0 doesn't support the Remote Configuration, the minimum version is 7.41.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := IsValidRemoteConfiguration(tt.spec)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsValidAgentSidecarInjectionConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		sidecar *AgentSidecarInjectionConfig
+		wantErr string
+	}{
+		{
+			name:    "default selection",
+			sidecar: &AgentSidecarInjectionConfig{Enabled: apiutils.NewBoolPointer(true)},
+		},
+		{
+			name:    "empty nodeSelector",
+			sidecar: &AgentSidecarInjectionConfig{NodeSelector: map[string]string{}},
+			wantErr: "'nodeSelector' cannot be empty, it would select all the pods",
+		},
+		{
+			name:    "empty profile",
+			sidecar: &AgentSidecarInjectionConfig{Profile: apiutils.NewStringPointer("")},
+			wantErr: "'profile' cannot be empty",
+		},
+		{
+			name:    "invalid namespace",
+			sidecar: &AgentSidecarInjectionConfig{Namespaces: []string{"Apps"}},
+			wantErr: `invalid namespace "Apps": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := IsValidAgentSidecarInjectionConfig(tt.sidecar)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsValidDatadogAgent_GKEAutopilot(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *DatadogAgentSpec
+		wantErr string
+	}{
+		{
+			name: "supported features",
+			spec: &DatadogAgentSpec{
+				Platform: PlatformGKEAutopilot,
+				Agent: DatadogAgentSpecAgentSpec{
+					Enabled: apiutils.NewBoolPointer(true),
+					Apm:     &APMSpec{Enabled: apiutils.NewBoolPointer(true)},
+				},
+			},
+		},
+		{
+			name: "system-probe and host namespaces",
+			spec: &DatadogAgentSpec{
+				Platform: PlatformGKEAutopilot,
+				Agent: DatadogAgentSpecAgentSpec{
+					Enabled:     apiutils.NewBoolPointer(true),
+					SystemProbe: &SystemProbeSpec{Enabled: apiutils.NewBoolPointer(true)},
+					HostNetwork: true,
+					HostPID:     true,
+				},
+			},
+			wantErr: "[invalid spec.agent.systemProbe, err: not supported on the gke-autopilot platform, it requires privileged capabilities, " +
+				"invalid spec.agent.hostNetwork, err: not supported on the gke-autopilot platform, the host network namespace is not allowed, " +
+				"invalid spec.agent.hostPID, err: not supported on the gke-autopilot platform, the host PID namespace is not allowed]",
+		},
+		{
+			name: "unix domain sockets",
+			spec: &DatadogAgentSpec{
+				Platform: PlatformGKEAutopilot,
+				Agent: DatadogAgentSpecAgentSpec{
+					Enabled: apiutils.NewBoolPointer(true),
+					Config: &NodeAgentConfig{
+						Dogstatsd: &DogstatsdConfig{UnixDomainSocket: &DSDUnixDomainSocketSpec{Enabled: apiutils.NewBoolPointer(true)}},
+					},
+				},
+			},
+			wantErr: "invalid spec.agent.config.dogstatsd.unixDomainSocket, err: not supported on the gke-autopilot platform, the socket cannot be created on the host",
+		},
+		{
+			name: "network monitoring",
+			spec: &DatadogAgentSpec{
+				Platform: PlatformGKEAutopilot,
+				Features: DatadogFeatures{NetworkMonitoring: &NetworkMonitoringConfig{Enabled: apiutils.NewBoolPointer(true)}},
+			},
+			wantErr: "invalid spec.features.networkMonitoring, err: not supported on the gke-autopilot platform, it requires the System Probe",
+		},
+		{
+			name: "system-probe on another platform",
+			spec: &DatadogAgentSpec{
+				Platform: PlatformOpenShift,
+				Agent: DatadogAgentSpecAgentSpec{
+					Enabled:     apiutils.NewBoolPointer(true),
+					SystemProbe: &SystemProbeSpec{Enabled: apiutils.NewBoolPointer(true)},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := IsValidDatadogAgent(tt.spec)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
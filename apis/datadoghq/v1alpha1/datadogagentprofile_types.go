@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DatadogAgentProfileSpec defines the Agent configuration of a pool of nodes
+// +k8s:openapi-gen=true
+type DatadogAgentProfileSpec struct {
+	// NodeSelectorRequirements selects the nodes of the profile, a node is part of the profile if it matches all the requirements.
+	// A node matching several profiles is part of the oldest one.
+	// +listType=atomic
+	NodeSelectorRequirements []corev1.NodeSelectorRequirement `json:"nodeSelectorRequirements"`
+
+	// Agent contains the Agent configuration overridden on the nodes of the profile.
+	// Unset fields keep the value of the DatadogAgent.
+	Agent *DatadogAgentProfileAgentSpec `json:"agent,omitempty"`
+}
+
+// DatadogAgentProfileAgentSpec defines the Agent configuration overridden by a profile
+// +k8s:openapi-gen=true
+type DatadogAgentProfileAgentSpec struct {
+	// Config overrides the configuration of the Agent container.
+	Config *DatadogAgentProfileNodeAgentConfig `json:"config,omitempty"`
+
+	// Apm overrides the configuration of the Trace Agent container.
+	Apm *DatadogAgentProfileFeatureConfig `json:"apm,omitempty"`
+
+	// Process overrides the configuration of the Process Agent container.
+	Process *DatadogAgentProfileFeatureConfig `json:"process,omitempty"`
+
+	// SystemProbe overrides the configuration of the System Probe container.
+	// System Probe cannot be enabled or disabled per profile as it requires cluster-wide resources.
+	SystemProbe *DatadogAgentProfileContainerConfig `json:"systemProbe,omitempty"`
+
+	// Security overrides the configuration of the Security Agent container.
+	// Security Agent cannot be enabled or disabled per profile as it requires cluster-wide resources.
+	Security *DatadogAgentProfileContainerConfig `json:"security,omitempty"`
+
+	// Env contains environment variables set in all the Agent containers, in addition to the ones of the DatadogAgent.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// PriorityClassName overrides the priority class of the Agent pods.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// AdditionalAnnotations are added to the Agent pods, in addition to the ones of the DatadogAgent.
+	AdditionalAnnotations map[string]string `json:"additionalAnnotations,omitempty"`
+
+	// AdditionalLabels are added to the Agent pods, in addition to the ones of the DatadogAgent.
+	AdditionalLabels map[string]string `json:"additionalLabels,omitempty"`
+}
+
+// DatadogAgentProfileContainerConfig defines the container configuration overridden by a profile
+// +k8s:openapi-gen=true
+type DatadogAgentProfileContainerConfig struct {
+	// Env contains environment variables set in the container, in addition to the ones of the DatadogAgent.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Resources replaces the resources of the container.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// DatadogAgentProfileNodeAgentConfig defines the Agent container configuration overridden by a profile
+// +k8s:openapi-gen=true
+type DatadogAgentProfileNodeAgentConfig struct {
+	DatadogAgentProfileContainerConfig `json:",inline"`
+
+	// Tolerations are added to the Agent pods, in addition to the ones of the DatadogAgent.
+	// +listType=atomic
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// DatadogAgentProfileFeatureConfig defines the configuration of an optional Agent container overridden by a profile
+// +k8s:openapi-gen=true
+type DatadogAgentProfileFeatureConfig struct {
+	DatadogAgentProfileContainerConfig `json:",inline"`
+
+	// Enabled enables or disables the feature on the nodes of the profile.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// DatadogAgentProfileStatus defines the observed state of DatadogAgentProfile
+// +k8s:openapi-gen=true
+type DatadogAgentProfileStatus struct {
+	// Conditions Represents the latest available observations of a DatadogAgentProfile's current state.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// CoveredNodes is the number of nodes that are part of the profile, for all the DatadogAgents of the namespace.
+	CoveredNodes int32 `json:"coveredNodes"`
+
+	// Nodes contains the names of the first nodes that are part of the profile, up to 5.
+	// +listType=set
+	Nodes []string `json:"nodes,omitempty"`
+
+	// LastUpdate is the last time the covered nodes changed.
+	LastUpdate *metav1.Time `json:"lastUpdate,omitempty"`
+
+	// DatadogAgents contains the nodes covered by the profile for each DatadogAgent of the namespace.
+	// +listType=map
+	// +listMapKey=name
+	DatadogAgents []DatadogAgentProfileAgentStatus `json:"datadogAgents,omitempty"`
+}
+
+// DatadogAgentProfileAgentStatus defines the nodes covered by a profile for a DatadogAgent
+// +k8s:openapi-gen=true
+type DatadogAgentProfileAgentStatus struct {
+	// Name is the name of the DatadogAgent.
+	Name string `json:"name"`
+
+	// CoveredNodes is the number of nodes that are part of the profile. It is 0 when the Agent is disabled.
+	CoveredNodes int32 `json:"coveredNodes"`
+
+	// Nodes contains the names of the first nodes that are part of the profile, up to 5.
+	// +listType=set
+	Nodes []string `json:"nodes,omitempty"`
+
+	// LastUpdate is the last time the covered nodes changed.
+	LastUpdate *metav1.Time `json:"lastUpdate,omitempty"`
+}
+
+const (
+	// DatadogAgentProfileConditionTypeValid means the profile is valid and its Agent DaemonSets are rendered
+	DatadogAgentProfileConditionTypeValid = "Valid"
+)
+
+// DatadogAgentProfile overrides the Agent configuration of the DatadogAgents of its namespace on a pool of nodes.
+// A DaemonSet is rendered for each profile, in addition to the default DaemonSet that runs on the nodes
+// not covered by any profile.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=datadogagentprofiles,shortName=dap
+// +kubebuilder:printcolumn:name="covered nodes",type="integer",JSONPath=".status.coveredNodes"
+// +kubebuilder:printcolumn:name="valid",type="string",JSONPath=".status.conditions[?(@.type=='Valid')].status"
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +k8s:openapi-gen=true
+// +genclient
+type DatadogAgentProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DatadogAgentProfileSpec   `json:"spec,omitempty"`
+	Status DatadogAgentProfileStatus `json:"status,omitempty"`
+}
+
+// DatadogAgentProfileList contains a list of DatadogAgentProfile
+// +kubebuilder:object:root=true
+type DatadogAgentProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatadogAgentProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DatadogAgentProfile{}, &DatadogAgentProfileList{})
+}
@@ -16,6 +16,9 @@ type DatadogMonitorSpec struct {
 	Name string `json:"name,omitempty"`
 	// Message is a message to include with notifications for this monitor
 	Message string `json:"message,omitempty"`
+	// MessageTemplate references a message template, rendered with the variables of the DatadogMonitor namespace
+	// and appended to Message
+	MessageTemplate *DatadogMonitorMessageTemplate `json:"messageTemplate,omitempty"`
 	// Priority is an integer from 1 (high) to 5 (low) indicating alert severity
 	Priority int64 `json:"priority,omitempty"`
 	// Query is the Datadog monitor query
@@ -28,6 +31,14 @@ type DatadogMonitorSpec struct {
 	Options DatadogMonitorOptions `json:"options,omitempty"`
 }
 
+// DatadogMonitorMessageTemplate references a message template. Exactly one source must be set.
+type DatadogMonitorMessageTemplate struct {
+	// ConfigMapKeyRef selects a template stored in a ConfigMap of the DatadogMonitor namespace
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// TemplateName is the name of a cluster-wide DatadogMonitorTemplate
+	TemplateName string `json:"templateName,omitempty"`
+}
+
 // DatadogMonitorType defines the type of monitor
 type DatadogMonitorType string
 
@@ -221,6 +232,16 @@ const (
 	SyncStatusUpdateError SyncStatusMessage = "error updating monitor"
 	// SyncStatusGetError means there is an error getting the monitor
 	SyncStatusGetError SyncStatusMessage = "error getting monitor"
+	// SyncStatusAuthenticationError means the Datadog API rejected the API or application key
+	SyncStatusAuthenticationError SyncStatusMessage = "authentication error"
+	// SyncStatusForbiddenError means the application key is not allowed to manage the monitor
+	SyncStatusForbiddenError SyncStatusMessage = "forbidden"
+	// SyncStatusNotFoundError means the monitor was deleted in Datadog
+	SyncStatusNotFoundError SyncStatusMessage = "monitor not found"
+	// SyncStatusRateLimitError means the Datadog API rate limit was reached
+	SyncStatusRateLimitError SyncStatusMessage = "rate limited"
+	// SyncStatusServerError means the Datadog API returned a server error
+	SyncStatusServerError SyncStatusMessage = "Datadog API server error"
 )
 
 // DatadogMonitorTriggeredState represents the details of a triggering DatadogMonitor
@@ -27,8 +27,14 @@ func IsValidDatadogMonitor(spec *DatadogMonitorSpec) error {
 		errs = append(errs, fmt.Errorf("spec.Name must be defined"))
 	}
 
-	if spec.Message == "" {
-		errs = append(errs, fmt.Errorf("spec.Message must be defined"))
+	if spec.Message == "" && spec.MessageTemplate == nil {
+		errs = append(errs, fmt.Errorf("spec.Message or spec.MessageTemplate must be defined"))
+	}
+
+	if spec.MessageTemplate != nil {
+		if (spec.MessageTemplate.ConfigMapKeyRef == nil) == (spec.MessageTemplate.TemplateName == "") {
+			errs = append(errs, fmt.Errorf("spec.MessageTemplate must define exactly one of configMapKeyRef or templateName"))
+		}
 	}
 
 	return utilserrors.NewAggregate(errs)
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestIsValidDatadogMonitor(t *testing.T) {
@@ -39,6 +40,21 @@ func TestIsValidDatadogMonitor(t *testing.T) {
 		Type:  "metric alert",
 		Name:  "Test Monitor",
 	}
+	withMessageTemplate := &DatadogMonitorSpec{
+		Query:           "avg(last_10m):avg:system.disk.in_use{*} by {host} > 0.05",
+		Type:            "metric alert",
+		Name:            "Test Monitor",
+		MessageTemplate: &DatadogMonitorMessageTemplate{TemplateName: "default"},
+	}
+	ambiguousMessageTemplate := &DatadogMonitorSpec{
+		Query: "avg(last_10m):avg:system.disk.in_use{*} by {host} > 0.05",
+		Type:  "metric alert",
+		Name:  "Test Monitor",
+		MessageTemplate: &DatadogMonitorMessageTemplate{
+			TemplateName:    "default",
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "message"},
+		},
+	}
 
 	testCases := []struct {
 		name    string
@@ -67,7 +83,16 @@ func TestIsValidDatadogMonitor(t *testing.T) {
 		{
 			name:    "monitor missing message",
 			spec:    missingMessage,
-			wantErr: "spec.Message must be defined",
+			wantErr: "spec.Message or spec.MessageTemplate must be defined",
+		},
+		{
+			name: "monitor with message template",
+			spec: withMessageTemplate,
+		},
+		{
+			name:    "monitor with ambiguous message template",
+			spec:    ambiguousMessageTemplate,
+			wantErr: "spec.MessageTemplate must define exactly one of configMapKeyRef or templateName",
 		},
 	}
 	for _, test := range testCases {
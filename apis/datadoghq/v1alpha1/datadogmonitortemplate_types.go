@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DatadogMonitorTemplateSpec defines a reusable DatadogMonitor message template
+type DatadogMonitorTemplateSpec struct {
+	// Message is the template rendered into the message of the DatadogMonitors referencing it.
+	// It uses the Go template syntax with `[[` and `]]` delimiters, to not conflict with the Datadog
+	// `{{` `}}` template variables. Available variables are `.Monitor.Name`, `.Monitor.Namespace`, `.Monitor.Labels`,
+	// `.Namespace.Name`, `.Namespace.Labels` and `.Namespace.Annotations`.
+	Message string `json:"message"`
+}
+
+// DatadogMonitorTemplate is a cluster-wide message template referenced by DatadogMonitors
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=datadogmonitortemplates,scope=Cluster
+// +kubebuilder:printcolumn:name="age",type="date",JSONPath=".metadata.creationTimestamp"
+// +k8s:openapi-gen=true
+// +genclient
+// +genclient:nonNamespaced
+type DatadogMonitorTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DatadogMonitorTemplateSpec `json:"spec,omitempty"`
+}
+
+// DatadogMonitorTemplateList contains a list of DatadogMonitorTemplates
+// +kubebuilder:object:root=true
+type DatadogMonitorTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DatadogMonitorTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DatadogMonitorTemplate{}, &DatadogMonitorTemplateList{})
+}
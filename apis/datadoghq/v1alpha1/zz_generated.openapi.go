@@ -22,19 +22,31 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"./apis/datadoghq/v1alpha1.APMUnixDomainSocketSpec":                 schema__apis_datadoghq_v1alpha1_APMUnixDomainSocketSpec(ref),
 		"./apis/datadoghq/v1alpha1.AdmissionControllerConfig":               schema__apis_datadoghq_v1alpha1_AdmissionControllerConfig(ref),
 		"./apis/datadoghq/v1alpha1.AgentCredentials":                        schema__apis_datadoghq_v1alpha1_AgentCredentials(ref),
+		"./apis/datadoghq/v1alpha1.AgentSidecarInjectionConfig":             schema__apis_datadoghq_v1alpha1_AgentSidecarInjectionConfig(ref),
+		"./apis/datadoghq/v1alpha1.AutoscalingConfig":                       schema__apis_datadoghq_v1alpha1_AutoscalingConfig(ref),
 		"./apis/datadoghq/v1alpha1.CRISocketConfig":                         schema__apis_datadoghq_v1alpha1_CRISocketConfig(ref),
 		"./apis/datadoghq/v1alpha1.ClusterAgentConfig":                      schema__apis_datadoghq_v1alpha1_ClusterAgentConfig(ref),
 		"./apis/datadoghq/v1alpha1.ClusterChecksRunnerConfig":               schema__apis_datadoghq_v1alpha1_ClusterChecksRunnerConfig(ref),
 		"./apis/datadoghq/v1alpha1.ComplianceSpec":                          schema__apis_datadoghq_v1alpha1_ComplianceSpec(ref),
+		"./apis/datadoghq/v1alpha1.ComponentVersionStatus":                  schema__apis_datadoghq_v1alpha1_ComponentVersionStatus(ref),
 		"./apis/datadoghq/v1alpha1.ConfigDirSpec":                           schema__apis_datadoghq_v1alpha1_ConfigDirSpec(ref),
 		"./apis/datadoghq/v1alpha1.ConfigFileConfigMapSpec":                 schema__apis_datadoghq_v1alpha1_ConfigFileConfigMapSpec(ref),
 		"./apis/datadoghq/v1alpha1.CustomConfigSpec":                        schema__apis_datadoghq_v1alpha1_CustomConfigSpec(ref),
 		"./apis/datadoghq/v1alpha1.DSDUnixDomainSocketSpec":                 schema__apis_datadoghq_v1alpha1_DSDUnixDomainSocketSpec(ref),
 		"./apis/datadoghq/v1alpha1.DaemonSetDeploymentStrategy":             schema__apis_datadoghq_v1alpha1_DaemonSetDeploymentStrategy(ref),
 		"./apis/datadoghq/v1alpha1.DaemonSetRollingUpdateSpec":              schema__apis_datadoghq_v1alpha1_DaemonSetRollingUpdateSpec(ref),
+		"./apis/datadoghq/v1alpha1.DaemonSetStagedRolloutSpec":              schema__apis_datadoghq_v1alpha1_DaemonSetStagedRolloutSpec(ref),
 		"./apis/datadoghq/v1alpha1.DaemonSetStatus":                         schema__apis_datadoghq_v1alpha1_DaemonSetStatus(ref),
 		"./apis/datadoghq/v1alpha1.DatadogAgent":                            schema__apis_datadoghq_v1alpha1_DatadogAgent(ref),
 		"./apis/datadoghq/v1alpha1.DatadogAgentCondition":                   schema__apis_datadoghq_v1alpha1_DatadogAgentCondition(ref),
+		"./apis/datadoghq/v1alpha1.DatadogAgentProfile":                     schema__apis_datadoghq_v1alpha1_DatadogAgentProfile(ref),
+		"./apis/datadoghq/v1alpha1.DatadogAgentProfileAgentSpec":            schema__apis_datadoghq_v1alpha1_DatadogAgentProfileAgentSpec(ref),
+		"./apis/datadoghq/v1alpha1.DatadogAgentProfileAgentStatus":          schema__apis_datadoghq_v1alpha1_DatadogAgentProfileAgentStatus(ref),
+		"./apis/datadoghq/v1alpha1.DatadogAgentProfileContainerConfig":      schema__apis_datadoghq_v1alpha1_DatadogAgentProfileContainerConfig(ref),
+		"./apis/datadoghq/v1alpha1.DatadogAgentProfileFeatureConfig":        schema__apis_datadoghq_v1alpha1_DatadogAgentProfileFeatureConfig(ref),
+		"./apis/datadoghq/v1alpha1.DatadogAgentProfileNodeAgentConfig":      schema__apis_datadoghq_v1alpha1_DatadogAgentProfileNodeAgentConfig(ref),
+		"./apis/datadoghq/v1alpha1.DatadogAgentProfileSpec":                 schema__apis_datadoghq_v1alpha1_DatadogAgentProfileSpec(ref),
+		"./apis/datadoghq/v1alpha1.DatadogAgentProfileStatus":               schema__apis_datadoghq_v1alpha1_DatadogAgentProfileStatus(ref),
 		"./apis/datadoghq/v1alpha1.DatadogAgentSpec":                        schema__apis_datadoghq_v1alpha1_DatadogAgentSpec(ref),
 		"./apis/datadoghq/v1alpha1.DatadogAgentSpecAgentSpec":               schema__apis_datadoghq_v1alpha1_DatadogAgentSpecAgentSpec(ref),
 		"./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterAgentSpec":        schema__apis_datadoghq_v1alpha1_DatadogAgentSpecClusterAgentSpec(ref),
@@ -46,25 +58,40 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"./apis/datadoghq/v1alpha1.DatadogMetricCondition":                  schema__apis_datadoghq_v1alpha1_DatadogMetricCondition(ref),
 		"./apis/datadoghq/v1alpha1.DatadogMonitor":                          schema__apis_datadoghq_v1alpha1_DatadogMonitor(ref),
 		"./apis/datadoghq/v1alpha1.DatadogMonitorCondition":                 schema__apis_datadoghq_v1alpha1_DatadogMonitorCondition(ref),
+		"./apis/datadoghq/v1alpha1.DatadogMonitorTemplate":                  schema__apis_datadoghq_v1alpha1_DatadogMonitorTemplate(ref),
 		"./apis/datadoghq/v1alpha1.DeploymentStatus":                        schema__apis_datadoghq_v1alpha1_DeploymentStatus(ref),
 		"./apis/datadoghq/v1alpha1.DogstatsdConfig":                         schema__apis_datadoghq_v1alpha1_DogstatsdConfig(ref),
 		"./apis/datadoghq/v1alpha1.ExternalMetricsConfig":                   schema__apis_datadoghq_v1alpha1_ExternalMetricsConfig(ref),
 		"./apis/datadoghq/v1alpha1.ImageConfig":                             schema__apis_datadoghq_v1alpha1_ImageConfig(ref),
+		"./apis/datadoghq/v1alpha1.ImageMirror":                             schema__apis_datadoghq_v1alpha1_ImageMirror(ref),
+		"./apis/datadoghq/v1alpha1.ImagePolicy":                             schema__apis_datadoghq_v1alpha1_ImagePolicy(ref),
 		"./apis/datadoghq/v1alpha1.KubeStateMetricsCore":                    schema__apis_datadoghq_v1alpha1_KubeStateMetricsCore(ref),
 		"./apis/datadoghq/v1alpha1.KubeletConfig":                           schema__apis_datadoghq_v1alpha1_KubeletConfig(ref),
 		"./apis/datadoghq/v1alpha1.LocalService":                            schema__apis_datadoghq_v1alpha1_LocalService(ref),
 		"./apis/datadoghq/v1alpha1.LogCollectionConfig":                     schema__apis_datadoghq_v1alpha1_LogCollectionConfig(ref),
+		"./apis/datadoghq/v1alpha1.MaintenanceWindow":                       schema__apis_datadoghq_v1alpha1_MaintenanceWindow(ref),
 		"./apis/datadoghq/v1alpha1.NetworkPolicySpec":                       schema__apis_datadoghq_v1alpha1_NetworkPolicySpec(ref),
 		"./apis/datadoghq/v1alpha1.NodeAgentConfig":                         schema__apis_datadoghq_v1alpha1_NodeAgentConfig(ref),
+		"./apis/datadoghq/v1alpha1.OTLPConfig":                              schema__apis_datadoghq_v1alpha1_OTLPConfig(ref),
+		"./apis/datadoghq/v1alpha1.OTLPProtocolConfig":                      schema__apis_datadoghq_v1alpha1_OTLPProtocolConfig(ref),
+		"./apis/datadoghq/v1alpha1.OTLPUnixDomainSocketConfig":              schema__apis_datadoghq_v1alpha1_OTLPUnixDomainSocketConfig(ref),
 		"./apis/datadoghq/v1alpha1.OrchestratorExplorerConfig":              schema__apis_datadoghq_v1alpha1_OrchestratorExplorerConfig(ref),
+		"./apis/datadoghq/v1alpha1.PodDisruptionBudgetConfig":               schema__apis_datadoghq_v1alpha1_PodDisruptionBudgetConfig(ref),
 		"./apis/datadoghq/v1alpha1.ProcessSpec":                             schema__apis_datadoghq_v1alpha1_ProcessSpec(ref),
 		"./apis/datadoghq/v1alpha1.PrometheusScrapeConfig":                  schema__apis_datadoghq_v1alpha1_PrometheusScrapeConfig(ref),
 		"./apis/datadoghq/v1alpha1.RbacConfig":                              schema__apis_datadoghq_v1alpha1_RbacConfig(ref),
+		"./apis/datadoghq/v1alpha1.RemoteConfigurationConfig":               schema__apis_datadoghq_v1alpha1_RemoteConfigurationConfig(ref),
+		"./apis/datadoghq/v1alpha1.RollbackConfig":                          schema__apis_datadoghq_v1alpha1_RollbackConfig(ref),
 		"./apis/datadoghq/v1alpha1.RuntimeSecuritySpec":                     schema__apis_datadoghq_v1alpha1_RuntimeSecuritySpec(ref),
 		"./apis/datadoghq/v1alpha1.Secret":                                  schema__apis_datadoghq_v1alpha1_Secret(ref),
+		"./apis/datadoghq/v1alpha1.SecurityContextConstraintsConfig":        schema__apis_datadoghq_v1alpha1_SecurityContextConstraintsConfig(ref),
 		"./apis/datadoghq/v1alpha1.SecuritySpec":                            schema__apis_datadoghq_v1alpha1_SecuritySpec(ref),
+		"./apis/datadoghq/v1alpha1.StagedRolloutStatus":                     schema__apis_datadoghq_v1alpha1_StagedRolloutStatus(ref),
 		"./apis/datadoghq/v1alpha1.SyscallMonitorSpec":                      schema__apis_datadoghq_v1alpha1_SyscallMonitorSpec(ref),
 		"./apis/datadoghq/v1alpha1.SystemProbeSpec":                         schema__apis_datadoghq_v1alpha1_SystemProbeSpec(ref),
+		"./apis/datadoghq/v1alpha1.UpdatePolicy":                            schema__apis_datadoghq_v1alpha1_UpdatePolicy(ref),
+		"./apis/datadoghq/v1alpha1.VersionsStatus":                          schema__apis_datadoghq_v1alpha1_VersionsStatus(ref),
+		"./apis/datadoghq/v1alpha1.WindowsAgentConfig":                      schema__apis_datadoghq_v1alpha1_WindowsAgentConfig(ref),
 	}
 }
 
@@ -330,6 +357,132 @@ func schema__apis_datadoghq_v1alpha1_AgentCredentials(ref common.ReferenceCallba
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_AgentSidecarInjectionConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AgentSidecarInjectionConfig contains the configuration of the Agent sidecar injection. A pod is injected when it is created in one of the Namespaces and either its nodeSelector contains the NodeSelector labels, or it has the `agent.datadoghq.com/sidecar` label set to the Profile.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enable the injection of the Agent sidecar by the operator admission webhook. The operator must be started with the `-sidecarInjectionEnabled` flag to serve the webhook. Default: false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"nodeSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelector selects the pods scheduled on serverless nodes by their nodeSelector. Default: `eks.amazonaws.com/compute-type: fargate`",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"profile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Profile selects the pods with the `agent.datadoghq.com/sidecar` label set to this value. Default: `fargate`",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespaces": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespaces where the Agent sidecar is injected. The service accounts of these namespaces are granted the access to the Kubelet API. Default: the DatadogAgent namespace",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources of the Agent sidecar container, the Agent DaemonSet container resources are used by default.",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_AutoscalingConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AutoscalingConfig contains the configuration of a HorizontalPodAutoscaler managed by the operator.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enable the HorizontalPodAutoscaler. Default value is false.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"minReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinReplicas is the lower limit of the number of replicas. Default value is 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"maxReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxReplicas is the upper limit of the number of replicas. Default value is 5.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"targetCPUUtilizationPercentage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TargetCPUUtilizationPercentage is the target average CPU utilization of the pods, in percentage of their requests. Default value is 80 when no target is set.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"targetMemoryUtilizationPercentage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TargetMemoryUtilizationPercentage is the target average memory utilization of the pods, in percentage of their requests.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"targetClusterChecksPerRunner": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TargetClusterChecksPerRunner is the target average number of cluster checks dispatched to each runner. Only supported by the Cluster Checks Runner, it requires the external metrics provider of the Cluster Agent.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_CRISocketConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -718,6 +871,48 @@ func schema__apis_datadoghq_v1alpha1_ComplianceSpec(ref common.ReferenceCallback
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_ComponentVersionStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ComponentVersionStatus reports the versions of a component managed by the update policy.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"current": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Current is the version deployed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"target": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Target is the version the channel updates to, during the next maintenance window.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"available": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Available is the latest version of the catalog, whatever the channel.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastUpdateTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastUpdateTime is the last time the current version changed.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_ConfigDirSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -877,11 +1072,17 @@ func schema__apis_datadoghq_v1alpha1_DaemonSetDeploymentStrategy(ref common.Refe
 							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
+					"stagedRollout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Configure a staged rollout of the DaemonSet driven by the Operator, when the ExtendedDaemonSet is not used.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DaemonSetStagedRolloutSpec"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"./apis/datadoghq/v1alpha1.DaemonSetRollingUpdateSpec", "github.com/DataDog/extendeddaemonset/api/v1alpha1.ExtendedDaemonSetSpecStrategyCanary", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+			"./apis/datadoghq/v1alpha1.DaemonSetRollingUpdateSpec", "./apis/datadoghq/v1alpha1.DaemonSetStagedRolloutSpec", "github.com/DataDog/extendeddaemonset/api/v1alpha1.ExtendedDaemonSetSpecStrategyCanary", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -931,6 +1132,53 @@ func schema__apis_datadoghq_v1alpha1_DaemonSetRollingUpdateSpec(ref common.Refer
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_DaemonSetStagedRolloutSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DaemonSetStagedRolloutSpec contains the configuration of the staged rollout of the Agent DaemonSet. The DaemonSet uses the OnDelete update strategy, and the Operator deletes the outdated Agent pods batch by batch, once the pods of the previous batch are ready and healthy.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enable the staged rollout of the DaemonSet.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"canaryReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of pods updated in the first batch, used as canary. Value can be an absolute number (ex: 1) or a percentage of total number of DaemonSet pods (ex: 5%). Default value is 1.",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+					"batchSize": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of pods updated in each of the following batches. Value can be an absolute number (ex: 5) or a percentage of total number of DaemonSet pods (ex: 10%). Absolute number is calculated from percentage by rounding up. Default value is 25%.",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+					"pauseDuration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The duration to wait, once the pods of a batch are ready, before updating the next batch. Default value is 1min.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"maxRestarts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The maximum number of container restarts allowed for an updated pod. The rollout is halted when an updated pod restarts more often, until a new configuration is applied. Default value is 2.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/util/intstr.IntOrString"},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_DaemonSetStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1003,12 +1251,18 @@ func schema__apis_datadoghq_v1alpha1_DaemonSetStatus(ref common.ReferenceCallbac
 							Format:      "",
 						},
 					},
+					"stagedRollout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StagedRollout reports the progress of the staged rollout of the DaemonSet, when enabled.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.StagedRolloutStatus"),
+						},
+					},
 				},
 				Required: []string{"desired", "current", "ready", "available", "upToDate"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"./apis/datadoghq/v1alpha1.StagedRolloutStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -1119,104 +1373,630 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentCondition(ref common.ReferenceC
 	}
 }
 
-func schema__apis_datadoghq_v1alpha1_DatadogAgentSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema__apis_datadoghq_v1alpha1_DatadogAgentProfile(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "DatadogAgentSpec defines the desired state of DatadogAgent.",
+				Description: "DatadogAgentProfile overrides the Agent configuration of the DatadogAgents of its namespace on a pool of nodes. A DaemonSet is rendered for each profile, in addition to the default DaemonSet that runs on the nodes not covered by any profile.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"credentials": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Configure the credentials needed to run Agents. If not set, then the credentials set in the DatadogOperator will be used.",
-							Ref:         ref("./apis/datadoghq/v1alpha1.AgentCredentials"),
-						},
-					},
-					"features": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Features running on the Agent and Cluster Agent.",
-							Default:     map[string]interface{}{},
-							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogFeatures"),
-						},
-					},
-					"agent": {
-						SchemaProps: spec.SchemaProps{
-							Description: "The desired state of the Agent as an extended daemonset. Contains the Node Agent configuration and deployment strategy.",
-							Default:     map[string]interface{}{},
-							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentSpecAgentSpec"),
-						},
-					},
-					"clusterAgent": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The desired state of the Cluster Agent as a deployment.",
-							Default:     map[string]interface{}{},
-							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterAgentSpec"),
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"clusterChecksRunner": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The desired state of the Cluster Checks Runner as a deployment.",
-							Default:     map[string]interface{}{},
-							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterChecksRunnerSpec"),
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"clusterName": {
+					"metadata": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Set a unique cluster name to allow scoping hosts and Cluster Checks Runner easily.",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
 						},
 					},
-					"site": {
+					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The site of the Datadog intake to send Agent data to. Set to 'datadoghq.eu' to send data to the EU site.",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: map[string]interface{}{},
+							Ref:     ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileSpec"),
 						},
 					},
-					"registry": {
+					"status": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Registry to use for all Agent images (default gcr.io/datadoghq). Use public.ecr.aws/datadog for AWS Use docker.io/datadog for DockerHub",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: map[string]interface{}{},
+							Ref:     ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileStatus"),
 						},
 					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"./apis/datadoghq/v1alpha1.AgentCredentials", "./apis/datadoghq/v1alpha1.DatadogAgentSpecAgentSpec", "./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterAgentSpec", "./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterChecksRunnerSpec", "./apis/datadoghq/v1alpha1.DatadogFeatures"},
+			"./apis/datadoghq/v1alpha1.DatadogAgentProfileSpec", "./apis/datadoghq/v1alpha1.DatadogAgentProfileStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
 	}
 }
 
-func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecAgentSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema__apis_datadoghq_v1alpha1_DatadogAgentProfileAgentSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "DatadogAgentSpecAgentSpec defines the desired state of the node Agent.",
+				Description: "DatadogAgentProfileAgentSpec defines the Agent configuration overridden by a profile",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"enabled": {
+					"config": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Enabled",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Config overrides the configuration of the Agent container.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileNodeAgentConfig"),
 						},
 					},
-					"useExtendedDaemonset": {
+					"apm": {
 						SchemaProps: spec.SchemaProps{
-							Description: "UseExtendedDaemonset use ExtendedDaemonset for Agent deployment. default value is false.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "Apm overrides the configuration of the Trace Agent container.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileFeatureConfig"),
 						},
 					},
-					"image": {
+					"process": {
 						SchemaProps: spec.SchemaProps{
-							Description: "The container image of the Datadog Agent.",
-							Ref:         ref("./apis/datadoghq/v1alpha1.ImageConfig"),
+							Description: "Process overrides the configuration of the Process Agent container.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileFeatureConfig"),
 						},
 					},
-					"daemonsetName": {
+					"systemProbe": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SystemProbe overrides the configuration of the System Probe container. System Probe cannot be enabled or disabled per profile as it requires cluster-wide resources.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileContainerConfig"),
+						},
+					},
+					"security": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Security overrides the configuration of the Security Agent container. Security Agent cannot be enabled or disabled per profile as it requires cluster-wide resources.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileContainerConfig"),
+						},
+					},
+					"env": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Env contains environment variables set in all the Agent containers, in addition to the ones of the DatadogAgent.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"priorityClassName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PriorityClassName overrides the priority class of the Agent pods.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"additionalAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AdditionalAnnotations are added to the Agent pods, in addition to the ones of the DatadogAgent.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"additionalLabels": {
+						SchemaProps: spec.SchemaProps{
+							Description: "AdditionalLabels are added to the Agent pods, in addition to the ones of the DatadogAgent.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.DatadogAgentProfileContainerConfig", "./apis/datadoghq/v1alpha1.DatadogAgentProfileFeatureConfig", "./apis/datadoghq/v1alpha1.DatadogAgentProfileNodeAgentConfig", "k8s.io/api/core/v1.EnvVar"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_DatadogAgentProfileAgentStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogAgentProfileAgentStatus defines the nodes covered by a profile for a DatadogAgent",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the DatadogAgent.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"coveredNodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CoveredNodes is the number of nodes that are part of the profile. It is 0 when the Agent is disabled.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"nodes": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Nodes contains the names of the first nodes that are part of the profile, up to 5.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"lastUpdate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastUpdate is the last time the covered nodes changed.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"name", "coveredNodes"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_DatadogAgentProfileContainerConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogAgentProfileContainerConfig defines the container configuration overridden by a profile",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"env": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Env contains environment variables set in the container, in addition to the ones of the DatadogAgent.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources replaces the resources of the container.",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_DatadogAgentProfileFeatureConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogAgentProfileFeatureConfig defines the configuration of an optional Agent container overridden by a profile",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"env": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Env contains environment variables set in the container, in addition to the ones of the DatadogAgent.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources replaces the resources of the container.",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enabled enables or disables the feature on the nodes of the profile.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_DatadogAgentProfileNodeAgentConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogAgentProfileNodeAgentConfig defines the Agent container configuration overridden by a profile",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"env": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Env contains environment variables set in the container, in addition to the ones of the DatadogAgent.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources replaces the resources of the container.",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+					"tolerations": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Tolerations are added to the Agent pods, in addition to the ones of the DatadogAgent.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_DatadogAgentProfileSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogAgentProfileSpec defines the Agent configuration of a pool of nodes",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"nodeSelectorRequirements": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSelectorRequirements selects the nodes of the profile, a node is part of the profile if it matches all the requirements. A node matching several profiles is part of the oldest one.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.NodeSelectorRequirement"),
+									},
+								},
+							},
+						},
+					},
+					"agent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Agent contains the Agent configuration overridden on the nodes of the profile. Unset fields keep the value of the DatadogAgent.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileAgentSpec"),
+						},
+					},
+				},
+				Required: []string{"nodeSelectorRequirements"},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.DatadogAgentProfileAgentSpec", "k8s.io/api/core/v1.NodeSelectorRequirement"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_DatadogAgentProfileStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogAgentProfileStatus defines the observed state of DatadogAgentProfile",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"conditions": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"type",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions Represents the latest available observations of a DatadogAgentProfile's current state.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.Condition"),
+									},
+								},
+							},
+						},
+					},
+					"coveredNodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CoveredNodes is the number of nodes that are part of the profile, for all the DatadogAgents of the namespace.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"nodes": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Nodes contains the names of the first nodes that are part of the profile, up to 5.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"lastUpdate": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastUpdate is the last time the covered nodes changed.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"datadogAgents": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "DatadogAgents contains the nodes covered by the profile for each DatadogAgent of the namespace.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("./apis/datadoghq/v1alpha1.DatadogAgentProfileAgentStatus"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"coveredNodes"},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.DatadogAgentProfileAgentStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Condition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_DatadogAgentSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogAgentSpec defines the desired state of DatadogAgent.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"credentials": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Configure the credentials needed to run Agents. If not set, then the credentials set in the DatadogOperator will be used.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.AgentCredentials"),
+						},
+					},
+					"features": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Features running on the Agent and Cluster Agent.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogFeatures"),
+						},
+					},
+					"agent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The desired state of the Agent as an extended daemonset. Contains the Node Agent configuration and deployment strategy.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentSpecAgentSpec"),
+						},
+					},
+					"clusterAgent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The desired state of the Cluster Agent as a deployment.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterAgentSpec"),
+						},
+					},
+					"clusterChecksRunner": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The desired state of the Cluster Checks Runner as a deployment.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterChecksRunnerSpec"),
+						},
+					},
+					"clusterName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Set a unique cluster name to allow scoping hosts and Cluster Checks Runner easily.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"site": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The site of the Datadog intake to send Agent data to. Set to 'datadoghq.eu' to send data to the EU site.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"registry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Registry to use for all Agent images (default gcr.io/datadoghq). Use public.ecr.aws/datadog for AWS Use docker.io/datadog for DockerHub",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"rollback": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Rollback configures the automatic rollback of the Agent, Cluster Agent and Cluster Checks Runner to their last healthy revision when an update fails.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.RollbackConfig"),
+						},
+					},
+					"updatePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpdatePolicy configures the automatic update of the default Agent and Cluster Agent versions from a version catalog. It only applies to the images whose tag is not set in the DatadogAgent.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.UpdatePolicy"),
+						},
+					},
+					"imagePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePolicy configures the registry mirrors and the digest pinning applied to all the images rendered by the operator.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.ImagePolicy"),
+						},
+					},
+					"platform": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Platform the Agents are deployed on: generic, gke-autopilot or openshift. The platform applies a set of defaults suited to the distribution, and the features that cannot run on it are rejected. When not set, no platform specific configuration is applied.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.AgentCredentials", "./apis/datadoghq/v1alpha1.DatadogAgentSpecAgentSpec", "./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterAgentSpec", "./apis/datadoghq/v1alpha1.DatadogAgentSpecClusterChecksRunnerSpec", "./apis/datadoghq/v1alpha1.DatadogFeatures", "./apis/datadoghq/v1alpha1.ImagePolicy", "./apis/datadoghq/v1alpha1.RollbackConfig", "./apis/datadoghq/v1alpha1.UpdatePolicy"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecAgentSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogAgentSpecAgentSpec defines the desired state of the node Agent.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enabled",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"useExtendedDaemonset": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UseExtendedDaemonset use ExtendedDaemonset for Agent deployment. default value is false.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The container image of the Datadog Agent.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.ImageConfig"),
+						},
+					},
+					"daemonsetName": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Name of the Daemonset to create or migrate from.",
 							Type:        []string{"string"},
@@ -1275,14 +2055,14 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecAgentSpec(ref common.Refere
 					},
 					"keepLabels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "KeepLabels allows the specification of labels not managed by the Operator that will be kept on Agent DaemonSet. All labels containing 'datadoghq.com' are always included. This field uses glob syntax.",
+							Description: "KeepLabels allows the specification of labels not managed by the Operator that will be kept on Agent DaemonSet. All labels containing 'datadoghq.com', and the ones set by Kubernetes, are always included. This field uses glob syntax.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 					"keepAnnotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "KeepAnnotations allows the specification of annotations not managed by the Operator that will be kept on Agent DaemonSet. All annotations containing 'datadoghq.com' are always included. This field uses glob syntax.",
+							Description: "KeepAnnotations allows the specification of annotations not managed by the Operator that will be kept on Agent DaemonSet. All annotations containing 'datadoghq.com', and the ones set by Kubernetes, are always included. This field uses glob syntax.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -1397,11 +2177,36 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecAgentSpec(ref common.Refere
 							Ref:         ref("./apis/datadoghq/v1alpha1.LocalService"),
 						},
 					},
+					"sidecarInjection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SidecarInjection configures the injection of the Agent as a sidecar container in the pods running on serverless nodes (EKS Fargate, virtual-kubelet), where the Agent DaemonSet cannot run.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.AgentSidecarInjectionConfig"),
+						},
+					},
+					"windows": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Windows configures a second Agent DaemonSet running on the Windows nodes of the cluster.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.WindowsAgentConfig"),
+						},
+					},
+					"securityContextConstraints": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityContextConstraints configures the OpenShift SecurityContextConstraints created for the Agent.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.SecurityContextConstraintsConfig"),
+						},
+					},
+					"disjointNodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DisjointNodes declares that the Agents of this DatadogAgent run on nodes not shared with the other DatadogAgents of the cluster, for instance nodes selected with taints and tolerations. The node and host port conflicts with the other DatadogAgents are not checked.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"./apis/datadoghq/v1alpha1.APMSpec", "./apis/datadoghq/v1alpha1.CustomConfigSpec", "./apis/datadoghq/v1alpha1.DaemonSetDeploymentStrategy", "./apis/datadoghq/v1alpha1.ImageConfig", "./apis/datadoghq/v1alpha1.LocalService", "./apis/datadoghq/v1alpha1.LogCollectionConfig", "./apis/datadoghq/v1alpha1.NetworkPolicySpec", "./apis/datadoghq/v1alpha1.NodeAgentConfig", "./apis/datadoghq/v1alpha1.ProcessSpec", "./apis/datadoghq/v1alpha1.RbacConfig", "./apis/datadoghq/v1alpha1.SecuritySpec", "./apis/datadoghq/v1alpha1.SystemProbeSpec", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.PodDNSConfig"},
+			"./apis/datadoghq/v1alpha1.APMSpec", "./apis/datadoghq/v1alpha1.AgentSidecarInjectionConfig", "./apis/datadoghq/v1alpha1.CustomConfigSpec", "./apis/datadoghq/v1alpha1.DaemonSetDeploymentStrategy", "./apis/datadoghq/v1alpha1.ImageConfig", "./apis/datadoghq/v1alpha1.LocalService", "./apis/datadoghq/v1alpha1.LogCollectionConfig", "./apis/datadoghq/v1alpha1.NetworkPolicySpec", "./apis/datadoghq/v1alpha1.NodeAgentConfig", "./apis/datadoghq/v1alpha1.ProcessSpec", "./apis/datadoghq/v1alpha1.RbacConfig", "./apis/datadoghq/v1alpha1.SecurityContextConstraintsConfig", "./apis/datadoghq/v1alpha1.SecuritySpec", "./apis/datadoghq/v1alpha1.SystemProbeSpec", "./apis/datadoghq/v1alpha1.WindowsAgentConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.PodDNSConfig"},
 	}
 }
 
@@ -1452,11 +2257,17 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecClusterAgentSpec(ref common
 					},
 					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Number of the Cluster Agent replicas.",
+							Description: "Number of the Cluster Agent replicas. When set, the Operator owns the number of replicas and reverts manual scaling, leave it unset to scale the Deployment manually.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
+					"autoscaling": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Autoscaling configures a HorizontalPodAutoscaler for the Cluster Agent Deployment. When enabled, the replicas are managed by the HorizontalPodAutoscaler and Replicas is ignored.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.AutoscalingConfig"),
+						},
+					},
 					"additionalAnnotations": {
 						SchemaProps: spec.SchemaProps{
 							Description: "AdditionalAnnotations provide annotations that will be added to the Cluster Agent Pods.",
@@ -1491,14 +2302,14 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecClusterAgentSpec(ref common
 					},
 					"keepLabels": {
 						SchemaProps: spec.SchemaProps{
-							Description: "KeepLabels allows the specification of labels not managed by the Operator that will be kept on ClusterAgent Deployment. All labels containing 'datadoghq.com' are always included. This field uses glob syntax.",
+							Description: "KeepLabels allows the specification of labels not managed by the Operator that will be kept on ClusterAgent Deployment. All labels containing 'datadoghq.com', and the ones set by Kubernetes, are always included. This field uses glob syntax.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
 					"keepAnnotations": {
 						SchemaProps: spec.SchemaProps{
-							Description: "KeepAnnotations allows the specification of annotations not managed by the Operator that will be kept on ClusterAgent Deployment. All annotations containing 'datadoghq.com' are always included. This field uses glob syntax.",
+							Description: "KeepAnnotations allows the specification of annotations not managed by the Operator that will be kept on ClusterAgent Deployment. All annotations containing 'datadoghq.com', and the ones set by Kubernetes, are always included. This field uses glob syntax.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -1535,6 +2346,31 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecClusterAgentSpec(ref common
 							},
 						},
 					},
+					"topologySpreadConstraints": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "TopologySpreadConstraints describes how the Cluster Agent pods are spread across the topology domains. If not set, the pods are spread across the zones (topology.kubernetes.io/zone) when possible.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.TopologySpreadConstraint"),
+									},
+								},
+							},
+						},
+					},
+					"podDisruptionBudget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodDisruptionBudget configures the PodDisruptionBudget of the Cluster Agent pods.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.PodDisruptionBudgetConfig"),
+						},
+					},
 					"nodeSelector": {
 						SchemaProps: spec.SchemaProps{
 							Description: "NodeSelector is a selector which must be true for the pod to fit on a node. Selector which must match a node's labels for the pod to be scheduled on that node. More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/",
@@ -1561,7 +2397,7 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecClusterAgentSpec(ref common
 			},
 		},
 		Dependencies: []string{
-			"./apis/datadoghq/v1alpha1.ClusterAgentConfig", "./apis/datadoghq/v1alpha1.CustomConfigSpec", "./apis/datadoghq/v1alpha1.ImageConfig", "./apis/datadoghq/v1alpha1.NetworkPolicySpec", "./apis/datadoghq/v1alpha1.RbacConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Toleration"},
+			"./apis/datadoghq/v1alpha1.AutoscalingConfig", "./apis/datadoghq/v1alpha1.ClusterAgentConfig", "./apis/datadoghq/v1alpha1.CustomConfigSpec", "./apis/datadoghq/v1alpha1.ImageConfig", "./apis/datadoghq/v1alpha1.NetworkPolicySpec", "./apis/datadoghq/v1alpha1.PodDisruptionBudgetConfig", "./apis/datadoghq/v1alpha1.RbacConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.TopologySpreadConstraint"},
 	}
 }
 
@@ -1612,11 +2448,17 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecClusterChecksRunnerSpec(ref
 					},
 					"replicas": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Number of the Cluster Checks Runner replicas.",
+							Description: "Number of the Cluster Checks Runner replicas. When set, the Operator owns the number of replicas and reverts manual scaling, leave it unset to scale the Deployment manually.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
+					"autoscaling": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Autoscaling configures a HorizontalPodAutoscaler for the Cluster Checks Runner Deployment. When enabled, the replicas are managed by the HorizontalPodAutoscaler and Replicas is ignored.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.AutoscalingConfig"),
+						},
+					},
 					"additionalAnnotations": {
 						SchemaProps: spec.SchemaProps{
 							Description: "AdditionalAnnotations provide annotations that will be added to the cluster checks runner Pods.",
@@ -1658,29 +2500,54 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecClusterChecksRunnerSpec(ref
 					},
 					"affinity": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If specified, the pod's scheduling constraints.",
-							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+							Description: "If specified, the pod's scheduling constraints.",
+							Ref:         ref("k8s.io/api/core/v1.Affinity"),
+						},
+					},
+					"tolerations": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "If specified, the Cluster-Checks pod's tolerations.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
 						},
 					},
-					"tolerations": {
+					"topologySpreadConstraints": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
 								"x-kubernetes-list-type": "atomic",
 							},
 						},
 						SchemaProps: spec.SchemaProps{
-							Description: "If specified, the Cluster-Checks pod's tolerations.",
+							Description: "TopologySpreadConstraints describes how the Cluster Checks Runner pods are spread across the topology domains. If not set, the pods are spread across the zones (topology.kubernetes.io/zone) when possible.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref("k8s.io/api/core/v1.Toleration"),
+										Ref:     ref("k8s.io/api/core/v1.TopologySpreadConstraint"),
 									},
 								},
 							},
 						},
 					},
+					"podDisruptionBudget": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PodDisruptionBudget configures the PodDisruptionBudget of the Cluster Checks Runner pods.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.PodDisruptionBudgetConfig"),
+						},
+					},
 					"nodeSelector": {
 						SchemaProps: spec.SchemaProps{
 							Description: "NodeSelector is a selector which must be true for the pod to fit on a node. Selector which must match a node's labels for the pod to be scheduled on that node. More info: https://kubernetes.io/docs/concepts/configuration/assign-pod-node/",
@@ -1707,7 +2574,7 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentSpecClusterChecksRunnerSpec(ref
 			},
 		},
 		Dependencies: []string{
-			"./apis/datadoghq/v1alpha1.ClusterChecksRunnerConfig", "./apis/datadoghq/v1alpha1.CustomConfigSpec", "./apis/datadoghq/v1alpha1.ImageConfig", "./apis/datadoghq/v1alpha1.NetworkPolicySpec", "./apis/datadoghq/v1alpha1.RbacConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Toleration"},
+			"./apis/datadoghq/v1alpha1.AutoscalingConfig", "./apis/datadoghq/v1alpha1.ClusterChecksRunnerConfig", "./apis/datadoghq/v1alpha1.CustomConfigSpec", "./apis/datadoghq/v1alpha1.ImageConfig", "./apis/datadoghq/v1alpha1.NetworkPolicySpec", "./apis/datadoghq/v1alpha1.PodDisruptionBudgetConfig", "./apis/datadoghq/v1alpha1.RbacConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.TopologySpreadConstraint"},
 	}
 }
 
@@ -1730,6 +2597,12 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentStatus(ref common.ReferenceCall
 							Ref:         ref("./apis/datadoghq/v1alpha1.DaemonSetStatus"),
 						},
 					},
+					"agentWindows": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The actual state of the Windows Agent as a daemonset.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DaemonSetStatus"),
+						},
+					},
 					"clusterAgent": {
 						SchemaProps: spec.SchemaProps{
 							Description: "The actual state of the Cluster Agent as a deployment.",
@@ -1764,11 +2637,17 @@ func schema__apis_datadoghq_v1alpha1_DatadogAgentStatus(ref common.ReferenceCall
 							},
 						},
 					},
+					"versions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Versions reports the versions selected by the update policy, when configured.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.VersionsStatus"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"./apis/datadoghq/v1alpha1.DaemonSetStatus", "./apis/datadoghq/v1alpha1.DatadogAgentCondition", "./apis/datadoghq/v1alpha1.DatadogAgentSpec", "./apis/datadoghq/v1alpha1.DeploymentStatus"},
+			"./apis/datadoghq/v1alpha1.DaemonSetStatus", "./apis/datadoghq/v1alpha1.DatadogAgentCondition", "./apis/datadoghq/v1alpha1.DatadogAgentSpec", "./apis/datadoghq/v1alpha1.DeploymentStatus", "./apis/datadoghq/v1alpha1.VersionsStatus"},
 	}
 }
 
@@ -1864,11 +2743,23 @@ func schema__apis_datadoghq_v1alpha1_DatadogFeatures(ref common.ReferenceCallbac
 							Ref:         ref("./apis/datadoghq/v1alpha1.LogCollectionConfig"),
 						},
 					},
+					"otlp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OTLP ingest configuration.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.OTLPConfig"),
+						},
+					},
+					"remoteConfiguration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RemoteConfiguration configuration.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.RemoteConfigurationConfig"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"./apis/datadoghq/v1alpha1.KubeStateMetricsCore", "./apis/datadoghq/v1alpha1.LogCollectionConfig", "./apis/datadoghq/v1alpha1.NetworkMonitoringConfig", "./apis/datadoghq/v1alpha1.OrchestratorExplorerConfig", "./apis/datadoghq/v1alpha1.PrometheusScrapeConfig"},
+			"./apis/datadoghq/v1alpha1.KubeStateMetricsCore", "./apis/datadoghq/v1alpha1.LogCollectionConfig", "./apis/datadoghq/v1alpha1.NetworkMonitoringConfig", "./apis/datadoghq/v1alpha1.OTLPConfig", "./apis/datadoghq/v1alpha1.OrchestratorExplorerConfig", "./apis/datadoghq/v1alpha1.PrometheusScrapeConfig", "./apis/datadoghq/v1alpha1.RemoteConfigurationConfig"},
 	}
 }
 
@@ -2086,6 +2977,47 @@ func schema__apis_datadoghq_v1alpha1_DatadogMonitorCondition(ref common.Referenc
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_DatadogMonitorTemplate(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DatadogMonitorTemplate is a cluster-wide message template referenced by DatadogMonitors",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref("./apis/datadoghq/v1alpha1.DatadogMonitorTemplateSpec"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.DatadogMonitorTemplateSpec", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_DeploymentStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2286,6 +3218,13 @@ func schema__apis_datadoghq_v1alpha1_ImageConfig(ref common.ReferenceCallback) c
 							Format:      "",
 						},
 					},
+					"digest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Define the image digest to pin the image to, for example sha256:<hex>. Takes precedence over the digests of the image policy catalog.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"jmxEnabled": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Define whether the Agent image should support JMX.",
@@ -2322,6 +3261,87 @@ func schema__apis_datadoghq_v1alpha1_ImageConfig(ref common.ReferenceCallback) c
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_ImageMirror(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ImageMirror maps a source registry or repository to a mirror.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Source registry or repository, for example gcr.io/datadoghq or gcr.io/datadoghq/agent.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mirror": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Mirror registry or repository replacing the source, for example registry.example.com/datadog.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"source", "mirror"},
+			},
+		},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_ImagePolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ImagePolicy contains the configuration of the image references rendered by the operator.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"mirrors": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"source",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Mirrors maps source registries or repositories to mirrors. When several sources match an image, the longest one is used.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("./apis/datadoghq/v1alpha1.ImageMirror"),
+									},
+								},
+							},
+						},
+					},
+					"digestCatalog": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DigestCatalog is the name of the ConfigMap, in the namespace of the DatadogAgent, listing the digests of the images by reference. It can be the version catalog of the update policy.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"requireDigest": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RequireDigest fails the reconciliation when an image cannot be pinned to a digest. Default value is false.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.ImageMirror"},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_KubeStateMetricsCore(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2477,7 +3497,7 @@ func schema__apis_datadoghq_v1alpha1_LogCollectionConfig(ref common.ReferenceCal
 					},
 					"tempStoragePath": {
 						SchemaProps: spec.SchemaProps{
-							Description: "This path (always mounted from the host) is used by Datadog Agent to store information about processed log files. If the Datadog Agent is restarted, it starts tailing the log files immediately. Default to `/var/lib/datadog-agent/logs`",
+							Description: "This path (always mounted from the host) is used by Datadog Agent to store information about processed log files. If the Datadog Agent is restarted, it starts tailing the log files immediately. Default to `/var/lib/datadog-agent/logs` Ignored on the gke-autopilot platform, which doesn't allow writing to the host: the information is stored in an emptyDir volume and lost when the Agent pod is recreated.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
@@ -2495,6 +3515,57 @@ func schema__apis_datadoghq_v1alpha1_LogCollectionConfig(ref common.ReferenceCal
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_MaintenanceWindow(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "MaintenanceWindow defines a recurring time window.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"days": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Days of the week of the window, for example Saturday. The window is open every day if not set.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"start": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Start time of the window, in UTC, with the HH:MM format.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration of the window.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+				},
+				Required: []string{"start", "duration"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_NetworkPolicySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2783,47 +3854,148 @@ func schema__apis_datadoghq_v1alpha1_NodeAgentConfig(ref common.ReferenceCallbac
 					},
 					"dogstatsd": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Configure Dogstatsd.",
-							Ref:         ref("./apis/datadoghq/v1alpha1.DogstatsdConfig"),
+							Description: "Configure Dogstatsd.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.DogstatsdConfig"),
+						},
+					},
+					"tolerations": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "If specified, the Agent pod's tolerations.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.Toleration"),
+									},
+								},
+							},
+						},
+					},
+					"hostPort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Number of port to expose on the host. If specified, this must be a valid port number, 0 < x < 65536. If HostNetwork is specified, this must match ContainerPort. Most containers do not need this.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"kubelet": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KubeletConfig contains the Kubelet configuration parameters",
+							Ref:         ref("./apis/datadoghq/v1alpha1.KubeletConfig"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.CRISocketConfig", "./apis/datadoghq/v1alpha1.ConfigDirSpec", "./apis/datadoghq/v1alpha1.DogstatsdConfig", "./apis/datadoghq/v1alpha1.KubeletConfig", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Probe", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_OTLPConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OTLPConfig contains the configuration of the OpenTelemetry Protocol (OTLP) ingest in the Agent. See also: https://docs.datadoghq.com/tracing/setup_overview/open_standards/#otlp-ingest-in-datadog-agent",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enable this option to activate the OTLP ingest in the Agent.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"grpc": {
+						SchemaProps: spec.SchemaProps{
+							Description: "GRPC configures the OTLP/gRPC receiver.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.OTLPProtocolConfig"),
+						},
+					},
+					"http": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HTTP configures the OTLP/HTTP receiver.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.OTLPProtocolConfig"),
+						},
+					},
+					"unixDomainSocket": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UnixDomainSocket configures the OTLP/gRPC receiver to listen on a Unix Domain Socket instead of its TCP port.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.OTLPUnixDomainSocketConfig"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.OTLPProtocolConfig", "./apis/datadoghq/v1alpha1.OTLPUnixDomainSocketConfig"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_OTLPProtocolConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OTLPProtocolConfig contains the configuration of an OTLP receiver.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enable the receiver.",
+							Type:        []string{"boolean"},
+							Format:      "",
 						},
 					},
-					"tolerations": {
-						VendorExtensible: spec.VendorExtensible{
-							Extensions: spec.Extensions{
-								"x-kubernetes-list-type": "atomic",
-							},
-						},
+					"port": {
 						SchemaProps: spec.SchemaProps{
-							Description: "If specified, the Agent pod's tolerations.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref("k8s.io/api/core/v1.Toleration"),
-									},
-								},
-							},
+							Description: "Port of the receiver in the Agent pod. (default value: 4317 for gRPC, 4318 for HTTP)",
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 					"hostPort": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Number of port to expose on the host. If specified, this must be a valid port number, 0 < x < 65536. If HostNetwork is specified, this must match ContainerPort. Most containers do not need this.",
+							Description: "Number of port to expose on the host. If specified, this must be a valid port number, 0 < x < 65536. If HostNetwork is specified, this must match Port.",
 							Type:        []string{"integer"},
 							Format:      "int32",
 						},
 					},
-					"kubelet": {
+				},
+			},
+		},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_OTLPUnixDomainSocketConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "OTLPUnixDomainSocketConfig contains the configuration of the OTLP/gRPC receiver over Unix Domain Socket.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
 						SchemaProps: spec.SchemaProps{
-							Description: "KubeletConfig contains the Kubelet configuration parameters",
-							Ref:         ref("./apis/datadoghq/v1alpha1.KubeletConfig"),
+							Description: "Enable the OTLP/gRPC receiver over Unix Domain Socket.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"hostFilepath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Define the host OTLP socket filepath used when the OTLP/gRPC receiver over Unix Domain Socket is enabled. (default value: /var/run/datadog/otlp.sock)",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
 			},
 		},
-		Dependencies: []string{
-			"./apis/datadoghq/v1alpha1.CRISocketConfig", "./apis/datadoghq/v1alpha1.ConfigDirSpec", "./apis/datadoghq/v1alpha1.DogstatsdConfig", "./apis/datadoghq/v1alpha1.KubeletConfig", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Probe", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount"},
 	}
 }
 
@@ -2902,6 +4074,33 @@ func schema__apis_datadoghq_v1alpha1_OrchestratorExplorerConfig(ref common.Refer
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_PodDisruptionBudgetConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PodDisruptionBudgetConfig contains the configuration of a PodDisruptionBudget managed by the operator.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"minAvailable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinAvailable is the number, or percentage, of pods that must remain available during a voluntary disruption. Default value is 50%, rounded up, when MaxUnavailable is not set.",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+					"maxUnavailable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxUnavailable is the number, or percentage, of pods that can be unavailable during a voluntary disruption. Cannot be set with MinAvailable.",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/util/intstr.IntOrString"},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_ProcessSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3083,6 +4282,73 @@ func schema__apis_datadoghq_v1alpha1_RbacConfig(ref common.ReferenceCallback) co
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_RemoteConfigurationConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RemoteConfigurationConfig contains the configuration of the Remote Configuration of the Agent, Cluster Agent and Security Agent, which allows managing APM sampling and CWS rules from the Datadog UI. The API key must have the Remote Configuration scope. See also: https://docs.datadoghq.com/agent/guide/how_rc_works",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enable this option to activate the Remote Configuration. Requires the Agent and Cluster Agent 7.41.0 or later.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_RollbackConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RollbackConfig contains the configuration of the automatic rollback of failed updates. The rendered pod template of each component is stored in a ControllerRevision. While the pods of a new revision are rolled out, the revision is rolled back to the last healthy one if the failure criteria are met.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enable the automatic rollback of failed updates.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"crashLoopBackOffThreshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of updated pods in CrashLoopBackOff triggering a rollback. Value can be an absolute number (ex: 2) or a percentage of the updated pods (ex: 50%). Default value is 50%.",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+					"unreadyThreshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of updated pods not ready for more than UnreadyTimeout triggering a rollback. Value can be an absolute number (ex: 2) or a percentage of the updated pods (ex: 50%). Default value is 50%.",
+							Ref:         ref("k8s.io/apimachinery/pkg/util/intstr.IntOrString"),
+						},
+					},
+					"unreadyTimeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The duration after which an updated pod that is not ready is considered as failed. Default value is 10min.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"revisionHistoryLimit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of revisions kept for each component. Default value is 5.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration", "k8s.io/apimachinery/pkg/util/intstr.IntOrString"},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_RuntimeSecuritySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3146,6 +4412,26 @@ func schema__apis_datadoghq_v1alpha1_Secret(ref common.ReferenceCallback) common
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_SecurityContextConstraintsConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "SecurityContextConstraintsConfig contains the configuration of the OpenShift SecurityContextConstraints allowing the Agent pods to run. The SecurityContextConstraints only grants the privileges needed by the enabled features, it is updated when the features change.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"create": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Create the SecurityContextConstraints used by the Agent service account. Requires the operator to be started with the `supportOpenShiftSCC` option. Default: false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_SecuritySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3266,6 +4552,69 @@ func schema__apis_datadoghq_v1alpha1_SecuritySpec(ref common.ReferenceCallback)
 	}
 }
 
+func schema__apis_datadoghq_v1alpha1_StagedRolloutStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "StagedRolloutStatus reports the progress of the staged rollout of the Agent DaemonSet.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Phase of the rollout.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"revision": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Revision is the DaemonSet revision (controller-revision-hash) being rolled out.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"batch": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Batch is the number of batches of pods deleted for the current revision.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"updatedPods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UpdatedPods is the number of pods running the current revision.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"totalPods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TotalPods is the total number of pods of the DaemonSet.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"lastBatchTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastBatchTime is the time the last batch of pods was deleted.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message gives details about the state of the rollout.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema__apis_datadoghq_v1alpha1_SyscallMonitorSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -3480,3 +4829,93 @@ func schema__apis_datadoghq_v1alpha1_SystemProbeSpec(ref common.ReferenceCallbac
 			"./apis/datadoghq/v1alpha1.CustomConfigSpec", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.SecurityContext", "k8s.io/api/core/v1.VolumeMount"},
 	}
 }
+
+func schema__apis_datadoghq_v1alpha1_UpdatePolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "UpdatePolicy contains the configuration of the automatic updates of the Agent versions.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"channel": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Channel defines which versions the Agents are updated to: pinned, patch or minor. Default value is pinned.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"versionCatalog": {
+						SchemaProps: spec.SchemaProps{
+							Description: "VersionCatalog is the name of the ConfigMap, in the namespace of the DatadogAgent, listing the available versions. Default value is datadog-version-catalog.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"maintenanceWindow": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaintenanceWindow restricts the updates to a time window. The Agents can be updated at any time if not set.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.MaintenanceWindow"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.MaintenanceWindow"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_VersionsStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "VersionsStatus reports the versions of the Agents managed by the update policy.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"agent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Agent versions of the Agent and of the Cluster Checks Runner.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.ComponentVersionStatus"),
+						},
+					},
+					"clusterAgent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterAgent versions of the Cluster Agent.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.ComponentVersionStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.ComponentVersionStatus"},
+	}
+}
+
+func schema__apis_datadoghq_v1alpha1_WindowsAgentConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WindowsAgentConfig contains the configuration of the Agent DaemonSet deployed on the Windows nodes. The Windows Agent shares the Agent configuration, the Linux-only components (System Probe, Security Agent, Process Agent) are not deployed.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"enabled": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Enable the deployment of the Windows Agent DaemonSet. Default: false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image of the Windows Agent, the Agent image is used by default as it is published for Windows.",
+							Ref:         ref("./apis/datadoghq/v1alpha1.ImageConfig"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"./apis/datadoghq/v1alpha1.ImageConfig"},
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/DataDog/datadog-operator/cmd/kubectl-datadog/flare"
 	"github.com/DataDog/datadog-operator/cmd/kubectl-datadog/get"
 	"github.com/DataDog/datadog-operator/cmd/kubectl-datadog/metrics"
+	"github.com/DataDog/datadog-operator/cmd/kubectl-datadog/plan"
+	"github.com/DataDog/datadog-operator/cmd/kubectl-datadog/render"
 	"github.com/DataDog/datadog-operator/cmd/kubectl-datadog/validate/validate"
 
 	"github.com/spf13/cobra"
@@ -41,6 +43,8 @@ func NewCmd(streams genericclioptions.IOStreams) *cobra.Command {
 	cmd.AddCommand(get.New(streams))
 	cmd.AddCommand(flare.New(streams))
 	cmd.AddCommand(validate.New(streams))
+	cmd.AddCommand(plan.New(streams))
+	cmd.AddCommand(render.New(streams))
 
 	// Agent commands
 	cmd.AddCommand(agent.New(streams))
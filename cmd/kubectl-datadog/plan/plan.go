@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/controllers/datadogagent"
+	"github.com/DataDog/datadog-operator/pkg/plugin/common"
+	edsdatadoghqv1alpha1 "github.com/DataDog/extendeddaemonset/api/v1alpha1"
+
+	"github.com/spf13/cobra"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var planExample = `
+  # show the changes the operator would apply for the DatadogAgent defined in dda.yaml
+  %[1]s plan -f dda.yaml
+`
+
+// options provides information required by Datadog plan command.
+type options struct {
+	genericclioptions.IOStreams
+	common.Options
+	filename                  string
+	supportOpenShiftSCC       bool
+	podSecurityLabelNamespace bool
+	orphanCollectionEnabled   bool
+	datadogAgent              *v1alpha1.DatadogAgent
+}
+
+// newOptions provides an instance of options with default values.
+func newOptions(streams genericclioptions.IOStreams) *options {
+	o := &options{
+		IOStreams: streams,
+	}
+	o.SetConfigFlags()
+	return o
+}
+
+// New provides a cobra command wrapping options for "plan" sub command.
+func New(streams genericclioptions.IOStreams) *cobra.Command {
+	o := newOptions(streams)
+	cmd := &cobra.Command{
+		Use:          "plan -f <file>",
+		Short:        "Show the changes the operator would apply for a DatadogAgent",
+		Example:      fmt.Sprintf(planExample, "kubectl datadog"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.complete(c, args); err != nil {
+				return err
+			}
+			if err := o.validate(); err != nil {
+				return err
+			}
+			return o.run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.filename, "filename", "f", "", "The file containing the DatadogAgent to plan")
+	cmd.Flags().BoolVar(&o.supportOpenShiftSCC, "openshift-scc", false, "Plan the SecurityContextConstraints of the Agent, as the operator does with supportOpenShiftSCC")
+	cmd.Flags().BoolVar(&o.podSecurityLabelNamespace, "pod-security-label-namespace", false, "Plan the Pod Security labels of the namespaces, as the operator does with podSecurityLabelNamespace")
+	cmd.Flags().BoolVar(&o.orphanCollectionEnabled, "orphan-collection", false, "Plan the deletion of the orphaned resources, as the operator does with orphanCollectionEnabled")
+	o.ConfigFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// complete sets all information required for processing the command.
+func (o *options) complete(cmd *cobra.Command, args []string) error {
+	if o.filename == "" {
+		return errors.New("the DatadogAgent file is required, use --filename")
+	}
+	if err := o.Init(cmd); err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(o.filename)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", o.filename, err)
+	}
+	o.datadogAgent = &v1alpha1.DatadogAgent{}
+	if err = yaml.Unmarshal(content, o.datadogAgent); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", o.filename, err)
+	}
+	if o.datadogAgent.Namespace == "" {
+		o.datadogAgent.Namespace = o.UserNamespace
+	}
+
+	return nil
+}
+
+// validate ensures that all required arguments and flag values are provided.
+func (o *options) validate() error {
+	if o.datadogAgent.Kind != "DatadogAgent" {
+		return fmt.Errorf("%s doesn't contain a DatadogAgent", o.filename)
+	}
+	if o.datadogAgent.Name == "" {
+		return errors.New("the DatadogAgent name is required")
+	}
+	return nil
+}
+
+// run runs the plan command.
+func (o *options) run() error {
+	// Register the types managed by the operator
+	scheme := o.Client.Scheme()
+	if err := edsdatadoghqv1alpha1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("unable register ExtendedDaemonSet apis: %w", err)
+	}
+	if err := apiregistrationv1.AddToScheme(scheme); err != nil {
+		return fmt.Errorf("unable register APIService apis: %w", err)
+	}
+
+	versionInfo, err := o.Clientset.Discovery().ServerVersion()
+	if err != nil {
+		return fmt.Errorf("unable to get the Kubernetes version: %w", err)
+	}
+	reconcilerOptions := datadogagent.ReconcilerOptions{
+		SupportExtendedDaemonset:  o.isResourceAvailable(edsdatadoghqv1alpha1.GroupVersion.String(), "extendeddaemonsets"),
+		SupportCilium:             o.isResourceAvailable("cilium.io/v2", "ciliumnetworkpolicies"),
+		SupportOpenShiftSCC:       o.supportOpenShiftSCC && o.isResourceAvailable("security.openshift.io/v1", "securitycontextconstraints"),
+		PodSecurityLabelNamespace: o.podSecurityLabelNamespace,
+		OrphanCollectionEnabled:   o.orphanCollectionEnabled,
+	}
+
+	changes, err := datadogagent.Plan(context.TODO(), o.Client, reconcilerOptions, versionInfo, o.datadogAgent)
+	var reconcileErrors utilerrors.Aggregate
+	if err != nil && !errors.As(err, &reconcileErrors) {
+		return fmt.Errorf("unable to plan the changes: %w", err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(o.Out, "No changes.")
+	}
+	for _, change := range changes {
+		fmt.Fprintf(o.Out, "%s %s %s/%s", change.Operation, change.Kind, change.Namespace, change.Name)
+		if change.TriggersRollout {
+			fmt.Fprint(o.Out, " (triggers a pod rollout)")
+		}
+		fmt.Fprintf(o.Out, "\n%s\n", change.Diff)
+	}
+
+	// The reconcile errors are reported as warnings, the plan is still relevant for the other components
+	if reconcileErrors != nil {
+		for _, reconcileError := range reconcileErrors.Errors() {
+			fmt.Fprintf(o.ErrOut, "Warning: %v\n", reconcileError)
+		}
+	}
+
+	return nil
+}
+
+// isResourceAvailable returns true if the API server serves the resource
+func (o *options) isResourceAvailable(groupVersion, resource string) bool {
+	resources, err := o.Clientset.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return false
+	}
+	for _, apiResource := range resources.APIResources {
+		if apiResource.Name == resource {
+			return true
+		}
+	}
+	return false
+}
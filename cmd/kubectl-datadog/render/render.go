@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/controllers/datadogagent"
+	edsdatadoghqv1alpha1 "github.com/DataDog/extendeddaemonset/api/v1alpha1"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var renderExample = `
+  # render the manifests of the DatadogAgent defined in dda.yaml
+  %[1]s render -f dda.yaml
+
+  # render the manifests for a GKE cluster, using ExtendedDaemonSets
+  %[1]s render -f dda.yaml --kubernetes-version v1.20.8-gke.900 --extended-daemonset
+`
+
+// options provides information required by Datadog render command.
+type options struct {
+	genericclioptions.IOStreams
+	filename                 string
+	namespace                string
+	kubernetesVersion        string
+	supportExtendedDaemonset bool
+	supportCilium            bool
+	supportOpenShiftSCC      bool
+	datadogAgent             *v1alpha1.DatadogAgent
+	versionInfo              *version.Info
+}
+
+// newOptions provides an instance of options with default values.
+func newOptions(streams genericclioptions.IOStreams) *options {
+	return &options{
+		IOStreams: streams,
+	}
+}
+
+// New provides a cobra command wrapping options for "render" sub command.
+func New(streams genericclioptions.IOStreams) *cobra.Command {
+	o := newOptions(streams)
+	cmd := &cobra.Command{
+		Use:          "render -f <file>",
+		Short:        "Render the manifests of a DatadogAgent without cluster access",
+		Example:      fmt.Sprintf(renderExample, "kubectl datadog"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.complete(c, args); err != nil {
+				return err
+			}
+			if err := o.validate(); err != nil {
+				return err
+			}
+			return o.run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.filename, "filename", "f", "", "The file containing the DatadogAgent to render")
+	cmd.Flags().StringVarP(&o.namespace, "namespace", "n", "default", "The namespace of the DatadogAgent, if not set in the file")
+	cmd.Flags().StringVar(&o.kubernetesVersion, "kubernetes-version", "", "The version of the target Kubernetes cluster, for instance v1.21.2 or v1.20.8-gke.900")
+	cmd.Flags().BoolVar(&o.supportExtendedDaemonset, "extended-daemonset", false, "Render ExtendedDaemonSets, the ExtendedDaemonSet controller must be installed in the cluster")
+	cmd.Flags().BoolVar(&o.supportCilium, "cilium", false, "Render CiliumNetworkPolicies, Cilium must be installed in the cluster")
+	cmd.Flags().BoolVar(&o.supportOpenShiftSCC, "openshift-scc", false, "Render the SecurityContextConstraints of the Agent, the cluster must be an OpenShift cluster")
+
+	return cmd
+}
+
+// complete sets all information required for processing the command.
+func (o *options) complete(cmd *cobra.Command, args []string) error {
+	if o.filename == "" {
+		return errors.New("the DatadogAgent file is required, use --filename")
+	}
+
+	content, err := ioutil.ReadFile(o.filename)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %w", o.filename, err)
+	}
+	o.datadogAgent = &v1alpha1.DatadogAgent{}
+	if err = yaml.Unmarshal(content, o.datadogAgent); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", o.filename, err)
+	}
+	if o.datadogAgent.Namespace == "" {
+		o.datadogAgent.Namespace = o.namespace
+	}
+
+	if o.kubernetesVersion != "" {
+		if o.versionInfo, err = parseKubernetesVersion(o.kubernetesVersion); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate ensures that all required arguments and flag values are provided.
+func (o *options) validate() error {
+	if o.datadogAgent.Kind != "DatadogAgent" {
+		return fmt.Errorf("%s doesn't contain a DatadogAgent", o.filename)
+	}
+	if o.datadogAgent.Name == "" {
+		return errors.New("the DatadogAgent name is required")
+	}
+	return nil
+}
+
+// run runs the render command.
+func (o *options) run() error {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(apiregistrationv1.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(edsdatadoghqv1alpha1.AddToScheme(scheme))
+
+	reconcilerOptions := datadogagent.ReconcilerOptions{
+		SupportExtendedDaemonset: o.supportExtendedDaemonset,
+		SupportCilium:            o.supportCilium,
+		SupportOpenShiftSCC:      o.supportOpenShiftSCC,
+	}
+	objects, err := datadogagent.Render(scheme, reconcilerOptions, o.versionInfo, o.datadogAgent)
+	if err != nil {
+		return fmt.Errorf("unable to render the DatadogAgent: %w", err)
+	}
+
+	for _, obj := range objects {
+		manifest, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return fmt.Errorf("unable to render %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		fmt.Fprintf(o.Out, "---\n%s", manifest)
+	}
+
+	return nil
+}
+
+// parseKubernetesVersion returns the version info of a Kubernetes version, as returned by the API server
+func parseKubernetesVersion(kubernetesVersion string) (*version.Info, error) {
+	parsed, err := utilversion.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes version %q: %w", kubernetesVersion, err)
+	}
+	if !strings.HasPrefix(kubernetesVersion, "v") {
+		kubernetesVersion = "v" + kubernetesVersion
+	}
+	return &version.Info{
+		Major:      strconv.FormatUint(uint64(parsed.Major()), 10),
+		Minor:      strconv.FormatUint(uint64(parsed.Minor()), 10),
+		GitVersion: kubernetesVersion,
+	}, nil
+}
@@ -44,6 +44,12 @@ func (r *Reconciler) reconcileAgent(logger logr.Logger, dda *datadoghqv1alpha1.D
 		return result, fmt.Errorf("the Datadog agent DaemonSet cannot be renamed once created")
 	}
 
+	// agentDDA renders the default DaemonSet, on the nodes not covered by a DatadogAgentProfile
+	agentDDA, err := r.reconcileAgentProfiles(logger, dda)
+	if err != nil {
+		return result, err
+	}
+
 	nameNamespace := types.NamespacedName{
 		Name:      daemonsetName(dda),
 		Namespace: dda.ObjectMeta.Namespace,
@@ -95,10 +101,10 @@ func (r *Reconciler) reconcileAgent(logger logr.Logger, dda *datadoghqv1alpha1.D
 			return result, nil
 		}
 		if eds == nil {
-			return r.createNewExtendedDaemonSet(logger, dda, newStatus)
+			return r.createNewExtendedDaemonSet(logger, agentDDA, newStatus)
 		}
 
-		return r.updateExtendedDaemonSet(logger, dda, eds, newStatus)
+		return r.updateExtendedDaemonSet(logger, agentDDA, eds, newStatus)
 	}
 
 	// Case when Daemonset is requested
@@ -112,10 +118,10 @@ func (r *Reconciler) reconcileAgent(logger logr.Logger, dda *datadoghqv1alpha1.D
 		return result, nil
 	}
 	if ds == nil {
-		return r.createNewDaemonSet(logger, dda, newStatus)
+		return r.createNewDaemonSet(logger, agentDDA, newStatus)
 	}
 
-	return r.updateDaemonSet(logger, dda, ds, newStatus)
+	return r.updateDaemonSet(logger, agentDDA, ds, newStatus)
 }
 
 func (r *Reconciler) deleteDaemonSet(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, ds *appsv1.DaemonSet) error {
@@ -156,12 +162,9 @@ func (r *Reconciler) createNewExtendedDaemonSet(logger logr.Logger, dda *datadog
 
 	logger.Info("Creating a new ExtendedDaemonSet", "extendedDaemonSet.Namespace", newEDS.Namespace, "extendedDaemonSet.Name", newEDS.Name, "agentdeployment.Status.Agent.CurrentHash", hashEDS)
 
-	err = r.client.Create(context.TODO(), newEDS)
-	if err != nil {
+	if _, err = r.applyResource(logger, dda, newEDS); err != nil {
 		return reconcile.Result{}, err
 	}
-	event := buildEventInfo(newEDS.Name, newEDS.Namespace, extendedDaemonSetKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
 	now := metav1.NewTime(time.Now())
 	newStatus.Agent = updateExtendedDaemonSetStatus(newEDS, newStatus.Agent, &now)
 
@@ -176,18 +179,18 @@ func (r *Reconciler) createNewDaemonSet(logger logr.Logger, dda *datadoghqv1alph
 	if newDS, hashDS, err = newDaemonSetFromInstance(logger, dda, nil); err != nil {
 		return reconcile.Result{}, err
 	}
+	if err = r.applyRollbackRevision(dda, datadoghqv1alpha1.DefaultAgentResourceSuffix, hashDS, &newDS.Spec.Template); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// Set DaemonSet instance as the owner and controller
 	if err = controllerutil.SetControllerReference(dda, newDS, r.scheme); err != nil {
 		return reconcile.Result{}, err
 	}
 	logger.Info("Creating a new DaemonSet", "daemonSet.Namespace", newDS.Namespace, "daemonSet.Name", newDS.Name, "agentdeployment.Status.Agent.CurrentHash", hashDS)
-	err = r.client.Create(context.TODO(), newDS)
-	if err != nil {
+	if _, err = r.applyResource(logger, dda, newDS); err != nil {
 		return reconcile.Result{}, err
 	}
-	event := buildEventInfo(newDS.Name, newDS.Namespace, daemonSetKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
 	now := metav1.NewTime(time.Now())
 	newStatus.Agent = updateDaemonSetStatus(newDS, newStatus.Agent, &now)
 
@@ -195,37 +198,31 @@ func (r *Reconciler) createNewDaemonSet(logger logr.Logger, dda *datadoghqv1alph
 }
 
 func (r *Reconciler) updateExtendedDaemonSet(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, eds *edsdatadoghqv1alpha1.ExtendedDaemonSet, newStatus *datadoghqv1alpha1.DatadogAgentStatus) (reconcile.Result, error) {
-	now := metav1.NewTime(time.Now())
 	newEDS, newHashEDS, err := newExtendedDaemonSetFromInstance(logger, dda, eds.Spec.Selector)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	if comparison.IsSameSpecMD5Hash(newHashEDS, eds.GetAnnotations()) {
-		// no update needed so return, update the status and return
-		newStatus.Agent = updateExtendedDaemonSetStatus(eds, newStatus.Agent, &now)
-		return reconcile.Result{}, nil
-	}
-
 	// Set ExtendedDaemonSet instance as the owner and controller
-	if err = controllerutil.SetControllerReference(dda, eds, r.scheme); err != nil {
+	if err = controllerutil.SetControllerReference(dda, newEDS, r.scheme); err != nil {
 		return reconcile.Result{}, err
 	}
-	logger.Info("Updating an existing ExtendedDaemonSet", "extendedDaemonSet.Namespace", newEDS.Namespace, "extendedDaemonSet.Name", newEDS.Name)
-
-	// Copy possibly changed fields
-	updatedEds := eds.DeepCopy()
-	updatedEds.Spec = *newEDS.Spec.DeepCopy()
-	updatedEds.Annotations = mergeAnnotationsLabels(logger, eds.GetAnnotations(), newEDS.GetAnnotations(), dda.Spec.Agent.KeepAnnotations)
-	updatedEds.Labels = mergeAnnotationsLabels(logger, eds.GetLabels(), newEDS.GetLabels(), dda.Spec.Agent.KeepLabels)
-
-	err = kubernetes.UpdateFromObject(context.TODO(), r.client, updatedEds, eds.ObjectMeta)
+	// The applied object is updated with the API server response, keep a copy of the applied metadata
+	labels, annotations := mergeStringMaps(nil, newEDS.Labels), mergeStringMaps(nil, newEDS.Annotations)
+	applyResult, err := r.applyResource(logger, dda, newEDS)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	event := buildEventInfo(updatedEds.Name, updatedEds.Namespace, extendedDaemonSetKind, datadog.UpdateEvent)
-	r.recordEvent(dda, event)
-	newStatus.Agent = updateExtendedDaemonSetStatus(updatedEds, newStatus.Agent, &now)
+	now := metav1.NewTime(time.Now())
+	newStatus.Agent = updateExtendedDaemonSetStatus(newEDS, newStatus.Agent, &now)
+	if applyResult.Operation == kubernetes.ApplyOperationNone {
+		return reconcile.Result{}, nil
+	}
+	if err = r.pruneMetadata(logger, newEDS, labels, annotations, dda.Spec.Agent.KeepLabels, dda.Spec.Agent.KeepAnnotations); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	logger.Info("Updated an existing ExtendedDaemonSet", "extendedDaemonSet.Namespace", newEDS.Namespace, "extendedDaemonSet.Name", newEDS.Name, "currentHash", newHashEDS)
 	return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
 }
 
@@ -241,32 +238,34 @@ func (r *Reconciler) updateDaemonSet(logger logr.Logger, dda *datadoghqv1alpha1.
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	now := metav1.NewTime(time.Now())
-	if comparison.IsSameSpecMD5Hash(newHashDS, ds.GetAnnotations()) {
-		// no update needed so update the status and return
-		newStatus.Agent = updateDaemonSetStatus(ds, newStatus.Agent, &now)
-		return reconcile.Result{}, nil
+	if err = r.applyRollbackRevision(dda, datadoghqv1alpha1.DefaultAgentResourceSuffix, newHashDS, &newDS.Spec.Template); err != nil {
+		return reconcile.Result{}, err
 	}
 
 	// Set DaemonSet instance as the owner and controller
-	if err = controllerutil.SetControllerReference(dda, ds, r.scheme); err != nil {
+	if err = controllerutil.SetControllerReference(dda, newDS, r.scheme); err != nil {
 		return reconcile.Result{}, err
 	}
-	logger.Info("Updating an existing DaemonSet", "daemonSet.Namespace", newDS.Namespace, "daemonSet.Name", newDS.Name)
-
-	// Copy possibly changed fields
-	updatedDS := ds.DeepCopy()
-	updatedDS.Spec = *newDS.Spec.DeepCopy()
-	updatedDS.Annotations = mergeAnnotationsLabels(logger, ds.GetAnnotations(), newDS.GetAnnotations(), dda.Spec.Agent.KeepAnnotations)
-	updatedDS.Labels = mergeAnnotationsLabels(logger, ds.GetLabels(), newDS.GetLabels(), dda.Spec.Agent.KeepLabels)
-
-	err = kubernetes.UpdateFromObject(context.TODO(), r.client, updatedDS, ds.ObjectMeta)
+	// The applied object is updated with the API server response, keep a copy of the applied metadata
+	labels, annotations := mergeStringMaps(nil, newDS.Labels), mergeStringMaps(nil, newDS.Annotations)
+	applyResult, err := r.applyResource(logger, dda, newDS)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	event := buildEventInfo(updatedDS.Name, updatedDS.Namespace, daemonSetKind, datadog.UpdateEvent)
-	r.recordEvent(dda, event)
-	newStatus.Agent = updateDaemonSetStatus(updatedDS, newStatus.Agent, &now)
+	now := metav1.NewTime(time.Now())
+	newStatus.Agent = updateDaemonSetStatus(newDS, newStatus.Agent, &now)
+	if applyResult.Operation == kubernetes.ApplyOperationNone {
+		if !isStagedRolloutEnabled(dda) {
+			newStatus.Agent.StagedRollout = nil
+			return reconcile.Result{}, nil
+		}
+		return r.manageStagedRollout(logger, dda, ds, newStatus.Agent, now.Time)
+	}
+	if err = r.pruneMetadata(logger, newDS, labels, annotations, dda.Spec.Agent.KeepLabels, dda.Spec.Agent.KeepAnnotations); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	logger.Info("Updated an existing DaemonSet", "daemonSet.Namespace", newDS.Namespace, "daemonSet.Name", newDS.Name, "currentHash", newHashDS)
 	return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
 }
 
@@ -281,6 +280,16 @@ func (r *Reconciler) manageAgentDependencies(logger logr.Logger, dda *datadoghqv
 		return result, err
 	}
 
+	result, err = r.manageAgentSecurityContextConstraints(logger, dda)
+	if utils.ShouldReturn(result, err) {
+		return result, err
+	}
+
+	result, err = r.manageSidecarInjectionRBACs(logger, dda)
+	if utils.ShouldReturn(result, err) {
+		return result, err
+	}
+
 	result, err = r.manageSystemProbeDependencies(logger, dda)
 	if utils.ShouldReturn(result, err) {
 		return result, err
@@ -394,6 +403,21 @@ func (b agentNetworkPolicyBuilder) BuildKubernetesPolicy() *networkingv1.Network
 		})
 	}
 
+	// Ingress for OTLP
+	for _, port := range getOTLPContainerPorts(dda) {
+		ingressRules = append(ingressRules, networkingv1.NetworkPolicyIngressRule{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{
+					Port: &intstr.IntOrString{
+						Type:   intstr.Int,
+						IntVal: port.ContainerPort,
+					},
+					Protocol: &protocolTCP,
+				},
+			},
+		})
+	}
+
 	policy := &networkingv1.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:    getDefaultLabels(dda, name, getAgentVersion(dda)),
@@ -615,6 +639,30 @@ func (b agentNetworkPolicyBuilder) BuildCiliumPolicy() *cilium.NetworkPolicy {
 		})
 	}
 
+	for _, port := range getOTLPContainerPorts(b.dda) {
+		specs = append(specs, cilium.NetworkPolicySpec{
+			Description:      "Ingress for OTLP",
+			EndpointSelector: b.PodSelector(),
+			Ingress: []cilium.IngressRule{
+				{
+					FromEndpoints: []metav1.LabelSelector{
+						{},
+					},
+					ToPorts: []cilium.PortRule{
+						{
+							Ports: []cilium.PortProtocol{
+								{
+									Port:     strconv.Itoa(int(port.ContainerPort)),
+									Protocol: cilium.ProtocolTCP,
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
 	return &cilium.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:    getDefaultLabels(b.dda, b.Name(), getAgentVersion(b.dda)),
@@ -690,6 +738,12 @@ func newDaemonSetFromInstance(logger logr.Logger, dda *datadoghqv1alpha1.Datadog
 			},
 		},
 	}
+	if isStagedRolloutEnabled(dda) {
+		// the outdated pods are deleted by the Operator, see manageStagedRollout
+		ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+			Type: appsv1.OnDeleteDaemonSetStrategyType,
+		}
+	}
 	hashDS, err := comparison.SetMD5DatadogAgentGenerationAnnotation(&ds.ObjectMeta, dda.Spec)
 	if err != nil {
 		return nil, "", err
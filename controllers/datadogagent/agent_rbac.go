@@ -6,15 +6,10 @@
 package datadogagent
 
 import (
-	"context"
-
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	apiutils "github.com/DataDog/datadog-operator/apis/utils"
 	"github.com/go-logr/logr"
-	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -32,40 +27,17 @@ func (r *Reconciler) manageAgentRBACs(logger logr.Logger, dda *datadoghqv1alpha1
 	agentVersion := getAgentVersion(dda)
 
 	// Create or update ClusterRole
-	if result, err := r.manageClusterRole(logger, dda, rbacResourcesName, agentVersion, r.createAgentClusterRole, r.updateIfNeededAgentClusterRole, false); err != nil {
+	if result, err := r.manageClusterRole(logger, dda, rbacResourcesName, agentVersion, buildAgentClusterRole, false); err != nil {
 		return result, err
 	}
 
-	// Create ServiceAccount
-	serviceAccountName := getAgentServiceAccount(dda)
-	serviceAccount := &corev1.ServiceAccount{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: serviceAccountName, Namespace: dda.Namespace}, serviceAccount); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createServiceAccount(logger, dda, serviceAccountName, agentVersion)
-		}
-		return reconcile.Result{}, err
-	}
-
-	// Create ClusterRoleBinding
-	return r.manageClusterRoleBinding(logger, dda, rbacResourcesName, agentVersion, r.createAgentClusterRoleBinding, r.updateIfNeedAgentClusterRoleBinding, false)
-}
-
-func (r *Reconciler) createAgentClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	newClusterRoleBinding := buildAgentClusterRoleBinding(dda, name, agentVersion)
-
-	return r.createClusterRoleBinding(logger, dda, newClusterRoleBinding)
-}
-
-func (r *Reconciler) updateIfNeedAgentClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, clusterRoleBinding *rbacv1.ClusterRoleBinding) (reconcile.Result, error) {
-	serviceAccountName := getAgentServiceAccount(dda)
-	bindingInfo := roleBindingInfo{
-		name:               name,
-		roleName:           name,
-		serviceAccountName: serviceAccountName,
+	// Create or update ServiceAccount
+	if result, err := r.applyServiceAccount(logger, dda, getAgentServiceAccount(dda), agentVersion); err != nil {
+		return result, err
 	}
-	newClusterRoleBinding := buildClusterRoleBinding(dda, bindingInfo, agentVersion)
 
-	return r.updateIfNeededClusterRoleBindingRaw(logger, dda, clusterRoleBinding, newClusterRoleBinding)
+	// Create or update ClusterRoleBinding
+	return r.manageClusterRoleBinding(logger, dda, rbacResourcesName, agentVersion, buildAgentClusterRoleBinding, false)
 }
 
 // cleanupAgentRbacResources deletes ClusterRole, ClusterRoleBindings, and ServiceAccount of the Agent
@@ -83,7 +55,7 @@ func (r *Reconciler) cleanupAgentRbacResources(logger logr.Logger, dda *datadogh
 	}
 
 	// Delete Service Account
-	if result, err := r.cleanupServiceAccount(logger, r.client, dda, rbacResourcesName); err != nil {
+	if result, err := r.cleanupServiceAccount(logger, dda, rbacResourcesName); err != nil {
 		return result, err
 	}
 
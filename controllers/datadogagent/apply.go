@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
+	"github.com/DataDog/datadog-operator/pkg/kubernetes"
+)
+
+// kubernetesDaemonSetTemplateGenerationKey is set on the DaemonSets by the API server
+const kubernetesDaemonSetTemplateGenerationKey = "deprecated.daemonset.template.generation"
+
+// applyResource creates or updates obj with server-side apply.
+// Fields taken over from another field manager are reported in the DatadogAgent status, and an event is
+// recorded when the resource is created or updated.
+func (r *Reconciler) applyResource(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, obj client.Object) (kubernetes.ApplyResult, error) {
+	result, err := kubernetes.Apply(context.TODO(), r.client, obj)
+	if err != nil {
+		return result, err
+	}
+
+	r.recordAppliedResource(dda, obj)
+
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if len(result.Conflicts) > 0 {
+		logger.Info("Took over fields managed by another field manager", "kind", kind, "name", obj.GetName(), "conflicts", result.Conflicts)
+		r.applyConflicts.add(dda, fmt.Sprintf("%s %s: %s", kind, obj.GetName(), strings.Join(result.Conflicts, ", ")))
+	}
+
+	switch result.Operation {
+	case kubernetes.ApplyOperationCreated:
+		logger.V(1).Info("Created resource", "kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+		r.recordEvent(dda, buildEventInfo(obj.GetName(), obj.GetNamespace(), kind, datadog.CreationEvent))
+	case kubernetes.ApplyOperationUpdated:
+		logger.V(1).Info("Updated resource", "kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+		r.recordEvent(dda, buildEventInfo(obj.GetName(), obj.GetNamespace(), kind, datadog.UpdateEvent))
+	}
+
+	return result, nil
+}
+
+// pruneMetadata removes the labels and annotations of obj that are not applied by the Operator and that are not
+// matched by the keepLabels and keepAnnotations glob filters, which server-side apply would keep otherwise.
+// labels and annotations are the ones applied by the Operator. The labels and annotations containing 'datadoghq.com',
+// and the ones set by Kubernetes, are always kept.
+func (r *Reconciler) pruneMetadata(logger logr.Logger, obj client.Object, labels, annotations map[string]string, keepLabels, keepAnnotations string) error {
+	prunedLabels := keepKubernetesMetadata(obj.GetLabels(), mergeAnnotationsLabels(logger, obj.GetLabels(), labels, keepLabels))
+	prunedAnnotations := keepKubernetesMetadata(obj.GetAnnotations(), mergeAnnotationsLabels(logger, obj.GetAnnotations(), annotations, keepAnnotations))
+	if len(prunedLabels) == len(obj.GetLabels()) && len(prunedAnnotations) == len(obj.GetAnnotations()) {
+		return nil
+	}
+
+	current, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unable to copy %s", obj.GetName())
+	}
+	obj.SetLabels(prunedLabels)
+	obj.SetAnnotations(prunedAnnotations)
+	logger.V(1).Info("Removing labels and annotations not managed by the Operator", "kind", obj.GetObjectKind().GroupVersionKind().Kind, "name", obj.GetName())
+
+	return r.client.Patch(context.TODO(), obj, client.MergeFrom(current))
+}
+
+// keepKubernetesMetadata adds back to pruned the keys of current that are set by Kubernetes components
+func keepKubernetesMetadata(current, pruned map[string]string) map[string]string {
+	for key, value := range current {
+		if key == kubernetesDaemonSetTemplateGenerationKey || strings.Contains(key, "kubernetes.io/") || strings.Contains(key, "k8s.io/") {
+			pruned[key] = value
+		}
+	}
+	return pruned
+}
+
+// applyConflictsStore keeps the server-side apply conflicts found while reconciling each DatadogAgent,
+// until they are reported in its status.
+type applyConflictsStore struct {
+	mutex     sync.Mutex
+	conflicts map[types.NamespacedName][]string
+}
+
+func newApplyConflictsStore() *applyConflictsStore {
+	return &applyConflictsStore{
+		conflicts: make(map[types.NamespacedName][]string),
+	}
+}
+
+func (s *applyConflictsStore) add(dda *datadoghqv1alpha1.DatadogAgent, conflict string) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}
+	s.conflicts[key] = append(s.conflicts[key], conflict)
+}
+
+// pop returns and forgets the conflicts found for a DatadogAgent
+func (s *applyConflictsStore) pop(dda *datadoghqv1alpha1.DatadogAgent) []string {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}
+	conflicts := s.conflicts[key]
+	delete(s.conflicts, key)
+
+	return conflicts
+}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
+)
+
+func Test_pruneMetadata(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{})
+
+	tests := []struct {
+		name            string
+		keepLabels      string
+		keepAnnotations string
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{
+			name: "no filter",
+			wantLabels: map[string]string{
+				"app":                  "agent",
+				"foo.datadoghq.com/id": "1",
+			},
+			wantAnnotations: map[string]string{
+				"deployment.kubernetes.io/revision": "2",
+			},
+		},
+		{
+			name:            "filters",
+			keepLabels:      "team*",
+			keepAnnotations: "mesh.io/*",
+			wantLabels: map[string]string{
+				"app":                  "agent",
+				"foo.datadoghq.com/id": "1",
+				"team":                 "web",
+			},
+			wantAnnotations: map[string]string{
+				"deployment.kubernetes.io/revision": "2",
+				"mesh.io/inject":                    "true",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testutils.NewFakeClient()
+			r := &Reconciler{
+				client:   c,
+				scheme:   scheme.Scheme,
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: "bar", Name: "foo-agent", Labels: map[string]string{"app": "agent"}}}
+			_, err := r.applyResource(logger, dda, ds)
+			assert.NoError(t, err)
+
+			// Labels and annotations set by other field managers
+			ds.Labels["foo.datadoghq.com/id"] = "1"
+			ds.Labels["team"] = "web"
+			ds.Annotations = map[string]string{
+				"deployment.kubernetes.io/revision": "2",
+				"mesh.io/inject":                    "true",
+			}
+			assert.NoError(t, c.Update(context.TODO(), ds, client.FieldOwner("kubectl-label")))
+
+			assert.NoError(t, r.pruneMetadata(logger, ds, map[string]string{"app": "agent"}, nil, tt.keepLabels, tt.keepAnnotations))
+
+			got := &appsv1.DaemonSet{}
+			assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "bar", Name: "foo-agent"}, got))
+			assert.Equal(t, tt.wantLabels, got.Labels)
+			assert.Equal(t, tt.wantAnnotations, got.Annotations)
+		})
+	}
+}
@@ -85,6 +85,9 @@ func (r *Reconciler) createNewClusterAgentDeployment(logger logr.Logger, dda *da
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	if err = r.applyRollbackRevision(dda, datadoghqv1alpha1.DefaultClusterAgentResourceSuffix, hash, &newDCA.Spec.Template); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// Set DatadogAgent instance  instance as the owner and controller
 	if err = controllerutil.SetControllerReference(dda, newDCA, r.scheme); err != nil {
@@ -92,7 +95,7 @@ func (r *Reconciler) createNewClusterAgentDeployment(logger logr.Logger, dda *da
 	}
 	logger.Info("Creating a new Cluster Agent Deployment", "deployment.Namespace", newDCA.Namespace, "deployment.Name", newDCA.Name, "agentdeployment.Status.ClusterAgent.CurrentHash", hash)
 	newStatus.ClusterAgent = &datadoghqv1alpha1.DeploymentStatus{}
-	err = r.client.Create(context.TODO(), newDCA)
+	_, err = r.applyResource(logger, dda, newDCA)
 	now := metav1.NewTime(time.Now())
 	if err != nil {
 		updateStatusWithClusterAgent(nil, newStatus, &now)
@@ -100,8 +103,6 @@ func (r *Reconciler) createNewClusterAgentDeployment(logger logr.Logger, dda *da
 	}
 
 	updateStatusWithClusterAgent(newDCA, newStatus, &now)
-	event := buildEventInfo(newDCA.Name, newDCA.Namespace, deploymentKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
 	return reconcile.Result{}, nil
 }
 
@@ -114,36 +115,37 @@ func (r *Reconciler) updateClusterAgentDeployment(logger logr.Logger, dda *datad
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-
-	needUpdate := !comparison.IsSameSpecMD5Hash(hash, dca.GetAnnotations())
+	if err = r.applyRollbackRevision(dda, datadoghqv1alpha1.DefaultClusterAgentResourceSuffix, hash, &newDCA.Spec.Template); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	updateStatusWithClusterAgent(dca, newStatus, nil)
 
-	if !needUpdate {
-		return reconcile.Result{}, nil
-	}
-	logger.Info("Update ClusterAgent deployment", "name", dca.Name, "namespace", dca.Namespace)
 	// Set DatadogAgent instance  instance as the owner and controller
-	if err = controllerutil.SetControllerReference(dda, dca, r.scheme); err != nil {
+	if err = controllerutil.SetControllerReference(dda, newDCA, r.scheme); err != nil {
 		return reconcile.Result{}, err
 	}
-	logger.Info("Updating an existing Cluster Agent Deployment", "deployment.Namespace", newDCA.Namespace, "deployment.Name", newDCA.Name, "currentHash", hash)
-
-	// Copy possibly changed fields
-	updateDca := dca.DeepCopy()
-	updateDca.Spec = *newDCA.Spec.DeepCopy()
-	updateDca.Spec.Replicas = getReplicas(dca.Spec.Replicas, updateDca.Spec.Replicas)
-	updateDca.Annotations = mergeAnnotationsLabels(logger, dca.GetAnnotations(), newDCA.GetAnnotations(), dda.Spec.ClusterAgent.KeepAnnotations)
-	updateDca.Labels = mergeAnnotationsLabels(logger, dca.GetLabels(), newDCA.GetLabels(), dda.Spec.ClusterAgent.KeepLabels)
-
-	now := metav1.NewTime(time.Now())
-	err = kubernetes.UpdateFromObject(context.TODO(), r.client, updateDca, dca.ObjectMeta)
+	// When spec.clusterAgent.replicas is set, the Operator owns the replicas and overrides manual scaling,
+	// otherwise the replicas are not applied and the current value is kept
+	if err = r.handOverReplicas(logger, dca, newDCA); err != nil {
+		return reconcile.Result{}, err
+	}
+	// The applied object is updated with the API server response, keep a copy of the applied metadata
+	labels, annotations := mergeStringMaps(nil, newDCA.Labels), mergeStringMaps(nil, newDCA.Annotations)
+	applyResult, err := r.applyResource(logger, dda, newDCA)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	event := buildEventInfo(updateDca.Name, updateDca.Namespace, deploymentKind, datadog.UpdateEvent)
-	r.recordEvent(dda, event)
-	updateStatusWithClusterAgent(updateDca, newStatus, &now)
+	if applyResult.Operation == kubernetes.ApplyOperationNone {
+		return reconcile.Result{}, nil
+	}
+	if err = r.pruneMetadata(logger, newDCA, labels, annotations, dda.Spec.ClusterAgent.KeepLabels, dda.Spec.ClusterAgent.KeepAnnotations); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	logger.Info("Updated an existing Cluster Agent Deployment", "deployment.Namespace", newDCA.Namespace, "deployment.Name", newDCA.Name, "currentHash", hash)
+	now := metav1.NewTime(time.Now())
+	updateStatusWithClusterAgent(newDCA, newStatus, &now)
 	return reconcile.Result{}, nil
 }
 
@@ -182,7 +184,7 @@ func newClusterAgentDeploymentFromInstance(logger logr.Logger, dda *datadoghqv1a
 		},
 		Spec: appsv1.DeploymentSpec{
 			Template: dcaPodTemplate,
-			Replicas: dda.Spec.ClusterAgent.Replicas,
+			Replicas: getDeploymentReplicas(dda.Spec.ClusterAgent.Replicas, dda.Spec.ClusterAgent.Autoscaling),
 			Selector: selector,
 		},
 	}
@@ -231,6 +233,11 @@ func (r *Reconciler) manageClusterAgentDependencies(logger logr.Logger, dda *dat
 		return result, err
 	}
 
+	result, err = r.manageClusterAgentHPA(logger, dda)
+	if utils.ShouldReturn(result, err) {
+		return result, err
+	}
+
 	result, err = r.manageClusterAgentRBACs(logger, dda)
 	if utils.ShouldReturn(result, err) {
 		return result, err
@@ -429,6 +436,11 @@ func newClusterAgentPodTemplate(logger logr.Logger, dda *datadoghqv1alpha1.Datad
 		volumeMounts = append(volumeMounts, volumeMount)
 	}
 
+	if isRemoteConfigurationEnabled(&dda.Spec) {
+		volumes = append(volumes, getVolumeForRemoteConfiguration())
+		volumeMounts = append(volumeMounts, getVolumeMountForRemoteConfiguration())
+	}
+
 	// Add other volumes
 	if dda.Spec.ClusterAgent.Config != nil {
 		volumes = append(volumes, dda.Spec.ClusterAgent.Config.Volumes...)
@@ -445,7 +457,7 @@ func newClusterAgentPodTemplate(logger logr.Logger, dda *datadoghqv1alpha1.Datad
 		Containers: []corev1.Container{
 			{
 				Name:            "cluster-agent",
-				Image:           getImage(clusterAgentSpec.Image, dda.Spec.Registry),
+				Image:           mirrorImage(getImage(clusterAgentSpec.Image, dda.Spec.Registry), dda.Spec.ImagePolicy),
 				ImagePullPolicy: *clusterAgentSpec.Image.PullPolicy,
 				Ports: []corev1.ContainerPort{
 					{
@@ -460,10 +472,11 @@ func newClusterAgentPodTemplate(logger logr.Logger, dda *datadoghqv1alpha1.Datad
 				Args:         getDefaultIfEmpty(dda.Spec.ClusterAgent.Config.Args, nil),
 			},
 		},
-		Affinity:          getClusterAgentAffinity(clusterAgentSpec.Affinity),
-		Tolerations:       clusterAgentSpec.Tolerations,
-		PriorityClassName: dda.Spec.ClusterAgent.PriorityClassName,
-		Volumes:           volumes,
+		Affinity:                  getClusterAgentAffinity(clusterAgentSpec.Affinity),
+		Tolerations:               clusterAgentSpec.Tolerations,
+		TopologySpreadConstraints: getTopologySpreadConstraints(clusterAgentSpec.TopologySpreadConstraints, dda, datadoghqv1alpha1.DefaultClusterAgentResourceSuffix),
+		PriorityClassName:         dda.Spec.ClusterAgent.PriorityClassName,
+		Volumes:                   volumes,
 	}
 
 	newPodTemplate := corev1.PodTemplateSpec{
@@ -759,6 +772,7 @@ func getEnvVarsForClusterAgent(logger logr.Logger, dda *datadoghqv1alpha1.Datado
 	}
 
 	envVars = append(envVars, prometheusScrapeEnvVars(logger, dda)...)
+	envVars = append(envVars, getEnvVarsForRemoteConfiguration(dda)...)
 
 	return append(envVars, spec.ClusterAgent.Config.Env...), nil
 }
@@ -799,70 +813,37 @@ func (r *Reconciler) manageClusterAgentRBACs(logger logr.Logger, dda *datadoghqv
 
 	clusterAgentVersion := getClusterAgentVersion(dda)
 
-	// Create ServiceAccount
+	// Create or update ServiceAccount
 	serviceAccountName := getClusterAgentServiceAccount(dda)
-	serviceAccount := &corev1.ServiceAccount{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: serviceAccountName, Namespace: dda.Namespace}, serviceAccount); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createServiceAccount(logger, dda, serviceAccountName, clusterAgentVersion)
-		}
-		return reconcile.Result{}, err
+	if result, err := r.applyServiceAccount(logger, dda, serviceAccountName, clusterAgentVersion); err != nil {
+		return result, err
 	}
 
 	rbacResourcesName := getClusterAgentRbacResourcesName(dda)
 
 	// Create or update ClusterRole
-	clusterRole := &rbacv1.ClusterRole{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: rbacResourcesName}, clusterRole); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createClusterAgentClusterRole(logger, dda, rbacResourcesName, clusterAgentVersion)
-		}
-		return reconcile.Result{}, err
-	}
-	if result, err := r.updateIfNeededClusterAgentClusterRole(logger, dda, rbacResourcesName, clusterAgentVersion, clusterRole); err != nil {
+	if result, err := r.applyClusterRole(logger, dda, buildClusterAgentClusterRole(dda, rbacResourcesName, clusterAgentVersion)); err != nil {
 		return result, err
 	}
 
-	// Create ClusterRoleBinding
-	clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: rbacResourcesName}, clusterRoleBinding); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createClusterRoleBindingFromInfo(logger, dda, roleBindingInfo{
-				name:               rbacResourcesName,
-				roleName:           rbacResourcesName,
-				serviceAccountName: serviceAccountName,
-			}, clusterAgentVersion)
-		}
-		return reconcile.Result{}, err
+	// Create or update ClusterRoleBinding
+	info := roleBindingInfo{
+		name:               rbacResourcesName,
+		roleName:           rbacResourcesName,
+		serviceAccountName: serviceAccountName,
 	}
-	if result, err := r.updateIfNeededClusterRoleBinding(logger, dda, rbacResourcesName, rbacResourcesName, serviceAccountName, clusterAgentVersion, clusterRoleBinding); err != nil {
+	if result, err := r.applyClusterRoleBindingFromInfo(logger, dda, info, clusterAgentVersion); err != nil {
 		return result, err
 	}
 
 	// Create or update Role
-	role := &rbacv1.Role{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: rbacResourcesName, Namespace: dda.Namespace}, role); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createClusterAgentRole(logger, dda, rbacResourcesName, clusterAgentVersion)
-		}
-		return reconcile.Result{}, err
-	}
-	if result, err := r.updateIfNeededClusterAgentRole(logger, dda, rbacResourcesName, clusterAgentVersion, role); err != nil {
+	if result, err := r.applyRole(logger, dda, buildClusterAgentRole(dda, rbacResourcesName, clusterAgentVersion)); err != nil {
 		return result, err
 	}
 
 	// Create or update RoleBinding
-	roleBinding := &rbacv1.RoleBinding{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: rbacResourcesName, Namespace: dda.Namespace}, roleBinding); err != nil {
-		if errors.IsNotFound(err) {
-			info := roleBindingInfo{
-				name:               rbacResourcesName,
-				roleName:           rbacResourcesName,
-				serviceAccountName: getClusterAgentServiceAccount(dda),
-			}
-			return r.createClusterAgentRoleBinding(logger, dda, info, clusterAgentVersion)
-		}
-		return reconcile.Result{}, err
+	if result, err := r.applyRoleBinding(logger, dda, info, clusterAgentVersion); err != nil {
+		return result, err
 	}
 
 	if isKSMCoreEnabled(dda) && !isKSMCoreClusterCheck(dda) {
@@ -886,80 +867,23 @@ func (r *Reconciler) manageClusterAgentRBACs(logger logr.Logger, dda *datadoghqv
 	metricsProviderEnabled := isMetricsProviderEnabled(dda.Spec.ClusterAgent)
 	// Create or delete HPA ClusterRoleBinding
 	hpaClusterRoleBindingName := getHPAClusterRoleBindingName(dda)
-	if result, err := r.manageClusterRoleBinding(logger, dda, hpaClusterRoleBindingName, clusterAgentVersion, r.createHPAClusterRoleBinding, r.updateIfNeededHPAClusterRole, metricsProviderEnabled); err != nil {
+	if result, err := r.manageClusterRoleBinding(logger, dda, hpaClusterRoleBindingName, clusterAgentVersion, buildMetricsServerClusterRoleBinding, !metricsProviderEnabled); err != nil {
 		return result, err
 	}
 
 	// Create or delete external metrics reader ClusterRole and ClusterRoleBinding
 	metricsReaderClusterRoleName := getExternalMetricsReaderClusterRoleName(dda, r.versionInfo)
-	if result, err := r.manageClusterRole(logger, dda, metricsReaderClusterRoleName, clusterAgentVersion, r.createExternalMetricsReaderClusterRole, r.updateIfNeededExternalMetricsReaderClusterRole, metricsProviderEnabled); err != nil {
+	if result, err := r.manageClusterRole(logger, dda, metricsReaderClusterRoleName, clusterAgentVersion, buildExternalMetricsReaderClusterRole, !metricsProviderEnabled); err != nil {
 		return result, err
 	}
 
-	if result, err := r.manageClusterRoleBinding(logger, dda, metricsReaderClusterRoleName, clusterAgentVersion, r.createExternalMetricsReaderClusterRoleBinding, r.updateIfNeededClusterAgentClusterRoleBinding, metricsProviderEnabled); err != nil {
+	if result, err := r.manageClusterRoleBinding(logger, dda, metricsReaderClusterRoleName, clusterAgentVersion, buildExternalMetricsReaderClusterRoleBinding, !metricsProviderEnabled); err != nil {
 		return result, err
 	}
 
 	return reconcile.Result{}, nil
 }
 
-func (r *Reconciler) createClusterAgentClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	clusterRole := buildClusterAgentClusterRole(dda, name, agentVersion)
-	logger.V(1).Info("createClusterAgentClusterRole", "clusterRole.name", clusterRole.Name)
-	event := buildEventInfo(clusterRole.Name, clusterRole.Namespace, clusterRoleKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{Requeue: true}, r.client.Create(context.TODO(), clusterRole)
-}
-
-func (r *Reconciler) createClusterAgentRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	role := buildClusterAgentRole(dda, name, agentVersion)
-	if err := controllerutil.SetControllerReference(dda, role, r.scheme); err != nil {
-		return reconcile.Result{}, err
-	}
-	logger.V(1).Info("createClusterAgentRole", "role.name", role.Name)
-	event := buildEventInfo(role.Name, role.Namespace, roleKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{Requeue: true}, r.client.Create(context.TODO(), role)
-}
-
-func (r *Reconciler) createAgentClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	clusterRole := buildAgentClusterRole(dda, name, agentVersion)
-	logger.V(1).Info("createAgentClusterRole", "clusterRole.name", clusterRole.Name)
-	event := buildEventInfo(clusterRole.Name, clusterRole.Namespace, clusterRoleKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	err := r.client.Create(context.TODO(), clusterRole)
-	return reconcile.Result{Requeue: true}, err
-}
-
-func (r *Reconciler) createClusterCheckRunnerClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	clusterRole := buildClusterCheckRunnerClusterRole(dda, name, agentVersion)
-	logger.V(1).Info("createAgentClusterRole", "clusterRole.name", clusterRole.Name)
-	event := buildEventInfo(clusterRole.Name, clusterRole.Namespace, clusterRoleKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	err := r.client.Create(context.TODO(), clusterRole)
-	return reconcile.Result{Requeue: true}, err
-}
-
-func (r *Reconciler) updateIfNeededClusterAgentClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, clusterRole *rbacv1.ClusterRole) (reconcile.Result, error) {
-	newClusterRole := buildClusterAgentClusterRole(dda, name, agentVersion)
-	return r.updateIfNeededClusterRole(logger, dda, clusterRole, newClusterRole)
-}
-
-func (r *Reconciler) updateIfNeededClusterAgentRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, role *rbacv1.Role) (reconcile.Result, error) {
-	newRole := buildClusterAgentRole(dda, name, agentVersion)
-	return r.updateIfNeededRole(logger, dda, role, newRole)
-}
-
-func (r *Reconciler) updateIfNeededAgentClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, clusterRole *rbacv1.ClusterRole) (reconcile.Result, error) {
-	newClusterRole := buildAgentClusterRole(dda, name, agentVersion)
-	return r.updateIfNeededClusterRole(logger, dda, clusterRole, newClusterRole)
-}
-
-func (r *Reconciler) updateIfNeededClusterCheckRunnerClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, clusterRole *rbacv1.ClusterRole) (reconcile.Result, error) {
-	newClusterRole := buildClusterCheckRunnerClusterRole(dda, name, agentVersion)
-	return r.updateIfNeededClusterRole(logger, dda, clusterRole, newClusterRole)
-}
-
 // cleanupClusterAgentRbacResources deletes ClusterRole, ClusterRoleBindings, and ServiceAccount of the Cluster Agent
 func (r *Reconciler) cleanupClusterAgentRbacResources(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
 	rbacResourcesName := getClusterAgentRbacResourcesName(dda)
@@ -987,33 +911,12 @@ func (r *Reconciler) cleanupClusterAgentRbacResources(logger logr.Logger, dda *d
 	}
 
 	// Delete Service Account
-	if result, err := r.cleanupServiceAccount(logger, r.client, dda, rbacResourcesName); err != nil {
+	if result, err := r.cleanupServiceAccount(logger, dda, rbacResourcesName); err != nil {
 		return result, err
 	}
 	return reconcile.Result{}, nil
 }
 
-func (r *Reconciler) createClusterAgentRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, info roleBindingInfo, agentVersion string) (reconcile.Result, error) {
-	roleBinding := buildRoleBinding(dda, info, agentVersion)
-	if err := controllerutil.SetControllerReference(dda, roleBinding, r.scheme); err != nil {
-		return reconcile.Result{}, err
-	}
-	logger.V(1).Info("createClusterAgentRoleBinding", "roleBinding.name", roleBinding.Name, "roleBinding.Namespace", roleBinding.Namespace, "serviceAccount", info.serviceAccountName)
-	event := buildEventInfo(roleBinding.Name, roleBinding.Namespace, roleBindingKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{}, r.client.Create(context.TODO(), roleBinding)
-}
-
-func (r *Reconciler) updateIfNeededClusterAgentClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, clusterRoleBinding *rbacv1.ClusterRoleBinding) (reconcile.Result, error) {
-	info := roleBindingInfo{
-		name:               name,
-		roleName:           getClusterAgentRbacResourcesName(dda),
-		serviceAccountName: getClusterAgentServiceAccount(dda),
-	}
-	newRoleBinding := buildClusterRoleBinding(dda, info, agentVersion)
-	return r.updateIfNeededClusterRoleBindingRaw(logger, dda, clusterRoleBinding, newRoleBinding)
-}
-
 // buildAgentClusterRole creates a ClusterRole object for the Agent based on its config
 func buildAgentClusterRole(dda *datadoghqv1alpha1.DatadogAgent, name, version string) *rbacv1.ClusterRole {
 	return buildClusterRole(dda, !isClusterAgentEnabled(dda.Spec.ClusterAgent), name, version)
@@ -6,14 +6,9 @@
 package datadogagent
 
 import (
-	"context"
-
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
-	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
-	"github.com/go-logr/logr"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // buildMetricsServerClusterRoleBinding creates a ClusterRoleBinding for the Cluster Agent HPA metrics server
@@ -38,19 +33,6 @@ func buildMetricsServerClusterRoleBinding(dda *datadoghqv1alpha1.DatadogAgent, n
 	}
 }
 
-func (r *Reconciler) createHPAClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	clusterRoleBinding := buildMetricsServerClusterRoleBinding(dda, name, agentVersion)
-	logger.V(1).Info("createClusterAgentHPARoleBinding", "clusterRoleBinding.name", clusterRoleBinding.Name)
-	event := buildEventInfo(clusterRoleBinding.Name, clusterRoleBinding.Namespace, clusterRoleBindingKind, datadog.UpdateEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{Requeue: true}, r.client.Create(context.TODO(), clusterRoleBinding)
-}
-
-func (r *Reconciler) updateIfNeededHPAClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, clusterRoleBinding *rbacv1.ClusterRoleBinding) (reconcile.Result, error) {
-	newClusterRoleBinding := buildMetricsServerClusterRoleBinding(dda, name, agentVersion)
-	return r.updateIfNeededClusterRoleBindingRaw(logger, dda, clusterRoleBinding, newClusterRoleBinding)
-}
-
 // buildExternalMetricsReaderClusterRoleBinding creates a ClusterRoleBinding for the HPA controller to be able to read external metrics
 func buildExternalMetricsReaderClusterRoleBinding(dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) *rbacv1.ClusterRoleBinding {
 	if isMetricsProviderEnabled(dda.Spec.ClusterAgent) {
@@ -76,36 +58,6 @@ func buildExternalMetricsReaderClusterRoleBinding(dda *datadoghqv1alpha1.Datadog
 	return nil
 }
 
-func (r *Reconciler) createExternalMetricsReaderClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	clusterRoleBinding := buildExternalMetricsReaderClusterRoleBinding(dda, name, agentVersion)
-	if clusterRoleBinding == nil {
-		return reconcile.Result{}, nil
-	}
-	logger.V(1).Info("createExternalMetricsClusterRoleBinding", "clusterRoleBinding.name", clusterRoleBinding.Name)
-	event := buildEventInfo(clusterRoleBinding.Name, clusterRoleBinding.Namespace, clusterRoleBindingKind, datadog.UpdateEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{Requeue: true}, r.client.Create(context.TODO(), clusterRoleBinding)
-}
-
-func (r *Reconciler) createExternalMetricsReaderClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	clusterRole := buildExternalMetricsReaderClusterRole(dda, name, agentVersion)
-	if clusterRole == nil {
-		return reconcile.Result{}, nil
-	}
-	logger.V(1).Info("createExternalMetricsClusterRole", "clusterRole.name", clusterRole.Name)
-	event := buildEventInfo(clusterRole.Name, clusterRole.Namespace, clusterRoleKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{Requeue: true}, r.client.Create(context.TODO(), clusterRole)
-}
-
-func (r *Reconciler) updateIfNeededExternalMetricsReaderClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, clusterRole *rbacv1.ClusterRole) (reconcile.Result, error) {
-	newClusterRole := buildExternalMetricsReaderClusterRole(dda, name, agentVersion)
-	if newClusterRole == nil {
-		return reconcile.Result{}, nil
-	}
-	return r.updateIfNeededClusterRole(logger, dda, clusterRole, newClusterRole)
-}
-
 // buildExternalMetricsReaderClusterRole creates a ClusterRole object for access to external metrics resources
 func buildExternalMetricsReaderClusterRole(dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) *rbacv1.ClusterRole {
 	if isMetricsProviderEnabled(dda.Spec.ClusterAgent) {
@@ -10,6 +10,7 @@ import (
 	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
 	apiutils "github.com/DataDog/datadog-operator/apis/utils"
 	"github.com/DataDog/datadog-operator/controllers/datadogagent/orchestrator"
+	agenttestutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
 	"github.com/DataDog/datadog-operator/pkg/defaulting"
 	"github.com/DataDog/datadog-operator/pkg/testutils"
 
@@ -31,8 +32,9 @@ import (
 
 func clusterAgentDefaultPodSpec() v1.PodSpec {
 	return v1.PodSpec{
-		Affinity:           getClusterAgentAffinity(nil),
-		ServiceAccountName: "foo-cluster-agent",
+		Affinity:                  getClusterAgentAffinity(nil),
+		TopologySpreadConstraints: getTopologySpreadConstraints(nil, &datadoghqv1alpha1.DatadogAgent{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, datadoghqv1alpha1.DefaultClusterAgentResourceSuffix),
+		ServiceAccountName:        "foo-cluster-agent",
 		Containers: []v1.Container{
 			{
 				Name:            "cluster-agent",
@@ -1442,7 +1444,7 @@ func TestReconcileDatadogAgent_createNewClusterAgentDeployment(t *testing.T) {
 		{
 			name: "create new DCA",
 			fields: fields{
-				client:   fake.NewClientBuilder().Build(),
+				client:   agenttestutils.WithApplySupport(fake.NewClientBuilder().Build()),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -90,6 +90,9 @@ func (r *Reconciler) createNewClusterChecksRunnerDeployment(logger logr.Logger,
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	if err = r.applyRollbackRevision(dda, datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix, hash, &newDCAW.Spec.Template); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	// Set ClusterChecksRunner Deployment instance as the owner and controller
 	if err = controllerutil.SetControllerReference(dda, newDCAW, r.scheme); err != nil {
@@ -97,7 +100,7 @@ func (r *Reconciler) createNewClusterChecksRunnerDeployment(logger logr.Logger,
 	}
 	logger.Info("Creating a new Cluster Checks Runner Deployment", "deployment.Namespace", newDCAW.Namespace, "deployment.Name", newDCAW.Name, "agentdeployment.Status.ClusterChecksRunner.CurrentHash", hash)
 	newStatus.ClusterChecksRunner = &datadoghqv1alpha1.DeploymentStatus{}
-	err = r.client.Create(context.TODO(), newDCAW)
+	_, err = r.applyResource(logger, dda, newDCAW)
 	now := metav1.NewTime(time.Now())
 	if err != nil {
 		updateStatusWithClusterChecksRunner(nil, newStatus, &now)
@@ -105,8 +108,6 @@ func (r *Reconciler) createNewClusterChecksRunnerDeployment(logger logr.Logger,
 	}
 
 	updateStatusWithClusterChecksRunner(newDCAW, newStatus, &now)
-	event := buildEventInfo(newDCAW.Name, newDCAW.Namespace, deploymentKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
 	return reconcile.Result{}, nil
 }
 
@@ -119,42 +120,30 @@ func (r *Reconciler) updateClusterChecksRunnerDeployment(logger logr.Logger, dda
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-
-	needUpdate := !comparison.IsSameSpecMD5Hash(hash, dep.GetAnnotations())
-
-	updateStatusWithClusterChecksRunner(dep, newStatus, nil)
-
-	if !needUpdate {
-		return reconcile.Result{}, nil
+	if err = r.applyRollbackRevision(dda, datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix, hash, &newCLCR.Spec.Template); err != nil {
+		return reconcile.Result{}, err
 	}
 
-	logger.Info("update Cluster Checks Runner deployment", "name", dep.Name, "namespace", dep.Namespace)
+	updateStatusWithClusterChecksRunner(dep, newStatus, nil)
 
 	// Set DatadogAgent instance  instance as the owner and controller
-	if err = controllerutil.SetControllerReference(dda, dep, r.scheme); err != nil {
+	if err = controllerutil.SetControllerReference(dda, newCLCR, r.scheme); err != nil {
 		return reconcile.Result{}, err
 	}
-	logger.Info("Updating an existing Cluster Checks Runner Deployment", "deployment.Namespace", newCLCR.Namespace, "deployment.Name", newCLCR.Name, "currentHash", hash)
-
-	// Copy possibly changed fields
-	updateCLCR := dep.DeepCopy()
-	updateCLCR.Spec = *newCLCR.Spec.DeepCopy()
-	updateCLCR.Spec.Replicas = getReplicas(dep.Spec.Replicas, updateCLCR.Spec.Replicas)
-	for k, v := range newCLCR.Annotations {
-		updateCLCR.Annotations[k] = v
-	}
-	for k, v := range newCLCR.Labels {
-		updateCLCR.Labels[k] = v
+	if err = r.handOverReplicas(logger, dep, newCLCR); err != nil {
+		return reconcile.Result{}, err
 	}
-
-	now := metav1.NewTime(time.Now())
-	err = kubernetes.UpdateFromObject(context.TODO(), r.client, updateCLCR, dep.ObjectMeta)
+	applyResult, err := r.applyResource(logger, dda, newCLCR)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	event := buildEventInfo(updateCLCR.Name, updateCLCR.Namespace, deploymentKind, datadog.UpdateEvent)
-	r.recordEvent(dda, event)
-	updateStatusWithClusterChecksRunner(updateCLCR, newStatus, &now)
+	if applyResult.Operation == kubernetes.ApplyOperationNone {
+		return reconcile.Result{}, nil
+	}
+
+	logger.Info("Updated an existing Cluster Checks Runner Deployment", "deployment.Namespace", newCLCR.Namespace, "deployment.Name", newCLCR.Name, "currentHash", hash)
+	now := metav1.NewTime(time.Now())
+	updateStatusWithClusterChecksRunner(newCLCR, newStatus, &now)
 	return reconcile.Result{}, nil
 }
 
@@ -189,7 +178,7 @@ func newClusterChecksRunnerDeploymentFromInstance(
 		},
 		Spec: appsv1.DeploymentSpec{
 			Template: newClusterChecksRunnerPodTemplate(dda, labels, annotations),
-			Replicas: dda.Spec.ClusterChecksRunner.Replicas,
+			Replicas: getDeploymentReplicas(dda.Spec.ClusterChecksRunner.Replicas, dda.Spec.ClusterChecksRunner.Autoscaling),
 			Selector: selector,
 		},
 	}
@@ -208,6 +197,11 @@ func (r *Reconciler) manageClusterChecksRunnerDependencies(logger logr.Logger, d
 		return result, err
 	}
 
+	result, err = r.manageClusterChecksRunnerHPA(logger, dda)
+	if utils.ShouldReturn(result, err) {
+		return result, err
+	}
+
 	result, err = r.manageConfigMap(logger, dda, getClusterChecksRunnerCustomConfigConfigMapName(dda), buildClusterChecksRunnerConfigurationConfigMap)
 	if utils.ShouldReturn(result, err) {
 		return result, err
@@ -264,7 +258,7 @@ func newClusterChecksRunnerPodTemplate(dda *datadoghqv1alpha1.DatadogAgent, labe
 	spec := &dda.Spec
 	volumeMounts := getVolumeMountsForClusterChecksRunner(dda)
 	envVars := getEnvVarsForClusterChecksRunner(dda)
-	image := getImage(clusterChecksRunnerSpec.Image, spec.Registry)
+	image := mirrorImage(getImage(clusterChecksRunnerSpec.Image, spec.Registry), spec.ImagePolicy)
 
 	newPodTemplate := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -294,10 +288,11 @@ func newClusterChecksRunnerPodTemplate(dda *datadoghqv1alpha1.DatadogAgent, labe
 					Args:            getDefaultIfEmpty(dda.Spec.ClusterChecksRunner.Config.Args, nil),
 				},
 			},
-			Volumes:           getVolumesForClusterChecksRunner(dda),
-			Affinity:          getPodAffinity(clusterChecksRunnerSpec.Affinity),
-			Tolerations:       clusterChecksRunnerSpec.Tolerations,
-			PriorityClassName: clusterChecksRunnerSpec.PriorityClassName,
+			Volumes:                   getVolumesForClusterChecksRunner(dda),
+			Affinity:                  getPodAffinity(clusterChecksRunnerSpec.Affinity),
+			Tolerations:               clusterChecksRunnerSpec.Tolerations,
+			TopologySpreadConstraints: getTopologySpreadConstraints(clusterChecksRunnerSpec.TopologySpreadConstraints, dda, datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix),
+			PriorityClassName:         clusterChecksRunnerSpec.PriorityClassName,
 		},
 	}
 
@@ -6,15 +6,9 @@
 package datadogagent
 
 import (
-	"context"
-
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	apiutils "github.com/DataDog/datadog-operator/apis/utils"
 	"github.com/go-logr/logr"
-	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -33,41 +27,22 @@ func (r *Reconciler) manageClusterChecksRunnerRBACs(logger logr.Logger, dda *dat
 	agentVersion := getAgentVersion(dda)
 
 	// Create or update ClusterRole
-	clusterRole := &rbacv1.ClusterRole{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: rbacResourcesName}, clusterRole); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createClusterCheckRunnerClusterRole(logger, dda, rbacResourcesName, agentVersion)
-		}
-		return reconcile.Result{}, err
-	}
-	if result, err := r.updateIfNeededClusterCheckRunnerClusterRole(logger, dda, rbacResourcesName, agentVersion, clusterRole); err != nil {
+	if result, err := r.manageClusterRole(logger, dda, rbacResourcesName, agentVersion, buildClusterCheckRunnerClusterRole, false); err != nil {
 		return result, err
 	}
 
-	// Create ServiceAccount
+	// Create or update ServiceAccount
 	serviceAccountName := getClusterChecksRunnerServiceAccount(dda)
-	serviceAccount := &corev1.ServiceAccount{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: serviceAccountName, Namespace: dda.Namespace}, serviceAccount); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createServiceAccount(logger, dda, serviceAccountName, agentVersion)
-		}
-		return reconcile.Result{}, err
-	}
-
-	// Create ClusterRoleBinding
-	clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: rbacResourcesName}, clusterRoleBinding); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createClusterRoleBindingFromInfo(logger, dda, roleBindingInfo{
-				name:               rbacResourcesName,
-				roleName:           rbacResourcesName,
-				serviceAccountName: serviceAccountName,
-			}, clusterChecksRunnerVersion)
-		}
-		return reconcile.Result{}, err
+	if result, err := r.applyServiceAccount(logger, dda, serviceAccountName, agentVersion); err != nil {
+		return result, err
 	}
 
-	if result, err := r.updateIfNeededClusterRoleBinding(logger, dda, rbacResourcesName, rbacResourcesName, serviceAccountName, agentVersion, clusterRoleBinding); err != nil {
+	// Create or update ClusterRoleBinding
+	if result, err := r.applyClusterRoleBindingFromInfo(logger, dda, roleBindingInfo{
+		name:               rbacResourcesName,
+		roleName:           rbacResourcesName,
+		serviceAccountName: serviceAccountName,
+	}, clusterChecksRunnerVersion); err != nil {
 		return result, err
 	}
 
@@ -109,7 +84,7 @@ func (r *Reconciler) cleanupClusterChecksRunnerRbacResources(logger logr.Logger,
 	}
 
 	// Delete Service Account
-	if result, err := r.cleanupServiceAccount(logger, r.client, dda, rbacResourcesName); err != nil {
+	if result, err := r.cleanupServiceAccount(logger, dda, rbacResourcesName); err != nil {
 		return result, err
 	}
 
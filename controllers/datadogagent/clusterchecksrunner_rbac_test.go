@@ -13,6 +13,7 @@ import (
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	test "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
 	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
 	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
 
 	"github.com/go-logr/logr"
@@ -25,7 +26,6 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -102,7 +102,7 @@ func TestReconciler_manageClusterChecksRunnerRBACs(t *testing.T) {
 				dda:    ddaDefault,
 			},
 			fields: fields{
-				client:     fake.NewFakeClientWithScheme(s, serviceAccount, agentClusterRoleRBAC, clusterRoleBinding),
+				client:     testutils.NewFakeClientWithScheme(s, serviceAccount, agentClusterRoleRBAC, clusterRoleBinding),
 				scheme:     s,
 				recorder:   recorder,
 				forwarders: forwarders,
@@ -126,7 +126,7 @@ func TestReconciler_manageClusterChecksRunnerRBACs(t *testing.T) {
 				dda:    ddaDefault,
 			},
 			fields: fields{
-				client:     fake.NewFakeClientWithScheme(s, serviceAccount, agentClusterRoleRBAC, clusterRoleBinding, clusterRoleRBAC),
+				client:     testutils.NewFakeClientWithScheme(s, serviceAccount, agentClusterRoleRBAC, clusterRoleBinding, clusterRoleRBAC),
 				scheme:     s,
 				recorder:   recorder,
 				forwarders: forwarders,
@@ -21,8 +21,9 @@ import (
 
 func clusterChecksRunnerDefaultPodSpec() corev1.PodSpec {
 	return corev1.PodSpec{
-		Affinity:           getPodAffinity(nil),
-		ServiceAccountName: "foo-cluster-checks-runner",
+		Affinity:                  getPodAffinity(nil),
+		TopologySpreadConstraints: getTopologySpreadConstraints(nil, &datadoghqv1alpha1.DatadogAgent{ObjectMeta: metav1.ObjectMeta{Name: "foo"}}, datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix),
+		ServiceAccountName:        "foo-cluster-checks-runner",
 		InitContainers: []corev1.Container{
 			{
 				Name:            "init-config",
@@ -1,25 +1,19 @@
 package datadogagent
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/go-logr/logr"
 	networkingv1 "k8s.io/api/networking/v1"
-	apiequality "k8s.io/apimachinery/pkg/api/equality"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	cilium "github.com/DataDog/datadog-operator/pkg/cilium/v1"
-	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
-	"github.com/DataDog/datadog-operator/pkg/kubernetes"
 )
 
 const (
@@ -35,34 +29,17 @@ type networkPolicyBuilder interface {
 }
 
 func (r *Reconciler) ensureNetworkPolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, builder networkPolicyBuilder) (reconcile.Result, error) {
-	var (
-		err    error
-		result reconcile.Result
-	)
-
 	policyName := builder.Name()
 	policySpec := builder.NetworkPolicySpec()
 
 	switch policySpec.Flavor {
 	case datadoghqv1alpha1.NetworkPolicyFlavorKubernetes:
-		policy := &networkingv1.NetworkPolicy{}
-		err = r.client.Get(context.TODO(), types.NamespacedName{Name: policyName, Namespace: dda.Namespace}, policy)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				return r.createKubernetesNetworkPolicy(logger, dda, builder)
-			}
-
-			return reconcile.Result{}, err
-		}
-
-		result, err = r.updateKubernetesNetworkPolicy(logger, dda, policy, builder)
-		if err != nil {
+		if result, err := r.manageResource(logger, dda, kubernetesNetworkPolicyResource(dda, policyName, builder)); err != nil {
 			return result, err
 		}
 
 		if r.options.SupportCilium {
-			err = r.cleanupCiliumNetworkPolicy(logger, dda, policyName)
-			if err != nil {
+			if err := r.cleanupResource(logger, dda, ciliumNetworkPolicyResource(dda, policyName, nil)); err != nil {
 				return reconcile.Result{}, err
 			}
 		}
@@ -71,23 +48,11 @@ func (r *Reconciler) ensureNetworkPolicy(logger logr.Logger, dda *datadoghqv1alp
 			return reconcile.Result{}, fmt.Errorf("cilium network policy support is not enabled in the operator")
 		}
 
-		policy := emptyCiliumUnstructuredPolicy()
-		err = r.client.Get(context.TODO(), types.NamespacedName{Name: policyName, Namespace: dda.Namespace}, policy)
-		if err != nil {
-			if errors.IsNotFound(err) {
-				return r.createCiliumNetworkPolicy(logger, dda, builder)
-			}
-
-			return reconcile.Result{}, err
-		}
-
-		result, err = r.updateCiliumNetworkPolicy(logger, dda, policy, builder)
-		if err != nil {
+		if result, err := r.manageResource(logger, dda, ciliumNetworkPolicyResource(dda, policyName, builder)); err != nil {
 			return result, err
 		}
 
-		err = r.cleanupKubernetesNetworkPolicy(logger, dda, policyName)
-		if err != nil {
+		if err := r.cleanupResource(logger, dda, kubernetesNetworkPolicyResource(dda, policyName, nil)); err != nil {
 			return reconcile.Result{}, err
 		}
 	default:
@@ -98,16 +63,12 @@ func (r *Reconciler) ensureNetworkPolicy(logger logr.Logger, dda *datadoghqv1alp
 }
 
 func (r *Reconciler) cleanupNetworkPolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) (reconcile.Result, error) {
-	var err error
-
-	err = r.cleanupKubernetesNetworkPolicy(logger, dda, name)
-	if err != nil {
+	if err := r.cleanupResource(logger, dda, kubernetesNetworkPolicyResource(dda, name, nil)); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	if r.options.SupportCilium {
-		err = r.cleanupCiliumNetworkPolicy(logger, dda, name)
-		if err != nil {
+		if err := r.cleanupResource(logger, dda, ciliumNetworkPolicyResource(dda, name, nil)); err != nil {
 			return reconcile.Result{}, err
 		}
 	}
@@ -115,135 +76,40 @@ func (r *Reconciler) cleanupNetworkPolicy(logger logr.Logger, dda *datadoghqv1al
 	return reconcile.Result{}, nil
 }
 
-func (r *Reconciler) cleanupKubernetesNetworkPolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) error {
-	policy := &networkingv1.NetworkPolicy{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: dda.Namespace}, policy)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return nil
-		}
-
-		return err
-	}
-
-	if !CheckOwnerReference(dda, policy) {
-		return nil
-	}
-
-	logger.V(1).Info("deleteNetworkPolicy", "networkPolicy.name", policy.Name, "networkPolicy.Namespace", policy.Namespace, "networkPolicy.Flavor", datadoghqv1alpha1.NetworkPolicyFlavorKubernetes)
-	event := buildEventInfo(policy.Name, policy.Namespace, networkPolicyKind, datadog.DeletionEvent)
-	r.recordEvent(dda, event)
-
-	return r.client.Delete(context.TODO(), policy)
-}
-
-func (r *Reconciler) cleanupCiliumNetworkPolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) error {
-	policy := emptyCiliumUnstructuredPolicy()
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: dda.Namespace}, policy)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return nil
-		}
-
-		return err
-	}
-
-	if !CheckOwnerReference(dda, policy) {
-		return nil
-	}
-
-	logger.V(1).Info("deleteNetworkPolicy", "networkPolicy.name", policy.GetName(), "networkPolicy.Namespace", policy.GetNamespace(), "networkPolicy.Flavor", datadoghqv1alpha1.NetworkPolicyFlavorCilium)
-	event := buildEventInfo(policy.GetName(), policy.GetNamespace(), ciliumNetworkPolicyKind, datadog.DeletionEvent)
-	r.recordEvent(dda, event)
-
-	return r.client.Delete(context.TODO(), policy)
-}
-
-func (r *Reconciler) createKubernetesNetworkPolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, builder networkPolicyBuilder) (reconcile.Result, error) {
-	policy := builder.BuildKubernetesPolicy()
-
-	err := controllerutil.SetControllerReference(dda, policy, r.scheme)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	logger.V(1).Info("createNetworkPolicy", "networkPolicy.name", policy.Name, "networkPolicy.Namespace", policy.Namespace, "networkPolicy.Flavor", datadoghqv1alpha1.NetworkPolicyFlavorKubernetes)
-	event := buildEventInfo(policy.Name, policy.Namespace, networkPolicyKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-
-	return reconcile.Result{}, r.client.Create(context.TODO(), policy)
-}
-
-func (r *Reconciler) updateKubernetesNetworkPolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, policy *networkingv1.NetworkPolicy, builder networkPolicyBuilder) (reconcile.Result, error) {
-	newPolicy := builder.BuildKubernetesPolicy()
-
-	if !apiequality.Semantic.DeepEqual(newPolicy.Spec, policy.Spec) {
-		logger.V(1).Info("updateNetworkPolicy", "networkPolicy.name", policy.Name, "networkPolicy.Namespace", policy.Namespace, "networkPolicy.Flavor", datadoghqv1alpha1.NetworkPolicyFlavorCilium)
-
-		if err := kubernetes.UpdateFromObject(context.TODO(), r.client, newPolicy, policy.ObjectMeta); err != nil {
-			return reconcile.Result{}, err
-		}
-
-		event := buildEventInfo(newPolicy.Name, newPolicy.Namespace, ciliumNetworkPolicyKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
-	}
-
-	return reconcile.Result{}, nil
-}
-
-func (r *Reconciler) createCiliumNetworkPolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, builder networkPolicyBuilder) (reconcile.Result, error) {
-	policy := builder.BuildCiliumPolicy()
-
-	err := controllerutil.SetControllerReference(dda, policy, r.scheme)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	logger.V(1).Info("createNetworkPolicy", "networkPolicy.name", policy.GetName(), "networkPolicy.Namespace", policy.GetNamespace(), "networkPolicy.Flavor", datadoghqv1alpha1.NetworkPolicyFlavorCilium)
-	event := buildEventInfo(policy.GetName(), policy.GetNamespace(), ciliumNetworkPolicyKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-
-	unstructured := &unstructured.Unstructured{}
-	unstructured.Object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(policy)
-	if err != nil {
-		return reconcile.Result{}, err
+// kubernetesNetworkPolicyResource describes a Kubernetes NetworkPolicy, builder is only needed to apply it
+func kubernetesNetworkPolicyResource(dda *datadoghqv1alpha1.DatadogAgent, name string, builder networkPolicyBuilder) managedResource {
+	return managedResource{
+		kind:      networkPolicyKind,
+		name:      name,
+		namespace: dda.Namespace,
+		newObject: func() client.Object { return &networkingv1.NetworkPolicy{} },
+		build: func() (client.Object, error) {
+			return builder.BuildKubernetesPolicy(), nil
+		},
+		ownership: ownedByController,
 	}
-	unstructured.SetGroupVersionKind(ciliumGroupVersionKind())
-
-	return reconcile.Result{}, r.client.Create(context.TODO(), unstructured)
 }
 
-func (r *Reconciler) updateCiliumNetworkPolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, policy *unstructured.Unstructured, builder networkPolicyBuilder) (reconcile.Result, error) {
-	newPolicy := builder.BuildCiliumPolicy()
-
-	var (
-		newUnstructured map[string]interface{}
-		err             error
-	)
-
-	newUnstructured, err = runtime.DefaultUnstructuredConverter.ToUnstructured(newPolicy)
-	if err != nil {
-		return reconcile.Result{}, err
-	}
-
-	if !apiequality.Semantic.DeepEqual(newUnstructured["specs"], policy.Object["specs"]) {
-		logger.V(1).Info("updateNetworkPolicy", "networkPolicy.name", policy.GetName(), "networkPolicy.Namespace", policy.GetNamespace(), "networkPolicy.Flavor", datadoghqv1alpha1.NetworkPolicyFlavorCilium)
-
-		newUnstructuredPolicy := &unstructured.Unstructured{}
-		newUnstructuredPolicy.Object = newUnstructured
-		newUnstructuredPolicy.SetGroupVersionKind(ciliumGroupVersionKind())
-		newUnstructuredPolicy.SetResourceVersion(policy.GetResourceVersion())
-
-		err := r.client.Update(context.TODO(), newUnstructuredPolicy)
-		if err != nil {
-			return reconcile.Result{}, err
-		}
-
-		event := buildEventInfo(newPolicy.GetName(), newPolicy.GetNamespace(), ciliumNetworkPolicyKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
+// ciliumNetworkPolicyResource describes a CiliumNetworkPolicy, builder is only needed to apply it.
+// The Cilium types are not registered in the scheme, the policy is handled as an unstructured object.
+func ciliumNetworkPolicyResource(dda *datadoghqv1alpha1.DatadogAgent, name string, builder networkPolicyBuilder) managedResource {
+	return managedResource{
+		kind:      ciliumNetworkPolicyKind,
+		name:      name,
+		namespace: dda.Namespace,
+		newObject: func() client.Object { return emptyCiliumUnstructuredPolicy() },
+		build: func() (client.Object, error) {
+			policy := &unstructured.Unstructured{}
+			var err error
+			policy.Object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(builder.BuildCiliumPolicy())
+			if err != nil {
+				return nil, err
+			}
+			policy.SetGroupVersionKind(ciliumGroupVersionKind())
+			return policy, nil
+		},
+		ownership: ownedByController,
 	}
-
-	return reconcile.Result{}, nil
 }
 
 func ciliumGroupVersionKind() schema.GroupVersionKind {
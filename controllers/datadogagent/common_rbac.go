@@ -1,21 +1,14 @@
 package datadogagent
 
 import (
-	"context"
-
 	"github.com/DataDog/datadog-operator/pkg/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
-	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
-	apiequality "k8s.io/apimachinery/pkg/api/equality"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -88,177 +81,113 @@ func getLeaderElectionPolicyRule() []rbacv1.PolicyRule {
 	}
 }
 
-func (r *Reconciler) createServiceAccount(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
-	serviceAccount := buildServiceAccount(dda, name, agentVersion)
-	if err := controllerutil.SetControllerReference(dda, serviceAccount, r.scheme); err != nil {
-		return reconcile.Result{}, err
-	}
-	logger.V(1).Info("createServiceAccount", "serviceAccount.name", serviceAccount.Name, "serviceAccount.Namespace", serviceAccount.Namespace)
-	event := buildEventInfo(serviceAccount.Name, serviceAccount.Namespace, serviceAccountKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{Requeue: true}, r.client.Create(context.TODO(), serviceAccount)
+func (r *Reconciler) cleanupClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) (reconcile.Result, error) {
+	return reconcile.Result{}, r.cleanupResource(logger, dda, clusterRoleResource(name, nil))
 }
 
-func (r *Reconciler) createClusterRoleBindingFromInfo(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, info roleBindingInfo, agentVersion string) (reconcile.Result, error) {
-	clusterRoleBinding := buildClusterRoleBinding(dda, info, agentVersion)
-	return r.createClusterRoleBinding(logger, dda, clusterRoleBinding)
+func (r *Reconciler) cleanupClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) (reconcile.Result, error) {
+	return reconcile.Result{}, r.cleanupResource(logger, dda, clusterRoleBindingResource(name, nil))
 }
 
-func (r *Reconciler) createClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRoleBinding *rbacv1.ClusterRoleBinding) (reconcile.Result, error) {
-	logger.V(1).Info("createClusterRoleBinding", "clusterRoleBinding.name", clusterRoleBinding.Name)
-	event := buildEventInfo(clusterRoleBinding.Name, clusterRoleBinding.Namespace, clusterRoleBindingKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	err := r.client.Create(context.TODO(), clusterRoleBinding)
-	return reconcile.Result{}, err
+func (r *Reconciler) cleanupServiceAccount(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) (reconcile.Result, error) {
+	return reconcile.Result{}, r.cleanupResource(logger, dda, serviceAccountResource(dda, name, nil))
 }
 
-func (r *Reconciler) cleanupClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) (reconcile.Result, error) {
-	clusterRole := &rbacv1.ClusterRole{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name}, clusterRole)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return reconcile.Result{}, nil
-		}
-		return reconcile.Result{}, err
-	}
-
-	if !isOwnerBasedOnLabels(dda, clusterRole.Labels) {
-		return reconcile.Result{}, nil
-	}
+type (
+	buildClusterRoleFunc        func(dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) *rbacv1.ClusterRole
+	buildClusterRoleBindingFunc func(dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) *rbacv1.ClusterRoleBinding
+)
 
-	logger.V(1).Info("deleteClusterRole", "clusterRole.name", clusterRole.Name, "clusterRole.Namespace", clusterRole.Namespace)
-	event := buildEventInfo(clusterRole.Name, clusterRole.Namespace, clusterRoleKind, datadog.DeletionEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{}, r.client.Delete(context.TODO(), clusterRole)
+func (r *Reconciler) manageClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRoleName, agentVersion string, buildFunc buildClusterRoleFunc, shouldCleanup bool) (reconcile.Result, error) {
+	res := clusterRoleResource(clusterRoleName, func() *rbacv1.ClusterRole { return buildFunc(dda, clusterRoleName, agentVersion) })
+	res.cleanup = shouldCleanup
+	return r.manageResource(logger, dda, res)
 }
 
-func (r *Reconciler) cleanupClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) (reconcile.Result, error) {
-	clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name}, clusterRoleBinding)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return reconcile.Result{}, nil
-		}
-		return reconcile.Result{}, err
-	}
-
-	if !isOwnerBasedOnLabels(dda, clusterRoleBinding.Labels) {
-		return reconcile.Result{}, nil
-	}
-
-	logger.V(1).Info("deleteClusterRoleBinding", "clusterRoleBinding.name", clusterRoleBinding.Name, "clusterRoleBinding.Namespace", clusterRoleBinding.Namespace)
-	event := buildEventInfo(clusterRoleBinding.Name, clusterRoleBinding.Namespace, clusterRoleBindingKind, datadog.DeletionEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{}, r.client.Delete(context.TODO(), clusterRoleBinding)
+func (r *Reconciler) manageClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRoleBindingName, agentVersion string, buildFunc buildClusterRoleBindingFunc, shouldCleanup bool) (reconcile.Result, error) {
+	res := clusterRoleBindingResource(clusterRoleBindingName, func() *rbacv1.ClusterRoleBinding {
+		return buildFunc(dda, clusterRoleBindingName, agentVersion)
+	})
+	res.cleanup = shouldCleanup
+	return r.manageResource(logger, dda, res)
 }
 
-func (r *Reconciler) cleanupServiceAccount(logger logr.Logger, client client.Client, dda *datadoghqv1alpha1.DatadogAgent, name string) (reconcile.Result, error) {
-	serviceAccount := &corev1.ServiceAccount{}
-	err := client.Get(context.TODO(), types.NamespacedName{Name: name}, serviceAccount)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return reconcile.Result{}, nil
-		}
-		return reconcile.Result{}, err
-	}
-	if !CheckOwnerReference(dda, serviceAccount) {
-		return reconcile.Result{}, nil
-	}
-	logger.V(1).Info("deleteServiceAccount", "serviceAccount.name", serviceAccount.Name, "serviceAccount.Namespace", serviceAccount.Namespace)
-	event := buildEventInfo(serviceAccount.Name, serviceAccount.Namespace, serviceAccountKind, datadog.DeletionEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{}, client.Delete(context.TODO(), serviceAccount)
+func (r *Reconciler) applyServiceAccount(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error) {
+	return r.manageResource(logger, dda, serviceAccountResource(dda, name, func() *corev1.ServiceAccount {
+		return buildServiceAccount(dda, name, agentVersion)
+	}))
 }
 
-type (
-	createClusterRoleFunc       func(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, resourceName, agentVersion string) (reconcile.Result, error)
-	updateIfNeedClusterRoleFunc func(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, resourceName, agentVersion string, clusterRole *rbacv1.ClusterRole) (reconcile.Result, error)
-)
-
-func (r *Reconciler) manageClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRoleName, agentVersion string, createFunc createClusterRoleFunc, updateFunc updateIfNeedClusterRoleFunc, shouldCleanup bool) (reconcile.Result, error) {
-	clusterRole := &rbacv1.ClusterRole{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: clusterRoleName}, clusterRole); err != nil {
-		if errors.IsNotFound(err) {
-			return createFunc(logger, dda, clusterRoleName, agentVersion)
-		} else if err != nil {
-			return reconcile.Result{}, err
-		}
-	}
-	if shouldCleanup {
-		return r.cleanupClusterRole(logger, dda, clusterRoleName)
-	}
-	return updateFunc(logger, dda, clusterRoleName, agentVersion, clusterRole)
+func (r *Reconciler) applyClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRole *rbacv1.ClusterRole) (reconcile.Result, error) {
+	return r.manageResource(logger, dda, clusterRoleResource(clusterRole.Name, func() *rbacv1.ClusterRole { return clusterRole }))
 }
 
-type (
-	createClusterRoleBindingFunc       func(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string) (reconcile.Result, error)
-	updateIfNeedClusterRoleBindingFunc func(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, agentVersion string, clusterRole *rbacv1.ClusterRoleBinding) (reconcile.Result, error)
-)
-
-func (r *Reconciler) manageClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRoleBindingName, agentVersion string, createFunc createClusterRoleBindingFunc, updateFunc updateIfNeedClusterRoleBindingFunc, shouldCleanup bool) (reconcile.Result, error) {
-	clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: clusterRoleBindingName}, clusterRoleBinding); err != nil {
-		if errors.IsNotFound(err) {
-			return createFunc(logger, dda, clusterRoleBindingName, agentVersion)
-		} else if err != nil {
-			return reconcile.Result{}, err
-		}
-	}
-	if shouldCleanup {
-		return r.cleanupClusterRoleBinding(logger, dda, clusterRoleBindingName)
-	}
+func (r *Reconciler) applyClusterRoleBindingFromInfo(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, info roleBindingInfo, agentVersion string) (reconcile.Result, error) {
+	return r.manageResource(logger, dda, clusterRoleBindingResource(info.name, func() *rbacv1.ClusterRoleBinding {
+		return buildClusterRoleBinding(dda, info, agentVersion)
+	}))
+}
 
-	return updateFunc(logger, dda, clusterRoleBindingName, agentVersion, clusterRoleBinding)
+func (r *Reconciler) applyRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, role *rbacv1.Role) (reconcile.Result, error) {
+	return r.manageResource(logger, dda, managedResource{
+		kind:      roleKind,
+		name:      role.Name,
+		namespace: role.Namespace,
+		newObject: func() client.Object { return &rbacv1.Role{} },
+		build:     func() (client.Object, error) { return role, nil },
+		ownership: ownedByController,
+	})
 }
 
-func (r *Reconciler) updateIfNeededClusterRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRoleBindingName, roleName, serviceAccountName, version string, clusterRoleBinding *rbacv1.ClusterRoleBinding) (reconcile.Result, error) {
-	info := roleBindingInfo{
-		name:               clusterRoleBindingName,
-		roleName:           roleName,
-		serviceAccountName: serviceAccountName,
-	}
-	newClusterRoleBinding := buildClusterRoleBinding(dda, info, version)
-	return r.updateIfNeededClusterRoleBindingRaw(logger, dda, clusterRoleBinding, newClusterRoleBinding)
+func (r *Reconciler) applyRoleBinding(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, info roleBindingInfo, agentVersion string) (reconcile.Result, error) {
+	return r.manageResource(logger, dda, managedResource{
+		kind:      roleBindingKind,
+		name:      info.name,
+		namespace: dda.Namespace,
+		newObject: func() client.Object { return &rbacv1.RoleBinding{} },
+		build:     func() (client.Object, error) { return buildRoleBinding(dda, info, agentVersion), nil },
+		ownership: ownedByController,
+		// The RoleRef of a RoleBinding is immutable
+		recreateOnInvalid: true,
+	})
 }
 
-func (r *Reconciler) updateIfNeededClusterRoleBindingRaw(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRoleBinding, newClusterRoleBinding *rbacv1.ClusterRoleBinding) (reconcile.Result, error) {
-	if !isClusterRolesBindingEqual(newClusterRoleBinding, clusterRoleBinding) {
-		logger.V(1).Info("updateIfNeededClusterRoleBinding", "clusterRoleBinding.name", clusterRoleBinding.Name)
-		// ClusterRoleBinding can't be updated, if we change the RoleRef in it, we need to delete and recreate
-		if err := r.client.Delete(context.TODO(), clusterRoleBinding); err != nil {
-			return reconcile.Result{}, err
-		}
-		if err := r.client.Create(context.TODO(), newClusterRoleBinding); err != nil {
-			return reconcile.Result{}, err
-		}
-		event := buildEventInfo(newClusterRoleBinding.Name, newClusterRoleBinding.Namespace, clusterRoleKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
+// clusterRoleResource describes a ClusterRole, build is only needed to apply it.
+// ClusterRoles are cluster-scoped, the DatadogAgent ownership is tracked with labels.
+func clusterRoleResource(name string, build func() *rbacv1.ClusterRole) managedResource {
+	return managedResource{
+		kind:      clusterRoleKind,
+		name:      name,
+		newObject: func() client.Object { return &rbacv1.ClusterRole{} },
+		build:     func() (client.Object, error) { return build(), nil },
+		ownership: ownedByLabels,
 	}
-	return reconcile.Result{}, nil
 }
 
-func (r *Reconciler) updateIfNeededClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, clusterRole, newClusterRole *rbacv1.ClusterRole) (reconcile.Result, error) {
-	if !isClusterRolesEqual(newClusterRole, clusterRole) {
-		logger.V(1).Info("updateIfNeededClusterRole", "clusterRole.name", clusterRole.Name)
-		if err := kubernetes.UpdateFromObject(context.TODO(), r.client, newClusterRole, clusterRole.ObjectMeta); err != nil {
-			return reconcile.Result{}, err
-		}
-		event := buildEventInfo(newClusterRole.Name, newClusterRole.Namespace, clusterRoleKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
+// clusterRoleBindingResource describes a ClusterRoleBinding, build is only needed to apply it.
+// Like ClusterRoles, the DatadogAgent ownership is tracked with labels.
+func clusterRoleBindingResource(name string, build func() *rbacv1.ClusterRoleBinding) managedResource {
+	return managedResource{
+		kind:      clusterRoleBindingKind,
+		name:      name,
+		newObject: func() client.Object { return &rbacv1.ClusterRoleBinding{} },
+		build:     func() (client.Object, error) { return build(), nil },
+		ownership: ownedByLabels,
+		// The RoleRef of a ClusterRoleBinding is immutable
+		recreateOnInvalid: true,
 	}
-	return reconcile.Result{}, nil
 }
 
-func (r *Reconciler) updateIfNeededRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, role, newRole *rbacv1.Role) (reconcile.Result, error) {
-	if !isRolesEqual(newRole, role) {
-		logger.V(1).Info("updateIfNeededRole", "role.name", role.Name)
-		if err := kubernetes.UpdateFromObject(context.TODO(), r.client, newRole, role.ObjectMeta); err != nil {
-			return reconcile.Result{}, err
-		}
-		event := buildEventInfo(newRole.Name, newRole.Namespace, roleKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
+// serviceAccountResource describes a ServiceAccount, build is only needed to apply it
+func serviceAccountResource(dda *datadoghqv1alpha1.DatadogAgent, name string, build func() *corev1.ServiceAccount) managedResource {
+	return managedResource{
+		kind:      serviceAccountKind,
+		name:      name,
+		namespace: dda.Namespace,
+		newObject: func() client.Object { return &corev1.ServiceAccount{} },
+		build:     func() (client.Object, error) { return build(), nil },
+		ownership: ownedByController,
 	}
-	return reconcile.Result{}, nil
 }
 
 // isOwnerBasedOnLabels returns whether a DatadogAgent is the owner of a
@@ -289,19 +218,3 @@ func rbacNamesForDda(dda *datadoghqv1alpha1.DatadogAgent, versionInfo *version.I
 		getOrchestratorRBACResourceName(dda, checkRunnersSuffix),
 	}
 }
-
-func isRolesEqual(a, b *rbacv1.Role) bool {
-	return apiequality.Semantic.DeepEqual(a.Rules, b.Rules) && apiequality.Semantic.DeepEqual(a.ObjectMeta.OwnerReferences, b.ObjectMeta.OwnerReferences)
-}
-
-func isClusterRolesEqual(a, b *rbacv1.ClusterRole) bool {
-	// Even if a ClusterRole should not contain DatadogAgent ownerref, we need to check it is the case to be able to remove the ownerref that might have added
-	// by a previous Operator version.
-	return apiequality.Semantic.DeepEqual(a.Rules, b.Rules) && apiequality.Semantic.DeepEqual(a.ObjectMeta.OwnerReferences, b.ObjectMeta.OwnerReferences)
-}
-
-func isClusterRolesBindingEqual(a, b *rbacv1.ClusterRoleBinding) bool {
-	// Even if a ClusterRoleBinding should not contain DatadogAgent ownerref, we need to check it is the case to be able to remove the ownerref that might have added
-	// by a previous Operator version.
-	return apiequality.Semantic.DeepEqual(a.RoleRef, b.RoleRef) && apiequality.Semantic.DeepEqual(a.Subjects, b.Subjects) && apiequality.Semantic.DeepEqual(a.ObjectMeta.OwnerReferences, b.ObjectMeta.OwnerReferences)
-}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
 	"github.com/DataDog/datadog-operator/pkg/kubernetes"
 	assert "github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -64,7 +65,7 @@ func Test_cleanupClusterRole(t *testing.T) {
 					Labels: tt.clusterRoleLabels,
 				},
 			}
-			fakeClient := fake.NewClientBuilder().WithObjects(&clusterRole).Build()
+			fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithObjects(&clusterRole).Build())
 			r := newReconcilerForRbacTests(fakeClient)
 
 			_, err := r.cleanupClusterRole(
@@ -128,7 +129,7 @@ func Test_cleanupClusterRoleBinding(t *testing.T) {
 					Labels: tt.clusterRoleBindingLabels,
 				},
 			}
-			fakeClient := fake.NewClientBuilder().WithObjects(&clusterRoleBinding).Build()
+			fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithObjects(&clusterRoleBinding).Build())
 			reconciler := newReconcilerForRbacTests(fakeClient)
 
 			_, err := reconciler.cleanupClusterRoleBinding(
@@ -0,0 +1,439 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	"github.com/DataDog/datadog-operator/pkg/controller/utils/condition"
+)
+
+const (
+	conflictEventReason = "Conflict"
+
+	// maxConflictingNodes is the number of conflicting nodes listed in the Conflict condition
+	maxConflictingNodes = 5
+)
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// reconcileConflicts checks that the DatadogAgent doesn't conflict with an older DatadogAgent of the cluster:
+// node Agents running on the same nodes or using the same host ports, and cluster-scoped RBACs with the same names.
+// A conflicting DatadogAgent gets the Conflict condition and is not reconciled, until the conflict is solved.
+func (r *Reconciler) reconcileConflicts(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus) (reconcile.Result, error) {
+	now := metav1.NewTime(time.Now())
+
+	conflicts, err := r.getConflicts(logger, dda)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if len(conflicts) == 0 {
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypeConflict, corev1.ConditionFalse, "", false)
+		return reconcile.Result{}, nil
+	}
+
+	description := fmt.Sprintf("Conflicts with older DatadogAgents, not reconciled: %s", strings.Join(conflicts, "; "))
+	logger.Info("DatadogAgent in conflict, skipping", "conflicts", conflicts)
+	if !isConditionReported(&dda.Status, datadoghqv1alpha1.DatadogAgentConditionTypeConflict, description) {
+		r.recorder.Event(dda, corev1.EventTypeWarning, conflictEventReason, description)
+	}
+	condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypeConflict, corev1.ConditionTrue, description, false)
+
+	// Check again later, the conflict is solved when the other DatadogAgent is updated or deleted
+	return reconcile.Result{RequeueAfter: defaultRequeuePeriod}, nil
+}
+
+// getConflicts returns a description of the conflicts of the DatadogAgent with each older DatadogAgent.
+// The pod templates and the nodes of each DatadogAgent are kept in r.nodeAgentFootprints, they are only rendered
+// and matched again when the DatadogAgent, its profiles or the node labels change.
+func (r *Reconciler) getConflicts(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) ([]string, error) {
+	ddaList := &datadoghqv1alpha1.DatadogAgentList{}
+	if err := r.client.List(context.TODO(), ddaList); err != nil {
+		return nil, err
+	}
+	r.nodeAgentFootprints.prune(ddaList.Items)
+
+	profileList := &datadoghqv1alpha1.DatadogAgentProfileList{}
+	if err := r.client.List(context.TODO(), profileList); err != nil {
+		return nil, err
+	}
+	profiles := make(map[string][]datadoghqv1alpha1.DatadogAgentProfile)
+	for _, profile := range profileList.Items {
+		profiles[profile.Namespace] = append(profiles[profile.Namespace], profile)
+	}
+	for _, nsProfiles := range profiles {
+		sortAgentProfiles(nsProfiles)
+	}
+
+	footprint, err := r.getNodeAgentFootprint(logger, dda, profiles[dda.Namespace])
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []corev1.Node
+	var nodesKey string
+	var nodesListed bool
+	var conflicts []string
+	for i := range ddaList.Items {
+		other := ddaList.Items[i].DeepCopy()
+		if other.Namespace == dda.Namespace && other.Name == dda.Name {
+			continue
+		}
+		if other.DeletionTimestamp != nil || !isOlderDatadogAgent(other, dda) {
+			continue
+		}
+		datadoghqv1alpha1.DefaultDatadogAgent(other)
+
+		var reasons []string
+		if rbacNames := intersectStrings(rbacNamesForDda(dda, r.versionInfo), rbacNamesForDda(other, r.versionInfo)); len(rbacNames) > 0 {
+			reasons = append(reasons, fmt.Sprintf("cluster-scoped RBAC names (%s)", strings.Join(rbacNames, ", ")))
+		}
+
+		if len(footprint.templates) > 0 && !apiutils.BoolValue(dda.Spec.Agent.DisjointNodes) && !apiutils.BoolValue(other.Spec.Agent.DisjointNodes) {
+			otherFootprint, err := r.getNodeAgentFootprint(logger, other, profiles[other.Namespace])
+			if err != nil {
+				return nil, err
+			}
+
+			if len(otherFootprint.templates) > 0 && !nodesListed {
+				nodeList := &corev1.NodeList{}
+				if err = r.client.List(context.TODO(), nodeList); err != nil {
+					return nil, err
+				}
+				nodes = nodeList.Items
+				nodesKey = getNodeLabelsKey(nodes)
+				nodesListed = true
+			}
+
+			var sharedNodes []string
+			if len(otherFootprint.templates) > 0 {
+				footprint = r.matchNodeAgentFootprint(dda, footprint, nodes, nodesKey)
+				otherFootprint = r.matchNodeAgentFootprint(other, otherFootprint, nodes, nodesKey)
+				sharedNodes = intersectStrings(footprint.nodes, otherFootprint.nodes)
+			}
+			if len(sharedNodes) > 0 {
+				if len(sharedNodes) > maxConflictingNodes {
+					sharedNodes = append(sharedNodes[:maxConflictingNodes], fmt.Sprintf("+%d more", len(sharedNodes)-maxConflictingNodes))
+				}
+				reasons = append(reasons, fmt.Sprintf("nodes (%s)", strings.Join(sharedNodes, ", ")))
+
+				if hostPorts := intersectStrings(footprint.hostPorts, otherFootprint.hostPorts); len(hostPorts) > 0 {
+					reasons = append(reasons, fmt.Sprintf("host ports (%s)", strings.Join(hostPorts, ", ")))
+				}
+			}
+		}
+
+		if len(reasons) > 0 {
+			conflicts = append(conflicts, fmt.Sprintf("%s/%s: %s", other.Namespace, other.Name, strings.Join(reasons, ", ")))
+		}
+	}
+
+	return conflicts, nil
+}
+
+// getNodeAgentFootprint returns the pod templates and the host ports of the node Agents of a DatadogAgent,
+// rendered again only when the DatadogAgent or its profiles change
+func (r *Reconciler) getNodeAgentFootprint(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, profiles []datadoghqv1alpha1.DatadogAgentProfile) (*nodeAgentFootprint, error) {
+	templatesKey := getNodeAgentTemplatesKey(dda, profiles)
+	if footprint := r.nodeAgentFootprints.get(dda); footprint != nil && footprint.templatesKey == templatesKey {
+		return footprint, nil
+	}
+
+	templates, err := getNodeAgentPodTemplates(logger, dda, profiles)
+	if err != nil {
+		return nil, err
+	}
+	footprint := &nodeAgentFootprint{
+		templatesKey: templatesKey,
+		templates:    templates,
+		hostPorts:    getHostPorts(templates),
+	}
+	r.nodeAgentFootprints.set(dda, footprint)
+	return footprint, nil
+}
+
+// matchNodeAgentFootprint returns the footprint with the nodes selected by the node Agents of a DatadogAgent,
+// matched again only when the footprint or the node labels change
+func (r *Reconciler) matchNodeAgentFootprint(dda *datadoghqv1alpha1.DatadogAgent, footprint *nodeAgentFootprint, nodes []corev1.Node, nodesKey string) *nodeAgentFootprint {
+	if footprint.nodesMatched && footprint.nodesKey == nodesKey {
+		return footprint
+	}
+
+	// Footprints are shared between reconciles, a copy is stored with the matching nodes
+	matched := *footprint
+	matched.nodes = getMatchingNodes(nodes, footprint.templates)
+	matched.nodesKey = nodesKey
+	matched.nodesMatched = true
+	r.nodeAgentFootprints.set(dda, &matched)
+	return &matched
+}
+
+// getNodeAgentTemplatesKey identifies the specs of a DatadogAgent and of the profiles of its namespace
+func getNodeAgentTemplatesKey(dda *datadoghqv1alpha1.DatadogAgent, profiles []datadoghqv1alpha1.DatadogAgentProfile) string {
+	key := fmt.Sprintf("%s/%d", dda.UID, dda.Generation)
+	for _, profile := range profiles {
+		key += fmt.Sprintf(",%s/%s/%d", profile.Name, profile.UID, profile.Generation)
+	}
+	return key
+}
+
+// getNodeLabelsKey returns a hash of the names and labels of the nodes
+func getNodeLabelsKey(nodes []corev1.Node) string {
+	nodeKeys := make([]string, 0, len(nodes))
+	for i := range nodes {
+		nodeKeys = append(nodeKeys, nodes[i].Name+"{"+labels.Set(nodes[i].Labels).String()+"}")
+	}
+	sort.Strings(nodeKeys)
+
+	hash := fnv.New64a()
+	for _, nodeKey := range nodeKeys {
+		_, _ = hash.Write([]byte(nodeKey))
+	}
+	return strconv.FormatUint(hash.Sum64(), 16)
+}
+
+// isOlderDatadogAgent returns true if dda was created before other, the namespaced names break the ties
+func isOlderDatadogAgent(dda, other *datadoghqv1alpha1.DatadogAgent) bool {
+	if !dda.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return dda.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	if dda.Namespace != other.Namespace {
+		return dda.Namespace < other.Namespace
+	}
+	return dda.Name < other.Name
+}
+
+// getNodeAgentPodTemplates renders the pod templates of the Agents running on the nodes,
+// including the DaemonSets of the profiles of the DatadogAgent namespace
+func getNodeAgentPodTemplates(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, profiles []datadoghqv1alpha1.DatadogAgentProfile) ([]*corev1.PodTemplateSpec, error) {
+	var templates []*corev1.PodTemplateSpec
+
+	validProfiles, profileDDAs, _, err := buildAgentProfileDatadogAgents(dda, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiutils.BoolValue(dda.Spec.Agent.Enabled) {
+		// The default DaemonSet excludes the nodes of the profiles
+		agentDDA := dda
+		if len(validProfiles) > 0 {
+			if agentDDA, err = newProfileDatadogAgent(dda, validProfiles, -1); err != nil {
+				return nil, err
+			}
+		}
+		template, err := newAgentPodTemplate(logger, agentDDA, nil)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	for _, profileDDA := range profileDDAs {
+		template, err := newAgentPodTemplate(logger, profileDDA, nil)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	if isWindowsAgentEnabled(dda) {
+		template, err := newWindowsAgentPodTemplate(logger, dda)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	return templates, nil
+}
+
+// getMatchingNodes returns the sorted names of the nodes selected by the pod templates.
+// The taints and tolerations are not evaluated.
+func getMatchingNodes(nodes []corev1.Node, templates []*corev1.PodTemplateSpec) []string {
+	var names []string
+	for i := range nodes {
+		for _, template := range templates {
+			if nodeMatchesPodSpec(&nodes[i], &template.Spec) {
+				names = append(names, nodes[i].Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nodeMatchesPodSpec returns true if the node matches the node selector and the required node affinity of the pod
+func nodeMatchesPodSpec(node *corev1.Node, spec *corev1.PodSpec) bool {
+	if !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false
+	}
+
+	if spec.Affinity == nil || spec.Affinity.NodeAffinity == nil || spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	for _, term := range spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if nodeMatchesSelectorTerm(node, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeMatchesSelectorTerm returns true if the node matches all the requirements of the term, an empty term matches no node
+func nodeMatchesSelectorTerm(node *corev1.Node, term corev1.NodeSelectorTerm) bool {
+	if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+		return false
+	}
+
+	for _, expression := range term.MatchExpressions {
+		requirement, err := labels.NewRequirement(expression.Key, nodeSelectorOperators[expression.Operator], expression.Values)
+		if err != nil || !requirement.Matches(labels.Set(node.Labels)) {
+			return false
+		}
+	}
+
+	for _, field := range term.MatchFields {
+		// metadata.name is the only field supported by the scheduler
+		requirement, err := labels.NewRequirement(field.Key, nodeSelectorOperators[field.Operator], field.Values)
+		if err != nil || field.Key != "metadata.name" || !requirement.Matches(labels.Set{field.Key: node.Name}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getHostPorts returns the sorted host ports of the pod templates, as port/protocol
+func getHostPorts(templates []*corev1.PodTemplateSpec) []string {
+	var hostPorts []string
+	for _, template := range templates {
+		for _, container := range getAllContainers(&template.Spec) {
+			for _, port := range container.Ports {
+				if port.HostPort == 0 {
+					continue
+				}
+				protocol := port.Protocol
+				if protocol == "" {
+					protocol = corev1.ProtocolTCP
+				}
+				hostPorts = append(hostPorts, fmt.Sprintf("%d/%s", port.HostPort, protocol))
+			}
+		}
+	}
+	sort.Strings(hostPorts)
+	return hostPorts
+}
+
+// intersectStrings returns the strings of a found in b, in the order of a
+func intersectStrings(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, s := range b {
+		inB[s] = true
+	}
+
+	var intersection []string
+	for _, s := range a {
+		if inB[s] {
+			intersection = append(intersection, s)
+			// Only report duplicates once
+			delete(inB, s)
+		}
+	}
+	return intersection
+}
+
+// nodeAgentFootprint is the footprint of the node Agents of a DatadogAgent on the cluster
+type nodeAgentFootprint struct {
+	// templatesKey identifies the DatadogAgent and profiles specs the templates were rendered from
+	templatesKey string
+	templates    []*corev1.PodTemplateSpec
+	hostPorts    []string
+
+	// nodesKey identifies the node labels the nodes were matched against
+	nodesKey     string
+	nodesMatched bool
+	nodes        []string
+}
+
+// nodeAgentFootprintsStore keeps the node Agent footprint of each DatadogAgent between reconciles,
+// the conflicts checks of every DatadogAgent use the footprints of all the DatadogAgents of the cluster.
+// The stored footprints are never modified, they are replaced.
+type nodeAgentFootprintsStore struct {
+	mutex      sync.Mutex
+	footprints map[types.NamespacedName]*nodeAgentFootprint
+}
+
+func newNodeAgentFootprintsStore() *nodeAgentFootprintsStore {
+	return &nodeAgentFootprintsStore{
+		footprints: make(map[types.NamespacedName]*nodeAgentFootprint),
+	}
+}
+
+func (s *nodeAgentFootprintsStore) get(dda *datadoghqv1alpha1.DatadogAgent) *nodeAgentFootprint {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.footprints[types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}]
+}
+
+func (s *nodeAgentFootprintsStore) set(dda *datadoghqv1alpha1.DatadogAgent, footprint *nodeAgentFootprint) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.footprints[types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}] = footprint
+}
+
+// prune forgets the footprints of the deleted DatadogAgents, terminating DatadogAgents are ignored by the conflicts checks
+func (s *nodeAgentFootprintsStore) prune(ddas []datadoghqv1alpha1.DatadogAgent) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing := make(map[types.NamespacedName]bool, len(ddas))
+	for _, dda := range ddas {
+		if dda.DeletionTimestamp == nil {
+			existing[types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}] = true
+		}
+	}
+	for key := range s.footprints {
+		if !existing[key] {
+			delete(s.footprints, key)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+func newConflictDatadogAgent(ns, name string, created time.Time) *datadoghqv1alpha1.DatadogAgent {
+	dda := test.NewDefaultedDatadogAgent(ns, name, &test.NewDatadogAgentOptions{UseEDS: false, ClusterAgentEnabled: false})
+	dda.CreationTimestamp = metav1.NewTime(created)
+	return dda
+}
+
+func newConflictNode(name string, labels map[string]string) *corev1.Node {
+	nodeLabels := map[string]string{corev1.LabelOSStable: "linux"}
+	for key, value := range labels {
+		nodeLabels[key] = value
+	}
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels}}
+}
+
+func Test_reconcileConflicts(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	now := time.Now()
+	nodes := []*corev1.Node{
+		newConflictNode("node-a", map[string]string{"pool": "a"}),
+		newConflictNode("node-b", map[string]string{"pool": "b"}),
+	}
+
+	tests := []struct {
+		name          string
+		older         *datadoghqv1alpha1.DatadogAgent
+		newer         *datadoghqv1alpha1.DatadogAgent
+		profiles      []datadoghqv1alpha1.DatadogAgentProfile
+		wantConflicts []string
+	}{
+		{
+			name:  "same nodes",
+			older: newConflictDatadogAgent("bar", "foo", now.Add(-time.Hour)),
+			newer: newConflictDatadogAgent("bar", "foo2", now),
+			wantConflicts: []string{
+				"bar/foo: nodes (node-a, node-b)",
+			},
+		},
+		{
+			name:  "disjoint node selectors",
+			older: withAgentNodeSelector(newConflictDatadogAgent("bar", "foo", now.Add(-time.Hour)), "a"),
+			newer: withAgentNodeSelector(newConflictDatadogAgent("bar", "foo2", now), "b"),
+		},
+		{
+			name:  "same host ports",
+			older: withDogstatsdHostPort(newConflictDatadogAgent("bar", "foo", now.Add(-time.Hour))),
+			newer: withDogstatsdHostPort(newConflictDatadogAgent("bar", "foo2", now)),
+			wantConflicts: []string{
+				"bar/foo: nodes (node-a, node-b), host ports (8125/UDP)",
+			},
+		},
+		{
+			name:     "same host ports in a profile",
+			older:    withAPMHostPort(newConflictDatadogAgent("bar", "foo", now.Add(-time.Hour)), false),
+			newer:    withAPMHostPort(newConflictDatadogAgent("baz", "foo2", now), true),
+			profiles: []datadoghqv1alpha1.DatadogAgentProfile{withProfileAPM(newTestProfile("apm", time.Hour, poolRequirement("b")))},
+			wantConflicts: []string{
+				"bar/foo: nodes (node-a, node-b), host ports (8126/TCP)",
+			},
+		},
+		{
+			name:  "disjoint nodes override",
+			older: newConflictDatadogAgent("bar", "foo", now.Add(-time.Hour)),
+			newer: withDisjointNodes(newConflictDatadogAgent("bar", "foo2", now)),
+		},
+		{
+			name:  "same cluster-scoped RBAC names",
+			older: withDisjointNodes(newConflictDatadogAgent("bar", "foo", now.Add(-time.Hour))),
+			newer: newConflictDatadogAgent("baz", "foo", now),
+			wantConflicts: []string{
+				"bar/foo: cluster-scoped RBAC names (foo-agent, foo-cluster-agent, foo-cluster-checks-runner, foo-cluster-agent-auth-delegator, foo-cluster-agent-metrics-reader)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := scheme.Scheme
+			s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{}, &datadoghqv1alpha1.DatadogAgentList{}, &datadoghqv1alpha1.DatadogAgentProfile{}, &datadoghqv1alpha1.DatadogAgentProfileList{})
+			objects := []client.Object{tt.older, tt.newer, nodes[0], nodes[1]}
+			for i := range tt.profiles {
+				objects = append(objects, &tt.profiles[i])
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(objects...).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &Reconciler{client: fakeClient, scheme: s, recorder: recorder}
+
+			// The older DatadogAgent is always reconciled
+			newStatus := &datadoghqv1alpha1.DatadogAgentStatus{}
+			result, err := r.reconcileConflicts(logger, tt.older, newStatus)
+			assert.NoError(t, err)
+			assert.Equal(t, time.Duration(0), result.RequeueAfter)
+			assert.Empty(t, newStatus.Conditions)
+
+			conflicts, err := r.getConflicts(logger, tt.newer)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantConflicts, conflicts)
+
+			newStatus = &datadoghqv1alpha1.DatadogAgentStatus{}
+			result, err = r.reconcileConflicts(logger, tt.newer, newStatus)
+			assert.NoError(t, err)
+			if len(tt.wantConflicts) > 0 {
+				assert.Equal(t, defaultRequeuePeriod, result.RequeueAfter)
+				assert.Equal(t, datadoghqv1alpha1.DatadogAgentConditionTypeConflict, newStatus.Conditions[0].Type)
+				assert.Equal(t, corev1.ConditionTrue, newStatus.Conditions[0].Status)
+				assert.Len(t, recorder.Events, 1)
+			} else {
+				assert.Equal(t, time.Duration(0), result.RequeueAfter)
+				assert.Empty(t, newStatus.Conditions)
+				assert.Len(t, recorder.Events, 0)
+			}
+		})
+	}
+}
+
+func withAgentNodeSelector(dda *datadoghqv1alpha1.DatadogAgent, pool string) *datadoghqv1alpha1.DatadogAgent {
+	dda.Spec.Agent.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{pool}},
+						},
+					},
+				},
+			},
+		},
+	}
+	return dda
+}
+
+func withDogstatsdHostPort(dda *datadoghqv1alpha1.DatadogAgent) *datadoghqv1alpha1.DatadogAgent {
+	dda.Spec.Agent.Config.HostPort = apiutils.NewInt32Pointer(8125)
+	return dda
+}
+
+func withAPMHostPort(dda *datadoghqv1alpha1.DatadogAgent, enabled bool) *datadoghqv1alpha1.DatadogAgent {
+	dda.Spec.Agent.Apm.Enabled = apiutils.NewBoolPointer(enabled)
+	dda.Spec.Agent.Apm.HostPort = apiutils.NewInt32Pointer(8126)
+	datadoghqv1alpha1.DefaultDatadogAgentSpecAgentApm(&dda.Spec.Agent)
+	return dda
+}
+
+func withProfileAPM(profile datadoghqv1alpha1.DatadogAgentProfile) datadoghqv1alpha1.DatadogAgentProfile {
+	profile.Spec.Agent = &datadoghqv1alpha1.DatadogAgentProfileAgentSpec{
+		Apm: &datadoghqv1alpha1.DatadogAgentProfileFeatureConfig{Enabled: apiutils.NewBoolPointer(true)},
+	}
+	return profile
+}
+
+func poolRequirement(pool string) corev1.NodeSelectorRequirement {
+	return corev1.NodeSelectorRequirement{Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{pool}}
+}
+
+func Test_getConflicts_footprints(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	now := time.Now()
+	older := withAgentNodeSelector(newConflictDatadogAgent("bar", "foo", now.Add(-time.Hour)), "a")
+	newer := withAgentNodeSelector(newConflictDatadogAgent("bar", "foo2", now), "b")
+	nodeA := newConflictNode("node-a", map[string]string{"pool": "a"})
+	nodeB := newConflictNode("node-b", map[string]string{"pool": "b"})
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{}, &datadoghqv1alpha1.DatadogAgentList{}, &datadoghqv1alpha1.DatadogAgentProfile{}, &datadoghqv1alpha1.DatadogAgentProfileList{})
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(older, newer, nodeA, nodeB).Build()
+	r := &Reconciler{client: fakeClient, scheme: s, nodeAgentFootprints: newNodeAgentFootprintsStore()}
+
+	conflicts, err := r.getConflicts(logger, newer)
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+	footprint := r.nodeAgentFootprints.get(older)
+	assert.NotNil(t, footprint)
+	assert.Equal(t, []string{"node-a"}, footprint.nodes)
+
+	// Nothing changed, the footprints are reused
+	_, err = r.getConflicts(logger, newer)
+	assert.NoError(t, err)
+	assert.True(t, footprint == r.nodeAgentFootprints.get(older))
+
+	// The node labels changed, the nodes are matched again
+	nodeB.Labels["pool"] = "a"
+	assert.NoError(t, fakeClient.Update(context.TODO(), nodeB))
+	nodeA.Labels["pool"] = "b"
+	assert.NoError(t, fakeClient.Update(context.TODO(), nodeA))
+	conflicts, err = r.getConflicts(logger, newer)
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+	assert.Equal(t, footprint.templatesKey, r.nodeAgentFootprints.get(older).templatesKey)
+	assert.Equal(t, []string{"node-b"}, r.nodeAgentFootprints.get(older).nodes)
+
+	// The footprints of the deleted DatadogAgents are forgotten
+	assert.NoError(t, fakeClient.Delete(context.TODO(), older))
+	_, err = r.getConflicts(logger, newer)
+	assert.NoError(t, err)
+	assert.Nil(t, r.nodeAgentFootprints.get(older))
+}
+
+func withDisjointNodes(dda *datadoghqv1alpha1.DatadogAgent) *datadoghqv1alpha1.DatadogAgent {
+	dda.Spec.Agent.DisjointNodes = apiutils.NewBoolPointer(true)
+	return dda
+}
+
+func Test_nodeMatchesPodSpec(t *testing.T) {
+	node := newConflictNode("node-a", map[string]string{"pool": "a", "size": "4"})
+
+	tests := []struct {
+		name string
+		spec *corev1.PodSpec
+		want bool
+	}{
+		{
+			name: "no constraint",
+			spec: &corev1.PodSpec{},
+			want: true,
+		},
+		{
+			name: "node selector mismatch",
+			spec: &corev1.PodSpec{NodeSelector: map[string]string{corev1.LabelOSStable: "windows"}},
+			want: false,
+		},
+		{
+			name: "one matching term",
+			spec: &corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "pool", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"a"}}}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "size", Operator: corev1.NodeSelectorOpGt, Values: []string{"2"}}}},
+				}},
+			}}},
+			want: true,
+		},
+		{
+			name: "match fields",
+			spec: &corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-b"}}}},
+				}},
+			}}},
+			want: false,
+		},
+		{
+			name: "empty term",
+			spec: &corev1.PodSpec{Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{}}},
+			}}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nodeMatchesPodSpec(node, tt.spec))
+		})
+	}
+}
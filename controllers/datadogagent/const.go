@@ -19,21 +19,23 @@ const (
 	FieldPathMetaName = "metadata.name"
 
 	// kind names definition
-	extendedDaemonSetKind   = "ExtendedDaemonSet"
-	daemonSetKind           = "DaemonSet"
-	deploymentKind          = "Deployment"
-	clusterRoleKind         = "ClusterRole"
-	clusterRoleBindingKind  = "ClusterRoleBinding"
-	roleKind                = "Role"
-	roleBindingKind         = "RoleBinding"
-	configMapKind           = "ConfigMap"
-	serviceAccountKind      = "ServiceAccount"
-	podDisruptionBudgetKind = "PodDisruptionBudget"
-	secretKind              = "Secret"
-	serviceKind             = "Service"
-	apiServiceKind          = "APIService"
-	networkPolicyKind       = "NetworkPolicy"
-	ciliumNetworkPolicyKind = "CiliumNetworkPolicy"
+	extendedDaemonSetKind          = "ExtendedDaemonSet"
+	daemonSetKind                  = "DaemonSet"
+	deploymentKind                 = "Deployment"
+	clusterRoleKind                = "ClusterRole"
+	clusterRoleBindingKind         = "ClusterRoleBinding"
+	roleKind                       = "Role"
+	roleBindingKind                = "RoleBinding"
+	configMapKind                  = "ConfigMap"
+	serviceAccountKind             = "ServiceAccount"
+	podDisruptionBudgetKind        = "PodDisruptionBudget"
+	horizontalPodAutoscalerKind    = "HorizontalPodAutoscaler"
+	secretKind                     = "Secret"
+	serviceKind                    = "Service"
+	apiServiceKind                 = "APIService"
+	networkPolicyKind              = "NetworkPolicy"
+	ciliumNetworkPolicyKind        = "CiliumNetworkPolicy"
+	securityContextConstraintsKind = "SecurityContextConstraints"
 
 	checkRunnersSuffix = "ccr"
 	clusterAgentSuffix = "dca"
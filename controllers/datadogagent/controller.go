@@ -7,6 +7,8 @@ package datadogagent
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -33,33 +35,48 @@ const (
 
 // ReconcilerOptions provides options read from command line
 type ReconcilerOptions struct {
-	SupportExtendedDaemonset bool
-	SupportCilium            bool
-	OperatorMetricsEnabled   bool
+	SupportExtendedDaemonset  bool
+	SupportCilium             bool
+	SupportOpenShiftSCC       bool
+	PodSecurityLabelNamespace bool
+	OrphanCollectionEnabled   bool
+	OrphanCollectionDryRun    bool
+	OperatorMetricsEnabled    bool
 }
 
 // Reconciler is the internal reconciler for Datadog Agent
 type Reconciler struct {
 	options     ReconcilerOptions
 	client      client.Client
+	apiReader   client.Reader
 	versionInfo *version.Info
 	scheme      *runtime.Scheme
 	log         logr.Logger
 	recorder    record.EventRecorder
 	forwarders  datadog.MetricForwardersManager
+
+	applyConflicts      *applyConflictsStore
+	appliedResources    *appliedResourcesStore
+	nodeAgentFootprints *nodeAgentFootprintsStore
 }
 
-// NewReconciler returns a reconciler for DatadogAgent
-func NewReconciler(options ReconcilerOptions, client client.Client, versionInfo *version.Info,
+// NewReconciler returns a reconciler for DatadogAgent.
+// The pods are listed with apiReader, to avoid caching all the pods of the cluster.
+func NewReconciler(options ReconcilerOptions, client client.Client, apiReader client.Reader, versionInfo *version.Info,
 	scheme *runtime.Scheme, log logr.Logger, recorder record.EventRecorder, metricForwarder datadog.MetricForwardersManager) (*Reconciler, error) {
 	return &Reconciler{
 		options:     options,
 		client:      client,
+		apiReader:   apiReader,
 		versionInfo: versionInfo,
 		scheme:      scheme,
 		log:         log,
 		recorder:    recorder,
 		forwarders:  metricForwarder,
+
+		applyConflicts:      newApplyConflictsStore(),
+		appliedResources:    newAppliedResourcesStore(),
+		nodeAgentFootprints: newNodeAgentFootprintsStore(),
 	}, nil
 }
 
@@ -114,19 +131,17 @@ func (r *Reconciler) internalReconcile(ctx context.Context, request reconcile.Re
 	}
 
 	newStatus := instance.Status.DeepCopy()
-	reconcileFuncs :=
-		[]reconcileFuncInterface{
-			r.reconcileClusterAgent,
-			r.reconcileClusterChecksRunner,
-			r.reconcileAgent,
-		}
-	for _, reconcileFunc := range reconcileFuncs {
+	r.appliedResources.reset(instance)
+	for _, reconcileFunc := range r.reconcileFuncs() {
 		result, err = reconcileFunc(reqLogger, instance, newStatus)
 		if utils.ShouldReturn(result, err) {
 			return r.updateStatusIfNeeded(reqLogger, instance, newStatus, result, err)
 		}
 	}
 
+	// All the resources of the DatadogAgent were applied
+	r.collectOrphans(reqLogger, instance)
+
 	// Always requeue
 	if !result.Requeue && result.RequeueAfter == 0 {
 		result.RequeueAfter = defaultRequeuePeriod
@@ -136,6 +151,21 @@ func (r *Reconciler) internalReconcile(ctx context.Context, request reconcile.Re
 
 type reconcileFuncInterface func(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus) (reconcile.Result, error)
 
+// reconcileFuncs returns the functions reconciling each component of a DatadogAgent, in order
+func (r *Reconciler) reconcileFuncs() []reconcileFuncInterface {
+	return []reconcileFuncInterface{
+		r.reconcileConflicts,
+		r.reconcileUpdatePolicy,
+		r.reconcileImagePolicy,
+		r.reconcileRollback,
+		r.reconcileClusterAgent,
+		r.reconcileClusterChecksRunner,
+		r.reconcileAgent,
+		r.reconcileWindowsAgent,
+		r.reconcilePodSecurity,
+	}
+}
+
 func (r *Reconciler) updateOverrideIfNeeded(logger logr.Logger, agentdeployment *datadoghqv1alpha1.DatadogAgent, newOverride *datadoghqv1alpha1.DatadogAgentStatus, result reconcile.Result) (*datadoghqv1alpha1.DatadogAgent, reconcile.Result, error) {
 	// We returned the most up to date instance to avoid conflict during the updateStatusIfNeeded after all the reconcile cycles.
 	updateAgentDeployment := agentdeployment.DeepCopy()
@@ -162,6 +192,7 @@ func (r *Reconciler) updateStatusIfNeeded(logger logr.Logger, agentdeployment *d
 		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypeActive, corev1.ConditionFalse, "DatadogAgent reconcile error", false)
 	}
 
+	r.setApplyConflictsStatus(agentdeployment, newStatus, now)
 	r.setMetricsForwarderStatus(logger, agentdeployment, newStatus)
 	if !apiequality.Semantic.DeepEqual(&agentdeployment.Status, newStatus) {
 		updateAgentDeployment := agentdeployment.DeepCopy()
@@ -179,6 +210,26 @@ func (r *Reconciler) updateStatusIfNeeded(logger logr.Logger, agentdeployment *d
 	return result, currentError
 }
 
+// setApplyConflictsStatus sets the ResourceConflict condition with the fields taken over from other field managers
+func (r *Reconciler) setApplyConflictsStatus(agentdeployment *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus, now metav1.Time) {
+	if conflicts := r.applyConflicts.pop(agentdeployment); len(conflicts) > 0 {
+		desc := fmt.Sprintf("Fields managed by another field manager were overridden: %s", strings.Join(conflicts, "; "))
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypeResourceConflict, corev1.ConditionTrue, desc, false)
+	} else {
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypeResourceConflict, corev1.ConditionFalse, "", false)
+	}
+}
+
+// isConditionReported returns true if the condition is already true with the same message, to record its event only once
+func isConditionReported(status *datadoghqv1alpha1.DatadogAgentStatus, conditionType datadoghqv1alpha1.DatadogAgentConditionType, message string) bool {
+	for _, c := range status.Conditions {
+		if c.Type == conditionType {
+			return c.Status == corev1.ConditionTrue && c.Message == message
+		}
+	}
+	return false
+}
+
 // setMetricsForwarderStatus sets the metrics forwarder status condition if enabled
 func (r *Reconciler) setMetricsForwarderStatus(logger logr.Logger, agentdeployment *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus) {
 	if r.options.OperatorMetricsEnabled {
@@ -19,6 +19,7 @@ import (
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	test "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
 	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
 	cilium "github.com/DataDog/datadog-operator/pkg/cilium/v1"
 	"github.com/DataDog/datadog-operator/pkg/controller/utils/comparison"
 	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
@@ -41,7 +42,6 @@ import (
 	"k8s.io/client-go/tools/record"
 	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -85,7 +85,7 @@ func TestReconcileDatadogAgent_createNewExtendedDaemonSet(t *testing.T) {
 		{
 			name: "create new EDS",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -139,7 +139,8 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 	// Register operator types with the runtime scheme.
 	s := scheme.Scheme
 	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{})
-	s.AddKnownTypes(edsdatadoghqv1alpha1.GroupVersion, &edsdatadoghqv1alpha1.ExtendedDaemonSet{})
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgentProfile{}, &datadoghqv1alpha1.DatadogAgentProfileList{})
+	s.AddKnownTypes(edsdatadoghqv1alpha1.GroupVersion, &edsdatadoghqv1alpha1.ExtendedDaemonSet{}, &edsdatadoghqv1alpha1.ExtendedDaemonSetList{})
 	s.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.DaemonSet{})
 	s.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.Deployment{})
 	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Secret{})
@@ -190,7 +191,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent not found",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -203,7 +204,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found, add finalizer",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -243,7 +244,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found, but not defaulted",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -275,7 +276,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, create the Agent's ClusterRole",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -295,7 +296,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), installinfoCM)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				datadogAgent := &datadoghqv1alpha1.DatadogAgent{}
@@ -322,7 +323,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, create the Agent's ClusterRoleBinding",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -366,7 +367,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, create the Agent's ServiceAccount",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -394,7 +395,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), installinfoCM)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				rbacResourcesName := "foo-agent"
@@ -417,7 +418,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, create the ExtendedDaemonSet",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -466,7 +467,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, block daemonsetName change",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -504,7 +505,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, create the ExtendedDaemonSet with non default config",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -596,7 +597,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "Cluster Agent enabled, create the cluster agent secret",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -606,7 +607,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), test.NewDefaultedDatadogAgent(resourcesNamespace, resourcesName, &test.NewDatadogAgentOptions{ClusterAgentEnabled: true, OrchestratorExplorerDisabled: true, Labels: map[string]string{"label-foo-key": "label-bar-value"}}))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				secret := &corev1.Secret{}
@@ -623,7 +624,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, create the DaemonSet",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -676,7 +677,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent with APM agent found and defaulted, create Daemonset",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -715,7 +716,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent with Process agent found and defaulted, create Daemonset",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -754,7 +755,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent with Process agent found and defaulted, create system-probe-config configmap",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -774,7 +775,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					createAgentDependencies(c, dda)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				configmap := &corev1.ConfigMap{}
@@ -788,7 +789,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent with Process agent found and defaulted, create datadog-agent-security configmap",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -810,7 +811,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), configCM)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				configmap := &corev1.ConfigMap{}
@@ -824,7 +825,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent with Process agent and system-probe found and defaulted, create Daemonset",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -885,7 +886,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, ExtendedDaemonSet already exists",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -928,7 +929,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, ExtendedDaemonSet already exists but not up-to-date",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -978,7 +979,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Cluster Agent Service",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -998,7 +999,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					}}))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				dcaService := &corev1.Service{}
@@ -1012,7 +1013,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Metrics Server Service",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1047,7 +1048,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), dcaService)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				dcaService := &corev1.Service{}
@@ -1061,7 +1062,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Admission Controller Service",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1096,7 +1097,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), dcaService)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				dcaService := &corev1.Service{}
@@ -1110,7 +1111,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Cluster Agent Deployment",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1132,7 +1133,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					createClusterAgentDependencies(c, dda)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				dca := &appsv1.Deployment{}
@@ -1148,7 +1149,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Cluster Agent PDB",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1183,7 +1184,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), dcaService)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				pdb := &policyv1.PodDisruptionBudget{}
@@ -1206,7 +1207,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Cluster Agent ClusterRole",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1243,7 +1244,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), buildClusterAgentPDB(dda))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				clusterRole := &rbacv1.ClusterRole{}
@@ -1269,7 +1270,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, WPA Controller enabled, create the Cluster Agent ClusterRole",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1308,7 +1309,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), buildClusterAgentPDB(dda))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				metricsService := &corev1.Service{}
@@ -1331,7 +1332,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, Admission Controller enabled, create the Cluster Agent ClusterRole",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1390,7 +1391,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), buildClusterAgentPDB(dda))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				clusterRole := &rbacv1.ClusterRole{}
@@ -1419,7 +1420,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Cluster Agent ClusterRoleBinding",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1457,7 +1458,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), buildClusterAgentPDB(dda))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
@@ -1479,7 +1480,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Cluster Agent HPA ClusterRoleBinding",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1538,7 +1539,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), buildClusterAgentPDB(dda))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				// Make sure Cluster Agent HPA ClusterRoleBinding is created properly
@@ -1561,7 +1562,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent enabled, create the Cluster Agent ServiceAccount",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1625,7 +1626,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), buildClusterAgentPDB(dda))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				// Make sure Cluster Agent ServiceAccount is created properly
@@ -1649,7 +1650,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent Deployment already exists, create Daemonset",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1706,7 +1707,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent Deployment already exists, block DeploymentName change",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1770,7 +1771,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 			{
 				name: "DatadogAgent found and defaulted, Cluster Agent enabled, block DeploymentName change",
 				fields: fields{
-					client:   fake.NewFakeClient(),
+					client:   testutils.NewFakeClient(),
 					scheme:   s,
 					recorder: recorder,
 				},
@@ -1805,7 +1806,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent Deployment already exists but with 0 pods ready, do not create Daemonset",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1864,7 +1865,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Checks Runner PDB Creation",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1903,7 +1904,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					createClusterAgentDependencies(c, dda)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				pdb := &policyv1.PodDisruptionBudget{}
@@ -1925,7 +1926,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Checks Runner PDB Update",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -1971,15 +1972,15 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), pdb)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				pdb := &policyv1.PodDisruptionBudget{}
 				if err := c.Get(context.TODO(), types.NamespacedName{Namespace: resourcesNamespace, Name: rbacResourcesNameClusterChecksRunner}, pdb); err != nil {
 					return err
 				}
-				if pdb.Spec.MinAvailable.IntValue() != pdbMinAvailableInstances {
-					return fmt.Errorf("MinAvailable incorrect, expected %d, got %d", pdbMinAvailableInstances, pdb.Spec.MinAvailable.IntValue())
+				if pdb.Spec.MinAvailable.String() != pdbDefaultMinAvailable {
+					return fmt.Errorf("MinAvailable incorrect, expected %s, got %s", pdbDefaultMinAvailable, pdb.Spec.MinAvailable.String())
 				}
 
 				return nil
@@ -1988,7 +1989,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Checks Runner ClusterRoleBinding creation",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2034,7 +2035,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					_ = c.Create(context.TODO(), buildServiceAccount(dda, getClusterChecksRunnerServiceAccount(dda), version))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
@@ -2057,7 +2058,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Checks Runner Service Account creation",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2107,7 +2108,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					}, version))
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				rbacResourcesNameClusterChecksRunner := rbacResourcesNameClusterChecksRunner
@@ -2131,7 +2132,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Checks Runner Deployment creation",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2197,7 +2198,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent Deployment already exists but not up-to-date",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2234,7 +2235,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 					createClusterChecksRunnerDependencies(c, dda, true)
 				},
 			},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				dca := &appsv1.Deployment{}
@@ -2254,7 +2255,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Agent network policies are created",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2299,7 +2300,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, DaemonSet has Affinity",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2336,7 +2337,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Agent network policies are created",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2383,7 +2384,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cluster Checks Runner network policies are created",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2440,7 +2441,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Cilium network policies created",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2494,7 +2495,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Local traffic Service created",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2514,7 +2515,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 				},
 			},
 			// want:    reconcile.Result{RequeueAfter: defaultRequeueDuration},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				svc := &corev1.Service{}
@@ -2534,7 +2535,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Local traffic Service not created",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2569,7 +2570,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 		{
 			name: "DatadogAgent found and defaulted, Local traffic Service forced",
 			fields: fields{
-				client:   fake.NewFakeClient(),
+				client:   testutils.NewFakeClient(),
 				scheme:   s,
 				recorder: recorder,
 			},
@@ -2592,7 +2593,7 @@ func TestReconcileDatadogAgent_Reconcile(t *testing.T) {
 				},
 			},
 			// want:    reconcile.Result{RequeueAfter: defaultRequeueDuration},
-			want:    reconcile.Result{Requeue: true},
+			want:    reconcile.Result{RequeueAfter: defaultRequeuePeriod},
 			wantErr: false,
 			wantFunc: func(c client.Client) error {
 				svc := &corev1.Service{}
@@ -68,6 +68,19 @@ func (r *Reconciler) finalizeDad(reqLogger logr.Logger, dda *datadoghqv1alpha1.D
 		}
 	}
 
+	if _, err = r.cleanupSidecarInjectionRbacResources(reqLogger, dda); err != nil {
+		reqLogger.Error(err, "Could not delete the Agent sidecar RBACs")
+	}
+
+	if err = r.cleanupAgentSecurityContextConstraints(reqLogger, dda); err != nil {
+		reqLogger.Error(err, "Could not delete the Agent security context constraints")
+	}
+
+	if err = r.cleanupAgentProfilesStatus(dda); err != nil {
+		reqLogger.Error(err, "Could not remove the DatadogAgent from the status of the profiles")
+	}
+
+	r.appliedResources.forget(dda)
 	r.forwarders.Unregister(dda)
 	reqLogger.Info("Successfully finalized DatadogAgent")
 }
@@ -7,6 +7,7 @@ import (
 
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -75,7 +76,7 @@ func reconcilerForFinalizerTest(initialKubeObjects []client.Object) Reconciler {
 	reconcilerScheme.AddKnownTypes(rbacv1.SchemeGroupVersion, &rbacv1.ClusterRoleBinding{}, &rbacv1.ClusterRole{})
 	reconcilerScheme.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{})
 
-	fakeClient := fake.NewClientBuilder().WithObjects(initialKubeObjects...).WithScheme(reconcilerScheme).Build()
+	fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithObjects(initialKubeObjects...).WithScheme(reconcilerScheme).Build())
 
 	return Reconciler{
 		client:     fakeClient,
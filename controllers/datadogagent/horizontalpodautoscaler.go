@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	"github.com/DataDog/datadog-operator/pkg/kubernetes"
+)
+
+const (
+	// clusterChecksDispatchedMetricName is the Cluster Agent metric of the number of cluster checks dispatched to each
+	// runner. Its average across the runners is served by the Cluster Agent external metrics provider.
+	clusterChecksDispatchedMetricName = "datadog.cluster_agent.cluster_checks.configs_dispatched"
+	kubeClusterNameTagKey             = "kube_cluster_name"
+	// replicasHandoverFieldManager is the field manager keeping the replicas of a Deployment when the Operator stops applying them
+	replicasHandoverFieldManager = "datadog-operator-replicas-handover"
+)
+
+type (
+	hpaBuilder func(dda *datadoghqv1alpha1.DatadogAgent) *autoscalingv2beta2.HorizontalPodAutoscaler
+)
+
+func (r *Reconciler) manageClusterAgentHPA(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
+	cleanUpCondition := !isClusterAgentEnabled(dda.Spec.ClusterAgent) || !isAutoscalingEnabled(dda.Spec.ClusterAgent.Autoscaling)
+	return r.manageHPA(logger, dda, getClusterAgentName(dda), buildClusterAgentHPA, cleanUpCondition)
+}
+
+func (r *Reconciler) manageClusterChecksRunnerHPA(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
+	cleanUpCondition := !needClusterChecksRunner(dda) || !isAutoscalingEnabled(dda.Spec.ClusterChecksRunner.Autoscaling)
+	return r.manageHPA(logger, dda, getClusterChecksRunnerName(dda), buildClusterChecksRunnerHPA, cleanUpCondition)
+}
+
+func (r *Reconciler) manageHPA(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, hpaName string, builder hpaBuilder, cleanUp bool) (reconcile.Result, error) {
+	return r.manageResource(logger, dda, managedResource{
+		kind:      horizontalPodAutoscalerKind,
+		name:      hpaName,
+		namespace: dda.Namespace,
+		newObject: func() client.Object { return &autoscalingv2beta2.HorizontalPodAutoscaler{} },
+		build:     func() (client.Object, error) { return builder(dda), nil },
+		ownership: ownedByController,
+		cleanup:   cleanUp,
+	})
+}
+
+func buildClusterAgentHPA(dda *datadoghqv1alpha1.DatadogAgent) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	labels := getDefaultLabels(dda, datadoghqv1alpha1.DefaultClusterAgentResourceSuffix, getClusterAgentVersion(dda))
+	metadata := metav1.ObjectMeta{
+		Name:        getClusterAgentName(dda),
+		Namespace:   dda.Namespace,
+		Labels:      labels,
+		Annotations: getDefaultAnnotations(dda),
+	}
+
+	return buildHPA(dda, metadata, getClusterAgentName(dda), dda.Spec.ClusterAgent.Autoscaling)
+}
+
+func buildClusterChecksRunnerHPA(dda *datadoghqv1alpha1.DatadogAgent) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	labels := getDefaultLabels(dda, datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix, getAgentVersion(dda))
+	metadata := metav1.ObjectMeta{
+		Name:        getClusterChecksRunnerName(dda),
+		Namespace:   dda.Namespace,
+		Labels:      labels,
+		Annotations: getDefaultAnnotations(dda),
+	}
+
+	return buildHPA(dda, metadata, getClusterChecksRunnerName(dda), dda.Spec.ClusterChecksRunner.Autoscaling)
+}
+
+func buildHPA(dda *datadoghqv1alpha1.DatadogAgent, metadata metav1.ObjectMeta, deploymentName string, autoscaling *datadoghqv1alpha1.AutoscalingConfig) *autoscalingv2beta2.HorizontalPodAutoscaler {
+	var metrics []autoscalingv2beta2.MetricSpec
+	if autoscaling.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, newResourceMetricSpec(corev1.ResourceCPU, *autoscaling.TargetCPUUtilizationPercentage))
+	}
+	if autoscaling.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, newResourceMetricSpec(corev1.ResourceMemory, *autoscaling.TargetMemoryUtilizationPercentage))
+	}
+	if autoscaling.TargetClusterChecksPerRunner != nil {
+		metric := autoscalingv2beta2.MetricIdentifier{Name: clusterChecksDispatchedMetricName}
+		if dda.Spec.ClusterName != "" {
+			metric.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{kubeClusterNameTagKey: dda.Spec.ClusterName}}
+		}
+		metrics = append(metrics, autoscalingv2beta2.MetricSpec{
+			Type: autoscalingv2beta2.ExternalMetricSourceType,
+			External: &autoscalingv2beta2.ExternalMetricSource{
+				Metric: metric,
+				// The metric is already averaged across the runners
+				Target: autoscalingv2beta2.MetricTarget{
+					Type:  autoscalingv2beta2.ValueMetricType,
+					Value: resource.NewQuantity(int64(*autoscaling.TargetClusterChecksPerRunner), resource.DecimalSI),
+				},
+			},
+		})
+	}
+
+	return &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metadata,
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       deploymentKind,
+				Name:       deploymentName,
+			},
+			MinReplicas: autoscaling.MinReplicas,
+			MaxReplicas: *autoscaling.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+}
+
+func newResourceMetricSpec(name corev1.ResourceName, targetUtilization int32) autoscalingv2beta2.MetricSpec {
+	return autoscalingv2beta2.MetricSpec{
+		Type: autoscalingv2beta2.ResourceMetricSourceType,
+		Resource: &autoscalingv2beta2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2beta2.MetricTarget{
+				Type:               autoscalingv2beta2.UtilizationMetricType,
+				AverageUtilization: apiutils.NewInt32Pointer(targetUtilization),
+			},
+		},
+	}
+}
+
+func isAutoscalingEnabled(autoscaling *datadoghqv1alpha1.AutoscalingConfig) bool {
+	return autoscaling != nil && apiutils.BoolValue(autoscaling.Enabled)
+}
+
+// getDeploymentReplicas returns the replicas of a Deployment, nil when they are managed by a HorizontalPodAutoscaler
+// so that server-side apply doesn't take them over, see handOverReplicas
+func getDeploymentReplicas(replicas *int32, autoscaling *datadoghqv1alpha1.AutoscalingConfig) *int32 {
+	if isAutoscalingEnabled(autoscaling) {
+		return nil
+	}
+	return replicas
+}
+
+// handOverReplicas keeps the current replicas of a Deployment when the Operator stops applying them, for instance when
+// the autoscaling is enabled: server-side apply would remove the replicas owned by the Operator, scaling the Deployment
+// down to 1 replica. The current replicas are applied once with another field manager, which keeps them until the
+// HorizontalPodAutoscaler or a manual scaling changes them.
+func (r *Reconciler) handOverReplicas(logger logr.Logger, current, desired *appsv1.Deployment) error {
+	if desired.Spec.Replicas != nil || current.Spec.Replicas == nil {
+		return nil
+	}
+	owned, err := isReplicasAppliedByOperator(current)
+	if err != nil || !owned {
+		return err
+	}
+
+	handover := &unstructured.Unstructured{}
+	handover.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(deploymentKind))
+	handover.SetNamespace(current.Namespace)
+	handover.SetName(current.Name)
+	if err = unstructured.SetNestedField(handover.Object, int64(*current.Spec.Replicas), "spec", "replicas"); err != nil {
+		return err
+	}
+	logger.V(1).Info("Handing over the replicas of the Deployment", "deployment.Name", current.Name, "replicas", *current.Spec.Replicas)
+	return r.client.Patch(context.TODO(), handover, client.Apply, client.FieldOwner(replicasHandoverFieldManager))
+}
+
+// isReplicasAppliedByOperator returns true if the replicas of the Deployment are owned by the apply of the Operator
+func isReplicasAppliedByOperator(deployment *appsv1.Deployment) (bool, error) {
+	for _, entry := range deployment.ManagedFields {
+		if entry.Manager != kubernetes.FieldManager || entry.Operation != metav1.ManagedFieldsOperationApply || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := map[string]map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return false, err
+		}
+		if _, found := fields["f:spec"]["f:replicas"]; found {
+			return true, nil
+		}
+	}
+	return false, nil
+}
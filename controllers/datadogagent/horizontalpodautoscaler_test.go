@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
+)
+
+func Test_buildClusterChecksRunnerHPA(t *testing.T) {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true, ClusterChecksRunnerEnabled: true})
+	dda.Spec.ClusterName = "prod"
+	dda.Spec.ClusterChecksRunner.Autoscaling = &datadoghqv1alpha1.AutoscalingConfig{
+		Enabled:                      apiutils.NewBoolPointer(true),
+		MaxReplicas:                  apiutils.NewInt32Pointer(10),
+		TargetClusterChecksPerRunner: apiutils.NewInt32Pointer(20),
+	}
+	datadoghqv1alpha1.DefaultAutoscalingConfig(dda.Spec.ClusterChecksRunner.Autoscaling)
+
+	hpa := buildClusterChecksRunnerHPA(dda)
+	assert.Equal(t, getClusterChecksRunnerName(dda), hpa.Spec.ScaleTargetRef.Name)
+	assert.Equal(t, int32(1), *hpa.Spec.MinReplicas)
+	assert.Equal(t, int32(10), hpa.Spec.MaxReplicas)
+	// No default CPU target when another target is set
+	assert.Len(t, hpa.Spec.Metrics, 1)
+	external := hpa.Spec.Metrics[0].External
+	assert.Equal(t, clusterChecksDispatchedMetricName, external.Metric.Name)
+	assert.Equal(t, map[string]string{kubeClusterNameTagKey: "prod"}, external.Metric.Selector.MatchLabels)
+	assert.Equal(t, autoscalingv2beta2.ValueMetricType, external.Target.Type)
+	assert.Equal(t, int64(20), external.Target.Value.Value())
+}
+
+func Test_manageClusterAgentHPA(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true})
+	dda.Spec.ClusterAgent.Replicas = apiutils.NewInt32Pointer(2)
+	dda.Spec.ClusterAgent.Autoscaling = &datadoghqv1alpha1.AutoscalingConfig{Enabled: apiutils.NewBoolPointer(true)}
+	datadoghqv1alpha1.DefaultAutoscalingConfig(dda.Spec.ClusterAgent.Autoscaling)
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{})
+	fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithScheme(s).Build())
+	r := &Reconciler{client: fakeClient, scheme: s, recorder: record.NewFakeRecorder(10)}
+
+	_, err := r.manageClusterAgentHPA(logger, dda)
+	assert.NoError(t, err)
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: getClusterAgentName(dda)}, hpa))
+	assert.Len(t, hpa.Spec.Metrics, 1)
+	assert.Equal(t, int32(80), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+
+	// The replicas of the Deployment are left to the HorizontalPodAutoscaler
+	dca, _, err := newClusterAgentDeploymentFromInstance(logger, dda, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, dca.Spec.Replicas)
+
+	// The HorizontalPodAutoscaler is deleted when the autoscaling is disabled
+	dda.Spec.ClusterAgent.Autoscaling.Enabled = apiutils.NewBoolPointer(false)
+	_, err = r.manageClusterAgentHPA(logger, dda)
+	assert.NoError(t, err)
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: getClusterAgentName(dda)}, hpa)
+	assert.True(t, errors.IsNotFound(err))
+
+	dca, _, err = newClusterAgentDeploymentFromInstance(logger, dda, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), *dca.Spec.Replicas)
+}
+
+func Test_updateClusterAgentDeployment_enableAutoscaling(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true})
+	dda.Spec.ClusterAgent.Replicas = apiutils.NewInt32Pointer(3)
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{})
+	fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithScheme(s).Build())
+	r := &Reconciler{client: fakeClient, scheme: s, recorder: record.NewFakeRecorder(10)}
+
+	getDeployment := func() *appsv1.Deployment {
+		dca := &appsv1.Deployment{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: getClusterAgentName(dda)}, dca))
+		return dca
+	}
+	update := func() {
+		_, err := r.updateClusterAgentDeployment(logger, dda, getDeployment(), &datadoghqv1alpha1.DatadogAgentStatus{})
+		assert.NoError(t, err)
+	}
+
+	dca, _, err := newClusterAgentDeploymentFromInstance(logger, dda, nil)
+	assert.NoError(t, err)
+	_, err = r.applyResource(logger, dda, dca)
+	assert.NoError(t, err)
+
+	// The replicas are kept when the autoscaling is enabled
+	dda.Spec.ClusterAgent.Autoscaling = &datadoghqv1alpha1.AutoscalingConfig{Enabled: apiutils.NewBoolPointer(true)}
+	datadoghqv1alpha1.DefaultAutoscalingConfig(dda.Spec.ClusterAgent.Autoscaling)
+	update()
+	assert.Equal(t, apiutils.NewInt32Pointer(3), getDeployment().Spec.Replicas)
+	owned, err := isReplicasAppliedByOperator(getDeployment())
+	assert.NoError(t, err)
+	assert.False(t, owned)
+
+	// The replicas set by the HorizontalPodAutoscaler are kept
+	dca = getDeployment()
+	dca.Spec.Replicas = apiutils.NewInt32Pointer(5)
+	assert.NoError(t, fakeClient.Update(context.TODO(), dca, client.FieldOwner("kube-controller-manager")))
+	update()
+	assert.Equal(t, apiutils.NewInt32Pointer(5), getDeployment().Spec.Replicas)
+
+	// The Operator owns the replicas again when the autoscaling is disabled
+	dda.Spec.ClusterAgent.Autoscaling.Enabled = apiutils.NewBoolPointer(false)
+	update()
+	assert.Equal(t, apiutils.NewInt32Pointer(3), getDeployment().Spec.Replicas)
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+// reconcileImagePolicy pins the images of the enabled components to the digests of the image policy catalog, when
+// their digest is not set in the DatadogAgent. It runs after reconcileUpdatePolicy, which selects the image tags.
+func (r *Reconciler) reconcileImagePolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus) (reconcile.Result, error) {
+	policy := dda.Spec.ImagePolicy
+	if policy == nil {
+		return reconcile.Result{}, nil
+	}
+
+	images := getEnabledImageConfigs(dda)
+	if policy.DigestCatalog != "" {
+		catalog, err := r.getVersionCatalog(dda.Namespace, policy.DigestCatalog)
+		switch {
+		case apierrors.IsNotFound(err):
+			logger.V(1).Info("Digest catalog not found, the images are not pinned", "configmap", policy.DigestCatalog)
+		case err != nil:
+			logger.Error(err, "Unable to read the digest catalog, the images are not pinned", "configmap", policy.DigestCatalog)
+			r.recorder.Event(dda, corev1.EventTypeWarning, versionCatalogErrorEventReason, err.Error())
+		default:
+			for _, image := range images {
+				if image.Digest == "" {
+					image.Digest = catalog.Digest(getImage(image, dda.Spec.Registry))
+				}
+			}
+		}
+	}
+
+	if apiutils.BoolValue(policy.RequireDigest) {
+		for _, image := range images {
+			if ref := getImage(image, dda.Spec.Registry); !strings.Contains(ref, "@") {
+				return reconcile.Result{}, fmt.Errorf("the image %s is not pinned to a digest", ref)
+			}
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// getEnabledImageConfigs returns the image configurations of the enabled components
+func getEnabledImageConfigs(dda *datadoghqv1alpha1.DatadogAgent) []*datadoghqv1alpha1.ImageConfig {
+	var images []*datadoghqv1alpha1.ImageConfig
+	if apiutils.BoolValue(dda.Spec.Agent.Enabled) && dda.Spec.Agent.Image != nil {
+		images = append(images, dda.Spec.Agent.Image)
+	}
+	if isWindowsAgentEnabled(dda) && dda.Spec.Agent.Windows.Image != nil && dda.Spec.Agent.Windows.Image.Name != "" {
+		// The Windows Agent uses the Agent image when its image is not set
+		images = append(images, dda.Spec.Agent.Windows.Image)
+	}
+	if isClusterAgentEnabled(dda.Spec.ClusterAgent) && dda.Spec.ClusterAgent.Image != nil {
+		images = append(images, dda.Spec.ClusterAgent.Image)
+	}
+	if apiutils.BoolValue(dda.Spec.ClusterChecksRunner.Enabled) && dda.Spec.ClusterChecksRunner.Image != nil {
+		images = append(images, dda.Spec.ClusterChecksRunner.Image)
+	}
+	return images
+}
+
+// mirrorImage replaces the registry or repository of an image with its mirror. When several sources match the image,
+// the longest one is used.
+func mirrorImage(image string, policy *datadoghqv1alpha1.ImagePolicy) string {
+	if policy == nil {
+		return image
+	}
+
+	var match *datadoghqv1alpha1.ImageMirror
+	for i, mirror := range policy.Mirrors {
+		if !strings.HasPrefix(image, mirror.Source) {
+			continue
+		}
+		// The source must match whole path components
+		if rest := image[len(mirror.Source):]; rest != "" && !strings.ContainsAny(rest[:1], "/:@") {
+			continue
+		}
+		if match == nil || len(mirror.Source) > len(match.Source) {
+			match = &policy.Mirrors[i]
+		}
+	}
+
+	if match == nil {
+		return image
+	}
+	return match.Mirror + image[len(match.Source):]
+}
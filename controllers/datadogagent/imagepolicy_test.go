@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	"github.com/DataDog/datadog-operator/pkg/versioncatalog"
+)
+
+func Test_mirrorImage(t *testing.T) {
+	policy := &datadoghqv1alpha1.ImagePolicy{
+		Mirrors: []datadoghqv1alpha1.ImageMirror{
+			{Source: "gcr.io/datadoghq", Mirror: "registry.example.com/datadog"},
+			{Source: "gcr.io/datadoghq/cluster-agent", Mirror: "registry.example.com:5000/dca"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		policy *datadoghqv1alpha1.ImagePolicy
+		image  string
+		want   string
+	}{
+		{
+			name:  "no policy",
+			image: "gcr.io/datadoghq/agent:7.33.0",
+			want:  "gcr.io/datadoghq/agent:7.33.0",
+		},
+		{
+			name:   "registry mirror",
+			policy: policy,
+			image:  "gcr.io/datadoghq/agent:7.33.0",
+			want:   "registry.example.com/datadog/agent:7.33.0",
+		},
+		{
+			name:   "longest source wins",
+			policy: policy,
+			image:  "gcr.io/datadoghq/cluster-agent:1.17.0@sha256:abcd",
+			want:   "registry.example.com:5000/dca:1.17.0@sha256:abcd",
+		},
+		{
+			name:   "partial path component",
+			policy: policy,
+			image:  "gcr.io/datadoghq-dev/agent:7.33.0",
+			want:   "gcr.io/datadoghq-dev/agent:7.33.0",
+		},
+		{
+			name:   "no match",
+			policy: policy,
+			image:  "docker.io/datadog/agent:7.33.0",
+			want:   "docker.io/datadog/agent:7.33.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mirrorImage(tt.image, tt.policy))
+		})
+	}
+}
+
+func Test_reconcileImagePolicy(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	agentDigest := "sha256:" + strings.Repeat("a", 64)
+	userDigest := "sha256:" + strings.Repeat("b", 64)
+
+	newDDA := func() *datadoghqv1alpha1.DatadogAgent {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true})
+		dda.Spec.Agent.Image = &datadoghqv1alpha1.ImageConfig{Name: "agent", Tag: "7.33.0"}
+		dda.Spec.ClusterAgent.Image = &datadoghqv1alpha1.ImageConfig{Name: "cluster-agent", Tag: "1.17.0"}
+		dda.Spec.ImagePolicy = &datadoghqv1alpha1.ImagePolicy{DigestCatalog: "digests"}
+		datadoghqv1alpha1.DefaultImagePolicy(dda.Spec.ImagePolicy)
+		return dda
+	}
+	catalog := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "digests", Namespace: "bar"},
+		Data: map[string]string{
+			versioncatalog.CatalogKey: "digests:\n  gcr.io/datadoghq/agent:7.33.0: " + agentDigest + "\n",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(catalog).Build()
+	r := &Reconciler{client: fakeClient, scheme: scheme.Scheme, recorder: record.NewFakeRecorder(10)}
+
+	// the Agent is pinned from the catalog, the Cluster Agent isn't listed
+	dda := newDDA()
+	_, err := r.reconcileImagePolicy(logger, dda, &datadoghqv1alpha1.DatadogAgentStatus{})
+	assert.NoError(t, err)
+	assert.Equal(t, agentDigest, dda.Spec.Agent.Image.Digest)
+	assert.Equal(t, "", dda.Spec.ClusterAgent.Image.Digest)
+
+	// the digest of the DatadogAgent takes precedence
+	dda = newDDA()
+	dda.Spec.Agent.Image.Digest = userDigest
+	_, err = r.reconcileImagePolicy(logger, dda, &datadoghqv1alpha1.DatadogAgentStatus{})
+	assert.NoError(t, err)
+	assert.Equal(t, userDigest, dda.Spec.Agent.Image.Digest)
+
+	// the Cluster Agent image cannot be pinned
+	dda = newDDA()
+	dda.Spec.ImagePolicy.RequireDigest = apiutils.NewBoolPointer(true)
+	_, err = r.reconcileImagePolicy(logger, dda, &datadoghqv1alpha1.DatadogAgentStatus{})
+	assert.EqualError(t, err, "the image gcr.io/datadoghq/cluster-agent:1.17.0 is not pinned to a digest")
+
+	// the Windows Agent image is pinned, and required to be pinned
+	dda = newDDA()
+	dda.Spec.ClusterAgent.Image.Digest = userDigest
+	dda.Spec.Agent.Windows = &datadoghqv1alpha1.WindowsAgentConfig{
+		Enabled: apiutils.NewBoolPointer(true),
+		Image:   &datadoghqv1alpha1.ImageConfig{Name: "agent", Tag: "7.33.0"},
+	}
+	_, err = r.reconcileImagePolicy(logger, dda, &datadoghqv1alpha1.DatadogAgentStatus{})
+	assert.NoError(t, err)
+	assert.Equal(t, agentDigest, dda.Spec.Agent.Windows.Image.Digest)
+
+	dda.Spec.Agent.Windows.Image = &datadoghqv1alpha1.ImageConfig{Name: "agent", Tag: "7.34.0"}
+	dda.Spec.ImagePolicy.RequireDigest = apiutils.NewBoolPointer(true)
+	_, err = r.reconcileImagePolicy(logger, dda, &datadoghqv1alpha1.DatadogAgentStatus{})
+	assert.EqualError(t, err, "the image gcr.io/datadoghq/agent:7.34.0 is not pinned to a digest")
+}
@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/pkg/controller/utils/comparison"
+	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
+)
+
+// resourceOwnership defines how the DatadogAgent ownership of a managed resource is tracked
+type resourceOwnership int
+
+const (
+	// ownedByController the DatadogAgent is the controller of the resource, the resource must be in the same namespace
+	ownedByController resourceOwnership = iota
+	// ownedByReference the DatadogAgent is set as controller owner reference of a cluster-scoped resource
+	ownedByReference
+	// ownedByLabels the ownership of a cluster-scoped resource is tracked with its labels, see isOwnerBasedOnLabels
+	ownedByLabels
+)
+
+// resourceHashFunc computes the hash of a resource, stored in its MD5AgentDeploymentAnnotationKey annotation
+type resourceHashFunc func(obj client.Object) (string, error)
+
+// hashObject hashes the whole resource as built
+func hashObject(obj client.Object) (string, error) {
+	return comparison.GenerateMD5ForSpec(obj)
+}
+
+// managedResource describes a resource created, updated and deleted by the DatadogAgent reconciler.
+type managedResource struct {
+	// kind of the resource, used for logs and events
+	kind string
+	// name and namespace of the resource, the namespace is empty for cluster-scoped resources
+	name      string
+	namespace string
+	// newObject returns an empty object of the resource type, used to retrieve and delete the resource
+	newObject func() client.Object
+	// build returns the desired state of the resource, or nil if the resource is not needed anymore
+	build func() (client.Object, error)
+	// ownership defines how the DatadogAgent ownership is set and checked
+	ownership resourceOwnership
+	// hash is optional, when set the hash of the built resource is stored in an annotation
+	hash resourceHashFunc
+	// cleanup deletes the resource instead of applying it
+	cleanup bool
+	// skipIfNotOwned leaves untouched an existing resource that is not owned by the DatadogAgent,
+	// for instance a Secret provided by the user
+	skipIfNotOwned bool
+	// recreateOnInvalid deletes and recreates the resource when the apply is rejected, for resources
+	// with immutable fields
+	recreateOnInvalid bool
+}
+
+// manageResource applies or cleans up a managed resource.
+// Dependent resources are applied in a single reconcile loop, server-side apply returning the up-to-date
+// resource there is no need to requeue.
+func (r *Reconciler) manageResource(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, res managedResource) (reconcile.Result, error) {
+	if res.cleanup {
+		return reconcile.Result{}, r.cleanupResource(logger, dda, res)
+	}
+
+	if res.skipIfNotOwned {
+		current, err := r.getResource(res)
+		if err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		if err == nil && !isResourceOwner(dda, current, res.ownership) {
+			logger.V(1).Info("Resource not managed by this DatadogAgent, skipping", "kind", res.kind, "name", res.name, "namespace", res.namespace)
+			return reconcile.Result{}, nil
+		}
+	}
+
+	obj, err := res.build()
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("unable to build %s %s: %w", res.kind, res.name, err)
+	}
+	if isNilObject(obj) {
+		return reconcile.Result{}, r.cleanupResource(logger, dda, res)
+	}
+
+	if res.hash != nil {
+		hash, hashErr := res.hash(obj)
+		if hashErr != nil {
+			return reconcile.Result{}, fmt.Errorf("unable to hash %s %s: %w", res.kind, res.name, hashErr)
+		}
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[datadoghqv1alpha1.MD5AgentDeploymentAnnotationKey] = hash
+		obj.SetAnnotations(annotations)
+	}
+
+	if err = r.setResourceOwner(dda, obj, res.ownership); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	_, err = r.applyResource(logger, dda, obj)
+	if err != nil && res.recreateOnInvalid && errors.IsInvalid(err) {
+		logger.Info("Recreating resource with immutable fields", "kind", res.kind, "name", res.name, "namespace", res.namespace)
+		if err = r.deleteResource(res); err != nil {
+			return reconcile.Result{}, err
+		}
+		_, err = r.applyResource(logger, dda, obj)
+	}
+
+	return reconcile.Result{}, err
+}
+
+// cleanupResource deletes a managed resource, if it exists and is owned by the DatadogAgent
+func (r *Reconciler) cleanupResource(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, res managedResource) error {
+	current, err := r.getResource(res)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !isResourceOwner(dda, current, res.ownership) {
+		return nil
+	}
+
+	logger.V(1).Info("Deleting resource", "kind", res.kind, "name", res.name, "namespace", res.namespace)
+	if err = r.client.Delete(context.TODO(), current); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	r.recordEvent(dda, buildEventInfo(res.name, res.namespace, res.kind, datadog.DeletionEvent))
+
+	return nil
+}
+
+func (r *Reconciler) getResource(res managedResource) (client.Object, error) {
+	obj := res.newObject()
+	err := r.client.Get(context.TODO(), types.NamespacedName{Name: res.name, Namespace: res.namespace}, obj)
+	return obj, err
+}
+
+func (r *Reconciler) deleteResource(res managedResource) error {
+	obj := res.newObject()
+	obj.SetName(res.name)
+	obj.SetNamespace(res.namespace)
+	if err := r.client.Delete(context.TODO(), obj); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *Reconciler) setResourceOwner(dda *datadoghqv1alpha1.DatadogAgent, obj client.Object, ownership resourceOwnership) error {
+	switch ownership {
+	case ownedByController:
+		return controllerutil.SetControllerReference(dda, obj, r.scheme)
+	case ownedByReference:
+		return SetOwnerReference(dda, obj, r.scheme)
+	default:
+		// The ownership labels are set by the resource builder
+		return nil
+	}
+}
+
+func isResourceOwner(dda *datadoghqv1alpha1.DatadogAgent, obj client.Object, ownership resourceOwnership) bool {
+	if ownership == ownedByLabels {
+		return isOwnerBasedOnLabels(dda, obj.GetLabels())
+	}
+	return CheckOwnerReference(dda, obj)
+}
+
+// isNilObject returns true if obj is nil or is a nil pointer returned by a typed builder
+func isNilObject(obj client.Object) bool {
+	if obj == nil {
+		return true
+	}
+	value := reflect.ValueOf(obj)
+	return value.Kind() == reflect.Ptr && value.IsNil()
+}
@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
+)
+
+func Test_manageResource(t *testing.T) {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+	dda.UID = "dda-uid"
+	otherDda := test.NewDefaultedDatadogAgent("bar", "other", nil)
+	otherDda.UID = "other-uid"
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{})
+
+	newConfigMap := func(owner *datadoghqv1alpha1.DatadogAgent, value string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo-config",
+				Namespace: "bar",
+			},
+			Data: map[string]string{"key": value},
+		}
+		if owner != nil {
+			_ = SetOwnerReference(owner, cm, s)
+		}
+		return cm
+	}
+	configMapResource := func(build *corev1.ConfigMap) managedResource {
+		return managedResource{
+			kind:      configMapKind,
+			name:      "foo-config",
+			namespace: "bar",
+			newObject: func() client.Object { return &corev1.ConfigMap{} },
+			build:     func() (client.Object, error) { return build, nil },
+			ownership: ownedByController,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		existing []client.Object
+		res      managedResource
+		// wantValue is the expected ConfigMap value, empty if the ConfigMap should not exist
+		wantValue string
+		wantOwned bool
+		wantHash  bool
+	}{
+		{
+			name:      "resource created with the DatadogAgent as owner",
+			res:       configMapResource(newConfigMap(nil, "new")),
+			wantValue: "new",
+			wantOwned: true,
+		},
+		{
+			name:     "owned resource updated",
+			existing: []client.Object{newConfigMap(dda, "old")},
+			res: func() managedResource {
+				res := configMapResource(newConfigMap(nil, "new"))
+				res.hash = hashObject
+				return res
+			}(),
+			wantValue: "new",
+			wantOwned: true,
+			wantHash:  true,
+		},
+		{
+			name:     "nil resource, owned resource deleted",
+			existing: []client.Object{newConfigMap(dda, "old")},
+			res:      configMapResource(nil),
+		},
+		{
+			name:     "cleanup, resource owned by another DatadogAgent kept",
+			existing: []client.Object{newConfigMap(otherDda, "other")},
+			res: func() managedResource {
+				res := configMapResource(nil)
+				res.cleanup = true
+				return res
+			}(),
+			wantValue: "other",
+		},
+		{
+			name:     "skipIfNotOwned, resource provided by the user kept",
+			existing: []client.Object{newConfigMap(nil, "user")},
+			res: func() managedResource {
+				res := configMapResource(newConfigMap(nil, "new"))
+				res.skipIfNotOwned = true
+				return res
+			}(),
+			wantValue: "user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithScheme(s).WithObjects(tt.existing...).Build())
+			r := &Reconciler{
+				client:   fakeClient,
+				scheme:   s,
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			_, err := r.manageResource(logf.Log.WithName(tt.name), dda, tt.res)
+			assert.NoError(t, err)
+
+			cm := &corev1.ConfigMap{}
+			err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: "foo-config"}, cm)
+			if tt.wantValue == "" {
+				assert.True(t, apierrors.IsNotFound(err), "ConfigMap not deleted")
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValue, cm.Data["key"])
+			_, hasHash := cm.Annotations[datadoghqv1alpha1.MD5AgentDeploymentAnnotationKey]
+			assert.Equal(t, tt.wantHash, hasHash)
+			assert.Equal(t, tt.wantOwned, CheckOwnerReference(dda, cm))
+		})
+	}
+}
@@ -6,20 +6,15 @@
 package datadogagent
 
 import (
-	"context"
 	"fmt"
 	"strconv"
 
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	apiutils "github.com/DataDog/datadog-operator/apis/utils"
-	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
-	"github.com/DataDog/datadog-operator/pkg/kubernetes"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -69,58 +64,21 @@ func buildOrchestratorExplorerConfigMap(dda *datadoghqv1alpha1.DatadogAgent) (*c
 	return configMap, nil
 }
 
-func (r *Reconciler) createOrchestratorExplorerClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, version string) (reconcile.Result, error) {
-	clusterRole := buildOrchestratorExplorerRBAC(dda, name, version)
-	logger.V(1).Info("createOrchestratorClusterRole", "clusterRole.name", clusterRole.Name)
-	event := buildEventInfo(clusterRole.Name, clusterRole.Namespace, clusterRoleKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-	return reconcile.Result{Requeue: true}, r.client.Create(context.TODO(), clusterRole)
-}
-
-func (r *Reconciler) updateIfNeededOrchestratorExplorerClusterRole(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name, version string, clusterRole *rbacv1.ClusterRole) (reconcile.Result, error) {
-	newClusterRole := buildOrchestratorExplorerRBAC(dda, name, version)
-	if !isClusterRolesEqual(newClusterRole, clusterRole) {
-		logger.V(1).Info("updateOrchestratorClusterRole", "clusterRole.name", clusterRole.Name)
-		if err := kubernetes.UpdateFromObject(context.TODO(), r.client, newClusterRole, clusterRole.ObjectMeta); err != nil {
-			return reconcile.Result{}, err
-		}
-		event := buildEventInfo(newClusterRole.Name, newClusterRole.Namespace, clusterRoleKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
-	}
-	return reconcile.Result{}, nil
-}
-
 func getOrchestratorRBACResourceName(dda *datadoghqv1alpha1.DatadogAgent, suffix string) string {
 	return fmt.Sprintf("%s-%s-%s-%s", dda.Namespace, dda.Name, orchestratorExplorerRBACPrefix, suffix)
 }
 
 func (r *Reconciler) createOrUpdateOrchestratorCoreRBAC(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, serviceAccountName, componentVersion, nameSuffix string) (reconcile.Result, error) {
 	orchestratorRBACName := getOrchestratorRBACResourceName(dda, nameSuffix)
-	orchestratorClusterRole := &rbacv1.ClusterRole{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: orchestratorRBACName}, orchestratorClusterRole); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createOrchestratorExplorerClusterRole(logger, dda, orchestratorRBACName, componentVersion)
-		}
-		return reconcile.Result{}, err
-	}
-
-	if result, err := r.updateIfNeededOrchestratorExplorerClusterRole(logger, dda, orchestratorRBACName, componentVersion, orchestratorClusterRole); err != nil {
+	if result, err := r.applyClusterRole(logger, dda, buildOrchestratorExplorerRBAC(dda, orchestratorRBACName, componentVersion)); err != nil {
 		return result, err
 	}
 
-	orchestratorClusterRoleBinding := &rbacv1.ClusterRoleBinding{}
-	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: orchestratorRBACName}, orchestratorClusterRoleBinding); err != nil {
-		if errors.IsNotFound(err) {
-			return r.createClusterRoleBindingFromInfo(logger, dda, roleBindingInfo{
-				name:               orchestratorRBACName,
-				roleName:           orchestratorRBACName,
-				serviceAccountName: serviceAccountName,
-			}, componentVersion)
-		}
-		return reconcile.Result{}, err
-	}
-
-	return r.updateIfNeededClusterRoleBinding(logger, dda, orchestratorRBACName, orchestratorRBACName, serviceAccountName, componentVersion, orchestratorClusterRoleBinding)
+	return r.applyClusterRoleBindingFromInfo(logger, dda, roleBindingInfo{
+		name:               orchestratorRBACName,
+		roleName:           orchestratorRBACName,
+		serviceAccountName: serviceAccountName,
+	}, componentVersion)
 }
 
 func (r *Reconciler) cleanupOrchestratorCoreRBAC(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, nameSuffix string) (reconcile.Result, error) {
@@ -0,0 +1,217 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	edsdatadoghqv1alpha1 "github.com/DataDog/extendeddaemonset/api/v1alpha1"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
+	"github.com/DataDog/datadog-operator/pkg/kubernetes"
+)
+
+const (
+	// orphanCollectionPeriod is the minimum period between two collections of the orphaned resources of a DatadogAgent
+	orphanCollectionPeriod = 5 * time.Minute
+)
+
+// appliedResourcesStore keeps the resources applied while reconciling each DatadogAgent, they are the desired
+// resources of the DatadogAgent when the reconcile completes. It also keeps the time of the last orphan collection.
+type appliedResourcesStore struct {
+	mutex          sync.Mutex
+	resources      map[types.NamespacedName]map[string]bool
+	lastCollection map[types.NamespacedName]time.Time
+}
+
+func newAppliedResourcesStore() *appliedResourcesStore {
+	return &appliedResourcesStore{
+		resources:      make(map[types.NamespacedName]map[string]bool),
+		lastCollection: make(map[types.NamespacedName]time.Time),
+	}
+}
+
+// reset forgets the resources applied by the previous reconcile of a DatadogAgent
+func (s *appliedResourcesStore) reset(dda *datadoghqv1alpha1.DatadogAgent) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.resources[types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}] = make(map[string]bool)
+}
+
+func (s *appliedResourcesStore) add(dda *datadoghqv1alpha1.DatadogAgent, key string) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ddaKey := types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}
+	if s.resources[ddaKey] == nil {
+		s.resources[ddaKey] = make(map[string]bool)
+	}
+	s.resources[ddaKey][key] = true
+}
+
+// collect returns the resources applied while reconciling a DatadogAgent if its orphans are due for collection
+func (s *appliedResourcesStore) collect(dda *datadoghqv1alpha1.DatadogAgent, now time.Time) (map[string]bool, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ddaKey := types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}
+	if last, found := s.lastCollection[ddaKey]; found && now.Sub(last) < orphanCollectionPeriod {
+		return nil, false
+	}
+	s.lastCollection[ddaKey] = now
+	return s.resources[ddaKey], true
+}
+
+// forget removes a deleted DatadogAgent from the store
+func (s *appliedResourcesStore) forget(dda *datadoghqv1alpha1.DatadogAgent) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ddaKey := types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}
+	delete(s.resources, ddaKey)
+	delete(s.lastCollection, ddaKey)
+}
+
+func appliedResourceKey(gk schema.GroupKind, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", gk.String(), namespace, name)
+}
+
+// recordAppliedResource adds obj to the desired resources of the DatadogAgent
+func (r *Reconciler) recordAppliedResource(dda *datadoghqv1alpha1.DatadogAgent, obj client.Object) {
+	gvk, err := apiutil.GVKForObject(obj, r.scheme)
+	if err != nil {
+		return
+	}
+	r.appliedResources.add(dda, appliedResourceKey(gvk.GroupKind(), obj.GetNamespace(), obj.GetName()))
+}
+
+// collectOrphans deletes the resources labeled as part of the DatadogAgent that were not applied by the last reconcile,
+// for instance the resources of a disabled feature or the resources renamed with a name override.
+// It must only be called when all the resources of the DatadogAgent were applied, after a complete reconcile.
+func (r *Reconciler) collectOrphans(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) {
+	if !r.options.OrphanCollectionEnabled {
+		return
+	}
+	desired, due := r.appliedResources.collect(dda, time.Now())
+	if !due {
+		return
+	}
+
+	selector := client.MatchingLabels{
+		kubernetes.AppKubernetesManageByLabelKey: "datadog-operator",
+		kubernetes.AppKubernetesPartOfLabelKey:   NewPartOfLabelValue(dda).String(),
+	}
+	for _, gvk := range r.getCollectableKinds() {
+		list := r.newObjectList(gvk)
+		if err := r.client.List(context.TODO(), list, selector); err != nil {
+			if !meta.IsNoMatchError(err) {
+				logger.Error(err, "Unable to list the resources of the DatadogAgent", "kind", gvk.Kind)
+			}
+			continue
+		}
+
+		err := meta.EachListItem(list, func(item runtime.Object) error {
+			obj, ok := item.(client.Object)
+			if !ok || obj.GetDeletionTimestamp() != nil || !isOwnerBasedOnLabels(dda, obj.GetLabels()) {
+				return nil
+			}
+			if desired[appliedResourceKey(gvk.GroupKind(), obj.GetNamespace(), obj.GetName())] {
+				return nil
+			}
+
+			if r.options.OrphanCollectionDryRun {
+				logger.Info("Orphaned resource would be deleted (dry-run)", "kind", gvk.Kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+				return nil
+			}
+			logger.Info("Deleting orphaned resource", "kind", gvk.Kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+			if err := r.client.Delete(context.TODO(), obj); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			r.recordEvent(dda, buildEventInfo(obj.GetName(), obj.GetNamespace(), gvk.Kind, datadog.DeletionEvent))
+			return nil
+		})
+		if err != nil {
+			logger.Error(err, "Unable to delete the orphaned resources of the DatadogAgent", "kind", gvk.Kind)
+		}
+	}
+}
+
+// getCollectableKinds returns the kinds of the resources created by the reconciler.
+// The resources created by the workloads, like the Pods, also get the DatadogAgent labels and are not collected.
+func (r *Reconciler) getCollectableKinds() []schema.GroupVersionKind {
+	kinds := []schema.GroupVersionKind{
+		appsv1.SchemeGroupVersion.WithKind(daemonSetKind),
+		appsv1.SchemeGroupVersion.WithKind(deploymentKind),
+		corev1.SchemeGroupVersion.WithKind(configMapKind),
+		corev1.SchemeGroupVersion.WithKind(secretKind),
+		corev1.SchemeGroupVersion.WithKind(serviceKind),
+		corev1.SchemeGroupVersion.WithKind(serviceAccountKind),
+		rbacv1.SchemeGroupVersion.WithKind(roleKind),
+		rbacv1.SchemeGroupVersion.WithKind(roleBindingKind),
+		rbacv1.SchemeGroupVersion.WithKind(clusterRoleKind),
+		rbacv1.SchemeGroupVersion.WithKind(clusterRoleBindingKind),
+		policyv1.SchemeGroupVersion.WithKind(podDisruptionBudgetKind),
+		autoscalingv2beta2.SchemeGroupVersion.WithKind(horizontalPodAutoscalerKind),
+		networkingv1.SchemeGroupVersion.WithKind(networkPolicyKind),
+		apiregistrationv1.SchemeGroupVersion.WithKind(apiServiceKind),
+	}
+	if r.options.SupportExtendedDaemonset {
+		kinds = append(kinds, edsdatadoghqv1alpha1.GroupVersion.WithKind(extendedDaemonSetKind))
+	}
+	if r.options.SupportCilium {
+		kinds = append(kinds, ciliumGroupVersionKind())
+	}
+	if r.options.SupportOpenShiftSCC {
+		kinds = append(kinds, securityContextConstraintsGroupVersionKind())
+	}
+	return kinds
+}
+
+// newObjectList returns a typed list for the kinds registered in the scheme, an unstructured list otherwise
+func (r *Reconciler) newObjectList(gvk schema.GroupVersionKind) client.ObjectList {
+	listGVK := gvk.GroupVersion().WithKind(gvk.Kind + "List")
+	if obj, err := r.scheme.New(listGVK); err == nil {
+		if list, ok := obj.(client.ObjectList); ok {
+			return list
+		}
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(listGVK)
+	return list
+}
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+)
+
+func Test_collectOrphans(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{UseEDS: false, ClusterAgentEnabled: false})
+	otherDDA := test.NewDefaultedDatadogAgent("bar", "other", &test.NewDatadogAgentOptions{UseEDS: false, ClusterAgentEnabled: false})
+
+	s := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(s))
+	utilruntime.Must(apiregistrationv1.AddToScheme(s))
+	utilruntime.Must(datadoghqv1alpha1.AddToScheme(s))
+
+	newObjects := func() []client.Object {
+		labels := getDefaultLabels(dda, dda.Name, "")
+		return []client.Object{
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo-current", Namespace: "bar", Labels: labels}},
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo-renamed", Namespace: "bar", Labels: labels}},
+			&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "foo-disabled", Labels: labels}},
+			// Created by a workload, not collected
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo-agent-abcde", Namespace: "bar", Labels: labels}},
+			// Part of another DatadogAgent
+			&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "bar", Labels: getDefaultLabels(otherDDA, otherDDA.Name, "")}},
+		}
+	}
+	exists := func(c client.Client, obj client.Object, name, namespace string) bool {
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, obj)
+		if apierrors.IsNotFound(err) {
+			return false
+		}
+		assert.NoError(t, err)
+		return true
+	}
+
+	tests := []struct {
+		name        string
+		options     ReconcilerOptions
+		wantDeleted bool
+	}{
+		{
+			name:        "disabled",
+			options:     ReconcilerOptions{},
+			wantDeleted: false,
+		},
+		{
+			name:        "dry-run",
+			options:     ReconcilerOptions{OrphanCollectionEnabled: true, OrphanCollectionDryRun: true},
+			wantDeleted: false,
+		},
+		{
+			name:        "enabled",
+			options:     ReconcilerOptions{OrphanCollectionEnabled: true},
+			wantDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(newObjects()...).Build()
+			r := &Reconciler{
+				client:           fakeClient,
+				scheme:           s,
+				recorder:         record.NewFakeRecorder(10),
+				options:          tt.options,
+				appliedResources: newAppliedResourcesStore(),
+			}
+
+			r.appliedResources.reset(dda)
+			current := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "foo-current", Namespace: "bar"}}
+			r.recordAppliedResource(dda, current)
+			r.collectOrphans(logger, dda)
+
+			assert.True(t, exists(fakeClient, &corev1.ConfigMap{}, "foo-current", "bar"))
+			assert.True(t, exists(fakeClient, &corev1.Pod{}, "foo-agent-abcde", "bar"))
+			assert.True(t, exists(fakeClient, &corev1.ConfigMap{}, "other", "bar"))
+			assert.Equal(t, !tt.wantDeleted, exists(fakeClient, &corev1.ConfigMap{}, "foo-renamed", "bar"))
+			assert.Equal(t, !tt.wantDeleted, exists(fakeClient, &rbacv1.ClusterRole{}, "foo-disabled", ""))
+		})
+	}
+}
+
+func Test_appliedResourcesStore_collect(t *testing.T) {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{})
+	store := newAppliedResourcesStore()
+	now := time.Now()
+
+	store.reset(dda)
+	store.add(dda, "ConfigMap/bar/foo")
+	desired, due := store.collect(dda, now)
+	assert.True(t, due)
+	assert.Equal(t, map[string]bool{"ConfigMap/bar/foo": true}, desired)
+
+	// The orphans are collected periodically
+	_, due = store.collect(dda, now.Add(time.Minute))
+	assert.False(t, due)
+	_, due = store.collect(dda, now.Add(orphanCollectionPeriod))
+	assert.True(t, due)
+
+	store.forget(dda)
+	_, due = store.collect(dda, now.Add(orphanCollectionPeriod+time.Second))
+	assert.True(t, due)
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+const (
+	otlpGRPCPortName  = "otlpgrpcport"
+	otlpHTTPPortName  = "otlphttpport"
+	otlpUnixTransport = "unix"
+)
+
+func isOTLPEnabled(spec *datadoghqv1alpha1.DatadogAgentSpec) bool {
+	return spec.Features.OTLP != nil && apiutils.BoolValue(spec.Features.OTLP.Enabled)
+}
+
+func isOTLPUDSEnabled(spec *datadoghqv1alpha1.DatadogAgentSpec) bool {
+	if !isOTLPGRPCEnabled(spec) || spec.Features.OTLP.UnixDomainSocket == nil {
+		return false
+	}
+	return apiutils.BoolValue(spec.Features.OTLP.UnixDomainSocket.Enabled)
+}
+
+func isOTLPGRPCEnabled(spec *datadoghqv1alpha1.DatadogAgentSpec) bool {
+	return isOTLPEnabled(spec) && spec.Features.OTLP.GRPC != nil && apiutils.BoolValue(spec.Features.OTLP.GRPC.Enabled)
+}
+
+func isOTLPHTTPEnabled(spec *datadoghqv1alpha1.DatadogAgentSpec) bool {
+	return isOTLPEnabled(spec) && spec.Features.OTLP.HTTP != nil && apiutils.BoolValue(spec.Features.OTLP.HTTP.Enabled)
+}
+
+// getEnvVarsForOTLP returns the env vars configuring the OTLP receivers, they are set on the core Agent, which runs the
+// receivers, and on the trace Agent, which receives the traces from the core Agent.
+func getEnvVarsForOTLP(dda *datadoghqv1alpha1.DatadogAgent) []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	spec := &dda.Spec
+
+	if isOTLPUDSEnabled(spec) {
+		envVars = append(envVars,
+			corev1.EnvVar{
+				Name:  datadoghqv1alpha1.DDOTLPGRPCEndpoint,
+				Value: getLocalFilepath(*spec.Features.OTLP.UnixDomainSocket.HostFilepath, datadoghqv1alpha1.OTLPSocketVolumePath),
+			},
+			corev1.EnvVar{
+				Name:  datadoghqv1alpha1.DDOTLPGRPCTransport,
+				Value: otlpUnixTransport,
+			},
+		)
+	} else if isOTLPGRPCEnabled(spec) {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  datadoghqv1alpha1.DDOTLPGRPCEndpoint,
+			Value: fmt.Sprintf("0.0.0.0:%d", *spec.Features.OTLP.GRPC.Port),
+		})
+	}
+
+	if isOTLPHTTPEnabled(spec) {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  datadoghqv1alpha1.DDOTLPHTTPEndpoint,
+			Value: fmt.Sprintf("0.0.0.0:%d", *spec.Features.OTLP.HTTP.Port),
+		})
+	}
+
+	return envVars
+}
+
+// getOTLPContainerPorts returns the TCP ports of the OTLP receivers of the core Agent container
+func getOTLPContainerPorts(dda *datadoghqv1alpha1.DatadogAgent) []corev1.ContainerPort {
+	var ports []corev1.ContainerPort
+	spec := &dda.Spec
+
+	if isOTLPGRPCEnabled(spec) && !isOTLPUDSEnabled(spec) {
+		ports = append(ports, newOTLPContainerPort(otlpGRPCPortName, spec.Features.OTLP.GRPC))
+	}
+	if isOTLPHTTPEnabled(spec) {
+		ports = append(ports, newOTLPContainerPort(otlpHTTPPortName, spec.Features.OTLP.HTTP))
+	}
+
+	return ports
+}
+
+func newOTLPContainerPort(name string, protocol *datadoghqv1alpha1.OTLPProtocolConfig) corev1.ContainerPort {
+	port := corev1.ContainerPort{
+		ContainerPort: *protocol.Port,
+		Name:          name,
+		Protocol:      corev1.ProtocolTCP,
+	}
+	if protocol.HostPort != nil {
+		port.HostPort = *protocol.HostPort
+	}
+	return port
+}
+
+func getVolumeForOTLPSocket(dda *datadoghqv1alpha1.DatadogAgent) corev1.Volume {
+	volumeType := corev1.HostPathDirectoryOrCreate
+	return corev1.Volume{
+		Name: datadoghqv1alpha1.OTLPSocketVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: getDirFromFilepath(*dda.Spec.Features.OTLP.UnixDomainSocket.HostFilepath),
+				Type: &volumeType,
+			},
+		},
+	}
+}
+
+func getVolumeMountForOTLPSocket() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      datadoghqv1alpha1.OTLPSocketVolumeName,
+		MountPath: datadoghqv1alpha1.OTLPSocketVolumePath,
+	}
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+func Test_OTLP(t *testing.T) {
+	newDDA := func(otlp *datadoghqv1alpha1.OTLPConfig) *datadoghqv1alpha1.DatadogAgent {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{})
+		dda.Spec.Features.OTLP = otlp
+		datadoghqv1alpha1.DefaultDatadogFeatureOTLP(&dda.Spec.Features)
+		return dda
+	}
+
+	tests := []struct {
+		name        string
+		otlp        *datadoghqv1alpha1.OTLPConfig
+		wantEnvVars []corev1.EnvVar
+		wantPorts   []corev1.ContainerPort
+		wantVolume  bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name: "gRPC and HTTP receivers",
+			otlp: &datadoghqv1alpha1.OTLPConfig{
+				Enabled: apiutils.NewBoolPointer(true),
+				HTTP:    &datadoghqv1alpha1.OTLPProtocolConfig{HostPort: apiutils.NewInt32Pointer(14318)},
+			},
+			wantEnvVars: []corev1.EnvVar{
+				{Name: datadoghqv1alpha1.DDOTLPGRPCEndpoint, Value: "0.0.0.0:4317"},
+				{Name: datadoghqv1alpha1.DDOTLPHTTPEndpoint, Value: "0.0.0.0:4318"},
+			},
+			wantPorts: []corev1.ContainerPort{
+				{Name: otlpGRPCPortName, ContainerPort: 4317, Protocol: corev1.ProtocolTCP},
+				{Name: otlpHTTPPortName, ContainerPort: 4318, HostPort: 14318, Protocol: corev1.ProtocolTCP},
+			},
+		},
+		{
+			name: "gRPC receiver over Unix Domain Socket",
+			otlp: &datadoghqv1alpha1.OTLPConfig{
+				Enabled:          apiutils.NewBoolPointer(true),
+				HTTP:             &datadoghqv1alpha1.OTLPProtocolConfig{Enabled: apiutils.NewBoolPointer(false)},
+				UnixDomainSocket: &datadoghqv1alpha1.OTLPUnixDomainSocketConfig{Enabled: apiutils.NewBoolPointer(true)},
+			},
+			wantEnvVars: []corev1.EnvVar{
+				{Name: datadoghqv1alpha1.DDOTLPGRPCEndpoint, Value: "/var/run/datadog/otlp/otlp.sock"},
+				{Name: datadoghqv1alpha1.DDOTLPGRPCTransport, Value: "unix"},
+			},
+			wantVolume: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dda := newDDA(tt.otlp)
+			assert.Equal(t, tt.wantEnvVars, getEnvVarsForOTLP(dda))
+			assert.Equal(t, tt.wantPorts, getOTLPContainerPorts(dda))
+
+			hasVolume := false
+			for _, volume := range getVolumesForAgent(dda) {
+				if volume.Name == datadoghqv1alpha1.OTLPSocketVolumeName {
+					hasVolume = true
+					assert.Equal(t, "/var/run/datadog", volume.HostPath.Path)
+				}
+			}
+			assert.Equal(t, tt.wantVolume, hasVolume)
+
+			// the receivers are exposed by the Agent service
+			service := newAgentService(dda)
+			for _, port := range tt.wantPorts {
+				assert.Contains(t, service.Spec.Ports, corev1.ServicePort{
+					Name:       port.Name,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       port.ContainerPort,
+					TargetPort: intstr.FromInt(int(port.ContainerPort)),
+				})
+			}
+		})
+	}
+}
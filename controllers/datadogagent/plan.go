@@ -0,0 +1,380 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/patch"
+)
+
+// PlanOperation is the operation the operator would perform on an object
+type PlanOperation string
+
+const (
+	// PlanOperationCreate the object would be created
+	PlanOperationCreate PlanOperation = "create"
+	// PlanOperationUpdate the object would be updated
+	PlanOperationUpdate PlanOperation = "update"
+	// PlanOperationDelete the object would be deleted
+	PlanOperationDelete PlanOperation = "delete"
+)
+
+// planPlaceholderUID is used as UID of a DatadogAgent that does not exist yet, the owner references
+// of the planned objects require one.
+const planPlaceholderUID = types.UID("00000000-0000-0000-0000-000000000000")
+
+// PlannedChange is a change the operator would apply to an object
+type PlannedChange struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Operation PlanOperation
+	// Diff is the unified diff between the live object and the planned object, in YAML
+	Diff string
+	// TriggersRollout is true when the pod template of a workload changes: its pods would be recreated
+	TriggersRollout bool
+}
+
+// Plan computes the objects the operator would apply to reconcile dda, without applying them,
+// and returns their differences with the live objects.
+// The planned objects are submitted with server-side dry-run requests: they include the defaults set by the
+// API server, and the client must be allowed to create and update them.
+// The plan covers a single reconcile loop, the changes applied after a requeue (like the migration between
+// a DaemonSet and an ExtendedDaemonSet) are not planned. The errors returned while reconciling a component
+// are aggregated in the returned error, along with the changes planned.
+func Plan(ctx context.Context, c client.Client, options ReconcilerOptions, versionInfo *version.Info, dda *datadoghqv1alpha1.DatadogAgent) ([]PlannedChange, error) {
+	instance := dda.DeepCopy()
+	live := &datadoghqv1alpha1.DatadogAgent{}
+	err := c.Get(ctx, client.ObjectKeyFromObject(instance), live)
+	switch {
+	case err == nil:
+		instance.UID = live.UID
+		instance.ResourceVersion = live.ResourceVersion
+		instance.CreationTimestamp = live.CreationTimestamp
+		instance.Status = live.Status
+	case apierrors.IsNotFound(err):
+		instance.UID = planPlaceholderUID
+	default:
+		return nil, fmt.Errorf("unable to get DatadogAgent %s/%s: %w", instance.Namespace, instance.Name, err)
+	}
+
+	planner := newPlanClient(c)
+	reconcileErr := reconcileOnce(planner, options, versionInfo, instance)
+	if reconcileErr != nil && !isAggregate(reconcileErr) {
+		return nil, reconcileErr
+	}
+
+	changes, err := planner.changes()
+	if err != nil {
+		return nil, err
+	}
+	return changes, reconcileErr
+}
+
+// reconcileOnce runs a single reconcile loop of dda with the client c, the DatadogAgent and its status are not updated.
+// The requeues and the errors of a component, like a Cluster Agent not ready yet, don't prevent from reconciling
+// the next components: they are returned as an aggregate error.
+func reconcileOnce(c client.Client, options ReconcilerOptions, versionInfo *version.Info, dda *datadoghqv1alpha1.DatadogAgent) error {
+	options.OperatorMetricsEnabled = false
+	r, err := NewReconciler(options, c, c, versionInfo, c.Scheme(), logr.Discard(), &record.FakeRecorder{}, nil)
+	if err != nil {
+		return err
+	}
+
+	instance, _ := patch.CopyAndPatchDatadogAgent(dda)
+	if err = datadoghqv1alpha1.IsValidDatadogAgent(&instance.Spec); err != nil {
+		return fmt.Errorf("invalid DatadogAgent: %w", err)
+	}
+	datadoghqv1alpha1.DefaultDatadogAgent(instance)
+
+	var errs []error
+	newStatus := instance.Status.DeepCopy()
+	r.appliedResources.reset(instance)
+	for _, reconcileFunc := range r.reconcileFuncs() {
+		if _, err = reconcileFunc(r.log, instance, newStatus); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		// All the resources of the DatadogAgent were applied
+		r.collectOrphans(r.log, instance)
+		return nil
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func isAggregate(err error) bool {
+	var aggregate utilerrors.Aggregate
+	return errors.As(err, &aggregate)
+}
+
+// plannedObject contains the live and the planned state of an object, nil if the object doesn't exist
+type plannedObject struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+	current   map[string]interface{}
+	desired   map[string]interface{}
+}
+
+// planClient sends the writes of the reconciler as server-side dry-run requests and records
+// the resulting objects; the live objects are never modified.
+type planClient struct {
+	client.Client
+	keys    []string
+	objects map[string]*plannedObject
+}
+
+func newPlanClient(c client.Client) *planClient {
+	return &planClient{
+		Client:  c,
+		objects: make(map[string]*plannedObject),
+	}
+}
+
+// Create sends a dry-run create request
+func (c *planClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	current, err := c.getLive(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err = c.Client.Create(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+	return c.record(obj, current, obj)
+}
+
+// Update sends a dry-run update request
+func (c *planClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	current, err := c.getLive(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err = c.Client.Update(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+	return c.record(obj, current, obj)
+}
+
+// Patch sends a dry-run patch request, server-side apply included
+func (c *planClient) Patch(ctx context.Context, obj client.Object, p client.Patch, opts ...client.PatchOption) error {
+	current, err := c.getLive(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err = c.Client.Patch(ctx, obj, p, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+	return c.record(obj, current, obj)
+}
+
+// Delete sends a dry-run delete request
+func (c *planClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	current, err := c.getLive(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err = c.Client.Delete(ctx, obj, append(opts, client.DryRunAll)...); err != nil {
+		return err
+	}
+	return c.record(obj, current, nil)
+}
+
+// DeleteAllOf is not used by the reconciler, it is not supported to never delete live objects
+func (c *planClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return errors.New("DeleteAllOf is not supported when planning changes")
+}
+
+// Status ignores the status updates, the plan only contains the objects managed by the operator
+func (c *planClient) Status() client.StatusWriter {
+	return discardStatusWriter{}
+}
+
+// getLive returns the live state of obj, or nil if it doesn't exist
+func (c *planClient) getLive(ctx context.Context, obj client.Object) (client.Object, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return nil, err
+	}
+
+	var current client.Object
+	if _, isUnstructured := obj.(*unstructured.Unstructured); isUnstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		current = u
+	} else {
+		newObj, newErr := c.Scheme().New(gvk)
+		if newErr != nil {
+			return nil, newErr
+		}
+		var ok bool
+		if current, ok = newObj.(client.Object); !ok {
+			return nil, fmt.Errorf("unable to create an object of kind %s", gvk.Kind)
+		}
+	}
+
+	if err = c.Client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return current, nil
+}
+
+// record keeps the first live state and the last planned state of an object
+func (c *planClient) record(obj, current, desired client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s/%s/%s", gvk.GroupKind().String(), obj.GetNamespace(), obj.GetName())
+
+	planned, found := c.objects[key]
+	if !found {
+		planned = &plannedObject{
+			gvk:       gvk,
+			namespace: obj.GetNamespace(),
+			name:      obj.GetName(),
+		}
+		if planned.current, err = toUnstructuredContent(current); err != nil {
+			return err
+		}
+		c.objects[key] = planned
+		c.keys = append(c.keys, key)
+	}
+
+	planned.desired, err = toUnstructuredContent(desired)
+	return err
+}
+
+// changes returns the changes of the recorded objects, in the order of the reconcile
+func (c *planClient) changes() ([]PlannedChange, error) {
+	var changes []PlannedChange
+	for _, key := range c.keys {
+		planned := c.objects[key]
+		change := PlannedChange{
+			Kind:      planned.gvk.Kind,
+			Namespace: planned.namespace,
+			Name:      planned.name,
+		}
+		switch {
+		case planned.current == nil && planned.desired == nil:
+			continue
+		case planned.current == nil:
+			change.Operation = PlanOperationCreate
+		case planned.desired == nil:
+			change.Operation = PlanOperationDelete
+		default:
+			change.Operation = PlanOperationUpdate
+		}
+
+		from, err := planYAML(planned.current)
+		if err != nil {
+			return nil, err
+		}
+		to, err := planYAML(planned.desired)
+		if err != nil {
+			return nil, err
+		}
+		if from == to {
+			continue
+		}
+
+		path := fmt.Sprintf("%s/%s/%s", planned.gvk.Kind, planned.namespace, planned.name)
+		change.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(from),
+			B:        difflib.SplitLines(to),
+			FromFile: "live/" + path,
+			ToFile:   "planned/" + path,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+		change.TriggersRollout = triggersRollout(planned)
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// triggersRollout returns true if the pods of a workload would be recreated
+func triggersRollout(planned *plannedObject) bool {
+	switch planned.gvk.Kind {
+	case daemonSetKind, extendedDaemonSetKind, deploymentKind:
+	default:
+		return false
+	}
+	if planned.desired == nil {
+		return false
+	}
+	if planned.current == nil {
+		return true
+	}
+
+	currentTemplate, _, _ := unstructured.NestedFieldNoCopy(planned.current, "spec", "template")
+	desiredTemplate, _, _ := unstructured.NestedFieldNoCopy(planned.desired, "spec", "template")
+	return !reflect.DeepEqual(currentTemplate, desiredTemplate)
+}
+
+func toUnstructuredContent(obj client.Object) (map[string]interface{}, error) {
+	if isNilObject(obj) {
+		return nil, nil
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return runtime.DeepCopyJSON(u.Object), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj.DeepCopyObject())
+}
+
+// planYAML returns the YAML of an object without the fields updated on every write and the status
+func planYAML(content map[string]interface{}) (string, error) {
+	if content == nil {
+		return "", nil
+	}
+	content = runtime.DeepCopyJSON(content)
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+	delete(content, "status")
+	// apiVersion and kind are not always set on the live objects
+	delete(content, "apiVersion")
+	delete(content, "kind")
+
+	out, err := yaml.Marshal(content)
+	return string(out), err
+}
+
+// discardStatusWriter ignores the status updates
+type discardStatusWriter struct{}
+
+func (discardStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+
+func (discardStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
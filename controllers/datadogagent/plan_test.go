@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
+	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
+)
+
+func newPlanTestScheme() *runtime.Scheme {
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{}, &datadoghqv1alpha1.DatadogAgentProfile{}, &datadoghqv1alpha1.DatadogAgentProfileList{})
+	s.AddKnownTypes(appsv1.SchemeGroupVersion, &appsv1.DaemonSet{}, &appsv1.Deployment{})
+	s.AddKnownTypes(rbacv1.SchemeGroupVersion, &rbacv1.ClusterRole{}, &rbacv1.ClusterRoleBinding{}, &rbacv1.Role{}, &rbacv1.RoleBinding{})
+	s.AddKnownTypes(policyv1.SchemeGroupVersion, &policyv1.PodDisruptionBudget{})
+	s.AddKnownTypes(networkingv1.SchemeGroupVersion, &networkingv1.NetworkPolicy{})
+	s.AddKnownTypes(apiregistrationv1.SchemeGroupVersion, &apiregistrationv1.APIService{})
+	return s
+}
+
+func findPlannedChange(changes []PlannedChange, kind, name string) *PlannedChange {
+	for i := range changes {
+		if changes[i].Kind == kind && changes[i].Name == name {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+func TestPlan(t *testing.T) {
+	s := newPlanTestScheme()
+
+	t.Run("new DatadogAgent, objects created", func(t *testing.T) {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+		fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithScheme(s).Build())
+
+		changes, err := Plan(context.TODO(), fakeClient, ReconcilerOptions{}, nil, dda)
+		assert.NoError(t, err)
+
+		change := findPlannedChange(changes, daemonSetKind, "foo-agent")
+		assert.NotNil(t, change, "DaemonSet not planned")
+		assert.Equal(t, PlanOperationCreate, change.Operation)
+		assert.True(t, change.TriggersRollout)
+		assert.Contains(t, change.Diff, "+++ planned/DaemonSet/bar/foo-agent")
+		assert.NotNil(t, findPlannedChange(changes, clusterRoleKind, getAgentRbacResourcesName(dda)), "ClusterRole not planned")
+
+		// nothing is applied
+		dsList := &appsv1.DaemonSetList{}
+		assert.NoError(t, fakeClient.List(context.TODO(), dsList, client.InNamespace("bar")))
+		assert.Empty(t, dsList.Items)
+	})
+
+	t.Run("image updated, DaemonSet rollout", func(t *testing.T) {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+		dda.UID = "dda-uid"
+		fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithScheme(s).WithObjects(dda.DeepCopy()).Build())
+
+		// reconcile the live DatadogAgent
+		r, err := NewReconciler(ReconcilerOptions{}, fakeClient, fakeClient, nil, s, logf.Log.WithName(t.Name()), &record.FakeRecorder{}, nil)
+		assert.NoError(t, err)
+		for _, reconcileFunc := range r.reconcileFuncs() {
+			_, err = reconcileFunc(r.log, dda.DeepCopy(), dda.Status.DeepCopy())
+			assert.NoError(t, err)
+		}
+
+		dca := &appsv1.Deployment{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: getClusterAgentName(dda)}, dca))
+		dca.Status.AvailableReplicas = 1
+		assert.NoError(t, fakeClient.Status().Update(context.TODO(), dca))
+
+		updated := dda.DeepCopy()
+		updated.Spec.Agent.Image.Name = "gcr.io/datadoghq/agent:7.99.0"
+		changes, err := Plan(context.TODO(), fakeClient, ReconcilerOptions{}, nil, updated)
+		assert.NoError(t, err)
+
+		assert.Len(t, changes, 1, "only the DaemonSet should change")
+		assert.Equal(t, daemonSetKind, changes[0].Kind)
+		assert.Equal(t, PlanOperationUpdate, changes[0].Operation)
+		assert.True(t, changes[0].TriggersRollout)
+		assert.Contains(t, changes[0].Diff, "+        image: gcr.io/datadoghq/agent:7.99.0")
+
+		// the live DaemonSet is not modified
+		ds := &appsv1.DaemonSet{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: "foo-agent"}, ds))
+		for _, container := range ds.Spec.Template.Spec.Containers {
+			assert.False(t, strings.HasSuffix(container.Image, "7.99.0"))
+		}
+	})
+}
+
+func Test_triggersRollout(t *testing.T) {
+	template := func(image string) map[string]interface{} {
+		return map[string]interface{}{
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{"image": image},
+				"updateStrategy": map[string]interface{}{
+					"type": image,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		planned plannedObject
+		want    bool
+	}{
+		{
+			name:    "DaemonSet pod template updated",
+			planned: plannedObject{gvk: appsv1.SchemeGroupVersion.WithKind(daemonSetKind), current: template("a"), desired: template("b")},
+			want:    true,
+		},
+		{
+			name:    "Deployment created",
+			planned: plannedObject{gvk: appsv1.SchemeGroupVersion.WithKind(deploymentKind), desired: template("b")},
+			want:    true,
+		},
+		{
+			name:    "Deployment deleted",
+			planned: plannedObject{gvk: appsv1.SchemeGroupVersion.WithKind(deploymentKind), current: template("a")},
+			want:    false,
+		},
+		{
+			name:    "ConfigMap updated",
+			planned: plannedObject{gvk: corev1.SchemeGroupVersion.WithKind(configMapKind), current: template("a"), desired: template("b")},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, triggersRollout(&tt.planned))
+		})
+	}
+}
@@ -6,25 +6,20 @@
 package datadogagent
 
 import (
-	"context"
-
 	"github.com/go-logr/logr"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
-	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
-	"github.com/DataDog/datadog-operator/pkg/kubernetes"
 	policyv1 "k8s.io/api/policy/v1beta1"
-	apiequality "k8s.io/apimachinery/pkg/api/equality"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
-	pdbMinAvailableInstances = 1
+	// pdbDefaultMinAvailable is rounded up, so that a single replica is still protected and the budget follows the
+	// number of replicas, when they are scaled by a HorizontalPodAutoscaler for instance
+	pdbDefaultMinAvailable = "50%"
 )
 
 type (
@@ -42,77 +37,15 @@ func (r *Reconciler) manageClusterChecksRunnerPDB(logger logr.Logger, dda *datad
 }
 
 func (r *Reconciler) managePDB(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, pdbName string, builder pdbBuilder, cleanUp bool) (reconcile.Result, error) {
-	if cleanUp {
-		return r.cleanupPDB(dda, pdbName)
-	}
-
-	pdb := &policyv1.PodDisruptionBudget{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dda.Namespace, Name: pdbName}, pdb)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.createPDB(logger, dda, builder)
-		}
-		return reconcile.Result{}, err
-	}
-
-	return r.updateIfNeededPDB(dda, pdb, builder)
-}
-
-func (r *Reconciler) createPDB(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, builder pdbBuilder) (reconcile.Result, error) {
-	newPdb := builder(dda)
-	// Set DatadogAgent instance  instance as the owner and controller
-	if err := controllerutil.SetControllerReference(dda, newPdb, r.scheme); err != nil {
-		return reconcile.Result{}, err
-	}
-	if err := r.client.Create(context.TODO(), newPdb); err != nil {
-		return reconcile.Result{}, err
-	}
-	logger.Info("Create PDB", "name", newPdb.Name)
-	event := buildEventInfo(newPdb.Name, newPdb.Namespace, podDisruptionBudgetKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-
-	return reconcile.Result{Requeue: true}, nil
-}
-
-func (r *Reconciler) updateIfNeededPDB(dda *datadoghqv1alpha1.DatadogAgent, currentPDB *policyv1.PodDisruptionBudget, builder pdbBuilder) (reconcile.Result, error) {
-	if !CheckOwnerReference(dda, currentPDB) {
-		return reconcile.Result{}, nil
-	}
-	newPDB := builder(dda)
-	result := reconcile.Result{}
-	if !(apiequality.Semantic.DeepEqual(newPDB.Spec, currentPDB.Spec) &&
-		apiequality.Semantic.DeepEqual(newPDB.Labels, currentPDB.Labels) &&
-		apiequality.Semantic.DeepEqual(newPDB.Annotations, currentPDB.Annotations)) {
-		updatedPDB := currentPDB.DeepCopy()
-		updatedPDB.Labels = newPDB.Labels
-		updatedPDB.Annotations = newPDB.Annotations
-		updatedPDB.Spec = newPDB.Spec
-
-		if err := kubernetes.UpdateFromObject(context.TODO(), r.client, updatedPDB, currentPDB.ObjectMeta); err != nil {
-			return reconcile.Result{}, err
-		}
-		event := buildEventInfo(updatedPDB.Name, updatedPDB.Namespace, podDisruptionBudgetKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
-		result.Requeue = true
-	}
-
-	return result, nil
-}
-
-func (r *Reconciler) cleanupPDB(dda *datadoghqv1alpha1.DatadogAgent, pdbName string) (reconcile.Result, error) {
-	pdb := &policyv1.PodDisruptionBudget{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dda.Namespace, Name: pdbName}, pdb)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return reconcile.Result{}, nil
-		}
-		return reconcile.Result{}, err
-	}
-	if CheckOwnerReference(dda, pdb) {
-		err = r.client.Delete(context.TODO(), pdb)
-	}
-
-	return reconcile.Result{}, err
+	return r.manageResource(logger, dda, managedResource{
+		kind:      podDisruptionBudgetKind,
+		name:      pdbName,
+		namespace: dda.Namespace,
+		newObject: func() client.Object { return &policyv1.PodDisruptionBudget{} },
+		build:     func() (client.Object, error) { return builder(dda), nil },
+		ownership: ownedByController,
+		cleanup:   cleanUp,
+	})
 }
 
 func buildClusterAgentPDB(dda *datadoghqv1alpha1.DatadogAgent) *policyv1.PodDisruptionBudget {
@@ -129,7 +62,7 @@ func buildClusterAgentPDB(dda *datadoghqv1alpha1.DatadogAgent) *policyv1.PodDisr
 		datadoghqv1alpha1.AgentDeploymentComponentLabelKey: datadoghqv1alpha1.DefaultClusterAgentResourceSuffix,
 	}
 
-	return buildPDB(metadata, matchLabels, pdbMinAvailableInstances)
+	return buildPDB(metadata, matchLabels, dda.Spec.ClusterAgent.PodDisruptionBudget)
 }
 
 func buildClusterChecksRunnerPDB(dda *datadoghqv1alpha1.DatadogAgent) *policyv1.PodDisruptionBudget {
@@ -146,21 +79,28 @@ func buildClusterChecksRunnerPDB(dda *datadoghqv1alpha1.DatadogAgent) *policyv1.
 		datadoghqv1alpha1.AgentDeploymentComponentLabelKey: datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix,
 	}
 
-	return buildPDB(metadata, matchLabels, pdbMinAvailableInstances)
+	return buildPDB(metadata, matchLabels, dda.Spec.ClusterChecksRunner.PodDisruptionBudget)
 }
 
-func buildPDB(metadata metav1.ObjectMeta, matchLabels map[string]string, minAvailable int) *policyv1.PodDisruptionBudget {
-	minAvailableStr := intstr.FromInt(minAvailable)
-
+func buildPDB(metadata metav1.ObjectMeta, matchLabels map[string]string, config *datadoghqv1alpha1.PodDisruptionBudgetConfig) *policyv1.PodDisruptionBudget {
 	pdb := &policyv1.PodDisruptionBudget{
 		ObjectMeta: metadata,
 		Spec: policyv1.PodDisruptionBudgetSpec{
-			MinAvailable: &minAvailableStr,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: matchLabels,
 			},
 		},
 	}
 
+	switch {
+	case config != nil && config.MaxUnavailable != nil:
+		pdb.Spec.MaxUnavailable = config.MaxUnavailable
+	case config != nil && config.MinAvailable != nil:
+		pdb.Spec.MinAvailable = config.MinAvailable
+	default:
+		minAvailable := intstr.FromString(pdbDefaultMinAvailable)
+		pdb.Spec.MinAvailable = &minAvailable
+	}
+
 	return pdb
 }
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+)
+
+func Test_buildClusterAgentPDB(t *testing.T) {
+	maxUnavailable := intstr.FromInt(1)
+	minAvailable := intstr.FromInt(2)
+
+	tests := []struct {
+		name               string
+		config             *datadoghqv1alpha1.PodDisruptionBudgetConfig
+		wantMinAvailable   *intstr.IntOrString
+		wantMaxUnavailable *intstr.IntOrString
+	}{
+		{
+			name:             "default, scales with the replicas",
+			wantMinAvailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+		},
+		{
+			name:             "user-defined minAvailable",
+			config:           &datadoghqv1alpha1.PodDisruptionBudgetConfig{MinAvailable: &minAvailable},
+			wantMinAvailable: &minAvailable,
+		},
+		{
+			name:               "user-defined maxUnavailable",
+			config:             &datadoghqv1alpha1.PodDisruptionBudgetConfig{MaxUnavailable: &maxUnavailable},
+			wantMaxUnavailable: &maxUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true})
+			dda.Spec.ClusterAgent.PodDisruptionBudget = tt.config
+
+			pdb := buildClusterAgentPDB(dda)
+			assert.Equal(t, tt.wantMinAvailable, pdb.Spec.MinAvailable)
+			assert.Equal(t, tt.wantMaxUnavailable, pdb.Spec.MaxUnavailable)
+		})
+	}
+}
+
+func Test_getTopologySpreadConstraints(t *testing.T) {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterChecksRunnerEnabled: true})
+
+	// the pods are spread across the zones by default
+	constraints := getTopologySpreadConstraints(nil, dda, datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix)
+	assert.Len(t, constraints, 1)
+	assert.Equal(t, "topology.kubernetes.io/zone", constraints[0].TopologyKey)
+	assert.Equal(t, corev1.ScheduleAnyway, constraints[0].WhenUnsatisfiable)
+	assert.Equal(t, map[string]string{
+		datadoghqv1alpha1.AgentDeploymentNameLabelKey:      "foo",
+		datadoghqv1alpha1.AgentDeploymentComponentLabelKey: datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix,
+	}, constraints[0].LabelSelector.MatchLabels)
+
+	// the user-defined constraints replace the default ones
+	userConstraints := []corev1.TopologySpreadConstraint{
+		{MaxSkew: 2, TopologyKey: "kubernetes.io/hostname", WhenUnsatisfiable: corev1.DoNotSchedule},
+	}
+	assert.Equal(t, userConstraints, getTopologySpreadConstraints(userConstraints, dda, datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix))
+}
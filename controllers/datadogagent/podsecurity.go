@@ -0,0 +1,471 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	"github.com/DataDog/datadog-operator/pkg/controller/utils/condition"
+)
+
+const (
+	podSecurityEnforceLabelKey        = "pod-security.kubernetes.io/enforce"
+	podSecurityEnforceVersionLabelKey = "pod-security.kubernetes.io/enforce-version"
+	// podSecurityLabelNamespaceAnnotationKey opts a namespace in the Pod Security labeling by the operator
+	podSecurityLabelNamespaceAnnotationKey = "agent.datadoghq.com/pod-security-label-namespace"
+
+	podSecurityViolationEventReason        = "PodSecurityViolation"
+	podSecurityNamespaceLabeledEventReason = "PodSecurityNamespaceLabeled"
+
+	appArmorAnnotationPrefix = "container.apparmor.security.beta.kubernetes.io/"
+)
+
+// podSecurityLevel is a Pod Security Standards level
+type podSecurityLevel string
+
+const (
+	podSecurityLevelPrivileged podSecurityLevel = "privileged"
+	podSecurityLevelBaseline   podSecurityLevel = "baseline"
+	podSecurityLevelRestricted podSecurityLevel = "restricted"
+)
+
+var (
+	// baselineCapabilities are the capabilities that can be added by the containers at the baseline level
+	baselineCapabilities = map[corev1.Capability]bool{
+		"AUDIT_WRITE":      true,
+		"CHOWN":            true,
+		"DAC_OVERRIDE":     true,
+		"FOWNER":           true,
+		"FSETID":           true,
+		"KILL":             true,
+		"MKNOD":            true,
+		"NET_BIND_SERVICE": true,
+		"SETFCAP":          true,
+		"SETGID":           true,
+		"SETPCAP":          true,
+		"SETUID":           true,
+		"SYS_CHROOT":       true,
+	}
+
+	// baselineSELinuxTypes are the SELinux types that can be set at the baseline level
+	baselineSELinuxTypes = map[string]bool{
+		"":                 true,
+		"container_t":      true,
+		"container_init_t": true,
+		"container_kvm_t":  true,
+	}
+
+	// baselineSysctls are the safe sysctls that can be set at the baseline level
+	baselineSysctls = map[string]bool{
+		"kernel.shm_rmid_forced":              true,
+		"net.ipv4.ip_local_port_range":        true,
+		"net.ipv4.ip_unprivileged_port_start": true,
+		"net.ipv4.tcp_syncookies":             true,
+		"net.ipv4.ping_group_range":           true,
+	}
+)
+
+// podSecurityComponent is a pod template rendered for a component of the DatadogAgent
+type podSecurityComponent struct {
+	name     string
+	template *corev1.PodTemplateSpec
+}
+
+// reconcilePodSecurity evaluates the pod templates of the DatadogAgent against the Pod Security level enforced
+// on its namespace, and sets the PodSecurityViolation condition when pods would be rejected.
+// When the operator is allowed to, and the namespace opts in with the podSecurityLabelNamespaceAnnotationKey
+// annotation, the namespace is labeled with the most restrictive level admitting the pods instead.
+func (r *Reconciler) reconcilePodSecurity(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus) (reconcile.Result, error) {
+	now := metav1.NewTime(time.Now())
+
+	namespace := &corev1.Namespace{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: dda.Namespace}, namespace); err != nil {
+		if apierrors.IsNotFound(err) {
+			condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypePodSecurityViolation, corev1.ConditionFalse, "", false)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	level := podSecurityLevel(namespace.Labels[podSecurityEnforceLabelKey])
+	if level != podSecurityLevelBaseline && level != podSecurityLevelRestricted {
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypePodSecurityViolation, corev1.ConditionFalse, "", false)
+		return reconcile.Result{}, nil
+	}
+
+	profiles, profileDDAs, err := r.listAgentProfileDatadogAgents(dda)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	components, err := getPodSecurityComponents(logger, dda, profiles, profileDDAs)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var violations []string
+	admittedLevel := level
+	for _, component := range components {
+		componentViolations := checkPodSecurity(level, component.template)
+		if len(componentViolations) == 0 {
+			continue
+		}
+		violations = append(violations, fmt.Sprintf("%s: %s", component.name, strings.Join(componentViolations, ", ")))
+
+		if len(checkPodSecurity(podSecurityLevelBaseline, component.template)) > 0 {
+			admittedLevel = podSecurityLevelPrivileged
+		} else if admittedLevel != podSecurityLevelPrivileged {
+			admittedLevel = podSecurityLevelBaseline
+		}
+	}
+
+	if len(violations) == 0 {
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypePodSecurityViolation, corev1.ConditionFalse, "", false)
+		return reconcile.Result{}, nil
+	}
+
+	if r.options.PodSecurityLabelNamespace && namespace.Annotations[podSecurityLabelNamespaceAnnotationKey] == "true" {
+		enforceVersion := getPodSecurityVersion(r.versionInfo)
+		logger.Info("Labeling the namespace with the Pod Security level admitting the Agent pods", "namespace", dda.Namespace, "level", admittedLevel, "version", enforceVersion)
+		patch := client.MergeFrom(namespace.DeepCopy())
+		namespace.Labels[podSecurityEnforceLabelKey] = string(admittedLevel)
+		namespace.Labels[podSecurityEnforceVersionLabelKey] = enforceVersion
+		if err = r.client.Patch(context.TODO(), namespace, patch); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.recorder.Event(dda, corev1.EventTypeNormal, podSecurityNamespaceLabeledEventReason,
+			fmt.Sprintf("Namespace %s labeled with %s=%s and %s=%s, the %s level rejects the pods of the DatadogAgent", dda.Namespace, podSecurityEnforceLabelKey, admittedLevel, podSecurityEnforceVersionLabelKey, enforceVersion, level))
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypePodSecurityViolation, corev1.ConditionFalse, "", false)
+		return reconcile.Result{}, nil
+	}
+
+	description := fmt.Sprintf("Pods rejected by the %s Pod Security level of namespace %s: %s", level, dda.Namespace, strings.Join(violations, "; "))
+	if !isConditionReported(&dda.Status, datadoghqv1alpha1.DatadogAgentConditionTypePodSecurityViolation, description) {
+		r.recorder.Event(dda, corev1.EventTypeWarning, podSecurityViolationEventReason, description)
+	}
+	condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypePodSecurityViolation, corev1.ConditionTrue, description, false)
+	return reconcile.Result{}, nil
+}
+
+// getPodSecurityVersion returns the version of the Pod Security Standards checked by the operator: the version of the
+// cluster, so that the level set on the namespace doesn't change with the cluster upgrades
+func getPodSecurityVersion(versionInfo *version.Info) string {
+	if versionInfo == nil {
+		return "latest"
+	}
+	v, err := utilversion.ParseGeneric(versionInfo.GitVersion)
+	if err != nil {
+		return "latest"
+	}
+	return fmt.Sprintf("v%d.%d", v.Major(), v.Minor())
+}
+
+// getPodSecurityComponents renders the pod templates of the enabled components of the DatadogAgent, including the
+// DaemonSets of its profiles, rendered by profileDDAs and indexed like profiles
+func getPodSecurityComponents(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, profiles []datadoghqv1alpha1.DatadogAgentProfile, profileDDAs []*datadoghqv1alpha1.DatadogAgent) ([]podSecurityComponent, error) {
+	var components []podSecurityComponent
+
+	if apiutils.BoolValue(dda.Spec.Agent.Enabled) {
+		template, err := newAgentPodTemplate(logger, dda, nil)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, podSecurityComponent{name: datadoghqv1alpha1.DefaultAgentResourceSuffix, template: template})
+	}
+
+	for i, profileDDA := range profileDDAs {
+		template, err := newAgentPodTemplate(logger, profileDDA, nil)
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("%s-%s", datadoghqv1alpha1.DefaultAgentResourceSuffix, profiles[i].Name)
+		components = append(components, podSecurityComponent{name: name, template: template})
+	}
+
+	if isWindowsAgentEnabled(dda) {
+		template, err := newWindowsAgentPodTemplate(logger, dda)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, podSecurityComponent{name: datadoghqv1alpha1.DefaultAgentWindowsResourceSuffix, template: template})
+	}
+
+	if isClusterAgentEnabled(dda.Spec.ClusterAgent) {
+		deployment, _, err := newClusterAgentDeploymentFromInstance(logger, dda, nil)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, podSecurityComponent{name: datadoghqv1alpha1.DefaultClusterAgentResourceSuffix, template: &deployment.Spec.Template})
+	}
+
+	if needClusterChecksRunner(dda) {
+		deployment, _, err := newClusterChecksRunnerDeploymentFromInstance(dda, nil)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, podSecurityComponent{name: datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix, template: &deployment.Spec.Template})
+	}
+
+	return components, nil
+}
+
+// checkPodSecurity returns the fields of the pod template forbidden by the Pod Security Standards level
+func checkPodSecurity(level podSecurityLevel, template *corev1.PodTemplateSpec) []string {
+	switch level {
+	case podSecurityLevelBaseline:
+		return checkPodSecurityBaseline(template)
+	case podSecurityLevelRestricted:
+		return append(checkPodSecurityBaseline(template), checkPodSecurityRestricted(template)...)
+	default:
+		return nil
+	}
+}
+
+func checkPodSecurityBaseline(template *corev1.PodTemplateSpec) []string {
+	var violations []string
+	spec := &template.Spec
+	containers := getAllContainers(spec)
+
+	var hostNamespaces []string
+	if spec.HostNetwork {
+		hostNamespaces = append(hostNamespaces, "hostNetwork=true")
+	}
+	if spec.HostPID {
+		hostNamespaces = append(hostNamespaces, "hostPID=true")
+	}
+	if spec.HostIPC {
+		hostNamespaces = append(hostNamespaces, "hostIPC=true")
+	}
+	if len(hostNamespaces) > 0 {
+		violations = append(violations, fmt.Sprintf("host namespaces (%s)", strings.Join(hostNamespaces, ", ")))
+	}
+
+	var privileged, hostPorts, capabilities, procMount []string
+	for _, container := range containers {
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				hostPorts = append(hostPorts, fmt.Sprintf("%s=%d", container.Name, port.HostPort))
+			}
+		}
+		sc := container.SecurityContext
+		if sc == nil {
+			continue
+		}
+		if apiutils.BoolValue(sc.Privileged) {
+			privileged = append(privileged, container.Name)
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if !baselineCapabilities[capability] {
+					capabilities = append(capabilities, fmt.Sprintf("%s=%s", container.Name, capability))
+				}
+			}
+		}
+		if sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+			procMount = append(procMount, container.Name)
+		}
+	}
+	if len(privileged) > 0 {
+		violations = append(violations, fmt.Sprintf("privileged containers (%s)", strings.Join(privileged, ", ")))
+	}
+	if len(capabilities) > 0 {
+		violations = append(violations, fmt.Sprintf("non-default capabilities (%s)", strings.Join(capabilities, ", ")))
+	}
+	if len(hostPorts) > 0 {
+		violations = append(violations, fmt.Sprintf("hostPort (%s)", strings.Join(hostPorts, ", ")))
+	}
+	if len(procMount) > 0 {
+		violations = append(violations, fmt.Sprintf("procMount (%s)", strings.Join(procMount, ", ")))
+	}
+
+	var hostPaths []string
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			hostPaths = append(hostPaths, volume.Name)
+		}
+	}
+	if len(hostPaths) > 0 {
+		violations = append(violations, fmt.Sprintf("hostPath volumes (%s)", strings.Join(hostPaths, ", ")))
+	}
+
+	var appArmor, seccomp []string
+	for _, key := range sortedKeys(template.Annotations) {
+		value := template.Annotations[key]
+		if strings.HasPrefix(key, appArmorAnnotationPrefix) && value != "" && value != "runtime/default" && !strings.HasPrefix(value, "localhost/") {
+			appArmor = append(appArmor, fmt.Sprintf("%s=%s", strings.TrimPrefix(key, appArmorAnnotationPrefix), value))
+		}
+		if strings.HasPrefix(key, seccompAnnotationPrefix) && value == "unconfined" {
+			seccomp = append(seccomp, strings.TrimPrefix(key, seccompAnnotationPrefix))
+		}
+	}
+	if spec.SecurityContext != nil && isUnconfinedSeccompProfile(spec.SecurityContext.SeccompProfile) {
+		seccomp = append(seccomp, "pod")
+	}
+	var seLinux []string
+	if spec.SecurityContext != nil && !isBaselineSELinuxOptions(spec.SecurityContext.SELinuxOptions) {
+		seLinux = append(seLinux, "pod")
+	}
+	for _, container := range containers {
+		if container.SecurityContext == nil {
+			continue
+		}
+		if isUnconfinedSeccompProfile(container.SecurityContext.SeccompProfile) {
+			seccomp = append(seccomp, container.Name)
+		}
+		if !isBaselineSELinuxOptions(container.SecurityContext.SELinuxOptions) {
+			seLinux = append(seLinux, container.Name)
+		}
+	}
+	if len(appArmor) > 0 {
+		violations = append(violations, fmt.Sprintf("forbidden AppArmor profiles (%s)", strings.Join(appArmor, ", ")))
+	}
+	if len(seccomp) > 0 {
+		violations = append(violations, fmt.Sprintf("unconfined seccomp profile (%s)", strings.Join(seccomp, ", ")))
+	}
+	if len(seLinux) > 0 {
+		violations = append(violations, fmt.Sprintf("seLinuxOptions (%s)", strings.Join(seLinux, ", ")))
+	}
+
+	if spec.SecurityContext != nil {
+		var sysctls []string
+		for _, sysctl := range spec.SecurityContext.Sysctls {
+			if !baselineSysctls[sysctl.Name] {
+				sysctls = append(sysctls, sysctl.Name)
+			}
+		}
+		if len(sysctls) > 0 {
+			violations = append(violations, fmt.Sprintf("forbidden sysctls (%s)", strings.Join(sysctls, ", ")))
+		}
+	}
+
+	return violations
+}
+
+func checkPodSecurityRestricted(template *corev1.PodTemplateSpec) []string {
+	var violations []string
+	spec := &template.Spec
+	podSC := spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+
+	var volumes []string
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.ConfigMap != nil, volume.CSI != nil, volume.DownwardAPI != nil, volume.EmptyDir != nil,
+			volume.Ephemeral != nil, volume.PersistentVolumeClaim != nil, volume.Projected != nil, volume.Secret != nil:
+		case volume.HostPath != nil:
+			// already reported at the baseline level
+		default:
+			volumes = append(volumes, volume.Name)
+		}
+	}
+	if len(volumes) > 0 {
+		violations = append(violations, fmt.Sprintf("restricted volume types (%s)", strings.Join(volumes, ", ")))
+	}
+
+	var privilegeEscalation, runAsNonRoot, runAsRoot, seccomp, capabilities []string
+	podRunAsNonRoot := apiutils.BoolValue(podSC.RunAsNonRoot)
+	podSeccomp := isRestrictedSeccompProfile(podSC.SeccompProfile)
+	if podSC.RunAsUser != nil && *podSC.RunAsUser == 0 {
+		runAsRoot = append(runAsRoot, "pod")
+	}
+	for _, container := range getAllContainers(spec) {
+		sc := container.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			privilegeEscalation = append(privilegeEscalation, container.Name)
+		}
+		if sc.RunAsNonRoot == nil && !podRunAsNonRoot || sc.RunAsNonRoot != nil && !*sc.RunAsNonRoot {
+			runAsNonRoot = append(runAsNonRoot, container.Name)
+		}
+		if sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			runAsRoot = append(runAsRoot, container.Name)
+		}
+		if sc.SeccompProfile == nil && !podSeccomp || sc.SeccompProfile != nil && !isRestrictedSeccompProfile(sc.SeccompProfile) {
+			seccomp = append(seccomp, container.Name)
+		}
+		if !isRestrictedCapabilities(sc.Capabilities) {
+			capabilities = append(capabilities, container.Name)
+		}
+	}
+	if len(privilegeEscalation) > 0 {
+		violations = append(violations, fmt.Sprintf("allowPrivilegeEscalation != false (%s)", strings.Join(privilegeEscalation, ", ")))
+	}
+	if len(capabilities) > 0 {
+		violations = append(violations, fmt.Sprintf("unrestricted capabilities, ALL must be dropped (%s)", strings.Join(capabilities, ", ")))
+	}
+	if len(runAsNonRoot) > 0 {
+		violations = append(violations, fmt.Sprintf("runAsNonRoot != true (%s)", strings.Join(runAsNonRoot, ", ")))
+	}
+	if len(runAsRoot) > 0 {
+		violations = append(violations, fmt.Sprintf("runAsUser=0 (%s)", strings.Join(runAsRoot, ", ")))
+	}
+	if len(seccomp) > 0 {
+		violations = append(violations, fmt.Sprintf("seccompProfile not set to RuntimeDefault or Localhost (%s)", strings.Join(seccomp, ", ")))
+	}
+
+	return violations
+}
+
+func getAllContainers(spec *corev1.PodSpec) []corev1.Container {
+	return append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+}
+
+func isUnconfinedSeccompProfile(profile *corev1.SeccompProfile) bool {
+	return profile != nil && profile.Type == corev1.SeccompProfileTypeUnconfined
+}
+
+func isRestrictedSeccompProfile(profile *corev1.SeccompProfile) bool {
+	return profile != nil && (profile.Type == corev1.SeccompProfileTypeRuntimeDefault || profile.Type == corev1.SeccompProfileTypeLocalhost)
+}
+
+func isBaselineSELinuxOptions(options *corev1.SELinuxOptions) bool {
+	return options == nil || baselineSELinuxTypes[options.Type] && options.User == "" && options.Role == ""
+}
+
+func isRestrictedCapabilities(capabilities *corev1.Capabilities) bool {
+	if capabilities == nil {
+		return false
+	}
+	for _, capability := range capabilities.Add {
+		if capability != "NET_BIND_SERVICE" {
+			return false
+		}
+	}
+	for _, capability := range capabilities.Drop {
+		if capability == "ALL" {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,193 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+func Test_checkPodSecurity(t *testing.T) {
+	restrictedContainer := corev1.Container{
+		Name: "foo",
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: apiutils.NewBoolPointer(false),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	}
+	restrictedPodSecurityContext := &corev1.PodSecurityContext{
+		RunAsNonRoot:   apiutils.NewBoolPointer(true),
+		SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+
+	tests := []struct {
+		name     string
+		level    podSecurityLevel
+		template *corev1.PodTemplateSpec
+		want     []string
+	}{
+		{
+			name:  "privileged level",
+			level: podSecurityLevelPrivileged,
+			template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{HostPID: true},
+			},
+			want: nil,
+		},
+		{
+			name:  "baseline violations",
+			level: podSecurityLevelBaseline,
+			template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"container.apparmor.security.beta.kubernetes.io/bar": "unconfined",
+						"container.seccomp.security.alpha.kubernetes.io/bar": "localhost/bar",
+					},
+				},
+				Spec: corev1.PodSpec{
+					HostPID: true,
+					SecurityContext: &corev1.PodSecurityContext{
+						SELinuxOptions: &corev1.SELinuxOptions{Type: "spc_t"},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  "foo",
+							Ports: []corev1.ContainerPort{{ContainerPort: 8125, HostPort: 8125}},
+						},
+						{
+							Name: "bar",
+							SecurityContext: &corev1.SecurityContext{
+								Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"CHOWN", "SYS_ADMIN"}},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{Name: "procdir", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/proc"}}},
+						{Name: "config", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					},
+				},
+			},
+			want: []string{
+				"host namespaces (hostPID=true)",
+				"non-default capabilities (bar=SYS_ADMIN)",
+				"hostPort (foo=8125)",
+				"hostPath volumes (procdir)",
+				"forbidden AppArmor profiles (bar=unconfined)",
+				"seLinuxOptions (pod)",
+			},
+		},
+		{
+			name:  "restricted compliant",
+			level: podSecurityLevelRestricted,
+			template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					SecurityContext: restrictedPodSecurityContext,
+					Containers:      []corev1.Container{restrictedContainer},
+				},
+			},
+			want: nil,
+		},
+		{
+			name:  "restricted violations",
+			level: podSecurityLevelRestricted,
+			template: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{restrictedContainer, {Name: "bar"}},
+				},
+			},
+			want: []string{
+				"allowPrivilegeEscalation != false (bar)",
+				"unrestricted capabilities, ALL must be dropped (bar)",
+				"runAsNonRoot != true (foo, bar)",
+				"seccompProfile not set to RuntimeDefault or Localhost (foo, bar)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checkPodSecurity(tt.level, tt.template))
+		})
+	}
+}
+
+func Test_reconcilePodSecurity(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{UseEDS: false, ClusterAgentEnabled: true})
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "bar",
+			Labels: map[string]string{podSecurityEnforceLabelKey: "baseline"},
+		},
+	}
+
+	gpu := newTestProfile("gpu", time.Hour, corev1.NodeSelectorRequirement{
+		Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"},
+	})
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{}, &datadoghqv1alpha1.DatadogAgentProfile{}, &datadoghqv1alpha1.DatadogAgentProfileList{})
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(namespace, &gpu).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{client: fakeClient, scheme: s, recorder: recorder, versionInfo: &version.Info{GitVersion: "v1.23.4-gke.100"}}
+
+	// The Agent pods mount host directories, rejected at the baseline level
+	newStatus := &datadoghqv1alpha1.DatadogAgentStatus{}
+	_, err := r.reconcilePodSecurity(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Len(t, newStatus.Conditions, 1)
+	assert.Equal(t, datadoghqv1alpha1.DatadogAgentConditionTypePodSecurityViolation, newStatus.Conditions[0].Type)
+	assert.Equal(t, corev1.ConditionTrue, newStatus.Conditions[0].Status)
+	assert.Contains(t, newStatus.Conditions[0].Message, "agent: ")
+	assert.Contains(t, newStatus.Conditions[0].Message, "agent-gpu: ")
+	assert.Contains(t, newStatus.Conditions[0].Message, "hostPath volumes (")
+	assert.NotContains(t, newStatus.Conditions[0].Message, "cluster-agent: ")
+	assert.Len(t, recorder.Events, 1)
+
+	// The event is recorded only once
+	dda.Status = *newStatus
+	_, err = r.reconcilePodSecurity(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Len(t, recorder.Events, 1)
+
+	// The namespace is not labeled without its opt-in annotation
+	r.options.PodSecurityLabelNamespace = true
+	_, err = r.reconcilePodSecurity(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, newStatus.Conditions[0].Status)
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "bar"}, namespace))
+	assert.Equal(t, "baseline", namespace.Labels[podSecurityEnforceLabelKey])
+
+	// The namespace is labeled when the operator is allowed to, and the namespace opts in
+	namespace.Annotations = map[string]string{podSecurityLabelNamespaceAnnotationKey: "true"}
+	assert.NoError(t, fakeClient.Update(context.TODO(), namespace))
+	_, err = r.reconcilePodSecurity(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, newStatus.Conditions[0].Status)
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "bar"}, namespace))
+	assert.Equal(t, "privileged", namespace.Labels[podSecurityEnforceLabelKey])
+	assert.Equal(t, "v1.23", namespace.Labels[podSecurityEnforceVersionLabelKey])
+}
+
+func Test_getPodSecurityVersion(t *testing.T) {
+	assert.Equal(t, "latest", getPodSecurityVersion(nil))
+	assert.Equal(t, "latest", getPodSecurityVersion(&version.Info{GitVersion: "unknown"}))
+	assert.Equal(t, "v1.21", getPodSecurityVersion(&version.Info{GitVersion: "v1.21.2"}))
+}
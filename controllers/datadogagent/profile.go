@@ -0,0 +1,758 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	edsdatadoghqv1alpha1 "github.com/DataDog/extendeddaemonset/api/v1alpha1"
+)
+
+const (
+	// maxProfileNodeSelectorTerms limits the size of the node affinity generated to keep
+	// the profiles DaemonSets from overlapping
+	maxProfileNodeSelectorTerms = 100
+
+	nodeNameField = "metadata.name"
+
+	profileValidReason   = "Valid"
+	profileInvalidReason = "Invalid"
+
+	// maxProfileStatusNodes is the number of node names listed in the profile status
+	maxProfileStatusNodes = 5
+)
+
+// reconcileAgentProfiles renders the DaemonSets of the DatadogAgentProfiles of the DatadogAgent namespace,
+// and updates the profiles status. It returns the DatadogAgent to use to render the default DaemonSet,
+// restricted to the nodes not covered by any profile.
+func (r *Reconciler) reconcileAgentProfiles(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (*datadoghqv1alpha1.DatadogAgent, error) {
+	profiles, err := r.listAgentProfiles(dda)
+	if err != nil {
+		return nil, err
+	}
+
+	validProfiles, profileDDAs, validationErrors, err := buildAgentProfileDatadogAgents(dda, profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.manageAgentProfileDaemonSets(logger, dda, validProfiles, profileDDAs); err != nil {
+		return nil, err
+	}
+
+	if err = r.updateAgentProfilesStatus(logger, dda, profiles, validProfiles, profileDDAs, validationErrors); err != nil {
+		return nil, err
+	}
+
+	if len(validProfiles) == 0 {
+		return dda, nil
+	}
+	return newProfileDatadogAgent(dda, validProfiles, -1)
+}
+
+// listAgentProfiles returns the DatadogAgentProfiles of the DatadogAgent namespace, by priority:
+// the oldest profile first.
+func (r *Reconciler) listAgentProfiles(dda *datadoghqv1alpha1.DatadogAgent) ([]datadoghqv1alpha1.DatadogAgentProfile, error) {
+	profileList := &datadoghqv1alpha1.DatadogAgentProfileList{}
+	if err := r.client.List(context.TODO(), profileList, client.InNamespace(dda.Namespace)); err != nil {
+		return nil, err
+	}
+
+	profiles := profileList.Items
+	sortAgentProfiles(profiles)
+
+	return profiles, nil
+}
+
+// sortAgentProfiles sorts the profiles of a namespace by priority: the oldest profile first
+func sortAgentProfiles(profiles []datadoghqv1alpha1.DatadogAgentProfile) {
+	sort.SliceStable(profiles, func(i, j int) bool {
+		if !profiles[i].CreationTimestamp.Equal(&profiles[j].CreationTimestamp) {
+			return profiles[i].CreationTimestamp.Before(&profiles[j].CreationTimestamp)
+		}
+		return profiles[i].Name < profiles[j].Name
+	})
+}
+
+// listAgentProfileDatadogAgents returns the valid profiles of the DatadogAgent namespace, and the DatadogAgents
+// rendering their DaemonSets
+func (r *Reconciler) listAgentProfileDatadogAgents(dda *datadoghqv1alpha1.DatadogAgent) ([]datadoghqv1alpha1.DatadogAgentProfile, []*datadoghqv1alpha1.DatadogAgent, error) {
+	profiles, err := r.listAgentProfiles(dda)
+	if err != nil {
+		return nil, nil, err
+	}
+	validProfiles, profileDDAs, _, err := buildAgentProfileDatadogAgents(dda, profiles)
+	return validProfiles, profileDDAs, err
+}
+
+// buildAgentProfileDatadogAgents validates the profiles, and returns the DatadogAgents rendering the DaemonSets of the
+// valid ones, indexed like the valid profiles. There is no profile DaemonSet when the Agent is disabled.
+func buildAgentProfileDatadogAgents(dda *datadoghqv1alpha1.DatadogAgent, profiles []datadoghqv1alpha1.DatadogAgentProfile) ([]datadoghqv1alpha1.DatadogAgentProfile, []*datadoghqv1alpha1.DatadogAgent, map[string]error, error) {
+	validProfiles := make([]datadoghqv1alpha1.DatadogAgentProfile, 0, len(profiles))
+	validationErrors := make(map[string]error, len(profiles))
+	for _, profile := range profiles {
+		if err := validateAgentProfile(&profile); err != nil {
+			validationErrors[profile.Name] = err
+			continue
+		}
+		validProfiles = append(validProfiles, profile)
+	}
+
+	profileDDAs := make([]*datadoghqv1alpha1.DatadogAgent, 0, len(validProfiles))
+	if apiutils.BoolValue(dda.Spec.Agent.Enabled) {
+		for i := range validProfiles {
+			profileDDA, err := newProfileDatadogAgent(dda, validProfiles, i)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			profileDDAs = append(profileDDAs, profileDDA)
+		}
+	}
+
+	return validProfiles, profileDDAs, validationErrors, nil
+}
+
+// validateAgentProfile checks that the profile node selector requirements can be used in a node affinity
+func validateAgentProfile(profile *datadoghqv1alpha1.DatadogAgentProfile) error {
+	if profile.DeletionTimestamp != nil {
+		return fmt.Errorf("profile is being deleted")
+	}
+	if len(profile.Spec.NodeSelectorRequirements) == 0 {
+		return fmt.Errorf("nodeSelectorRequirements must not be empty")
+	}
+	for _, req := range profile.Spec.NodeSelectorRequirements {
+		if _, err := nodeSelectorRequirementAsSelector(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newProfileDatadogAgent returns a copy of the DatadogAgent used to render the DaemonSet of the profile at the
+// given index. The profile configuration is merged into the Agent spec and the Agent pods are scheduled on the nodes
+// matching the profile and not covered by an older profile.
+// A negative index returns the DatadogAgent of the default DaemonSet, scheduled on the nodes not covered by any profile.
+func newProfileDatadogAgent(dda *datadoghqv1alpha1.DatadogAgent, profiles []datadoghqv1alpha1.DatadogAgentProfile, index int) (*datadoghqv1alpha1.DatadogAgent, error) {
+	profileDDA := dda.DeepCopy()
+
+	terms := []corev1.NodeSelectorTerm{{}}
+	excluded := profiles
+	if index >= 0 {
+		profile := &profiles[index]
+		terms = []corev1.NodeSelectorTerm{{MatchExpressions: profile.Spec.NodeSelectorRequirements}}
+		excluded = profiles[:index]
+
+		applyAgentProfile(&profileDDA.Spec.Agent, profile.Spec.Agent)
+		profileDDA.Spec.Agent.DaemonsetName = profileDaemonSetName(dda, profile)
+		// the staged rollout only drives the default DaemonSet, profile DaemonSets use the DaemonSet update strategy
+		if profileDDA.Spec.Agent.DeploymentStrategy != nil {
+			profileDDA.Spec.Agent.DeploymentStrategy.StagedRollout = nil
+		}
+	}
+
+	for _, profile := range excluded {
+		terms = andNodeSelectorTerms(terms, excludeNodeSelectorRequirements(profile.Spec.NodeSelectorRequirements))
+		if len(terms) > maxProfileNodeSelectorTerms {
+			return nil, fmt.Errorf("too many profiles, the node affinity of the Agent DaemonSets exceeds %d terms", maxProfileNodeSelectorTerms)
+		}
+	}
+
+	if err := addRequiredNodeSelectorTerms(&profileDDA.Spec.Agent, terms); err != nil {
+		return nil, err
+	}
+	return profileDDA, nil
+}
+
+func profileDaemonSetName(dda *datadoghqv1alpha1.DatadogAgent, profile *datadoghqv1alpha1.DatadogAgentProfile) string {
+	return fmt.Sprintf("%s-%s", daemonsetName(dda), profile.Name)
+}
+
+func profileDaemonSetSelector(dda *datadoghqv1alpha1.DatadogAgent, profile *datadoghqv1alpha1.DatadogAgentProfile) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			datadoghqv1alpha1.AgentDeploymentNameLabelKey:      dda.Name,
+			datadoghqv1alpha1.AgentDeploymentComponentLabelKey: datadoghqv1alpha1.DefaultAgentResourceSuffix,
+			datadoghqv1alpha1.AgentProfileLabelKey:             profile.Name,
+		},
+	}
+}
+
+// manageAgentProfileDaemonSets applies the DaemonSets of the valid profiles, and deletes the profile DaemonSets
+// that are not needed anymore: deleted or invalid profiles, or Agent disabled.
+func (r *Reconciler) manageAgentProfileDaemonSets(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, profiles []datadoghqv1alpha1.DatadogAgentProfile, profileDDAs []*datadoghqv1alpha1.DatadogAgent) error {
+	useEDS := r.options.SupportExtendedDaemonset && apiutils.BoolValue(dda.Spec.Agent.UseExtendedDaemonset)
+
+	desired := map[string]bool{}
+	for i, profileDDA := range profileDDAs {
+		res := profileDaemonSetResource(logger, profileDDA, &profiles[i], useEDS)
+		if _, err := r.manageResource(logger, dda, res); err != nil {
+			return err
+		}
+		desired[res.kind+"/"+res.name] = true
+	}
+
+	existing, err := r.listAgentProfileDaemonSets(dda)
+	if err != nil {
+		return err
+	}
+	for _, res := range existing {
+		if desired[res.kind+"/"+res.name] {
+			continue
+		}
+		if err = r.cleanupResource(logger, dda, res); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func profileDaemonSetResource(logger logr.Logger, profileDDA *datadoghqv1alpha1.DatadogAgent, profile *datadoghqv1alpha1.DatadogAgentProfile, useEDS bool) managedResource {
+	res := managedResource{
+		kind:      daemonSetKind,
+		name:      daemonsetName(profileDDA),
+		namespace: profileDDA.Namespace,
+		newObject: func() client.Object { return &appsv1.DaemonSet{} },
+		build: func() (client.Object, error) {
+			ds, _, err := newDaemonSetFromInstance(logger, profileDDA, profileDaemonSetSelector(profileDDA, profile))
+			if err != nil {
+				return nil, err
+			}
+			ds.Labels[datadoghqv1alpha1.AgentProfileLabelKey] = profile.Name
+			return ds, nil
+		},
+		ownership: ownedByController,
+	}
+	if useEDS {
+		res.kind = extendedDaemonSetKind
+		res.newObject = func() client.Object { return &edsdatadoghqv1alpha1.ExtendedDaemonSet{} }
+		res.build = func() (client.Object, error) {
+			eds, _, err := newExtendedDaemonSetFromInstance(logger, profileDDA, profileDaemonSetSelector(profileDDA, profile))
+			if err != nil {
+				return nil, err
+			}
+			eds.Labels[datadoghqv1alpha1.AgentProfileLabelKey] = profile.Name
+			return eds, nil
+		}
+	}
+	return res
+}
+
+// listAgentProfileDaemonSets returns the profile DaemonSets and ExtendedDaemonSets of the DatadogAgent
+func (r *Reconciler) listAgentProfileDaemonSets(dda *datadoghqv1alpha1.DatadogAgent) ([]managedResource, error) {
+	listOptions := []client.ListOption{
+		client.InNamespace(dda.Namespace),
+		client.MatchingLabels{datadoghqv1alpha1.AgentDeploymentNameLabelKey: dda.Name},
+		client.HasLabels{datadoghqv1alpha1.AgentProfileLabelKey},
+	}
+
+	var resources []managedResource
+	dsList := &appsv1.DaemonSetList{}
+	if err := r.client.List(context.TODO(), dsList, listOptions...); err != nil {
+		return nil, err
+	}
+	for _, ds := range dsList.Items {
+		resources = append(resources, managedResource{
+			kind:      daemonSetKind,
+			name:      ds.Name,
+			namespace: ds.Namespace,
+			newObject: func() client.Object { return &appsv1.DaemonSet{} },
+			ownership: ownedByController,
+		})
+	}
+
+	if !r.options.SupportExtendedDaemonset {
+		return resources, nil
+	}
+	edsList := &edsdatadoghqv1alpha1.ExtendedDaemonSetList{}
+	if err := r.client.List(context.TODO(), edsList, listOptions...); err != nil {
+		return nil, err
+	}
+	for _, eds := range edsList.Items {
+		resources = append(resources, managedResource{
+			kind:      extendedDaemonSetKind,
+			name:      eds.Name,
+			namespace: eds.Namespace,
+			newObject: func() client.Object { return &edsdatadoghqv1alpha1.ExtendedDaemonSet{} },
+			ownership: ownedByController,
+		})
+	}
+
+	return resources, nil
+}
+
+// updateAgentProfilesStatus updates the validity of the profiles, and the nodes they cover for the DatadogAgent.
+// profileDDAs is empty when the Agent is disabled: the profiles then cover no node for the DatadogAgent.
+func (r *Reconciler) updateAgentProfilesStatus(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, profiles, validProfiles []datadoghqv1alpha1.DatadogAgentProfile, profileDDAs []*datadoghqv1alpha1.DatadogAgent, validationErrors map[string]error) error {
+	coveredNodes := make(map[string][]string, len(validProfiles))
+	if len(profileDDAs) > 0 {
+		nodeList := &corev1.NodeList{}
+		if err := r.client.List(context.TODO(), nodeList); err != nil {
+			return err
+		}
+		for i, profileDDA := range profileDDAs {
+			nodes, err := nodesMatchingAffinity(nodeList.Items, profileDDA.Spec.Agent.Affinity)
+			if err != nil {
+				return err
+			}
+			coveredNodes[validProfiles[i].Name] = nodes
+		}
+	}
+
+	ddaNames, err := r.listDatadogAgentNames(dda.Namespace)
+	if err != nil {
+		return err
+	}
+	ddaNames[dda.Name] = true
+
+	for i := range profiles {
+		profile := &profiles[i]
+		updated, err := r.updateAgentProfileStatus(profile, func(status *datadoghqv1alpha1.DatadogAgentProfileStatus, now metav1.Time) {
+			condition := metav1.Condition{
+				Type:               datadoghqv1alpha1.DatadogAgentProfileConditionTypeValid,
+				Status:             metav1.ConditionTrue,
+				Reason:             profileValidReason,
+				Message:            "Profile applied",
+				ObservedGeneration: profile.Generation,
+			}
+			if validationErr, invalid := validationErrors[profile.Name]; invalid {
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = profileInvalidReason
+				condition.Message = validationErr.Error()
+			}
+			apimeta.SetStatusCondition(&status.Conditions, condition)
+			setProfileAgentStatus(status, dda.Name, coveredNodes[profile.Name], ddaNames, now)
+		})
+		if err != nil {
+			return err
+		}
+		if updated {
+			logger.V(1).Info("Updated DatadogAgentProfile status", "datadogAgentProfile.Name", profile.Name, "coveredNodes", profile.Status.CoveredNodes)
+		}
+	}
+
+	return nil
+}
+
+// cleanupAgentProfilesStatus removes the DatadogAgent from the status of the profiles of its namespace
+func (r *Reconciler) cleanupAgentProfilesStatus(dda *datadoghqv1alpha1.DatadogAgent) error {
+	profiles, err := r.listAgentProfiles(dda)
+	if err != nil {
+		return err
+	}
+	ddaNames, err := r.listDatadogAgentNames(dda.Namespace)
+	if err != nil {
+		return err
+	}
+	delete(ddaNames, dda.Name)
+
+	for i := range profiles {
+		_, err = r.updateAgentProfileStatus(&profiles[i], func(status *datadoghqv1alpha1.DatadogAgentProfileStatus, now metav1.Time) {
+			setProfileAgentStatus(status, dda.Name, nil, ddaNames, now)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateAgentProfileStatus updates the status of the profile with the status computed by update, which is retried
+// on the latest version of the profile when another DatadogAgent of the namespace updated it concurrently.
+// It returns true if the status was updated.
+func (r *Reconciler) updateAgentProfileStatus(profile *datadoghqv1alpha1.DatadogAgentProfile, update func(*datadoghqv1alpha1.DatadogAgentProfileStatus, metav1.Time)) (bool, error) {
+	updated := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		newStatus := profile.Status.DeepCopy()
+		update(newStatus, metav1.Now())
+		if apiequality.Semantic.DeepEqual(&profile.Status, newStatus) {
+			return nil
+		}
+
+		newProfile := profile.DeepCopy()
+		newProfile.Status = *newStatus
+		err := r.client.Status().Update(context.TODO(), newProfile)
+		if err == nil {
+			*profile = *newProfile
+			updated = true
+			return nil
+		}
+		if apierrors.IsConflict(err) {
+			if getErr := r.client.Get(context.TODO(), client.ObjectKeyFromObject(profile), profile); getErr != nil {
+				return getErr
+			}
+		}
+		return err
+	})
+
+	return updated, err
+}
+
+func (r *Reconciler) listDatadogAgentNames(namespace string) (map[string]bool, error) {
+	ddaList := &datadoghqv1alpha1.DatadogAgentList{}
+	if err := r.client.List(context.TODO(), ddaList, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(ddaList.Items))
+	for _, dda := range ddaList.Items {
+		names[dda.Name] = true
+	}
+	return names, nil
+}
+
+// setProfileAgentStatus sets the nodes covered by the profile for the DatadogAgent ddaName, removes the DatadogAgents
+// that are not in ddaNames, and aggregates the nodes covered for all the DatadogAgents.
+func setProfileAgentStatus(status *datadoghqv1alpha1.DatadogAgentProfileStatus, ddaName string, nodes []string, ddaNames map[string]bool, now metav1.Time) {
+	agents := make([]datadoghqv1alpha1.DatadogAgentProfileAgentStatus, 0, len(status.DatadogAgents)+1)
+	for _, agent := range status.DatadogAgents {
+		if ddaNames[agent.Name] && agent.Name != ddaName {
+			agents = append(agents, agent)
+		}
+	}
+	if ddaNames[ddaName] {
+		agent := datadoghqv1alpha1.DatadogAgentProfileAgentStatus{
+			Name:         ddaName,
+			CoveredNodes: int32(len(nodes)),
+			Nodes:        truncateNodeNames(nodes),
+			LastUpdate:   &now,
+		}
+		for _, previous := range status.DatadogAgents {
+			if previous.Name == ddaName && previous.CoveredNodes == agent.CoveredNodes && reflect.DeepEqual(previous.Nodes, agent.Nodes) {
+				agent.LastUpdate = previous.LastUpdate
+			}
+		}
+		agents = append(agents, agent)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	if len(agents) == 0 {
+		agents = nil
+	}
+	status.DatadogAgents = agents
+
+	var coveredNodes int32
+	var allNodes []string
+	for _, agent := range agents {
+		coveredNodes += agent.CoveredNodes
+		allNodes = append(allNodes, agent.Nodes...)
+	}
+	sort.Strings(allNodes)
+	allNodes = truncateNodeNames(allNodes)
+	if coveredNodes != status.CoveredNodes || !reflect.DeepEqual(allNodes, status.Nodes) {
+		status.CoveredNodes = coveredNodes
+		status.Nodes = allNodes
+		status.LastUpdate = &now
+	}
+}
+
+// truncateNodeNames keeps the first maxProfileStatusNodes node names, to bound the size of the profile status
+func truncateNodeNames(nodes []string) []string {
+	if len(nodes) > maxProfileStatusNodes {
+		return nodes[:maxProfileStatusNodes]
+	}
+	return nodes
+}
+
+// nodesMatchingAffinity returns the sorted names of the nodes matching the required node affinity
+func nodesMatchingAffinity(nodes []corev1.Node, affinity *corev1.Affinity) ([]string, error) {
+	var terms []corev1.NodeSelectorTerm
+	if affinity != nil && affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		terms = affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	}
+
+	var names []string
+	for _, node := range nodes {
+		match, err := nodeMatchesNodeSelectorTerms(&node, terms)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			names = append(names, node.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// nodeMatchesNodeSelectorTerms returns true if the node matches one of the terms, or if there are no terms
+func nodeMatchesNodeSelectorTerms(node *corev1.Node, terms []corev1.NodeSelectorTerm) (bool, error) {
+	if len(terms) == 0 {
+		return true, nil
+	}
+	fields := labels.Set{nodeNameField: node.Name}
+	for _, term := range terms {
+		labelsMatch, err := nodeSelectorRequirementsMatch(term.MatchExpressions, labels.Set(node.Labels))
+		if err != nil {
+			return false, err
+		}
+		fieldsMatch, err := nodeSelectorRequirementsMatch(term.MatchFields, fields)
+		if err != nil {
+			return false, err
+		}
+		if labelsMatch && fieldsMatch {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func nodeSelectorRequirementsMatch(reqs []corev1.NodeSelectorRequirement, set labels.Set) (bool, error) {
+	for _, req := range reqs {
+		selector, err := nodeSelectorRequirementAsSelector(req)
+		if err != nil {
+			return false, err
+		}
+		if !selector.Matches(set) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func nodeSelectorRequirementAsSelector(req corev1.NodeSelectorRequirement) (labels.Selector, error) {
+	var op selection.Operator
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		op = selection.In
+	case corev1.NodeSelectorOpNotIn:
+		op = selection.NotIn
+	case corev1.NodeSelectorOpExists:
+		op = selection.Exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		op = selection.DoesNotExist
+	case corev1.NodeSelectorOpGt:
+		op = selection.GreaterThan
+	case corev1.NodeSelectorOpLt:
+		op = selection.LessThan
+	default:
+		return nil, fmt.Errorf("invalid node selector operator %q", req.Operator)
+	}
+	requirement, err := labels.NewRequirement(req.Key, op, req.Values)
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*requirement), nil
+}
+
+// excludeNodeSelectorRequirements returns the node selector terms matching the nodes that do not match
+// all the requirements: the negation of the requirements, in disjunctive normal form.
+func excludeNodeSelectorRequirements(reqs []corev1.NodeSelectorRequirement) []corev1.NodeSelectorTerm {
+	var terms []corev1.NodeSelectorTerm
+	for _, req := range reqs {
+		for _, negated := range negateNodeSelectorRequirement(req) {
+			terms = append(terms, corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{negated}})
+		}
+	}
+	return terms
+}
+
+// negateNodeSelectorRequirement returns the requirements matching the nodes that do not match req, any of them.
+// Gt and Lt do not match the nodes without the label, so the negation includes a DoesNotExist requirement.
+func negateNodeSelectorRequirement(req corev1.NodeSelectorRequirement) []corev1.NodeSelectorRequirement {
+	doesNotExist := corev1.NodeSelectorRequirement{Key: req.Key, Operator: corev1.NodeSelectorOpDoesNotExist}
+	switch req.Operator {
+	case corev1.NodeSelectorOpIn:
+		return []corev1.NodeSelectorRequirement{{Key: req.Key, Operator: corev1.NodeSelectorOpNotIn, Values: req.Values}}
+	case corev1.NodeSelectorOpNotIn:
+		return []corev1.NodeSelectorRequirement{{Key: req.Key, Operator: corev1.NodeSelectorOpIn, Values: req.Values}}
+	case corev1.NodeSelectorOpExists:
+		return []corev1.NodeSelectorRequirement{doesNotExist}
+	case corev1.NodeSelectorOpDoesNotExist:
+		return []corev1.NodeSelectorRequirement{{Key: req.Key, Operator: corev1.NodeSelectorOpExists}}
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		// Values are validated by validateAgentProfile
+		value, _ := strconv.ParseInt(req.Values[0], 10, 64)
+		negated := corev1.NodeSelectorRequirement{Key: req.Key, Operator: corev1.NodeSelectorOpLt, Values: []string{strconv.FormatInt(value+1, 10)}}
+		if req.Operator == corev1.NodeSelectorOpLt {
+			negated = corev1.NodeSelectorRequirement{Key: req.Key, Operator: corev1.NodeSelectorOpGt, Values: []string{strconv.FormatInt(value-1, 10)}}
+		}
+		return []corev1.NodeSelectorRequirement{doesNotExist, negated}
+	}
+	return nil
+}
+
+// andNodeSelectorTerms returns the terms matching the nodes matched by both a and b.
+// Node selector terms being ORed, the result is the cross product of the terms.
+func andNodeSelectorTerms(a, b []corev1.NodeSelectorTerm) []corev1.NodeSelectorTerm {
+	terms := make([]corev1.NodeSelectorTerm, 0, len(a)*len(b))
+	for _, termA := range a {
+		for _, termB := range b {
+			term := corev1.NodeSelectorTerm{}
+			term.MatchExpressions = append(append(term.MatchExpressions, termA.MatchExpressions...), termB.MatchExpressions...)
+			term.MatchFields = append(append(term.MatchFields, termA.MatchFields...), termB.MatchFields...)
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// addRequiredNodeSelectorTerms restricts the required node affinity of the Agent to the nodes matching the terms
+func addRequiredNodeSelectorTerms(agent *datadoghqv1alpha1.DatadogAgentSpecAgentSpec, terms []corev1.NodeSelectorTerm) error {
+	if len(terms) == 1 && len(terms[0].MatchExpressions) == 0 && len(terms[0].MatchFields) == 0 {
+		// no restriction
+		return nil
+	}
+
+	if agent.Affinity == nil {
+		agent.Affinity = &corev1.Affinity{}
+	}
+	if agent.Affinity.NodeAffinity == nil {
+		agent.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	required := agent.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		agent.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{NodeSelectorTerms: terms}
+		return nil
+	}
+
+	required.NodeSelectorTerms = andNodeSelectorTerms(required.NodeSelectorTerms, terms)
+	if len(required.NodeSelectorTerms) > maxProfileNodeSelectorTerms {
+		return fmt.Errorf("too many profiles, the node affinity of the Agent DaemonSets exceeds %d terms", maxProfileNodeSelectorTerms)
+	}
+	return nil
+}
+
+// applyAgentProfile merges the profile configuration into the Agent spec
+func applyAgentProfile(agent *datadoghqv1alpha1.DatadogAgentSpecAgentSpec, profile *datadoghqv1alpha1.DatadogAgentProfileAgentSpec) {
+	if profile == nil {
+		return
+	}
+
+	if profile.Config != nil {
+		if agent.Config == nil {
+			agent.Config = &datadoghqv1alpha1.NodeAgentConfig{}
+		}
+		agent.Config.Env = mergeEnvVars(agent.Config.Env, profile.Config.Env)
+		agent.Config.Resources = mergeResources(agent.Config.Resources, profile.Config.Resources)
+		agent.Config.Tolerations = mergeTolerations(agent.Config.Tolerations, profile.Config.Tolerations)
+	}
+
+	if profile.Apm != nil {
+		if agent.Apm == nil {
+			agent.Apm = &datadoghqv1alpha1.APMSpec{}
+		}
+		agent.Apm.Env = mergeEnvVars(agent.Apm.Env, profile.Apm.Env)
+		agent.Apm.Resources = mergeResources(agent.Apm.Resources, profile.Apm.Resources)
+		if profile.Apm.Enabled != nil {
+			agent.Apm.Enabled = apiutils.NewBoolPointer(*profile.Apm.Enabled)
+			// the DatadogAgent defaulting skips the fields of a disabled feature
+			datadoghqv1alpha1.DefaultDatadogAgentSpecAgentApm(agent)
+		}
+	}
+
+	if profile.Process != nil {
+		if agent.Process == nil {
+			agent.Process = &datadoghqv1alpha1.ProcessSpec{}
+		}
+		agent.Process.Env = mergeEnvVars(agent.Process.Env, profile.Process.Env)
+		agent.Process.Resources = mergeResources(agent.Process.Resources, profile.Process.Resources)
+		if profile.Process.Enabled != nil {
+			agent.Process.Enabled = apiutils.NewBoolPointer(*profile.Process.Enabled)
+			// the DatadogAgent defaulting skips the fields of a disabled feature
+			datadoghqv1alpha1.DefaultDatadogAgentSpecAgentProcess(agent)
+		}
+	}
+
+	if profile.SystemProbe != nil && agent.SystemProbe != nil {
+		agent.SystemProbe.Env = mergeEnvVars(agent.SystemProbe.Env, profile.SystemProbe.Env)
+		agent.SystemProbe.Resources = mergeResources(agent.SystemProbe.Resources, profile.SystemProbe.Resources)
+	}
+
+	if profile.Security != nil && agent.Security != nil {
+		agent.Security.Env = mergeEnvVars(agent.Security.Env, profile.Security.Env)
+		agent.Security.Resources = mergeResources(agent.Security.Resources, profile.Security.Resources)
+	}
+
+	agent.Env = mergeEnvVars(agent.Env, profile.Env)
+	if profile.PriorityClassName != "" {
+		agent.PriorityClassName = profile.PriorityClassName
+	}
+	agent.AdditionalAnnotations = mergeStringMaps(agent.AdditionalAnnotations, profile.AdditionalAnnotations)
+	agent.AdditionalLabels = mergeStringMaps(agent.AdditionalLabels, profile.AdditionalLabels)
+}
+
+// mergeEnvVars overrides the variables of base with the ones of override with the same name,
+// the other variables of override are appended
+func mergeEnvVars(base, override []corev1.EnvVar) []corev1.EnvVar {
+	if len(override) == 0 {
+		return base
+	}
+	merged := append([]corev1.EnvVar{}, base...)
+	for _, env := range override {
+		found := false
+		for i := range merged {
+			if merged[i].Name == env.Name {
+				merged[i] = env
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, env)
+		}
+	}
+	return merged
+}
+
+func mergeResources(base, override *corev1.ResourceRequirements) *corev1.ResourceRequirements {
+	if override == nil {
+		return base
+	}
+	return override.DeepCopy()
+}
+
+func mergeTolerations(base, additional []corev1.Toleration) []corev1.Toleration {
+	merged := append([]corev1.Toleration{}, base...)
+	for _, toleration := range additional {
+		found := false
+		for _, existing := range merged {
+			if reflect.DeepEqual(existing, toleration) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, toleration)
+		}
+	}
+	if len(merged) == 0 {
+		return base
+	}
+	return merged
+}
+
+func mergeStringMaps(base, additional map[string]string) map[string]string {
+	if len(additional) == 0 {
+		return base
+	}
+	merged := make(map[string]string, len(base)+len(additional))
+	for key, val := range base {
+		merged[key] = val
+	}
+	for key, val := range additional {
+		merged[key] = val
+	}
+	return merged
+}
@@ -0,0 +1,293 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
+)
+
+func newTestProfile(name string, age time.Duration, reqs ...corev1.NodeSelectorRequirement) datadoghqv1alpha1.DatadogAgentProfile {
+	return datadoghqv1alpha1.DatadogAgentProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "bar",
+			CreationTimestamp: metav1.NewTime(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Add(-age)),
+		},
+		Spec: datadoghqv1alpha1.DatadogAgentProfileSpec{
+			NodeSelectorRequirements: reqs,
+		},
+	}
+}
+
+func newTestNode(name string, nodeLabels map[string]string) corev1.Node {
+	return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels}}
+}
+
+func Test_newProfileDatadogAgent_nodeCoverage(t *testing.T) {
+	gpu := newTestProfile("gpu", 2*time.Hour, corev1.NodeSelectorRequirement{
+		Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"},
+	})
+	large := newTestProfile("large", time.Hour, corev1.NodeSelectorRequirement{
+		Key: "cpus", Operator: corev1.NodeSelectorOpGt, Values: []string{"32"},
+	})
+	profiles := []datadoghqv1alpha1.DatadogAgentProfile{gpu, large}
+
+	nodes := []corev1.Node{
+		newTestNode("default", nil),
+		newTestNode("small", map[string]string{"cpus": "8"}),
+		newTestNode("large", map[string]string{"cpus": "64"}),
+		newTestNode("large-gpu", map[string]string{"cpus": "64", "pool": "gpu"}),
+		newTestNode("gpu", map[string]string{"pool": "gpu"}),
+		newTestNode("linux-arm", map[string]string{"kubernetes.io/arch": "arm64"}),
+	}
+
+	tests := []struct {
+		name     string
+		affinity *corev1.Affinity
+		index    int
+		want     []string
+	}{
+		{
+			name:  "default DaemonSet, nodes not covered by any profile",
+			index: -1,
+			want:  []string{"default", "linux-arm", "small"},
+		},
+		{
+			name:  "oldest profile, all matching nodes",
+			index: 0,
+			want:  []string{"gpu", "large-gpu"},
+		},
+		{
+			name:  "newest profile, nodes not covered by the oldest one",
+			index: 1,
+			want:  []string{"large"},
+		},
+		{
+			name: "default DaemonSet, user node affinity kept",
+			affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "kubernetes.io/arch", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"arm64"}}}},
+						},
+					},
+				},
+			},
+			index: -1,
+			want:  []string{"default", "small"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+			dda.Spec.Agent.Affinity = tt.affinity
+
+			profileDDA, err := newProfileDatadogAgent(dda, profiles, tt.index)
+			assert.NoError(t, err)
+
+			got, err := nodesMatchingAffinity(nodes, profileDDA.Spec.Agent.Affinity)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_newProfileDatadogAgent_tooManyTerms(t *testing.T) {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+
+	var profiles []datadoghqv1alpha1.DatadogAgentProfile
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		profiles = append(profiles, newTestProfile(name, 0,
+			corev1.NodeSelectorRequirement{Key: name, Operator: corev1.NodeSelectorOpExists},
+			corev1.NodeSelectorRequirement{Key: name + "-size", Operator: corev1.NodeSelectorOpGt, Values: []string{"1"}},
+		))
+	}
+
+	_, err := newProfileDatadogAgent(dda, profiles, -1)
+	assert.Error(t, err)
+}
+
+func Test_applyAgentProfile(t *testing.T) {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+	dda.Spec.Agent.Env = []corev1.EnvVar{
+		{Name: "DD_TAGS", Value: "team:foo"},
+		{Name: "DD_LOG_LEVEL", Value: "info"},
+	}
+	agent := dda.Spec.Agent.DeepCopy()
+
+	resources := &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	toleration := corev1.Toleration{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}
+	applyAgentProfile(agent, &datadoghqv1alpha1.DatadogAgentProfileAgentSpec{
+		Config: &datadoghqv1alpha1.DatadogAgentProfileNodeAgentConfig{
+			DatadogAgentProfileContainerConfig: datadoghqv1alpha1.DatadogAgentProfileContainerConfig{
+				Resources: resources,
+			},
+			Tolerations: []corev1.Toleration{toleration},
+		},
+		Apm: &datadoghqv1alpha1.DatadogAgentProfileFeatureConfig{
+			Enabled: apiutils.NewBoolPointer(true),
+		},
+		Env: []corev1.EnvVar{
+			{Name: "DD_TAGS", Value: "team:gpu"},
+			{Name: "DD_GPU", Value: "true"},
+		},
+		PriorityClassName: "gpu",
+		AdditionalLabels:  map[string]string{"pool": "gpu"},
+	})
+
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "DD_TAGS", Value: "team:gpu"},
+		{Name: "DD_LOG_LEVEL", Value: "info"},
+		{Name: "DD_GPU", Value: "true"},
+	}, agent.Env)
+	assert.Equal(t, resources, agent.Config.Resources)
+	assert.Contains(t, agent.Config.Tolerations, toleration)
+	assert.True(t, apiutils.BoolValue(agent.Apm.Enabled))
+	// the fields of the enabled feature are defaulted
+	assert.NotNil(t, agent.Apm.HostPort)
+	assert.NotNil(t, agent.Apm.UnixDomainSocket)
+	assert.Equal(t, "gpu", agent.PriorityClassName)
+	assert.Equal(t, "gpu", agent.AdditionalLabels["pool"])
+
+	// the DatadogAgent is not modified
+	assert.Len(t, dda.Spec.Agent.Env, 2)
+	assert.False(t, apiutils.BoolValue(dda.Spec.Agent.Apm.Enabled))
+}
+
+func Test_reconcileAgentProfiles(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{}, &datadoghqv1alpha1.DatadogAgentList{}, &datadoghqv1alpha1.DatadogAgentProfile{}, &datadoghqv1alpha1.DatadogAgentProfileList{})
+
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+	dda.UID = "dda-uid"
+
+	gpu := newTestProfile("gpu", time.Hour, corev1.NodeSelectorRequirement{
+		Key: "pool", Operator: corev1.NodeSelectorOpIn, Values: []string{"gpu"},
+	})
+	invalid := newTestProfile("invalid", 0)
+	gpuNode := newTestNode("gpu-1", map[string]string{"pool": "gpu"})
+	defaultNode := newTestNode("default-1", nil)
+
+	// profile DaemonSet of a deleted profile
+	orphan := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-agent-deleted",
+			Namespace: "bar",
+			Labels: map[string]string{
+				datadoghqv1alpha1.AgentDeploymentNameLabelKey: "foo",
+				datadoghqv1alpha1.AgentProfileLabelKey:        "deleted",
+			},
+		},
+	}
+	_ = SetOwnerReference(dda, orphan, s)
+
+	fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithScheme(s).WithObjects(&gpu, &invalid, &gpuNode, &defaultNode, orphan).Build())
+	r := &Reconciler{
+		client:   fakeClient,
+		scheme:   s,
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	agentDDA, err := r.reconcileAgentProfiles(logf.Log.WithName(t.Name()), dda)
+	assert.NoError(t, err)
+	defaultNodes, err := nodesMatchingAffinity([]corev1.Node{gpuNode, defaultNode}, agentDDA.Spec.Agent.Affinity)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default-1"}, defaultNodes)
+
+	ds := &appsv1.DaemonSet{}
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: "foo-agent-gpu"}, ds)
+	assert.NoError(t, err)
+	assert.Equal(t, "gpu", ds.Labels[datadoghqv1alpha1.AgentProfileLabelKey])
+	assert.Equal(t, "gpu", ds.Spec.Selector.MatchLabels[datadoghqv1alpha1.AgentProfileLabelKey])
+	assert.True(t, CheckOwnerReference(dda, ds))
+
+	dsList := &appsv1.DaemonSetList{}
+	assert.NoError(t, fakeClient.List(context.TODO(), dsList, client.InNamespace("bar")))
+	assert.Len(t, dsList.Items, 1, "orphan profile DaemonSet not deleted")
+
+	profile := &datadoghqv1alpha1.DatadogAgentProfile{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: "gpu"}, profile))
+	assert.Equal(t, int32(1), profile.Status.CoveredNodes)
+	assert.Equal(t, []string{"gpu-1"}, profile.Status.Nodes)
+	assert.Equal(t, metav1.ConditionTrue, profile.Status.Conditions[0].Status)
+	assert.Len(t, profile.Status.DatadogAgents, 1)
+	assert.Equal(t, "foo", profile.Status.DatadogAgents[0].Name)
+	assert.Equal(t, int32(1), profile.Status.DatadogAgents[0].CoveredNodes)
+
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: "invalid"}, profile))
+	assert.Equal(t, int32(0), profile.Status.CoveredNodes)
+	assert.Equal(t, metav1.ConditionFalse, profile.Status.Conditions[0].Status)
+
+	// The profiles cover no node once the Agent is disabled
+	dda.Spec.Agent.Enabled = apiutils.NewBoolPointer(false)
+	_, err = r.reconcileAgentProfiles(logf.Log.WithName(t.Name()), dda)
+	assert.NoError(t, err)
+	profile = &datadoghqv1alpha1.DatadogAgentProfile{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: "gpu"}, profile))
+	assert.Equal(t, int32(0), profile.Status.CoveredNodes)
+	assert.Empty(t, profile.Status.Nodes)
+}
+
+func Test_setProfileAgentStatus(t *testing.T) {
+	before := metav1.NewTime(time.Unix(1600000000, 0))
+	now := metav1.NewTime(time.Unix(1600000600, 0))
+	ddaNames := map[string]bool{"foo": true, "bar": true}
+
+	status := &datadoghqv1alpha1.DatadogAgentProfileStatus{
+		CoveredNodes: 2,
+		Nodes:        []string{"a-1", "a-2"},
+		LastUpdate:   &before,
+		DatadogAgents: []datadoghqv1alpha1.DatadogAgentProfileAgentStatus{
+			{Name: "bar", CoveredNodes: 1, Nodes: []string{"a-1"}, LastUpdate: &before},
+			{Name: "deleted", CoveredNodes: 1, Nodes: []string{"a-2"}, LastUpdate: &before},
+		},
+	}
+
+	// The status of the other DatadogAgents is kept, the deleted ones are removed, and the node names are capped
+	setProfileAgentStatus(status, "foo", []string{"b-1", "b-2", "b-3", "b-4", "b-5", "b-6"}, ddaNames, now)
+	assert.Equal(t, []datadoghqv1alpha1.DatadogAgentProfileAgentStatus{
+		{Name: "bar", CoveredNodes: 1, Nodes: []string{"a-1"}, LastUpdate: &before},
+		{Name: "foo", CoveredNodes: 6, Nodes: []string{"b-1", "b-2", "b-3", "b-4", "b-5"}, LastUpdate: &now},
+	}, status.DatadogAgents)
+	assert.Equal(t, int32(7), status.CoveredNodes)
+	assert.Equal(t, []string{"a-1", "b-1", "b-2", "b-3", "b-4"}, status.Nodes)
+	assert.Equal(t, &now, status.LastUpdate)
+
+	// Unchanged nodes keep their last update
+	later := metav1.NewTime(time.Unix(1600001200, 0))
+	setProfileAgentStatus(status, "bar", []string{"a-1"}, ddaNames, later)
+	assert.Equal(t, &before, status.DatadogAgents[0].LastUpdate)
+	assert.Equal(t, &now, status.LastUpdate)
+
+	// A DatadogAgent that is not in the namespace anymore is removed
+	setProfileAgentStatus(status, "foo", nil, map[string]bool{"bar": true}, later)
+	assert.Len(t, status.DatadogAgents, 1)
+	assert.Equal(t, int32(1), status.CoveredNodes)
+	assert.Equal(t, []string{"a-1"}, status.Nodes)
+	assert.Equal(t, &later, status.LastUpdate)
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+func isRemoteConfigurationEnabled(spec *datadoghqv1alpha1.DatadogAgentSpec) bool {
+	return spec.Features.RemoteConfiguration != nil && apiutils.BoolValue(spec.Features.RemoteConfiguration.Enabled)
+}
+
+// getEnvVarsForRemoteConfiguration returns the env vars enabling the Remote Configuration, they are set on the
+// Agent, the Cluster Agent and the Security Agent so that the components are configured consistently
+func getEnvVarsForRemoteConfiguration(dda *datadoghqv1alpha1.DatadogAgent) []corev1.EnvVar {
+	if !isRemoteConfigurationEnabled(&dda.Spec) {
+		return nil
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  datadoghqv1alpha1.DDRemoteConfigurationEnabled,
+			Value: strconv.FormatBool(true),
+		},
+	}
+}
+
+// needRemoteConfigurationVolume returns true if a writable volume must be mounted for the Remote Configuration
+// database of the Agent. The database is stored in the run directory, which is already mounted from the host
+// when the log collection is enabled.
+func needRemoteConfigurationVolume(dda *datadoghqv1alpha1.DatadogAgent) bool {
+	logCollection := dda.Spec.Features.LogCollection
+	return isRemoteConfigurationEnabled(&dda.Spec) && (logCollection == nil || !apiutils.BoolValue(logCollection.Enabled))
+}
+
+func getVolumeForRemoteConfiguration() corev1.Volume {
+	return corev1.Volume{
+		Name: datadoghqv1alpha1.RemoteConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+}
+
+func getVolumeMountForRemoteConfiguration() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      datadoghqv1alpha1.RemoteConfigVolumeName,
+		MountPath: datadoghqv1alpha1.RemoteConfigVolumePath,
+	}
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+func Test_RemoteConfiguration(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	rcEnvVar := corev1.EnvVar{Name: datadoghqv1alpha1.DDRemoteConfigurationEnabled, Value: "true"}
+
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true})
+	dda.Spec.Features.RemoteConfiguration = &datadoghqv1alpha1.RemoteConfigurationConfig{Enabled: apiutils.NewBoolPointer(true)}
+	dda.Spec.Agent.Security = &datadoghqv1alpha1.SecuritySpec{
+		Runtime: datadoghqv1alpha1.RuntimeSecuritySpec{Enabled: apiutils.NewBoolPointer(true)},
+	}
+
+	agentEnvVars, err := getEnvVarsForAgent(logger, dda)
+	assert.NoError(t, err)
+	assert.Contains(t, agentEnvVars, rcEnvVar)
+
+	securityAgentEnvVars, err := getEnvVarsForSecurityAgent(dda)
+	assert.NoError(t, err)
+	assert.Contains(t, securityAgentEnvVars, rcEnvVar)
+
+	clusterAgentEnvVars, err := getEnvVarsForClusterAgent(logger, dda)
+	assert.NoError(t, err)
+	assert.Contains(t, clusterAgentEnvVars, rcEnvVar)
+
+	// The Remote Configuration database is stored in a writable volume
+	rcVolumeMount := getVolumeMountForRemoteConfiguration()
+	assert.Contains(t, getVolumesForAgent(dda), getVolumeForRemoteConfiguration())
+	assert.Contains(t, getVolumeMountsForAgent(dda), rcVolumeMount)
+
+	dca, _, err := newClusterAgentDeploymentFromInstance(logger, dda, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, dca.Spec.Template.Spec.Volumes, getVolumeForRemoteConfiguration())
+	assert.Contains(t, dca.Spec.Template.Spec.Containers[0].VolumeMounts, rcVolumeMount)
+
+	// The run directory is already mounted when the log collection is enabled
+	dda.Spec.Features.LogCollection = &datadoghqv1alpha1.LogCollectionConfig{Enabled: apiutils.NewBoolPointer(true)}
+	assert.NotContains(t, getVolumeMountsForAgent(dda), rcVolumeMount)
+
+	// The Remote Configuration is disabled
+	dda.Spec.Features.RemoteConfiguration.Enabled = apiutils.NewBoolPointer(false)
+	agentEnvVars, err = getEnvVarsForAgent(logger, dda)
+	assert.NoError(t, err)
+	assert.NotContains(t, agentEnvVars, rcEnvVar)
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+// Render returns the objects the operator would create for dda in an empty cluster, without any cluster access.
+// The objects are built by the reconciler builders, after defaulting dda. versionInfo is the version of the target
+// cluster, used by the version-dependent builders; it can be nil if unknown.
+// The rendered objects don't have owner references: they are not managed by the operator, and a reference to a
+// DatadogAgent that doesn't exist would get them garbage collected.
+func Render(scheme *runtime.Scheme, options ReconcilerOptions, versionInfo *version.Info, dda *datadoghqv1alpha1.DatadogAgent) ([]*unstructured.Unstructured, error) {
+	renderer := newRenderClient(scheme)
+	if err := reconcileOnce(renderer, options, versionInfo, dda.DeepCopy()); err != nil {
+		return nil, err
+	}
+	return renderer.rendered()
+}
+
+// renderClient is a client of an empty cluster that records the objects written by the reconciler
+type renderClient struct {
+	scheme  *runtime.Scheme
+	keys    []string
+	objects map[string]client.Object
+}
+
+func newRenderClient(scheme *runtime.Scheme) *renderClient {
+	return &renderClient{
+		scheme:  scheme,
+		objects: make(map[string]client.Object),
+	}
+}
+
+// Get always returns a not found error, the cluster is empty
+func (c *renderClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	return apierrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: gvk.Kind}, key.Name)
+}
+
+// List always returns an empty list, the cluster is empty
+func (c *renderClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return meta.SetList(list, nil)
+}
+
+// Create records obj
+func (c *renderClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.record(obj)
+}
+
+// Update records obj
+func (c *renderClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return c.record(obj)
+}
+
+// Patch records obj, the reconciler only uses server-side apply patches that contain the whole object
+func (c *renderClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.record(obj)
+}
+
+// Delete always returns a not found error, the cluster is empty
+func (c *renderClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+}
+
+// DeleteAllOf does nothing, the cluster is empty
+func (c *renderClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return nil
+}
+
+// Status ignores the status updates
+func (c *renderClient) Status() client.StatusWriter {
+	return discardStatusWriter{}
+}
+
+// Scheme returns the scheme of the rendered objects
+func (c *renderClient) Scheme() *runtime.Scheme {
+	return c.scheme
+}
+
+// RESTMapper is not available without cluster access
+func (c *renderClient) RESTMapper() meta.RESTMapper {
+	return nil
+}
+
+func (c *renderClient) record(obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s/%s/%s", gvk.GroupKind().String(), obj.GetNamespace(), obj.GetName())
+	if _, found := c.objects[key]; !found {
+		c.keys = append(c.keys, key)
+	}
+
+	rendered, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unable to copy %s %s", gvk.Kind, obj.GetName())
+	}
+	rendered.GetObjectKind().SetGroupVersionKind(gvk)
+	c.objects[key] = rendered
+	return nil
+}
+
+// rendered returns the recorded objects in the order of the reconcile, without the fields set by the API server
+func (c *renderClient) rendered() ([]*unstructured.Unstructured, error) {
+	objects := make([]*unstructured.Unstructured, 0, len(c.keys))
+	for _, key := range c.keys {
+		content, err := toUnstructuredContent(c.objects[key])
+		if err != nil {
+			return nil, err
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		obj.SetOwnerReferences(nil)
+		obj.SetResourceVersion("")
+		obj.SetManagedFields(nil)
+		unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+		unstructured.RemoveNestedField(obj.Object, "status")
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
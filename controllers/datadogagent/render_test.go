@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+)
+
+func findRenderedObject(objects []*unstructured.Unstructured, kind, name string) *unstructured.Unstructured {
+	for _, obj := range objects {
+		if obj.GetKind() == kind && obj.GetName() == name {
+			return obj
+		}
+	}
+	return nil
+}
+
+func TestRender(t *testing.T) {
+	s := newPlanTestScheme()
+
+	tests := []struct {
+		name        string
+		versionInfo *version.Info
+		// wantObjects are the kind and name of objects expected in the rendered manifests
+		wantObjects [][2]string
+	}{
+		{
+			name: "default DatadogAgent",
+			wantObjects: [][2]string{
+				{daemonSetKind, "foo-agent"},
+				{deploymentKind, "foo-cluster-agent"},
+				{clusterRoleKind, "foo-agent"},
+				{serviceAccountKind, "foo-agent"},
+			},
+		},
+		{
+			name:        "GKE cluster, external metrics reader ClusterRole name",
+			versionInfo: &version.Info{GitVersion: "v1.20.8-gke.900"},
+			wantObjects: [][2]string{
+				{clusterRoleKind, "external-metrics-reader"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{
+				ClusterAgentEnabled:  true,
+				MetricsServerEnabled: true,
+			})
+
+			objects, err := Render(s, ReconcilerOptions{}, tt.versionInfo, dda)
+			assert.NoError(t, err)
+
+			for _, want := range tt.wantObjects {
+				obj := findRenderedObject(objects, want[0], want[1])
+				assert.NotNil(t, obj, "%s %s not rendered", want[0], want[1])
+				assert.NotEmpty(t, obj.GetAPIVersion())
+			}
+			for _, obj := range objects {
+				assert.Empty(t, obj.GetOwnerReferences(), "%s %s has owner references", obj.GetKind(), obj.GetName())
+				_, hasStatus := obj.Object["status"]
+				assert.False(t, hasStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	"github.com/DataDog/datadog-operator/pkg/controller/utils/condition"
+)
+
+const (
+	rolledBackEventReason = "RolledBack"
+
+	deploymentRevisionAnnotationKey = "deployment.kubernetes.io/revision"
+	crashLoopBackOffReason          = "CrashLoopBackOff"
+)
+
+// isRollbackEnabled returns true if the failed updates of the DatadogAgent are rolled back
+func isRollbackEnabled(dda *datadoghqv1alpha1.DatadogAgent) bool {
+	return dda.Spec.Rollback != nil && apiutils.BoolValue(dda.Spec.Rollback.Enabled)
+}
+
+// rollbackComponent is a workload whose pod template revisions are tracked
+type rollbackComponent struct {
+	name     string
+	workload client.Object
+}
+
+// reconcileRollback stores the revisions of the Agent DaemonSet and of the Cluster Agent and Cluster Checks Runner Deployments,
+// and rolls back a component to its last healthy revision when its update fails.
+// It runs before the other components, which render the rolled back pod template, see applyRollbackRevision.
+func (r *Reconciler) reconcileRollback(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus) (reconcile.Result, error) {
+	now := metav1.NewTime(time.Now())
+	if !isRollbackEnabled(dda) {
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypeRolledBack, corev1.ConditionFalse, "", false)
+		return reconcile.Result{}, nil
+	}
+
+	components, err := r.getRollbackComponents(dda)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var rolledBack []string
+	for _, component := range components {
+		message, err := r.manageComponentRevision(logger, dda, component, now.Time)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if message != "" {
+			rolledBack = append(rolledBack, message)
+		}
+	}
+
+	if len(rolledBack) > 0 {
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypeRolledBack, corev1.ConditionTrue, strings.Join(rolledBack, "; "), false)
+	} else {
+		condition.UpdateDatadogAgentStatusConditions(newStatus, now, datadoghqv1alpha1.DatadogAgentConditionTypeRolledBack, corev1.ConditionFalse, "", false)
+	}
+	return reconcile.Result{}, nil
+}
+
+// getRollbackComponents returns the existing workloads of the DatadogAgent.
+// The ExtendedDaemonSet is not tracked, its canary deployment already validates the updates.
+func (r *Reconciler) getRollbackComponents(dda *datadoghqv1alpha1.DatadogAgent) ([]rollbackComponent, error) {
+	candidates := []rollbackComponent{
+		{name: datadoghqv1alpha1.DefaultAgentResourceSuffix, workload: &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: daemonsetName(dda)}}},
+		{name: datadoghqv1alpha1.DefaultClusterAgentResourceSuffix, workload: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: getClusterAgentName(dda)}}},
+		{name: datadoghqv1alpha1.DefaultClusterChecksRunnerResourceSuffix, workload: &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: getClusterChecksRunnerName(dda)}}},
+	}
+
+	var components []rollbackComponent
+	for _, component := range candidates {
+		nsName := types.NamespacedName{Namespace: dda.Namespace, Name: component.workload.GetName()}
+		if err := r.client.Get(context.TODO(), nsName, component.workload); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !metav1.IsControlledBy(component.workload, dda) {
+			continue
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+// manageComponentRevision stores the revision of the component, marks it as healthy once rolled out,
+// or rolls it back if the updated pods fail. It returns a description of the rollback, if any.
+func (r *Reconciler) manageComponentRevision(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, component rollbackComponent, now time.Time) (string, error) {
+	hash := getHashAnnotation(component.workload.GetAnnotations())
+	if hash == "" {
+		return "", nil
+	}
+
+	revisions, err := r.listComponentRevisions(dda, component.name)
+	if err != nil {
+		return "", err
+	}
+	current := findRevision(revisions, hash)
+	if current == nil {
+		return "", r.createComponentRevision(logger, dda, component, hash, revisions)
+	}
+
+	if rolledBackTo := current.Annotations[datadoghqv1alpha1.RevisionRolledBackToAnnotationKey]; rolledBackTo != "" {
+		return rollbackDescription(component.name, hash, rolledBackTo, current.Annotations[datadoghqv1alpha1.RevisionRollbackReasonAnnotationKey]), nil
+	}
+	if current.Annotations[datadoghqv1alpha1.RevisionHealthyAnnotationKey] == "true" {
+		return "", nil
+	}
+
+	if isWorkloadRolledOut(component.workload) {
+		logger.V(1).Info("Revision healthy", "component", component.name, "revision", hash)
+		return "", r.annotateRevision(current, map[string]string{datadoghqv1alpha1.RevisionHealthyAnnotationKey: "true"})
+	}
+
+	pods, err := r.listUpdatedPods(component.workload)
+	if err != nil {
+		return "", err
+	}
+	reason, err := failedUpdateReason(dda.Spec.Rollback, pods, now)
+	if err != nil || reason == "" {
+		return "", err
+	}
+
+	healthy := lastHealthyRevision(revisions, hash)
+	if healthy == nil {
+		logger.Info("Update failed, no healthy revision to roll back to", "component", component.name, "revision", hash, "reason", reason)
+		return "", nil
+	}
+
+	healthyHash := healthy.Labels[datadoghqv1alpha1.RevisionHashLabelKey]
+	if err = r.annotateRevision(current, map[string]string{
+		datadoghqv1alpha1.RevisionRolledBackToAnnotationKey:   healthyHash,
+		datadoghqv1alpha1.RevisionRollbackReasonAnnotationKey: reason,
+	}); err != nil {
+		return "", err
+	}
+
+	description := rollbackDescription(component.name, hash, healthyHash, reason)
+	logger.Info("Rolling back a failed update", "component", component.name, "revision", hash, "rolledBackTo", healthyHash, "reason", reason)
+	r.recorder.Event(dda, corev1.EventTypeWarning, rolledBackEventReason, description)
+	return description, nil
+}
+
+func rollbackDescription(component, hash, rolledBackTo, reason string) string {
+	return fmt.Sprintf("%s revision %s rolled back to %s: %s", component, hash, rolledBackTo, reason)
+}
+
+// applyRollbackRevision replaces the rendered pod template of a component with the one of the last healthy
+// revision, if the revision of the given hash was rolled back.
+func (r *Reconciler) applyRollbackRevision(dda *datadoghqv1alpha1.DatadogAgent, component, hash string, template *corev1.PodTemplateSpec) error {
+	if !isRollbackEnabled(dda) {
+		return nil
+	}
+
+	revision := &appsv1.ControllerRevision{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dda.Namespace, Name: revisionName(dda, component, hash)}, revision); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	rolledBackTo := revision.Annotations[datadoghqv1alpha1.RevisionRolledBackToAnnotationKey]
+	if rolledBackTo == "" {
+		return nil
+	}
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dda.Namespace, Name: revisionName(dda, component, rolledBackTo)}, revision); err != nil {
+		return fmt.Errorf("unable to get the revision %s of the %s to roll back to: %w", rolledBackTo, component, err)
+	}
+	healthyTemplate := corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(revision.Data.Raw, &healthyTemplate); err != nil {
+		return fmt.Errorf("unable to decode the revision %s of the %s: %w", rolledBackTo, component, err)
+	}
+	*template = healthyTemplate
+	return nil
+}
+
+func revisionName(dda *datadoghqv1alpha1.DatadogAgent, component, hash string) string {
+	return fmt.Sprintf("%s-%s-%s", dda.Name, component, hash)
+}
+
+// createComponentRevision stores the pod template of the component, and deletes the revisions exceeding the history limit
+func (r *Reconciler) createComponentRevision(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, component rollbackComponent, hash string, revisions []appsv1.ControllerRevision) error {
+	data, err := json.Marshal(workloadPodTemplate(component.workload))
+	if err != nil {
+		return err
+	}
+
+	var number int64 = 1
+	if len(revisions) > 0 {
+		number = revisions[len(revisions)-1].Revision + 1
+	}
+	revision := &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      revisionName(dda, component.name, hash),
+			Namespace: dda.Namespace,
+			Labels: map[string]string{
+				datadoghqv1alpha1.AgentDeploymentNameLabelKey:      dda.Name,
+				datadoghqv1alpha1.AgentDeploymentComponentLabelKey: component.name,
+				datadoghqv1alpha1.RevisionHashLabelKey:             hash,
+			},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: number,
+	}
+	if err = controllerutil.SetControllerReference(dda, revision, r.scheme); err != nil {
+		return err
+	}
+	logger.V(1).Info("Creating a new revision", "component", component.name, "revision", hash)
+	if err = r.client.Create(context.TODO(), revision); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	// keep the newest revisions, and the last healthy one to be able to roll back
+	limit := int(*dda.Spec.Rollback.RevisionHistoryLimit)
+	healthy := lastHealthyRevision(revisions, hash)
+	for i := 0; i < len(revisions)+1-limit && i < len(revisions); i++ {
+		if healthy != nil && revisions[i].Name == healthy.Name {
+			continue
+		}
+		if err = r.client.Delete(context.TODO(), &revisions[i]); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) annotateRevision(revision *appsv1.ControllerRevision, annotations map[string]string) error {
+	if revision.Annotations == nil {
+		revision.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		revision.Annotations[key] = value
+	}
+	return r.client.Update(context.TODO(), revision)
+}
+
+// listComponentRevisions returns the revisions of a component of the DatadogAgent, sorted from the oldest to the newest
+func (r *Reconciler) listComponentRevisions(dda *datadoghqv1alpha1.DatadogAgent, component string) ([]appsv1.ControllerRevision, error) {
+	revisionList := &appsv1.ControllerRevisionList{}
+	if err := r.client.List(context.TODO(), revisionList, client.InNamespace(dda.Namespace), client.MatchingLabels{
+		datadoghqv1alpha1.AgentDeploymentNameLabelKey:      dda.Name,
+		datadoghqv1alpha1.AgentDeploymentComponentLabelKey: component,
+	}, client.HasLabels{datadoghqv1alpha1.RevisionHashLabelKey}); err != nil {
+		return nil, err
+	}
+
+	revisions := revisionList.Items
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+func findRevision(revisions []appsv1.ControllerRevision, hash string) *appsv1.ControllerRevision {
+	for i := range revisions {
+		if revisions[i].Labels[datadoghqv1alpha1.RevisionHashLabelKey] == hash {
+			return &revisions[i]
+		}
+	}
+	return nil
+}
+
+// lastHealthyRevision returns the newest healthy revision, other than the one of the given hash
+func lastHealthyRevision(revisions []appsv1.ControllerRevision, hash string) *appsv1.ControllerRevision {
+	for i := len(revisions) - 1; i >= 0; i-- {
+		revision := &revisions[i]
+		if revision.Labels[datadoghqv1alpha1.RevisionHashLabelKey] != hash && revision.Annotations[datadoghqv1alpha1.RevisionHealthyAnnotationKey] == "true" {
+			return revision
+		}
+	}
+	return nil
+}
+
+func workloadPodTemplate(workload client.Object) *corev1.PodTemplateSpec {
+	switch w := workload.(type) {
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template
+	case *appsv1.Deployment:
+		return &w.Spec.Template
+	}
+	return nil
+}
+
+// isWorkloadRolledOut returns true once all the pods of the workload are updated and available
+func isWorkloadRolledOut(workload client.Object) bool {
+	switch w := workload.(type) {
+	case *appsv1.DaemonSet:
+		return w.Status.ObservedGeneration >= w.Generation &&
+			w.Status.UpdatedNumberScheduled == w.Status.DesiredNumberScheduled &&
+			w.Status.NumberAvailable == w.Status.DesiredNumberScheduled
+	case *appsv1.Deployment:
+		replicas := int32(1)
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+		return w.Status.ObservedGeneration >= w.Generation &&
+			w.Status.UpdatedReplicas == replicas &&
+			w.Status.Replicas == replicas &&
+			w.Status.AvailableReplicas == replicas
+	}
+	return false
+}
+
+// listUpdatedPods returns the pods of the workload running its latest pod template
+func (r *Reconciler) listUpdatedPods(workload client.Object) ([]corev1.Pod, error) {
+	switch w := workload.(type) {
+	case *appsv1.DaemonSet:
+		revision, err := r.getDaemonSetUpdateRevision(w)
+		if err != nil || revision == "" {
+			return nil, err
+		}
+		pods, err := r.listDaemonSetPods(w)
+		if err != nil {
+			return nil, err
+		}
+		return filterPodsByLabel(pods, appsv1.DefaultDaemonSetUniqueLabelKey, revision), nil
+	case *appsv1.Deployment:
+		return r.listDeploymentUpdatedPods(w)
+	}
+	return nil, nil
+}
+
+// listDeploymentUpdatedPods returns the pods of the newest ReplicaSet of the Deployment
+func (r *Reconciler) listDeploymentUpdatedPods(deployment *appsv1.Deployment) ([]corev1.Pod, error) {
+	rsList := &appsv1.ReplicaSetList{}
+	if err := r.client.List(context.TODO(), rsList, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+		return nil, err
+	}
+
+	var newest *appsv1.ReplicaSet
+	var newestRevision int64
+	for i := range rsList.Items {
+		rs := &rsList.Items[i]
+		if !metav1.IsControlledBy(rs, deployment) {
+			continue
+		}
+		revision, _ := strconv.ParseInt(rs.Annotations[deploymentRevisionAnnotationKey], 10, 64)
+		if newest == nil || revision > newestRevision {
+			newest = rs
+			newestRevision = revision
+		}
+	}
+	if newest == nil {
+		return nil, nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.apiReader.List(context.TODO(), podList, client.InNamespace(deployment.Namespace), client.MatchingLabels(deployment.Spec.Selector.MatchLabels)); err != nil {
+		return nil, err
+	}
+	var pods []corev1.Pod
+	for i := range podList.Items {
+		if metav1.IsControlledBy(&podList.Items[i], newest) {
+			pods = append(pods, podList.Items[i])
+		}
+	}
+	return pods, nil
+}
+
+func filterPodsByLabel(pods []corev1.Pod, key, value string) []corev1.Pod {
+	var filtered []corev1.Pod
+	for _, pod := range pods {
+		if pod.Labels[key] == value {
+			filtered = append(filtered, pod)
+		}
+	}
+	return filtered
+}
+
+// failedUpdateReason returns why the update is considered as failed, or an empty string if the failure criteria are not met
+func failedUpdateReason(config *datadoghqv1alpha1.RollbackConfig, pods []corev1.Pod, now time.Time) (string, error) {
+	if len(pods) == 0 {
+		return "", nil
+	}
+
+	var crashLooping, unready int
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if isPodCrashLooping(pod) {
+			crashLooping++
+		}
+		readySince, ready := podReadySince(pod)
+		if readySince.IsZero() {
+			readySince = pod.CreationTimestamp.Time
+		}
+		if !ready && now.Sub(readySince) > config.UnreadyTimeout.Duration {
+			unready++
+		}
+	}
+
+	crashLoopThreshold, err := rollbackThreshold(config.CrashLoopBackOffThreshold, len(pods))
+	if err != nil {
+		return "", err
+	}
+	if crashLooping >= crashLoopThreshold {
+		return fmt.Sprintf("%d/%d updated pods in %s", crashLooping, len(pods), crashLoopBackOffReason), nil
+	}
+
+	unreadyThreshold, err := rollbackThreshold(config.UnreadyThreshold, len(pods))
+	if err != nil {
+		return "", err
+	}
+	if unready >= unreadyThreshold {
+		return fmt.Sprintf("%d/%d updated pods not ready for more than %s", unready, len(pods), config.UnreadyTimeout.Duration), nil
+	}
+	return "", nil
+}
+
+func rollbackThreshold(threshold *intstr.IntOrString, total int) (int, error) {
+	value, err := intstr.GetScaledValueFromIntOrPercent(threshold, total, true)
+	if err != nil {
+		return 0, err
+	}
+	if value < 1 {
+		value = 1
+	}
+	return value, nil
+}
+
+func isPodCrashLooping(pod *corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == crashLoopBackOffReason {
+			return true
+		}
+	}
+	return false
+}
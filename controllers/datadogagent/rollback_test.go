@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+func Test_reconcileRollback(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{})
+	logger := logf.Log.WithName(t.Name())
+
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true})
+	dda.UID = "dda-uid"
+	dda.Spec.Rollback = &datadoghqv1alpha1.RollbackConfig{Enabled: apiutils.NewBoolPointer(true)}
+	datadoghqv1alpha1.DefaultRollbackConfig(dda.Spec.Rollback)
+
+	dca := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        getClusterAgentName(dda),
+			Namespace:   "bar",
+			UID:         "dca-uid",
+			Annotations: map[string]string{datadoghqv1alpha1.MD5AgentDeploymentAnnotationKey: "healthy"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: apiutils.NewInt32Pointer(1),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "dca"}},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "cluster-agent", Image: "cluster-agent:1.0.0"}}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+	assert.NoError(t, controllerutil.SetControllerReference(dda, dca, s))
+
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(dca).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{client: fakeClient, apiReader: fakeClient, scheme: s, recorder: recorder}
+
+	// the healthy revision is stored, then marked as healthy
+	newStatus := &datadoghqv1alpha1.DatadogAgentStatus{}
+	for i := 0; i < 2; i++ {
+		_, err := r.reconcileRollback(logger, dda, newStatus)
+		assert.NoError(t, err)
+	}
+	revision := &appsv1.ControllerRevision{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: "foo-cluster-agent-healthy"}, revision))
+	assert.Equal(t, "true", revision.Annotations[datadoghqv1alpha1.RevisionHealthyAnnotationKey])
+
+	// a new image is rolled out and crash-loops
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "bar", Name: dca.Name}, dca))
+	dca.Annotations[datadoghqv1alpha1.MD5AgentDeploymentAnnotationKey] = "failed"
+	dca.Spec.Template.Spec.Containers[0].Image = "cluster-agent:2.0.0"
+	dca.Status = appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1}
+	assert.NoError(t, fakeClient.Update(context.TODO(), dca))
+
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo-cluster-agent-2",
+			Namespace:   "bar",
+			UID:         "rs-uid",
+			Labels:      map[string]string{"app": "dca"},
+			Annotations: map[string]string{deploymentRevisionAnnotationKey: "2"},
+		},
+	}
+	assert.NoError(t, controllerutil.SetControllerReference(dca, rs, s))
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-cluster-agent-2-abcde", Namespace: "bar", Labels: map[string]string{"app": "dca"}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "cluster-agent",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOffReason}},
+			}},
+		},
+	}
+	assert.NoError(t, controllerutil.SetControllerReference(rs, pod, s))
+	assert.NoError(t, fakeClient.Create(context.TODO(), rs))
+	assert.NoError(t, fakeClient.Create(context.TODO(), pod))
+
+	for i := 0; i < 2; i++ {
+		_, err := r.reconcileRollback(logger, dda, newStatus)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, newStatus.Conditions, 1)
+	assert.Equal(t, datadoghqv1alpha1.DatadogAgentConditionTypeRolledBack, newStatus.Conditions[0].Type)
+	assert.Equal(t, corev1.ConditionTrue, newStatus.Conditions[0].Status)
+	assert.Contains(t, newStatus.Conditions[0].Message, "1/1 updated pods in CrashLoopBackOff")
+	assert.Len(t, recorder.Events, 1)
+
+	// the pod template of the healthy revision is rendered
+	template := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "cluster-agent", Image: "cluster-agent:2.0.0"}}},
+	}
+	assert.NoError(t, r.applyRollbackRevision(dda, datadoghqv1alpha1.DefaultClusterAgentResourceSuffix, "failed", &template))
+	assert.Equal(t, "cluster-agent:1.0.0", template.Spec.Containers[0].Image)
+
+	// a new configuration is applied, the rollback is over
+	dca.Annotations[datadoghqv1alpha1.MD5AgentDeploymentAnnotationKey] = "fixed"
+	assert.NoError(t, fakeClient.Update(context.TODO(), dca))
+	_, err := r.reconcileRollback(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, newStatus.Conditions[0].Status)
+}
+
+func Test_failedUpdateReason(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	config := &datadoghqv1alpha1.RollbackConfig{
+		CrashLoopBackOffThreshold: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+	}
+	datadoghqv1alpha1.DefaultRollbackConfig(config)
+
+	readyPod := corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}}}
+	crashLoopingPod := corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: crashLoopBackOffReason}},
+	}}}}
+	unreadyPod := func(since time.Duration) corev1.Pod {
+		return corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(now.Add(-since))},
+		}}}
+	}
+
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		want string
+	}{
+		{
+			name: "no updated pods",
+		},
+		{
+			name: "all pods ready",
+			pods: []corev1.Pod{readyPod, readyPod},
+		},
+		{
+			name: "crash-looping pods below the threshold",
+			pods: []corev1.Pod{crashLoopingPod, readyPod, readyPod},
+		},
+		{
+			name: "crash-looping pods above the threshold",
+			pods: []corev1.Pod{crashLoopingPod, readyPod},
+			want: "1/2 updated pods in CrashLoopBackOff",
+		},
+		{
+			name: "pods not ready recently",
+			pods: []corev1.Pod{unreadyPod(time.Minute), unreadyPod(time.Minute)},
+		},
+		{
+			name: "pods not ready for too long",
+			pods: []corev1.Pod{unreadyPod(time.Hour), readyPod},
+			want: "1/2 updated pods not ready for more than 10m0s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := failedUpdateReason(config, tt.pods, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
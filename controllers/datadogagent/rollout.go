@@ -0,0 +1,215 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+const (
+	stagedRolloutRequeuePeriod       = 10 * time.Second
+	stagedRolloutHaltedRequeuePeriod = time.Minute
+
+	stagedRolloutHaltedEventReason = "StagedRolloutHalted"
+)
+
+// isStagedRolloutEnabled returns true if the Operator drives the rollout of the Agent DaemonSet
+func isStagedRolloutEnabled(dda *datadoghqv1alpha1.DatadogAgent) bool {
+	strategy := dda.Spec.Agent.DeploymentStrategy
+	return strategy != nil && strategy.StagedRollout != nil && apiutils.BoolValue(strategy.StagedRollout.Enabled)
+}
+
+// manageStagedRollout deletes the outdated pods of an OnDelete DaemonSet batch by batch.
+// A new batch is started once all the updated pods are ready since the pause duration,
+// the rollout is halted if an updated pod restarts more than the allowed number of times.
+func (r *Reconciler) manageStagedRollout(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, ds *appsv1.DaemonSet, dsStatus *datadoghqv1alpha1.DaemonSetStatus, now time.Time) (reconcile.Result, error) {
+	spec := dda.Spec.Agent.DeploymentStrategy.StagedRollout
+	maxRestarts := *spec.MaxRestarts
+	requeue := reconcile.Result{RequeueAfter: stagedRolloutRequeuePeriod}
+
+	if ds.Status.ObservedGeneration < ds.Generation {
+		// the DaemonSet controller didn't create the new revision yet
+		return requeue, nil
+	}
+
+	revision, err := r.getDaemonSetUpdateRevision(ds)
+	if err != nil || revision == "" {
+		return requeue, err
+	}
+
+	pods, err := r.listDaemonSetPods(ds)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	rollout := dsStatus.StagedRollout
+	if rollout == nil || rollout.Revision != revision {
+		rollout = &datadoghqv1alpha1.StagedRolloutStatus{Revision: revision}
+		dsStatus.StagedRollout = rollout
+	}
+
+	var outdated []*corev1.Pod
+	var pending, unhealthy []string
+	var lastReady time.Time
+	for i := range pods {
+		pod := &pods[i]
+		switch {
+		case pod.DeletionTimestamp != nil:
+			pending = append(pending, pod.Name)
+		case pod.Labels[appsv1.DefaultDaemonSetUniqueLabelKey] != revision:
+			outdated = append(outdated, pod)
+		case podRestartCount(pod) > maxRestarts:
+			unhealthy = append(unhealthy, pod.Name)
+		default:
+			readySince, ready := podReadySince(pod)
+			if !ready {
+				pending = append(pending, pod.Name)
+			} else if readySince.After(lastReady) {
+				lastReady = readySince
+			}
+		}
+	}
+	rollout.TotalPods = int32(len(pods))
+	rollout.UpdatedPods = int32(len(pods) - len(outdated))
+
+	if len(unhealthy) > 0 {
+		message := fmt.Sprintf("updated pods restarted more than %d times: %v", maxRestarts, unhealthy)
+		if rollout.Phase != datadoghqv1alpha1.StagedRolloutPhaseHalted {
+			logger.Info("Staged rollout halted", "daemonSet.Name", ds.Name, "revision", revision, "pods", unhealthy)
+			r.recorder.Event(dda, corev1.EventTypeWarning, stagedRolloutHaltedEventReason, fmt.Sprintf("Rollout of DaemonSet %s/%s halted: %s", ds.Namespace, ds.Name, message))
+		}
+		rollout.Phase = datadoghqv1alpha1.StagedRolloutPhaseHalted
+		rollout.Message = message
+		return reconcile.Result{RequeueAfter: stagedRolloutHaltedRequeuePeriod}, nil
+	}
+
+	if len(outdated) == 0 {
+		rollout.Phase = datadoghqv1alpha1.StagedRolloutPhaseCompleted
+		rollout.Message = ""
+		return reconcile.Result{}, nil
+	}
+
+	rollout.Phase = datadoghqv1alpha1.StagedRolloutPhaseProgressing
+	if len(pending) > 0 || rollout.TotalPods < ds.Status.DesiredNumberScheduled {
+		rollout.Message = fmt.Sprintf("waiting for the pods of batch %d to be ready", rollout.Batch)
+		return requeue, nil
+	}
+	if next := lastReady.Add(spec.PauseDuration.Duration); rollout.Batch > 0 && now.Before(next) {
+		rollout.Message = fmt.Sprintf("batch %d ready, pausing until %s", rollout.Batch, next.UTC().Format(time.RFC3339))
+		return reconcile.Result{RequeueAfter: next.Sub(now)}, nil
+	}
+
+	batchSize := spec.BatchSize
+	if rollout.Batch == 0 {
+		batchSize = spec.CanaryReplicas
+	}
+	size, err := intstr.GetScaledValueFromIntOrPercent(batchSize, len(pods), true)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if size < 1 {
+		size = 1
+	}
+	if size > len(outdated) {
+		size = len(outdated)
+	}
+
+	// delete the pods that are not ready first, they don't run the Agent properly anyway
+	sort.SliceStable(outdated, func(i, j int) bool {
+		_, iReady := podReadySince(outdated[i])
+		_, jReady := podReadySince(outdated[j])
+		if iReady != jReady {
+			return !iReady
+		}
+		return outdated[i].Name < outdated[j].Name
+	})
+	for _, pod := range outdated[:size] {
+		if err = r.client.Delete(context.TODO(), pod); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+	}
+
+	rollout.Batch++
+	lastBatchTime := metav1.NewTime(now)
+	rollout.LastBatchTime = &lastBatchTime
+	rollout.Message = fmt.Sprintf("batch %d: %d outdated pods deleted", rollout.Batch, size)
+	logger.Info("Staged rollout batch started", "daemonSet.Name", ds.Name, "revision", revision, "batch", rollout.Batch, "pods", size)
+
+	return requeue, nil
+}
+
+// getDaemonSetUpdateRevision returns the controller-revision-hash of the latest ControllerRevision of the DaemonSet
+func (r *Reconciler) getDaemonSetUpdateRevision(ds *appsv1.DaemonSet) (string, error) {
+	revisionList := &appsv1.ControllerRevisionList{}
+	if err := r.client.List(context.TODO(), revisionList, client.InNamespace(ds.Namespace), client.MatchingLabels(ds.Spec.Selector.MatchLabels)); err != nil {
+		return "", err
+	}
+
+	var latest *appsv1.ControllerRevision
+	for i := range revisionList.Items {
+		revision := &revisionList.Items[i]
+		if !metav1.IsControlledBy(revision, ds) {
+			continue
+		}
+		if latest == nil || revision.Revision > latest.Revision {
+			latest = revision
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	return latest.Labels[appsv1.DefaultDaemonSetUniqueLabelKey], nil
+}
+
+// listDaemonSetPods returns the pods controlled by the DaemonSet.
+// The pods are read from the API server: listing them with the cached client would cache all the pods of the cluster.
+func (r *Reconciler) listDaemonSetPods(ds *appsv1.DaemonSet) ([]corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := r.apiReader.List(context.TODO(), podList, client.InNamespace(ds.Namespace), client.MatchingLabels(ds.Spec.Selector.MatchLabels)); err != nil {
+		return nil, err
+	}
+
+	pods := make([]corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		if metav1.IsControlledBy(&podList.Items[i], ds) {
+			pods = append(pods, podList.Items[i])
+		}
+	}
+	return pods, nil
+}
+
+// podReadySince returns whether the pod is ready, and since when
+func podReadySince(pod *corev1.Pod) (time.Time, bool) {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.LastTransitionTime.Time, condition.Status == corev1.ConditionTrue
+		}
+	}
+	return time.Time{}, false
+}
+
+func podRestartCount(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return restarts
+}
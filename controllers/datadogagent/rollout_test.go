@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+func Test_manageStagedRollout(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	selector := map[string]string{"app": "agent"}
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo-agent", Namespace: "bar", UID: "ds-uid", Generation: 2},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: selector},
+		},
+		Status: appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 4},
+	}
+	controllerRef := metav1.OwnerReference{APIVersion: "apps/v1", Kind: daemonSetKind, Name: ds.Name, UID: ds.UID, Controller: apiutils.NewBoolPointer(true)}
+
+	newRevision := func(hash string, revision int64) *appsv1.ControllerRevision {
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "foo-agent-" + hash,
+				Namespace:       "bar",
+				Labels:          map[string]string{"app": "agent", appsv1.DefaultDaemonSetUniqueLabelKey: hash},
+				OwnerReferences: []metav1.OwnerReference{controllerRef},
+			},
+			Revision: revision,
+		}
+	}
+	type podState struct {
+		hash     string
+		ready    bool
+		since    time.Duration
+		restarts int32
+	}
+	newPod := func(i int, state podState) *corev1.Pod {
+		readyStatus := corev1.ConditionFalse
+		if state.ready {
+			readyStatus = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            fmt.Sprintf("foo-agent-%d", i),
+				Namespace:       "bar",
+				Labels:          map[string]string{"app": "agent", appsv1.DefaultDaemonSetUniqueLabelKey: state.hash},
+				OwnerReferences: []metav1.OwnerReference{controllerRef},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{
+					{Type: corev1.PodReady, Status: readyStatus, LastTransitionTime: metav1.NewTime(now.Add(-state.since))},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "agent", RestartCount: state.restarts}},
+			},
+		}
+	}
+	outdated := podState{hash: "old", ready: true, since: time.Hour}
+
+	tests := []struct {
+		name        string
+		pods        []podState
+		batch       int32
+		wantPhase   datadoghqv1alpha1.StagedRolloutPhase
+		wantBatch   int32
+		wantDeleted int
+		wantRequeue bool
+	}{
+		{
+			name:        "canary batch",
+			pods:        []podState{outdated, outdated, outdated, outdated},
+			wantPhase:   datadoghqv1alpha1.StagedRolloutPhaseProgressing,
+			wantBatch:   1,
+			wantDeleted: 1,
+			wantRequeue: true,
+		},
+		{
+			name:        "updated pod not ready, wait",
+			pods:        []podState{{hash: "new"}, outdated, outdated, outdated},
+			batch:       1,
+			wantPhase:   datadoghqv1alpha1.StagedRolloutPhaseProgressing,
+			wantBatch:   1,
+			wantRequeue: true,
+		},
+		{
+			name:        "updated pod ready recently, pause",
+			pods:        []podState{{hash: "new", ready: true, since: 10 * time.Second}, outdated, outdated, outdated},
+			batch:       1,
+			wantPhase:   datadoghqv1alpha1.StagedRolloutPhaseProgressing,
+			wantBatch:   1,
+			wantRequeue: true,
+		},
+		{
+			name:        "updated pod ready, next batch",
+			pods:        []podState{{hash: "new", ready: true, since: 2 * time.Minute}, outdated, outdated, outdated},
+			batch:       1,
+			wantPhase:   datadoghqv1alpha1.StagedRolloutPhaseProgressing,
+			wantBatch:   2,
+			wantDeleted: 2,
+			wantRequeue: true,
+		},
+		{
+			name:        "updated pod restarting, halted",
+			pods:        []podState{{hash: "new", ready: true, since: 2 * time.Minute, restarts: 3}, outdated, outdated, outdated},
+			batch:       1,
+			wantPhase:   datadoghqv1alpha1.StagedRolloutPhaseHalted,
+			wantBatch:   1,
+			wantRequeue: true,
+		},
+		{
+			name: "all pods updated",
+			pods: []podState{
+				{hash: "new", ready: true, since: time.Minute},
+				{hash: "new", ready: true, since: time.Minute},
+				{hash: "new", ready: true, since: time.Minute},
+				{hash: "new", ready: true, since: time.Minute},
+			},
+			batch:     3,
+			wantPhase: datadoghqv1alpha1.StagedRolloutPhaseCompleted,
+			wantBatch: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+			dda.Spec.Agent.DeploymentStrategy.StagedRollout = &datadoghqv1alpha1.DaemonSetStagedRolloutSpec{
+				Enabled:   apiutils.NewBoolPointer(true),
+				BatchSize: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+			}
+			datadoghqv1alpha1.DefaultDaemonSetStagedRollout(dda.Spec.Agent.DeploymentStrategy.StagedRollout)
+
+			objects := []client.Object{newRevision("old", 1), newRevision("new", 2)}
+			for i, state := range tt.pods {
+				objects = append(objects, newPod(i, state))
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &Reconciler{client: fakeClient, apiReader: fakeClient, scheme: scheme.Scheme, recorder: recorder}
+
+			dsStatus := &datadoghqv1alpha1.DaemonSetStatus{
+				StagedRollout: &datadoghqv1alpha1.StagedRolloutStatus{Revision: "new", Batch: tt.batch},
+			}
+			result, err := r.manageStagedRollout(logf.Log.WithName(t.Name()), dda, ds, dsStatus, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantRequeue, result.RequeueAfter > 0)
+
+			rollout := dsStatus.StagedRollout
+			assert.Equal(t, tt.wantPhase, rollout.Phase)
+			assert.Equal(t, tt.wantBatch, rollout.Batch)
+			assert.Equal(t, int32(len(tt.pods)), rollout.TotalPods)
+
+			podList := &corev1.PodList{}
+			assert.NoError(t, fakeClient.List(context.TODO(), podList))
+			assert.Len(t, podList.Items, len(tt.pods)-tt.wantDeleted)
+			if tt.wantPhase == datadoghqv1alpha1.StagedRolloutPhaseHalted {
+				assert.Len(t, recorder.Events, 1)
+			}
+		})
+	}
+}
+
+func Test_newDaemonSetFromInstance_stagedRollout(t *testing.T) {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", nil)
+	dda.Spec.Agent.DeploymentStrategy.StagedRollout = &datadoghqv1alpha1.DaemonSetStagedRolloutSpec{
+		Enabled: apiutils.NewBoolPointer(true),
+	}
+
+	ds, _, err := newDaemonSetFromInstance(logf.Log.WithName(t.Name()), dda, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.OnDeleteDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+	assert.Nil(t, ds.Spec.UpdateStrategy.RollingUpdate)
+}
@@ -0,0 +1,274 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	openshift "github.com/DataDog/datadog-operator/pkg/openshift/v1"
+)
+
+const (
+	seccompAnnotationPrefix = "container.seccomp.security.alpha.kubernetes.io/"
+	seccompRuntimeDefault   = "runtime/default"
+)
+
+// manageAgentSecurityContextConstraints creates, updates and deletes the OpenShift SecurityContextConstraints of the Agent
+func (r *Reconciler) manageAgentSecurityContextConstraints(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
+	name := getAgentRbacResourcesName(dda)
+	if !isSecurityContextConstraintsEnabled(dda) {
+		if r.options.SupportOpenShiftSCC {
+			return reconcile.Result{}, r.cleanupResource(logger, dda, securityContextConstraintsResource(name, nil))
+		}
+		return reconcile.Result{}, nil
+	}
+
+	if !r.options.SupportOpenShiftSCC {
+		return reconcile.Result{}, fmt.Errorf("openshift security context constraints support is not enabled in the operator")
+	}
+
+	return r.manageResource(logger, dda, securityContextConstraintsResource(name, func() (*openshift.SecurityContextConstraints, error) {
+		return buildAgentSecurityContextConstraints(logger, dda, name)
+	}))
+}
+
+func (r *Reconciler) cleanupAgentSecurityContextConstraints(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) error {
+	if !r.options.SupportOpenShiftSCC {
+		return nil
+	}
+	return r.cleanupResource(logger, dda, securityContextConstraintsResource(getAgentRbacResourcesName(dda), nil))
+}
+
+func isSecurityContextConstraintsEnabled(dda *datadoghqv1alpha1.DatadogAgent) bool {
+	spec := dda.Spec.Agent
+	return apiutils.BoolValue(spec.Enabled) && spec.SecurityContextConstraints != nil && apiutils.BoolValue(spec.SecurityContextConstraints.Create)
+}
+
+// securityContextConstraintsResource describes a SecurityContextConstraints, build is only needed to apply it.
+// The SecurityContextConstraints is cluster-scoped, the DatadogAgent ownership is tracked with labels.
+// Like the Cilium policies, the OpenShift types are not registered in the scheme.
+func securityContextConstraintsResource(name string, build func() (*openshift.SecurityContextConstraints, error)) managedResource {
+	return managedResource{
+		kind:      securityContextConstraintsKind,
+		name:      name,
+		newObject: func() client.Object { return emptyUnstructuredSecurityContextConstraints() },
+		build: func() (client.Object, error) {
+			scc, err := build()
+			if err != nil {
+				return nil, err
+			}
+			obj := &unstructured.Unstructured{}
+			obj.Object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(scc)
+			if err != nil {
+				return nil, err
+			}
+			obj.SetGroupVersionKind(securityContextConstraintsGroupVersionKind())
+			return obj, nil
+		},
+		ownership: ownedByLabels,
+	}
+}
+
+func securityContextConstraintsGroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{
+		Group:   "security.openshift.io",
+		Version: "v1",
+		Kind:    "SecurityContextConstraints",
+	}
+}
+
+func emptyUnstructuredSecurityContextConstraints() *unstructured.Unstructured {
+	scc := &unstructured.Unstructured{}
+	scc.SetGroupVersionKind(securityContextConstraintsGroupVersionKind())
+
+	return scc
+}
+
+// buildAgentSecurityContextConstraints builds a SecurityContextConstraints granting only the privileges
+// required by the Agent pod, as rendered from the DatadogAgent spec.
+// AppArmor profiles are not part of the SecurityContextConstraints, OpenShift confines containers with SELinux.
+func buildAgentSecurityContextConstraints(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string) (*openshift.SecurityContextConstraints, error) {
+	template, err := newAgentPodTemplate(logger, dda, nil)
+	if err != nil {
+		return nil, err
+	}
+	podSpec := template.Spec
+
+	scc := &openshift.SecurityContextConstraints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: getDefaultLabels(dda, dda.Name, getAgentVersion(dda)),
+		},
+		AllowHostNetwork:         podSpec.HostNetwork,
+		AllowHostPID:             podSpec.HostPID,
+		AllowHostIPC:             podSpec.HostIPC,
+		AllowedCapabilities:      []corev1.Capability{},
+		DefaultAddCapabilities:   []corev1.Capability{},
+		RequiredDropCapabilities: []corev1.Capability{},
+		Volumes:                  []openshift.FSType{},
+		SELinuxContext:           openshift.SELinuxContextStrategyOptions{Type: openshift.SELinuxStrategyRunAsAny},
+		RunAsUser:                getRunAsUserStrategy(&podSpec),
+		SupplementalGroups:       openshift.SupplementalGroupsStrategyOptions{Type: openshift.SupplementalGroupsStrategyRunAsAny},
+		FSGroup:                  openshift.FSGroupStrategyOptions{Type: openshift.FSGroupStrategyRunAsAny},
+		Users:                    []string{fmt.Sprintf("system:serviceaccount:%s:%s", dda.Namespace, getAgentServiceAccount(dda))},
+		Groups:                   []string{},
+	}
+
+	if podSpec.SecurityContext != nil && podSpec.SecurityContext.SELinuxOptions != nil {
+		scc.SELinuxContext = openshift.SELinuxContextStrategyOptions{
+			Type:           openshift.SELinuxStrategyMustRunAs,
+			SELinuxOptions: podSpec.SecurityContext.SELinuxOptions.DeepCopy(),
+		}
+	}
+
+	volumes := map[openshift.FSType]bool{}
+	for _, volume := range podSpec.Volumes {
+		fsType := getVolumeFSType(volume)
+		if fsType == openshift.FSTypeHostPath {
+			scc.AllowHostDirVolumePlugin = true
+		}
+		volumes[fsType] = true
+	}
+	for fsType := range volumes {
+		scc.Volumes = append(scc.Volumes, fsType)
+	}
+	sort.Slice(scc.Volumes, func(i, j int) bool { return scc.Volumes[i] < scc.Volumes[j] })
+
+	// The privilege escalation is only allowed for the privileged containers, the containers adding capabilities,
+	// with which Kubernetes requires it, and the containers explicitly requesting it
+	allowPrivilegeEscalation := false
+	capabilities := map[corev1.Capability]bool{}
+	for _, container := range getAllContainers(&podSpec) {
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				scc.AllowHostPorts = true
+			}
+		}
+		if container.SecurityContext == nil {
+			continue
+		}
+		if apiutils.BoolValue(container.SecurityContext.Privileged) {
+			scc.AllowPrivilegedContainer = true
+			allowPrivilegeEscalation = true
+		}
+		if apiutils.BoolValue(container.SecurityContext.AllowPrivilegeEscalation) {
+			allowPrivilegeEscalation = true
+		}
+		if container.SecurityContext.Capabilities != nil {
+			for _, capability := range container.SecurityContext.Capabilities.Add {
+				capabilities[capability] = true
+				allowPrivilegeEscalation = true
+			}
+		}
+	}
+	scc.AllowPrivilegeEscalation = apiutils.NewBoolPointer(allowPrivilegeEscalation)
+	for capability := range capabilities {
+		scc.AllowedCapabilities = append(scc.AllowedCapabilities, capability)
+	}
+	sort.Slice(scc.AllowedCapabilities, func(i, j int) bool { return scc.AllowedCapabilities[i] < scc.AllowedCapabilities[j] })
+
+	scc.SeccompProfiles = getSeccompProfiles(template)
+
+	return scc, nil
+}
+
+// getRunAsUserStrategy restricts the users of the containers when they all set runAsUser, in their security context
+// or in the pod one. The containers without runAsUser run with the user of their image, any user is allowed then.
+func getRunAsUserStrategy(podSpec *corev1.PodSpec) openshift.RunAsUserStrategyOptions {
+	var podRunAsUser *int64
+	if podSpec.SecurityContext != nil {
+		podRunAsUser = podSpec.SecurityContext.RunAsUser
+	}
+
+	var minUID, maxUID *int64
+	for _, container := range getAllContainers(podSpec) {
+		runAsUser := podRunAsUser
+		if container.SecurityContext != nil && container.SecurityContext.RunAsUser != nil {
+			runAsUser = container.SecurityContext.RunAsUser
+		}
+		if runAsUser == nil {
+			return openshift.RunAsUserStrategyOptions{Type: openshift.RunAsUserStrategyRunAsAny}
+		}
+		if minUID == nil || *runAsUser < *minUID {
+			minUID = apiutils.NewInt64Pointer(*runAsUser)
+		}
+		if maxUID == nil || *runAsUser > *maxUID {
+			maxUID = apiutils.NewInt64Pointer(*runAsUser)
+		}
+	}
+
+	switch {
+	case minUID == nil:
+		return openshift.RunAsUserStrategyOptions{Type: openshift.RunAsUserStrategyRunAsAny}
+	case *minUID == *maxUID:
+		return openshift.RunAsUserStrategyOptions{Type: openshift.RunAsUserStrategyMustRunAs, UID: minUID}
+	default:
+		return openshift.RunAsUserStrategyOptions{Type: openshift.RunAsUserStrategyMustRunAsRange, UIDRangeMin: minUID, UIDRangeMax: maxUID}
+	}
+}
+
+// getSeccompProfiles returns the seccomp profiles used by the containers of the pod template.
+// The first profile is the default one applied by OpenShift to the containers without profile.
+func getSeccompProfiles(template *corev1.PodTemplateSpec) []string {
+	profiles := map[string]bool{}
+	for key, value := range template.Annotations {
+		if strings.HasPrefix(key, seccompAnnotationPrefix) && value != "" {
+			profiles[value] = true
+		}
+	}
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	seccompProfiles := []string{seccompRuntimeDefault}
+	delete(profiles, seccompRuntimeDefault)
+	for profile := range profiles {
+		seccompProfiles = append(seccompProfiles, profile)
+	}
+	sort.Strings(seccompProfiles[1:])
+
+	return seccompProfiles
+}
+
+// getVolumeFSType returns the SecurityContextConstraints volume type of a pod volume,
+// the volume types unknown to the operator are allowed with the wildcard
+func getVolumeFSType(volume corev1.Volume) openshift.FSType {
+	switch {
+	case volume.HostPath != nil:
+		return openshift.FSTypeHostPath
+	case volume.EmptyDir != nil:
+		return openshift.FSTypeEmptyDir
+	case volume.ConfigMap != nil:
+		return openshift.FSTypeConfigMap
+	case volume.Secret != nil:
+		return openshift.FSTypeSecret
+	case volume.Projected != nil:
+		return openshift.FSTypeProjected
+	case volume.DownwardAPI != nil:
+		return openshift.FSTypeDownwardAPI
+	case volume.PersistentVolumeClaim != nil:
+		return openshift.FSTypePersistentVolumeClaim
+	case volume.CSI != nil:
+		return openshift.FSTypeCSI
+	case volume.Ephemeral != nil:
+		return openshift.FSTypeEphemeral
+	default:
+		return openshift.FSTypeAll
+	}
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
+	openshift "github.com/DataDog/datadog-operator/pkg/openshift/v1"
+)
+
+func Test_buildAgentSecurityContextConstraints(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+
+	t.Run("agent only", func(t *testing.T) {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{UseEDS: false, ClusterAgentEnabled: false})
+
+		scc, err := buildAgentSecurityContextConstraints(logger, dda, "foo-agent")
+		assert.NoError(t, err)
+		assert.Equal(t, "foo-agent", scc.Name)
+		assert.True(t, isOwnerBasedOnLabels(dda, scc.Labels))
+		assert.Equal(t, []string{"system:serviceaccount:bar:foo-agent"}, scc.Users)
+		assert.True(t, scc.AllowHostDirVolumePlugin)
+		assert.Contains(t, scc.Volumes, openshift.FSTypeHostPath)
+		assert.NotContains(t, scc.Volumes, openshift.FSTypeAll)
+		assert.False(t, scc.AllowPrivilegedContainer)
+		assert.False(t, scc.AllowHostPID)
+		assert.False(t, scc.AllowHostNetwork)
+		assert.Empty(t, scc.AllowedCapabilities)
+		assert.Empty(t, scc.SeccompProfiles)
+		assert.Equal(t, openshift.SELinuxStrategyRunAsAny, scc.SELinuxContext.Type)
+		assert.False(t, *scc.AllowPrivilegeEscalation)
+		assert.Equal(t, openshift.RunAsUserStrategyOptions{Type: openshift.RunAsUserStrategyRunAsAny}, scc.RunAsUser)
+	})
+
+	t.Run("run as user", func(t *testing.T) {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{UseEDS: false, ClusterAgentEnabled: false})
+		dda.Spec.Agent.Config.SecurityContext = &corev1.PodSecurityContext{RunAsUser: apiutils.NewInt64Pointer(1000)}
+
+		scc, err := buildAgentSecurityContextConstraints(logger, dda, "foo-agent")
+		assert.NoError(t, err)
+		assert.Equal(t, openshift.RunAsUserStrategyOptions{
+			Type: openshift.RunAsUserStrategyMustRunAs,
+			UID:  apiutils.NewInt64Pointer(1000),
+		}, scc.RunAsUser)
+	})
+
+	t.Run("system-probe and compliance", func(t *testing.T) {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{
+			UseEDS:              false,
+			ClusterAgentEnabled: false,
+			SystemProbeEnabled:  true,
+		})
+		dda.Spec.Agent.Security = &datadoghqv1alpha1.SecuritySpec{
+			Compliance: datadoghqv1alpha1.ComplianceSpec{Enabled: apiutils.NewBoolPointer(true)},
+		}
+		dda.Spec.Agent.Config.SecurityContext = &corev1.PodSecurityContext{
+			SELinuxOptions: &corev1.SELinuxOptions{User: "system_u", Role: "system_r", Type: "spc_t", Level: "s0"},
+		}
+
+		scc, err := buildAgentSecurityContextConstraints(logger, dda, "foo-agent")
+		assert.NoError(t, err)
+		assert.True(t, scc.AllowHostPID)
+		assert.Contains(t, scc.AllowedCapabilities, corev1.Capability("SYS_ADMIN"))
+		assert.Contains(t, scc.AllowedCapabilities, corev1.Capability("AUDIT_CONTROL"))
+		assert.Equal(t, []string{"runtime/default", "localhost/system-probe"}, scc.SeccompProfiles)
+		assert.Equal(t, openshift.SELinuxStrategyMustRunAs, scc.SELinuxContext.Type)
+		assert.Equal(t, "spc_t", scc.SELinuxContext.SELinuxOptions.Type)
+		// Kubernetes requires the privilege escalation with the added capabilities
+		assert.True(t, *scc.AllowPrivilegeEscalation)
+	})
+}
+
+func Test_getRunAsUserStrategy(t *testing.T) {
+	containerRunAsUser := func(name string, uid *int64) corev1.Container {
+		return corev1.Container{Name: name, SecurityContext: &corev1.SecurityContext{RunAsUser: uid}}
+	}
+
+	tests := []struct {
+		name    string
+		podSpec *corev1.PodSpec
+		want    openshift.RunAsUserStrategyOptions
+	}{
+		{
+			name:    "image user",
+			podSpec: &corev1.PodSpec{Containers: []corev1.Container{{Name: "agent"}}},
+			want:    openshift.RunAsUserStrategyOptions{Type: openshift.RunAsUserStrategyRunAsAny},
+		},
+		{
+			name: "one container with the image user",
+			podSpec: &corev1.PodSpec{Containers: []corev1.Container{
+				containerRunAsUser("agent", apiutils.NewInt64Pointer(0)),
+				{Name: "trace-agent"},
+			}},
+			want: openshift.RunAsUserStrategyOptions{Type: openshift.RunAsUserStrategyRunAsAny},
+		},
+		{
+			name: "pod user overridden by a container",
+			podSpec: &corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: apiutils.NewInt64Pointer(1000)},
+				InitContainers:  []corev1.Container{containerRunAsUser("init-config", apiutils.NewInt64Pointer(0))},
+				Containers:      []corev1.Container{{Name: "agent"}},
+			},
+			want: openshift.RunAsUserStrategyOptions{
+				Type:        openshift.RunAsUserStrategyMustRunAsRange,
+				UIDRangeMin: apiutils.NewInt64Pointer(0),
+				UIDRangeMax: apiutils.NewInt64Pointer(1000),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getRunAsUserStrategy(tt.podSpec))
+		})
+	}
+}
+
+func Test_manageAgentSecurityContextConstraints(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{UseEDS: false, ClusterAgentEnabled: false})
+	dda.Spec.Agent.SecurityContextConstraints = &datadoghqv1alpha1.SecurityContextConstraintsConfig{Create: apiutils.NewBoolPointer(true)}
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{})
+	fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithScheme(s).Build())
+	r := &Reconciler{client: fakeClient, scheme: s, recorder: record.NewFakeRecorder(10)}
+
+	// The operator must be allowed to manage SecurityContextConstraints
+	_, err := r.manageAgentSecurityContextConstraints(logger, dda)
+	assert.Error(t, err)
+
+	r.options.SupportOpenShiftSCC = true
+	_, err = r.manageAgentSecurityContextConstraints(logger, dda)
+	assert.NoError(t, err)
+	scc := emptyUnstructuredSecurityContextConstraints()
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "foo-agent"}, scc))
+	users, _, _ := unstructured.NestedStringSlice(scc.Object, "users")
+	assert.Equal(t, []string{"system:serviceaccount:bar:foo-agent"}, users)
+
+	// The SecurityContextConstraints is deleted when its creation is disabled
+	dda.Spec.Agent.SecurityContextConstraints.Create = apiutils.NewBoolPointer(false)
+	_, err = r.manageAgentSecurityContextConstraints(logger, dda)
+	assert.NoError(t, err)
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "foo-agent"}, emptyUnstructuredSecurityContextConstraints())
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -6,60 +6,26 @@
 package datadogagent
 
 import (
-	"context"
-
 	"github.com/go-logr/logr"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/pkg/version"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
-	"github.com/DataDog/datadog-operator/pkg/controller/utils/comparison"
-	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
-	"github.com/DataDog/datadog-operator/pkg/kubernetes"
 	"github.com/DataDog/datadog-operator/pkg/utils"
 	apiregistrationv1 "k8s.io/kube-aggregator/pkg/apis/apiregistration/v1"
 )
 
-func (r *Reconciler) manageClusterAgentService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	if !isClusterAgentEnabled(dda.Spec.ClusterAgent) {
-		return r.cleanupClusterAgentService(dda)
-	}
-
-	serviceName := getClusterAgentServiceName(dda)
-	service := &corev1.Service{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dda.Namespace, Name: serviceName}, service)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.createClusterAgentService(logger, dda)
-		}
-		return reconcile.Result{}, err
-	}
-
-	return r.updateIfNeededClusterAgentService(logger, dda, service)
-}
-
-func (r *Reconciler) createClusterAgentService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	newService := newClusterAgentService(dda)
-	return r.createService(logger, dda, newService)
-}
-
-func (r *Reconciler) updateIfNeededClusterAgentService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, currentService *corev1.Service) (reconcile.Result, error) {
-	newService := newClusterAgentService(dda)
-	return r.updateIfNeededService(logger, dda, currentService, newService)
-}
+type serviceBuilder func(dda *datadoghqv1alpha1.DatadogAgent) *corev1.Service
 
-func (r *Reconciler) cleanupClusterAgentService(dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	serviceName := getClusterAgentServiceName(dda)
-	return cleanupService(r.client, serviceName, dda.Namespace, dda)
+func (r *Reconciler) manageClusterAgentService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
+	cleanUpCondition := !isClusterAgentEnabled(dda.Spec.ClusterAgent)
+	return r.manageService(logger, dda, getClusterAgentServiceName(dda), newClusterAgentService, cleanUpCondition)
 }
 
 func newClusterAgentService(dda *datadoghqv1alpha1.DatadogAgent) *corev1.Service {
@@ -89,63 +55,22 @@ func newClusterAgentService(dda *datadoghqv1alpha1.DatadogAgent) *corev1.Service
 			SessionAffinity: corev1.ServiceAffinityNone,
 		},
 	}
-	_, _ = comparison.SetMD5DatadogAgentGenerationAnnotation(&service.ObjectMeta, &service.Spec)
-
 	return service
 }
 
 func (r *Reconciler) manageMetricsServerService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	if !isMetricsProviderEnabled(dda.Spec.ClusterAgent) {
-		return r.cleanupMetricsServerService(dda)
-	}
-
-	serviceName := getMetricsServerServiceName(dda)
-	service := &corev1.Service{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dda.Namespace, Name: serviceName}, service)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.createMetricsServerService(logger, dda)
-		}
-		return reconcile.Result{}, err
-	}
-
-	return r.updateIfNeededMetricsServerService(logger, dda, service)
+	cleanUpCondition := !isMetricsProviderEnabled(dda.Spec.ClusterAgent)
+	return r.manageService(logger, dda, getMetricsServerServiceName(dda), newMetricsServerService, cleanUpCondition)
 }
 
 func (r *Reconciler) manageMetricsServerAPIService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	if !isMetricsProviderEnabled(dda.Spec.ClusterAgent) {
-		return r.cleanupMetricsServerAPIService(logger, dda)
-	}
-
-	apiServiceName := getMetricsServerAPIServiceName()
-	apiService := &apiregistrationv1.APIService{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: apiServiceName}, apiService)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.createMetricsServerAPIService(logger, dda)
-		}
-		return reconcile.Result{}, err
-	}
-
-	return r.updateIfNeededMetricsServerAPIService(logger, dda, apiService)
+	cleanUpCondition := !isMetricsProviderEnabled(dda.Spec.ClusterAgent)
+	return r.manageResource(logger, dda, metricsServerAPIServiceResource(dda, cleanUpCondition))
 }
 
 func (r *Reconciler) manageAdmissionControllerService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	if !isAdmissionControllerEnabled(dda.Spec.ClusterAgent) {
-		return r.cleanupAdmissionControllerService(dda)
-	}
-
-	serviceName := getAdmissionControllerServiceName(dda)
-	service := &corev1.Service{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dda.Namespace, Name: serviceName}, service)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.createAdmissionControllerService(logger, dda)
-		}
-		return reconcile.Result{}, err
-	}
-
-	return r.updateIfNeededAdmissionControllerService(logger, dda, service)
+	cleanUpCondition := !isAdmissionControllerEnabled(dda.Spec.ClusterAgent)
+	return r.manageService(logger, dda, getAdmissionControllerServiceName(dda), newAdmissionControllerService, cleanUpCondition)
 }
 
 var testGitVersion string
@@ -158,197 +83,37 @@ func (r *Reconciler) manageAgentService(logger logr.Logger, dda *datadoghqv1alph
 	}
 
 	forceLocalServiceEnable := dda.Spec.Agent.LocalService != nil && dda.Spec.Agent.LocalService.ForceLocalServiceEnable != nil && *dda.Spec.Agent.LocalService.ForceLocalServiceEnable
-	if !utils.IsAboveMinVersion(gitVersion, "1.22-0") && !forceLocalServiceEnable {
-		return r.cleanupAgentService(dda)
-	}
-
-	serviceName := getAgentServiceName(dda)
-	service := &corev1.Service{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: dda.Namespace, Name: serviceName}, service)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return r.createAgentService(logger, dda)
-		}
-		return reconcile.Result{}, err
-	}
-
-	return r.updateIfNeededAgentService(logger, dda, service)
-}
-
-func (r *Reconciler) createMetricsServerService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	newService := newMetricsServerService(dda)
-	return r.createService(logger, dda, newService)
-}
-
-func (r *Reconciler) createMetricsServerAPIService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	newAPIService := newMetricsServerAPIService(dda)
-	return r.createAPIService(logger, dda, newAPIService)
-}
-
-func (r *Reconciler) createAdmissionControllerService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	newService := newAdmissionControllerService(dda)
-	return r.createService(logger, dda, newService)
-}
-
-func (r *Reconciler) createAgentService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	newService := newAgentService(dda)
-	return r.createService(logger, dda, newService)
-}
-
-func (r *Reconciler) cleanupMetricsServerService(dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	serviceName := getMetricsServerServiceName(dda)
-	return cleanupService(r.client, serviceName, dda.Namespace, dda)
+	cleanUpCondition := !utils.IsAboveMinVersion(gitVersion, "1.22-0") && !forceLocalServiceEnable
+	return r.manageService(logger, dda, getAgentServiceName(dda), newAgentService, cleanUpCondition)
 }
 
 func (r *Reconciler) cleanupMetricsServerAPIService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	apiServiceName := getMetricsServerAPIServiceName()
-	return r.cleanupAPIService(logger, apiServiceName, dda)
-}
-
-func (r *Reconciler) cleanupAdmissionControllerService(dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	serviceName := getAdmissionControllerServiceName(dda)
-	return cleanupService(r.client, serviceName, dda.Namespace, dda)
-}
-
-func (r *Reconciler) cleanupAgentService(dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	serviceName := getAgentServiceName(dda)
-	return cleanupService(r.client, serviceName, dda.Namespace, dda)
-}
-
-func (r *Reconciler) updateIfNeededMetricsServerService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, currentService *corev1.Service) (reconcile.Result, error) {
-	newService := newMetricsServerService(dda)
-	return r.updateIfNeededService(logger, dda, currentService, newService)
-}
-
-func (r *Reconciler) updateIfNeededMetricsServerAPIService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, currentAPIService *apiregistrationv1.APIService) (reconcile.Result, error) {
-	newAPIService := newMetricsServerAPIService(dda)
-	return r.updateIfNeededAPIService(logger, dda, currentAPIService, newAPIService)
-}
-
-func (r *Reconciler) updateIfNeededAdmissionControllerService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, currentService *corev1.Service) (reconcile.Result, error) {
-	newService := newAdmissionControllerService(dda)
-	return r.updateIfNeededService(logger, dda, currentService, newService)
-}
-
-func (r *Reconciler) updateIfNeededAgentService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, currentService *corev1.Service) (reconcile.Result, error) {
-	newService := newAgentService(dda)
-	return r.updateIfNeededService(logger, dda, currentService, newService)
-}
-
-func (r *Reconciler) createService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newService *corev1.Service) (reconcile.Result, error) {
-	if err := controllerutil.SetControllerReference(dda, newService, r.scheme); err != nil {
-		return reconcile.Result{}, err
-	}
-	if err := r.client.Create(context.TODO(), newService); err != nil {
-		return reconcile.Result{}, err
-	}
-	logger.Info("Created Service", "name", newService.Name)
-	event := buildEventInfo(newService.Name, newService.Namespace, serviceKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-
-	return reconcile.Result{Requeue: true}, nil
-}
-
-func (r *Reconciler) createAPIService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newAPIService *apiregistrationv1.APIService) (reconcile.Result, error) {
-	if err := SetOwnerReference(dda, newAPIService, r.scheme); err != nil {
-		return reconcile.Result{}, err
-	}
-	if err := r.client.Create(context.TODO(), newAPIService); err != nil {
-		logger.Error(err, "failed to create APIService", "name", newAPIService.Name)
-		return reconcile.Result{}, err
-	}
-	logger.Info("Created APIService", "name", newAPIService.Name)
-	event := buildEventInfo(newAPIService.Name, newAPIService.Namespace, apiServiceKind, datadog.CreationEvent)
-	r.recordEvent(dda, event)
-
-	return reconcile.Result{Requeue: true}, nil
-}
-
-func cleanupService(client client.Client, name, namespace string, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	service := &corev1.Service{}
-	err := client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, service)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return reconcile.Result{}, nil
-		}
-		return reconcile.Result{}, err
-	}
-	if !CheckOwnerReference(dda, service) {
-		return reconcile.Result{}, nil
-	}
-
-	err = client.Delete(context.TODO(), service)
-	return reconcile.Result{}, err
+	return reconcile.Result{}, r.cleanupResource(logger, dda, metricsServerAPIServiceResource(dda, true))
 }
 
-func (r *Reconciler) cleanupAPIService(logger logr.Logger, name string, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
-	apiService := &apiregistrationv1.APIService{}
-	err := r.client.Get(context.TODO(), types.NamespacedName{Name: name}, apiService)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return reconcile.Result{}, nil
-		}
-		return reconcile.Result{}, err
-	}
-
-	// if the apiService object is not owner by the DatadogAgent resource
-	// do not delete it.
-	if !CheckOwnerReference(dda, apiService) {
-		return reconcile.Result{}, nil
-	}
-
-	err = r.client.Delete(context.TODO(), apiService)
-	if err != nil {
-		logger.Error(err, "failed to delete APIService", "name", name)
-	}
-	logger.Info("Deleted APIService", "name", name)
-	return reconcile.Result{}, err
-}
-
-func (r *Reconciler) updateIfNeededService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, currentService, newService *corev1.Service) (reconcile.Result, error) {
-	result := reconcile.Result{}
-	hash := newService.Annotations[datadoghqv1alpha1.MD5AgentDeploymentAnnotationKey]
-	if !comparison.IsSameSpecMD5Hash(hash, currentService.GetAnnotations()) {
-		updatedService := currentService.DeepCopy()
-		updatedService.Labels = newService.Labels
-		updatedService.Annotations = newService.Annotations
-		updatedService.Spec = newService.Spec
-		// ClusterIP is an immutable field
-		updatedService.Spec.ClusterIP = currentService.Spec.ClusterIP
-
-		if err := kubernetes.UpdateFromObject(context.TODO(), r.client, newService, currentService.ObjectMeta); err != nil {
-			return reconcile.Result{}, err
-		}
-		event := buildEventInfo(updatedService.Name, updatedService.Namespace, serviceKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
-		logger.Info("Update Service", "name", newService.Name)
-
-		result.Requeue = true
-	}
-
-	return result, nil
+func (r *Reconciler) manageService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, name string, builder serviceBuilder, cleanUp bool) (reconcile.Result, error) {
+	return r.manageResource(logger, dda, managedResource{
+		kind:      serviceKind,
+		name:      name,
+		namespace: dda.Namespace,
+		newObject: func() client.Object { return &corev1.Service{} },
+		build:     func() (client.Object, error) { return builder(dda), nil },
+		ownership: ownedByController,
+		cleanup:   cleanUp,
+	})
 }
 
-func (r *Reconciler) updateIfNeededAPIService(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, currentAPIService, newAPIService *apiregistrationv1.APIService) (reconcile.Result, error) {
-	result := reconcile.Result{}
-	hash := newAPIService.Annotations[datadoghqv1alpha1.MD5AgentDeploymentAnnotationKey]
-	if !comparison.IsSameSpecMD5Hash(hash, currentAPIService.GetAnnotations()) {
-		updatedAPIService := currentAPIService.DeepCopy()
-		updatedAPIService.Labels = newAPIService.Labels
-		updatedAPIService.Annotations = newAPIService.Annotations
-		updatedAPIService.Spec = newAPIService.Spec
-
-		if err := kubernetes.UpdateFromObject(context.TODO(), r.client, newAPIService, currentAPIService.ObjectMeta); err != nil {
-			return reconcile.Result{}, err
-		}
-		event := buildEventInfo(updatedAPIService.Name, updatedAPIService.Namespace, apiServiceKind, datadog.UpdateEvent)
-		r.recordEvent(dda, event)
-		logger.Info("Update APIService", "name", newAPIService.Name)
-
-		result.Requeue = true
+// metricsServerAPIServiceResource describes the APIService of the external metrics server.
+// APIServices are cluster-scoped, the DatadogAgent is set as owner reference.
+func metricsServerAPIServiceResource(dda *datadoghqv1alpha1.DatadogAgent, cleanUp bool) managedResource {
+	return managedResource{
+		kind:      apiServiceKind,
+		name:      getMetricsServerAPIServiceName(),
+		newObject: func() client.Object { return &apiregistrationv1.APIService{} },
+		build:     func() (client.Object, error) { return newMetricsServerAPIService(dda), nil },
+		ownership: ownedByReference,
+		cleanup:   cleanUp,
 	}
-
-	return result, nil
 }
 
 func newMetricsServerService(dda *datadoghqv1alpha1.DatadogAgent) *corev1.Service {
@@ -378,8 +143,6 @@ func newMetricsServerService(dda *datadoghqv1alpha1.DatadogAgent) *corev1.Servic
 			SessionAffinity: corev1.ServiceAffinityNone,
 		},
 	}
-	_, _ = comparison.SetMD5DatadogAgentGenerationAnnotation(&service.ObjectMeta, &service.Spec)
-
 	return service
 }
 
@@ -407,8 +170,6 @@ func newMetricsServerAPIService(dda *datadoghqv1alpha1.DatadogAgent) *apiregistr
 			VersionPriority:       100,
 		},
 	}
-	_, _ = comparison.SetMD5DatadogAgentGenerationAnnotation(&apiService.ObjectMeta, &apiService.Spec)
-
 	return apiService
 }
 
@@ -436,8 +197,6 @@ func newAdmissionControllerService(dda *datadoghqv1alpha1.DatadogAgent) *corev1.
 			SessionAffinity: corev1.ServiceAffinityNone,
 		},
 	}
-	_, _ = comparison.SetMD5DatadogAgentGenerationAnnotation(&service.ObjectMeta, &service.Spec)
-
 	return service
 }
 
@@ -459,6 +218,7 @@ func newAgentService(dda *datadoghqv1alpha1.DatadogAgent) *corev1.Service {
 			},
 			Ports: []corev1.ServicePort{
 				{
+					Name:       "dogstatsdport",
 					Protocol:   corev1.ProtocolUDP,
 					TargetPort: intstr.FromInt(datadoghqv1alpha1.DefaultDogstatsdPort),
 					Port:       datadoghqv1alpha1.DefaultDogstatsdPort,
@@ -472,13 +232,22 @@ func newAgentService(dda *datadoghqv1alpha1.DatadogAgent) *corev1.Service {
 	if isAPMEnabled(&dda.Spec) {
 		service.Spec.Ports = append(service.Spec.Ports,
 			corev1.ServicePort{
+				Name:       "traceport",
 				Protocol:   corev1.ProtocolTCP,
 				TargetPort: intstr.FromInt(int(*dda.Spec.Agent.Apm.HostPort)),
 				Port:       *dda.Spec.Agent.Apm.HostPort,
 			})
 	}
 
-	_, _ = comparison.SetMD5DatadogAgentGenerationAnnotation(&service.ObjectMeta, &service.Spec)
+	for _, port := range getOTLPContainerPorts(dda) {
+		service.Spec.Ports = append(service.Spec.Ports,
+			corev1.ServicePort{
+				Name:       port.Name,
+				Protocol:   corev1.ProtocolTCP,
+				TargetPort: intstr.FromInt(int(port.ContainerPort)),
+				Port:       port.ContainerPort,
+			})
+	}
 
 	return service
 }
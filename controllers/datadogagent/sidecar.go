@@ -0,0 +1,460 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	"github.com/DataDog/datadog-operator/pkg/kubernetes"
+)
+
+const (
+	// sidecarContainerName is the name of the injected Agent container, it differs from the DaemonSet container
+	// name to avoid conflicting with the application containers
+	sidecarContainerName = "datadog-agent"
+	// sidecarVolumePrefix prefixes the names of the injected volumes, for the same reason
+	sidecarVolumePrefix = "datadog-"
+	sidecarSuffix       = "sidecar"
+	// sidecarTokenVolumeName is the volume of the service account token of the Agent sidecar
+	sidecarTokenVolumeName = sidecarVolumePrefix + "sidecar-token"
+	// serviceAccountTokenMountPath is where the Kubernetes clients look for the service account token
+	serviceAccountTokenMountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+func isSidecarInjectionEnabled(dda *datadoghqv1alpha1.DatadogAgent) bool {
+	return apiutils.BoolValue(dda.Spec.Agent.Enabled) && dda.Spec.Agent.SidecarInjection != nil && apiutils.BoolValue(dda.Spec.Agent.SidecarInjection.Enabled)
+}
+
+// getSidecarInjectionNamespaces returns the namespaces where the Agent sidecar is injected
+func getSidecarInjectionNamespaces(dda *datadoghqv1alpha1.DatadogAgent) []string {
+	if len(dda.Spec.Agent.SidecarInjection.Namespaces) == 0 {
+		return []string{dda.Namespace}
+	}
+	return dda.Spec.Agent.SidecarInjection.Namespaces
+}
+
+// shouldInjectAgentSidecar returns true if the Agent sidecar of the DatadogAgent must be injected into the pod:
+// the pod is created in one of the injection namespaces, and either its nodeSelector targets serverless nodes
+// or it has the sidecar profile label.
+func shouldInjectAgentSidecar(dda *datadoghqv1alpha1.DatadogAgent, pod *corev1.Pod) bool {
+	if !isSidecarInjectionEnabled(dda) {
+		return false
+	}
+
+	inNamespace := false
+	for _, namespace := range getSidecarInjectionNamespaces(dda) {
+		if namespace == pod.Namespace {
+			inNamespace = true
+			break
+		}
+	}
+	if !inNamespace {
+		return false
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name == sidecarContainerName {
+			// Already injected
+			return false
+		}
+	}
+
+	config := dda.Spec.Agent.SidecarInjection
+	if config.Profile != nil && pod.Labels[datadoghqv1alpha1.AgentSidecarProfileLabelKey] == *config.Profile {
+		return true
+	}
+	if len(config.NodeSelector) == 0 {
+		return false
+	}
+	for key, value := range config.NodeSelector {
+		if podValue, found := pod.Spec.NodeSelector[key]; !found || podValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// getAgentSidecar returns the Agent sidecar container and its volumes. The container is built like the Agent DaemonSet
+// container, without the volumes mounted from the host that are not available on serverless nodes. The Kubelet is
+// reached through the APIServer proxy.
+func getAgentSidecar(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (*corev1.Container, []corev1.Volume, error) {
+	image := mirrorImage(getImage(dda.Spec.Agent.Image, dda.Spec.Registry), dda.Spec.ImagePolicy)
+	container, err := getAgentContainer(logger, dda, image)
+	if err != nil {
+		return nil, nil, err
+	}
+	container.Name = sidecarContainerName
+
+	if dda.Spec.Agent.SidecarInjection.Resources != nil {
+		container.Resources = *dda.Spec.Agent.SidecarInjection.Resources
+	}
+
+	for i := range container.Ports {
+		container.Ports[i].HostPort = 0
+	}
+
+	envVars := make([]corev1.EnvVar, 0, len(container.Env)+3)
+	for _, envVar := range container.Env {
+		if envVar.Name == datadoghqv1alpha1.DDKubeletHost {
+			continue
+		}
+		envVars = append(envVars, envVar)
+	}
+	envVars = append(envVars,
+		corev1.EnvVar{
+			Name:  datadoghqv1alpha1.DDEKSFargate,
+			Value: strconv.FormatBool(true),
+		},
+		corev1.EnvVar{
+			Name: datadoghqv1alpha1.DDKubeletNodeName,
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: FieldPathSpecNodeName,
+				},
+			},
+		},
+	)
+	if isClusterAgentEnabled(dda.Spec.ClusterAgent) {
+		// The Cluster Agent service environment variables are only set in the pods of its namespace
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  datadoghqv1alpha1.DDClusterAgentURL,
+			Value: fmt.Sprintf("https://%s.%s.svc:%d", getClusterAgentServiceName(dda), dda.Namespace, datadoghqv1alpha1.DefaultClusterAgentServicePort),
+		})
+	}
+	container.Env = envVars
+
+	volumes := []corev1.Volume{}
+	volumeNames := map[string]bool{}
+	for _, volume := range getVolumesForAgent(dda) {
+		if volume.HostPath != nil {
+			continue
+		}
+		volume = *volume.DeepCopy()
+		volumeNames[volume.Name] = true
+		volume.Name = sidecarVolumePrefix + volume.Name
+		// The ConfigMaps may not exist outside of the DatadogAgent namespace, they must not prevent the pod from starting
+		if volume.ConfigMap != nil {
+			volume.ConfigMap.Optional = apiutils.NewBoolPointer(true)
+		}
+		volumes = append(volumes, volume)
+	}
+
+	volumeMounts := []corev1.VolumeMount{}
+	for _, volumeMount := range container.VolumeMounts {
+		if !volumeNames[volumeMount.Name] {
+			continue
+		}
+		volumeMount.Name = sidecarVolumePrefix + volumeMount.Name
+		volumeMounts = append(volumeMounts, volumeMount)
+	}
+	container.VolumeMounts = volumeMounts
+
+	// When the RBACs are created by the operator, the token of the Agent sidecar service account is only mounted into
+	// the sidecar container: the service account of the pod, used by the application containers, is left untouched
+	if isCreateRBACEnabled(dda.Spec.Agent.Rbac) {
+		volumes = append(volumes, corev1.Volume{
+			Name: sidecarTokenVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: getSidecarTokenSecretName(dda),
+					Items: []corev1.KeyToPath{
+						{Key: corev1.ServiceAccountTokenKey, Path: corev1.ServiceAccountTokenKey},
+						{Key: corev1.ServiceAccountRootCAKey, Path: corev1.ServiceAccountRootCAKey},
+						{Key: corev1.ServiceAccountNamespaceKey, Path: corev1.ServiceAccountNamespaceKey},
+					},
+				},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      sidecarTokenVolumeName,
+			MountPath: serviceAccountTokenMountPath,
+			ReadOnly:  true,
+		})
+	}
+
+	return container, volumes, nil
+}
+
+// injectAgentSidecar adds the Agent sidecar container and its volumes to the pod. When the RBACs are not created by
+// the operator, the sidecar uses the token of the pod service account: the ServiceAccount admission plugin runs
+// before the webhook, the token mounted into the application containers is mounted into the sidecar too.
+func injectAgentSidecar(pod *corev1.Pod, container *corev1.Container, volumes []corev1.Volume) {
+	container = container.DeepCopy()
+	if !hasVolumeMount(container.VolumeMounts, serviceAccountTokenMountPath) {
+		for _, podContainer := range pod.Spec.Containers {
+			for _, volumeMount := range podContainer.VolumeMounts {
+				if volumeMount.MountPath == serviceAccountTokenMountPath {
+					container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+					break
+				}
+			}
+			if hasVolumeMount(container.VolumeMounts, serviceAccountTokenMountPath) {
+				break
+			}
+		}
+	}
+
+	pod.Spec.Containers = append(pod.Spec.Containers, *container)
+	pod.Spec.Volumes = append(pod.Spec.Volumes, volumes...)
+}
+
+func hasVolumeMount(volumeMounts []corev1.VolumeMount, mountPath string) bool {
+	for _, volumeMount := range volumeMounts {
+		if volumeMount.MountPath == mountPath {
+			return true
+		}
+	}
+	return false
+}
+
+// getSidecarSecretReferences returns the keys of the Secrets referenced by the Agent sidecar, indexed by Secret name.
+// The optional references are ignored, an empty key list means that the whole Secret is referenced.
+func getSidecarSecretReferences(container *corev1.Container, volumes []corev1.Volume) map[string][]string {
+	references := map[string][]string{}
+	for _, envVar := range container.Env {
+		if envVar.ValueFrom == nil || envVar.ValueFrom.SecretKeyRef == nil || apiutils.BoolValue(envVar.ValueFrom.SecretKeyRef.Optional) {
+			continue
+		}
+		ref := envVar.ValueFrom.SecretKeyRef
+		references[ref.Name] = append(references[ref.Name], ref.Key)
+	}
+	for _, volume := range volumes {
+		if volume.Secret == nil || apiutils.BoolValue(volume.Secret.Optional) {
+			continue
+		}
+		name := volume.Secret.SecretName
+		if _, found := references[name]; !found {
+			references[name] = nil
+		}
+		for _, item := range volume.Secret.Items {
+			references[name] = append(references[name], item.Key)
+		}
+	}
+
+	return references
+}
+
+// manageSidecarInjectionRBACs creates, updates and deletes the service accounts of the Agent sidecar in the namespaces
+// where it is injected, their token Secrets, and the RBACs granting them the Kubelet access
+func (r *Reconciler) manageSidecarInjectionRBACs(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
+	rbacResourcesName := getSidecarInjectionRbacResourcesName(dda)
+	agentVersion := getAgentVersion(dda)
+	enabled := isSidecarInjectionEnabled(dda) && isCreateRBACEnabled(dda.Spec.Agent.Rbac)
+
+	if result, err := r.manageClusterRole(logger, dda, rbacResourcesName, agentVersion, buildSidecarInjectionClusterRole, !enabled); err != nil {
+		return result, err
+	}
+
+	desiredBindings := map[string]bool{}
+	desiredNamespaces := map[string]bool{}
+	if enabled {
+		for _, namespace := range getSidecarInjectionNamespaces(dda) {
+			desiredNamespaces[namespace] = true
+			if result, err := r.manageResource(logger, dda, sidecarServiceAccountResource(dda, namespace, agentVersion)); err != nil {
+				return result, err
+			}
+			if result, err := r.manageResource(logger, dda, sidecarTokenSecretResource(dda, namespace, agentVersion)); err != nil {
+				return result, err
+			}
+
+			name := getSidecarInjectionClusterRoleBindingName(dda, namespace)
+			desiredBindings[name] = true
+			res := clusterRoleBindingResource(name, func() *rbacv1.ClusterRoleBinding {
+				return buildSidecarInjectionClusterRoleBinding(dda, name, namespace, agentVersion)
+			})
+			if result, err := r.manageResource(logger, dda, res); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// Delete the bindings and the service accounts of the namespaces removed from the configuration
+	if err := r.cleanupSidecarInjectionClusterRoleBindings(logger, dda, desiredBindings); err != nil {
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, r.cleanupSidecarServiceAccounts(logger, dda, desiredNamespaces)
+}
+
+// cleanupSidecarInjectionRbacResources deletes the ClusterRole, the ClusterRoleBindings and the service accounts of the Agent sidecar
+func (r *Reconciler) cleanupSidecarInjectionRbacResources(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (reconcile.Result, error) {
+	if err := r.cleanupSidecarInjectionClusterRoleBindings(logger, dda, nil); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.cleanupSidecarServiceAccounts(logger, dda, nil); err != nil {
+		return reconcile.Result{}, err
+	}
+	return r.cleanupClusterRole(logger, dda, getSidecarInjectionRbacResourcesName(dda))
+}
+
+// cleanupSidecarInjectionClusterRoleBindings deletes the ClusterRoleBindings of the Agent sidecar that are not kept
+func (r *Reconciler) cleanupSidecarInjectionClusterRoleBindings(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, keep map[string]bool) error {
+	bindingList := &rbacv1.ClusterRoleBindingList{}
+	if err := r.client.List(context.TODO(), bindingList, client.MatchingLabels{
+		kubernetes.AppKubernetesInstanceLabelKey: getSidecarInjectionRbacResourcesName(dda),
+		kubernetes.AppKubernetesPartOfLabelKey:   NewPartOfLabelValue(dda).String(),
+	}); err != nil {
+		return err
+	}
+	for _, binding := range bindingList.Items {
+		if keep[binding.Name] {
+			continue
+		}
+		if _, err := r.cleanupClusterRoleBinding(logger, dda, binding.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanupSidecarServiceAccounts deletes the service accounts of the Agent sidecar, and their token Secrets, in the
+// namespaces that are not kept
+func (r *Reconciler) cleanupSidecarServiceAccounts(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, keep map[string]bool) error {
+	serviceAccountList := &corev1.ServiceAccountList{}
+	if err := r.client.List(context.TODO(), serviceAccountList, client.MatchingLabels{
+		kubernetes.AppKubernetesInstanceLabelKey: getSidecarInjectionRbacResourcesName(dda),
+		kubernetes.AppKubernetesPartOfLabelKey:   NewPartOfLabelValue(dda).String(),
+	}); err != nil {
+		return err
+	}
+	for _, serviceAccount := range serviceAccountList.Items {
+		if keep[serviceAccount.Namespace] {
+			continue
+		}
+		if err := r.cleanupResource(logger, dda, sidecarTokenSecretResource(dda, serviceAccount.Namespace, "")); err != nil {
+			return err
+		}
+		if err := r.cleanupResource(logger, dda, sidecarServiceAccountResource(dda, serviceAccount.Namespace, "")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sidecarServiceAccountResource describes the service account of the Agent sidecar in one of the injection namespaces.
+// The namespace can differ from the DatadogAgent namespace, the ownership is tracked with labels.
+func sidecarServiceAccountResource(dda *datadoghqv1alpha1.DatadogAgent, namespace, version string) managedResource {
+	name := getSidecarInjectionRbacResourcesName(dda)
+	return managedResource{
+		kind:      serviceAccountKind,
+		name:      name,
+		namespace: namespace,
+		newObject: func() client.Object { return &corev1.ServiceAccount{} },
+		build: func() (client.Object, error) {
+			return &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:    getDefaultLabels(dda, name, version),
+					Name:      name,
+					Namespace: namespace,
+				},
+			}, nil
+		},
+		ownership: ownedByLabels,
+	}
+}
+
+// sidecarTokenSecretResource describes the token Secret of the Agent sidecar service account in one of the injection
+// namespaces, the token is generated by Kubernetes
+func sidecarTokenSecretResource(dda *datadoghqv1alpha1.DatadogAgent, namespace, version string) managedResource {
+	name := getSidecarTokenSecretName(dda)
+	return managedResource{
+		kind:      secretKind,
+		name:      name,
+		namespace: namespace,
+		newObject: func() client.Object { return &corev1.Secret{} },
+		build: func() (client.Object, error) {
+			return &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: getDefaultLabels(dda, getSidecarInjectionRbacResourcesName(dda), version),
+					Annotations: map[string]string{
+						corev1.ServiceAccountNameKey: getSidecarInjectionRbacResourcesName(dda),
+					},
+					Name:      name,
+					Namespace: namespace,
+				},
+				Type: corev1.SecretTypeServiceAccountToken,
+			}, nil
+		},
+		ownership:         ownedByLabels,
+		recreateOnInvalid: true,
+	}
+}
+
+func getSidecarInjectionRbacResourcesName(dda *datadoghqv1alpha1.DatadogAgent) string {
+	return fmt.Sprintf("%s-%s-%s", dda.Name, datadoghqv1alpha1.DefaultAgentResourceSuffix, sidecarSuffix)
+}
+
+func getSidecarTokenSecretName(dda *datadoghqv1alpha1.DatadogAgent) string {
+	return fmt.Sprintf("%s-token", getSidecarInjectionRbacResourcesName(dda))
+}
+
+func getSidecarInjectionClusterRoleBindingName(dda *datadoghqv1alpha1.DatadogAgent, namespace string) string {
+	return fmt.Sprintf("%s-%s", getSidecarInjectionRbacResourcesName(dda), namespace)
+}
+
+// buildSidecarInjectionClusterRole creates the ClusterRole of the Agent sidecar, limited to the Kubelet access
+func buildSidecarInjectionClusterRole(dda *datadoghqv1alpha1.DatadogAgent, name, version string) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: getDefaultLabels(dda, name, version),
+			Name:   name,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{datadoghqv1alpha1.CoreAPIGroup},
+				Resources: []string{datadoghqv1alpha1.NodesResource},
+				Verbs:     []string{datadoghqv1alpha1.GetVerb},
+			},
+			{
+				// Kubelet connectivity through the APIServer proxy
+				APIGroups: []string{datadoghqv1alpha1.CoreAPIGroup},
+				Resources: []string{
+					datadoghqv1alpha1.NodeMetricsResource,
+					datadoghqv1alpha1.NodeSpecResource,
+					datadoghqv1alpha1.NodeProxyResource,
+					datadoghqv1alpha1.NodeStats,
+				},
+				Verbs: []string{datadoghqv1alpha1.GetVerb},
+			},
+		},
+	}
+}
+
+// buildSidecarInjectionClusterRoleBinding binds the sidecar ClusterRole to the service account of the Agent sidecar
+// in a namespace
+func buildSidecarInjectionClusterRoleBinding(dda *datadoghqv1alpha1.DatadogAgent, name, namespace, version string) *rbacv1.ClusterRoleBinding {
+	rbacResourcesName := getSidecarInjectionRbacResourcesName(dda)
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: getDefaultLabels(dda, rbacResourcesName, version),
+			Name:   name,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: datadoghqv1alpha1.RbacAPIGroup,
+			Kind:     datadoghqv1alpha1.ClusterRoleKind,
+			Name:     rbacResourcesName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      rbacResourcesName,
+				Namespace: namespace,
+			},
+		},
+	}
+}
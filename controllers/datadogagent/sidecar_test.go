@@ -0,0 +1,335 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
+	"github.com/DataDog/datadog-operator/pkg/kubernetes"
+)
+
+func newSidecarInjectionDatadogAgent(config *datadoghqv1alpha1.AgentSidecarInjectionConfig) *datadoghqv1alpha1.DatadogAgent {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true})
+	dda.Spec.Agent.SidecarInjection = config
+	datadoghqv1alpha1.DefaultAgentSidecarInjection(dda.Spec.Agent.SidecarInjection)
+	return dda
+}
+
+func newSidecarTestPod(namespace string, labels, nodeSelector map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			NodeSelector: nodeSelector,
+			Containers: []corev1.Container{
+				{Name: "app", Image: "app:latest"},
+			},
+		},
+	}
+}
+
+func Test_shouldInjectAgentSidecar(t *testing.T) {
+	fargateNodeSelector := map[string]string{"eks.amazonaws.com/compute-type": "fargate"}
+	profileLabels := map[string]string{datadoghqv1alpha1.AgentSidecarProfileLabelKey: "fargate"}
+
+	tests := []struct {
+		name   string
+		config *datadoghqv1alpha1.AgentSidecarInjectionConfig
+		pod    *corev1.Pod
+		want   bool
+	}{
+		{
+			name:   "injection disabled",
+			config: &datadoghqv1alpha1.AgentSidecarInjectionConfig{},
+			pod:    newSidecarTestPod("bar", nil, fargateNodeSelector),
+			want:   false,
+		},
+		{
+			name:   "pod selected by its nodeSelector",
+			config: &datadoghqv1alpha1.AgentSidecarInjectionConfig{Enabled: apiutils.NewBoolPointer(true)},
+			pod:    newSidecarTestPod("bar", nil, fargateNodeSelector),
+			want:   true,
+		},
+		{
+			name:   "pod selected by the profile label",
+			config: &datadoghqv1alpha1.AgentSidecarInjectionConfig{Enabled: apiutils.NewBoolPointer(true)},
+			pod:    newSidecarTestPod("bar", profileLabels, nil),
+			want:   true,
+		},
+		{
+			name:   "pod not running on serverless nodes",
+			config: &datadoghqv1alpha1.AgentSidecarInjectionConfig{Enabled: apiutils.NewBoolPointer(true)},
+			pod:    newSidecarTestPod("bar", nil, map[string]string{"eks.amazonaws.com/compute-type": "ec2"}),
+			want:   false,
+		},
+		{
+			name:   "pod outside of the DatadogAgent namespace",
+			config: &datadoghqv1alpha1.AgentSidecarInjectionConfig{Enabled: apiutils.NewBoolPointer(true)},
+			pod:    newSidecarTestPod("other", profileLabels, nil),
+			want:   false,
+		},
+		{
+			name: "pod in one of the configured namespaces",
+			config: &datadoghqv1alpha1.AgentSidecarInjectionConfig{
+				Enabled:    apiutils.NewBoolPointer(true),
+				Namespaces: []string{"other"},
+			},
+			pod:  newSidecarTestPod("other", profileLabels, nil),
+			want: true,
+		},
+		{
+			name: "custom profile",
+			config: &datadoghqv1alpha1.AgentSidecarInjectionConfig{
+				Enabled: apiutils.NewBoolPointer(true),
+				Profile: apiutils.NewStringPointer("virtual-kubelet"),
+			},
+			pod:  newSidecarTestPod("bar", profileLabels, nil),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dda := newSidecarInjectionDatadogAgent(tt.config)
+			assert.Equal(t, tt.want, shouldInjectAgentSidecar(dda, tt.pod))
+		})
+	}
+}
+
+func Test_getAgentSidecar(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	resources := &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+	}
+	dda := newSidecarInjectionDatadogAgent(&datadoghqv1alpha1.AgentSidecarInjectionConfig{
+		Enabled:   apiutils.NewBoolPointer(true),
+		Resources: resources,
+	})
+	dda.Spec.Agent.Config.HostPort = apiutils.NewInt32Pointer(8125)
+
+	container, volumes, err := getAgentSidecar(logger, dda)
+	assert.NoError(t, err)
+	assert.Equal(t, sidecarContainerName, container.Name)
+	assert.Equal(t, *resources, container.Resources)
+	for _, port := range container.Ports {
+		assert.Zero(t, port.HostPort)
+	}
+
+	envVars := map[string]corev1.EnvVar{}
+	for _, envVar := range container.Env {
+		envVars[envVar.Name] = envVar
+	}
+	assert.Equal(t, "true", envVars[datadoghqv1alpha1.DDEKSFargate].Value)
+	assert.Equal(t, FieldPathSpecNodeName, envVars[datadoghqv1alpha1.DDKubeletNodeName].ValueFrom.FieldRef.FieldPath)
+	assert.Equal(t, "https://foo-cluster-agent.bar.svc:5005", envVars[datadoghqv1alpha1.DDClusterAgentURL].Value)
+	assert.NotContains(t, envVars, datadoghqv1alpha1.DDKubeletHost)
+
+	// The host volumes are not available on serverless nodes
+	volumeNames := map[string]bool{}
+	for _, volume := range volumes {
+		assert.Nil(t, volume.HostPath, "volume %s", volume.Name)
+		assert.Contains(t, volume.Name, sidecarVolumePrefix)
+		if volume.ConfigMap != nil {
+			assert.True(t, *volume.ConfigMap.Optional)
+		}
+		volumeNames[volume.Name] = true
+	}
+	assert.NotEmpty(t, container.VolumeMounts)
+	for _, volumeMount := range container.VolumeMounts {
+		assert.True(t, volumeNames[volumeMount.Name], "volume mount %s", volumeMount.Name)
+	}
+
+	// The token of the sidecar service account is only mounted into the sidecar
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: sidecarTokenVolumeName, MountPath: serviceAccountTokenMountPath, ReadOnly: true})
+	assert.Equal(t, []string{corev1.ServiceAccountTokenKey, corev1.ServiceAccountRootCAKey, corev1.ServiceAccountNamespaceKey}, getSidecarSecretReferences(container, volumes)["foo-agent-sidecar-token"])
+}
+
+func Test_injectAgentSidecar(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	tokenMount := corev1.VolumeMount{Name: "kube-api-access", MountPath: serviceAccountTokenMountPath, ReadOnly: true}
+	newPod := func() *corev1.Pod {
+		pod := newSidecarTestPod("bar", nil, nil)
+		pod.Spec.ServiceAccountName = "app"
+		pod.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{tokenMount}
+		return pod
+	}
+
+	// The sidecar mounts the token of its own service account, the pod service account is kept
+	dda := newSidecarInjectionDatadogAgent(&datadoghqv1alpha1.AgentSidecarInjectionConfig{Enabled: apiutils.NewBoolPointer(true)})
+	container, volumes, err := getAgentSidecar(logger, dda)
+	assert.NoError(t, err)
+	pod := newPod()
+	injectAgentSidecar(pod, container, volumes)
+	assert.Equal(t, "app", pod.Spec.ServiceAccountName)
+	assert.Equal(t, []corev1.VolumeMount{tokenMount}, pod.Spec.Containers[0].VolumeMounts)
+	assert.Contains(t, pod.Spec.Containers[1].VolumeMounts, corev1.VolumeMount{Name: sidecarTokenVolumeName, MountPath: serviceAccountTokenMountPath, ReadOnly: true})
+	assert.NotContains(t, pod.Spec.Containers[1].VolumeMounts, tokenMount)
+
+	// Without the RBACs of the operator, the sidecar uses the token of the pod service account
+	dda.Spec.Agent.Rbac.Create = apiutils.NewBoolPointer(false)
+	container, volumes, err = getAgentSidecar(logger, dda)
+	assert.NoError(t, err)
+	pod = newPod()
+	injectAgentSidecar(pod, container, volumes)
+	assert.Contains(t, pod.Spec.Containers[1].VolumeMounts, tokenMount)
+	for _, volume := range pod.Spec.Volumes {
+		assert.NotEqual(t, sidecarTokenVolumeName, volume.Name)
+	}
+}
+
+func Test_SidecarInjector_Handle(t *testing.T) {
+	s := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(s))
+	assert.NoError(t, datadoghqv1alpha1.AddToScheme(s))
+
+	dda := newSidecarInjectionDatadogAgent(&datadoghqv1alpha1.AgentSidecarInjectionConfig{Enabled: apiutils.NewBoolPointer(true)})
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(dda).Build()
+	injector, err := NewSidecarInjector(fakeClient, fakeClient, s, logf.Log.WithName(t.Name()))
+	assert.NoError(t, err)
+	profileLabels := map[string]string{datadoghqv1alpha1.AgentSidecarProfileLabelKey: "fargate"}
+
+	newRequest := func(pod *corev1.Pod) admission.Request {
+		raw, marshalErr := json.Marshal(pod)
+		assert.NoError(t, marshalErr)
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Namespace: "bar",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	// The pod is not injected while the Secrets of the sidecar are missing in its namespace
+	resp := injector.Handle(context.TODO(), newRequest(newSidecarTestPod("", profileLabels, nil)))
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+	assert.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "missing Secrets in namespace bar")
+
+	container, volumes, err := getAgentSidecar(logf.Log.WithName(t.Name()), dda)
+	assert.NoError(t, err)
+	references := getSidecarSecretReferences(container, volumes)
+	assert.NotEmpty(t, references)
+	for name, keys := range references {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "bar"}, Data: map[string][]byte{}}
+		for _, key := range keys {
+			secret.Data[key] = []byte("secret")
+		}
+		assert.NoError(t, fakeClient.Create(context.TODO(), secret))
+	}
+
+	// The pod is injected, its service account is not changed
+	pod := newSidecarTestPod("", profileLabels, nil)
+	pod.Spec.ServiceAccountName = "app"
+	resp = injector.Handle(context.TODO(), newRequest(pod))
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Warnings)
+	injected := false
+	for _, patch := range resp.Patches {
+		assert.NotEqual(t, "/metadata/namespace", patch.Path)
+		assert.NotEqual(t, "/spec/serviceAccountName", patch.Path)
+		if patch.Path == "/spec/containers/1" {
+			injected = true
+			assert.Equal(t, sidecarContainerName, patch.Value.(map[string]interface{})["name"])
+		}
+	}
+	assert.True(t, injected, "the Agent sidecar is not injected")
+
+	// The pod is not selected
+	resp = injector.Handle(context.TODO(), newRequest(newSidecarTestPod("", nil, nil)))
+	assert.True(t, resp.Allowed)
+	assert.Empty(t, resp.Patches)
+}
+
+func Test_manageSidecarInjectionRBACs(t *testing.T) {
+	dda := newSidecarInjectionDatadogAgent(&datadoghqv1alpha1.AgentSidecarInjectionConfig{
+		Enabled:    apiutils.NewBoolPointer(true),
+		Namespaces: []string{"ns1", "ns2"},
+	})
+
+	staleBinding := buildSidecarInjectionClusterRoleBinding(dda, getSidecarInjectionClusterRoleBindingName(dda, "ns3"), "ns3", getAgentVersion(dda))
+	staleServiceAccount, _ := sidecarServiceAccountResource(dda, "ns3", getAgentVersion(dda)).build()
+	staleToken, _ := sidecarTokenSecretResource(dda, "ns3", getAgentVersion(dda)).build()
+	fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithObjects(staleBinding, staleServiceAccount, staleToken).Build())
+	r := newReconcilerForRbacTests(fakeClient)
+
+	_, err := r.manageSidecarInjectionRBACs(logf.Log.WithName(t.Name()), dda)
+	assert.NoError(t, err)
+
+	clusterRole := &rbacv1.ClusterRole{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "foo-agent-sidecar"}, clusterRole))
+	assert.Equal(t, "bar-foo", clusterRole.Labels[kubernetes.AppKubernetesPartOfLabelKey])
+
+	for _, namespace := range []string{"ns1", "ns2"} {
+		binding := &rbacv1.ClusterRoleBinding{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "foo-agent-sidecar-" + namespace}, binding))
+		assert.Equal(t, "foo-agent-sidecar", binding.RoleRef.Name)
+		assert.Equal(t, []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      "foo-agent-sidecar",
+			Namespace: namespace,
+		}}, binding.Subjects)
+
+		serviceAccount := &corev1.ServiceAccount{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "foo-agent-sidecar"}, serviceAccount))
+		assert.Equal(t, "bar-foo", serviceAccount.Labels[kubernetes.AppKubernetesPartOfLabelKey])
+
+		token := &corev1.Secret{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: "foo-agent-sidecar-token"}, token))
+		assert.Equal(t, corev1.SecretTypeServiceAccountToken, token.Type)
+		assert.Equal(t, "foo-agent-sidecar", token.Annotations[corev1.ServiceAccountNameKey])
+	}
+
+	// The binding and the service account of the namespace removed from the configuration are deleted
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "foo-agent-sidecar-ns3"}, &rbacv1.ClusterRoleBinding{})
+	assert.True(t, apierrors.IsNotFound(err))
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "ns3", Name: "foo-agent-sidecar"}, &corev1.ServiceAccount{})
+	assert.True(t, apierrors.IsNotFound(err))
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Namespace: "ns3", Name: "foo-agent-sidecar-token"}, &corev1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	// Everything is deleted when the injection is disabled
+	dda.Spec.Agent.SidecarInjection.Enabled = apiutils.NewBoolPointer(false)
+	_, err = r.manageSidecarInjectionRBACs(logf.Log.WithName(t.Name()), dda)
+	assert.NoError(t, err)
+	bindingList := &rbacv1.ClusterRoleBindingList{}
+	assert.NoError(t, fakeClient.List(context.TODO(), bindingList))
+	assert.Empty(t, bindingList.Items)
+	serviceAccountList := &corev1.ServiceAccountList{}
+	assert.NoError(t, fakeClient.List(context.TODO(), serviceAccountList))
+	assert.Empty(t, serviceAccountList.Items)
+	secretList := &corev1.SecretList{}
+	assert.NoError(t, fakeClient.List(context.TODO(), secretList))
+	assert.Empty(t, secretList.Items)
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "foo-agent-sidecar"}, &rbacv1.ClusterRole{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+// SidecarInjectionWebhookPath is the path of the admission webhook injecting the Agent sidecar
+const SidecarInjectionWebhookPath = "/mutate-v1-pod-agent-sidecar"
+
+// The webhook marker doesn't support selectors, the namespaceSelector and the objectSelector of the webhook are set by
+// config/webhook/selector_patch.yaml.
+// +kubebuilder:webhook:path=/mutate-v1-pod-agent-sidecar,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=agent-sidecar.datadoghq.com,admissionReviewVersions=v1
+
+// SidecarInjector is the admission handler injecting the Agent sidecar into the pods running on serverless nodes.
+// The pods are matched against the DatadogAgents with the sidecar injection enabled, the first matching DatadogAgent
+// ordered by namespace and name is used.
+type SidecarInjector struct {
+	client client.Client
+	// apiReader reads the Secrets of the injection namespaces from the API server, to avoid caching all the Secrets of
+	// the cluster
+	apiReader client.Reader
+	decoder   *admission.Decoder
+	log       logr.Logger
+}
+
+// NewSidecarInjector returns a new SidecarInjector
+func NewSidecarInjector(client client.Client, apiReader client.Reader, scheme *runtime.Scheme, log logr.Logger) (*SidecarInjector, error) {
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SidecarInjector{
+		client:    client,
+		apiReader: apiReader,
+		decoder:   decoder,
+		log:       log,
+	}, nil
+}
+
+// Handle implements admission.Handler
+func (s *SidecarInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := s.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// The namespace of a pod created by a controller is only set in the request, it is restored before computing the
+	// patch so that only the sidecar is added
+	podNamespace := pod.Namespace
+	if pod.Namespace == "" {
+		pod.Namespace = req.Namespace
+	}
+	namespace := pod.Namespace
+
+	dda, err := s.getInjectingDatadogAgent(ctx, pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if dda == nil {
+		return admission.Allowed("no Agent sidecar to inject")
+	}
+
+	logger := s.log.WithValues("datadogagent", fmt.Sprintf("%s/%s", dda.Namespace, dda.Name), "namespace", namespace, "pod", pod.GenerateName+pod.Name)
+	pod.Namespace = podNamespace
+	container, volumes, err := getAgentSidecar(logger, dda)
+	if err != nil {
+		// The pod creation must not be blocked by the monitoring
+		logger.Error(err, "Unable to build the Agent sidecar")
+		return admission.Allowed("unable to build the Agent sidecar")
+	}
+
+	// The pod would not start without the Secrets referenced by the Agent sidecar, including its service account token
+	missing, err := s.getMissingSecrets(ctx, namespace, getSidecarSecretReferences(container, volumes))
+	if err != nil {
+		logger.Error(err, "Unable to check the Secrets of the Agent sidecar")
+		return admission.Allowed("unable to check the Secrets of the Agent sidecar")
+	}
+	if len(missing) > 0 {
+		logger.Info("Not injecting the Agent sidecar, its Secrets are missing in the namespace", "secrets", missing)
+		return admission.Allowed("the Secrets of the Agent sidecar are missing").
+			WithWarnings(fmt.Sprintf("Datadog Agent sidecar not injected: missing Secrets in namespace %s: %s", namespace, strings.Join(missing, ", ")))
+	}
+
+	injectAgentSidecar(pod, container, volumes)
+
+	marshaled, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	logger.V(1).Info("Injecting the Agent sidecar")
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// getMissingSecrets returns the Secrets, or the Secret keys, that don't exist in the namespace
+func (s *SidecarInjector) getMissingSecrets(ctx context.Context, namespace string, references map[string][]string) ([]string, error) {
+	missing := []string{}
+	for name, keys := range references {
+		secret := &corev1.Secret{}
+		if err := s.apiReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, secret); err != nil {
+			if errors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+			return nil, err
+		}
+		for _, key := range keys {
+			if _, found := secret.Data[key]; !found {
+				missing = append(missing, fmt.Sprintf("%s/%s", name, key))
+			}
+		}
+	}
+	sort.Strings(missing)
+
+	return missing, nil
+}
+
+// getInjectingDatadogAgent returns the defaulted DatadogAgent whose Agent sidecar must be injected into the pod,
+// or nil if the pod must not be injected
+func (s *SidecarInjector) getInjectingDatadogAgent(ctx context.Context, pod *corev1.Pod) (*datadoghqv1alpha1.DatadogAgent, error) {
+	ddaList := &datadoghqv1alpha1.DatadogAgentList{}
+	if err := s.client.List(ctx, ddaList); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ddaList.Items, func(i, j int) bool {
+		if ddaList.Items[i].Namespace != ddaList.Items[j].Namespace {
+			return ddaList.Items[i].Namespace < ddaList.Items[j].Namespace
+		}
+		return ddaList.Items[i].Name < ddaList.Items[j].Name
+	})
+
+	for i := range ddaList.Items {
+		dda := &ddaList.Items[i]
+		if dda.Spec.Agent.SidecarInjection == nil || dda.DeletionTimestamp != nil {
+			continue
+		}
+		if err := datadoghqv1alpha1.IsValidDatadogAgent(&dda.Spec); err != nil {
+			continue
+		}
+		datadoghqv1alpha1.DefaultDatadogAgent(dda)
+		if shouldInjectAgentSidecar(dda, pod) {
+			return dda, nil
+		}
+	}
+
+	return nil, nil
+}
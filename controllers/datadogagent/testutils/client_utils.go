@@ -0,0 +1,440 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package testutils_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// DefaultFieldManager is the field manager of the creates, updates and non-apply patches sent without field manager
+const DefaultFieldManager = "manager"
+
+// NewFakeClient returns a fake client supporting server-side apply patches, initialized with initObjs
+func NewFakeClient(initObjs ...runtime.Object) client.Client {
+	return NewFakeClientWithScheme(scheme.Scheme, initObjs...)
+}
+
+// NewFakeClientWithScheme returns a fake client supporting server-side apply patches, initialized with initObjs
+func NewFakeClientWithScheme(s *runtime.Scheme, initObjs ...runtime.Object) client.Client {
+	return WithApplySupport(fake.NewFakeClientWithScheme(s, initObjs...))
+}
+
+// WithApplySupport wraps a fake client to support server-side apply patches, which are not supported
+// by the controller-runtime fake client.
+// The field ownership is tracked in the managedFields of the objects, as the API server does, with
+// a simplified model: lists are atomic and the fields of the initial objects are not owned.
+// - creates, updates and non-apply patches give the ownership of the fields they change to their field manager
+// - an apply fails with a conflict when it changes the value of a field owned by another field manager,
+//   unless it is forced, in which case it takes over the field
+// - an apply removes the fields its field manager applied previously and no longer applies, when they
+//   are not owned by another field manager
+func WithApplySupport(c client.Client) client.Client {
+	return &applyClient{Client: c}
+}
+
+type applyClient struct {
+	client.Client
+}
+
+// managerKey identifies a managedFields entry
+type managerKey struct {
+	manager   string
+	operation metav1.ManagedFieldsOperationType
+}
+
+// fieldSet is a set of leaf field paths, a path is the list of the keys leading to the field
+type fieldSet map[string][]string
+
+// Create records the ownership of the created fields
+func (c *applyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	createOptions := &client.CreateOptions{}
+	createOptions.ApplyOptions(opts)
+
+	content, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	owners := map[managerKey]fieldSet{}
+	owners[managerKey{manager: fieldManagerOrDefault(createOptions.FieldManager), operation: metav1.ManagedFieldsOperationUpdate}] = leafFields(content)
+	if err = setManagedFields(obj, owners); err != nil {
+		return err
+	}
+
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// Update records the ownership of the updated fields
+func (c *applyClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	updateOptions := &client.UpdateOptions{}
+	updateOptions.ApplyOptions(opts)
+
+	current, _ := obj.DeepCopyObject().(client.Object)
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return c.Client.Update(ctx, obj, opts...)
+	}
+	if err := c.recordUpdate(current, obj, fieldManagerOrDefault(updateOptions.FieldManager)); err != nil {
+		return err
+	}
+
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+// Patch emulates server-side apply patches, other patches are handled by the wrapped client
+func (c *applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	patchOptions := &client.PatchOptions{}
+	patchOptions.ApplyOptions(opts)
+	if patch.Type() != types.ApplyPatchType {
+		return c.patch(ctx, obj, patch, patchOptions, opts...)
+	}
+
+	// dry-run apply patches are emulated with dry-run creates and updates
+	var createOptions []client.CreateOption
+	var updateOptions []client.UpdateOption
+	if len(patchOptions.DryRun) > 0 {
+		createOptions = append(createOptions, client.DryRunAll)
+		updateOptions = append(updateOptions, client.DryRunAll)
+	}
+
+	manager := managerKey{manager: fieldManagerOrDefault(patchOptions.FieldManager), operation: metav1.ManagedFieldsOperationApply}
+	force := patchOptions.Force != nil && *patchOptions.Force
+
+	applied, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	appliedFields := leafFields(applied)
+
+	current, _ := obj.DeepCopyObject().(client.Object)
+	if err = c.Client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		if apierrors.IsNotFound(err) {
+			if err = setManagedFields(obj, map[managerKey]fieldSet{manager: appliedFields}); err != nil {
+				return err
+			}
+			return c.Client.Create(ctx, obj, createOptions...)
+		}
+		return err
+	}
+
+	currentContent, err := toUnstructured(current)
+	if err != nil {
+		return err
+	}
+	owners, err := getManagedFields(current)
+	if err != nil {
+		return err
+	}
+
+	// Fields owned by another field manager with a different value are conflicts
+	var causes []metav1.StatusCause
+	for key, path := range appliedFields {
+		if apiequality.Semantic.DeepEqual(fieldValue(currentContent, path), fieldValue(applied, path)) {
+			continue
+		}
+		for owner, fields := range owners {
+			if owner == manager {
+				continue
+			}
+			if _, owned := fields[key]; !owned {
+				continue
+			}
+			if force {
+				delete(fields, key)
+				continue
+			}
+			causes = append(causes, metav1.StatusCause{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: fmt.Sprintf("conflict with %q: %s", owner.manager, formatPath(path)),
+				Field:   formatPath(path),
+			})
+		}
+	}
+	if len(causes) > 0 {
+		sort.Slice(causes, func(i, j int) bool { return causes[i].Message < causes[j].Message })
+		return apierrors.NewApplyConflict(causes, fmt.Sprintf("Apply failed with %d conflicts", len(causes)))
+	}
+
+	merged := runtime.DeepCopyJSON(currentContent)
+	// The fields no longer applied are removed, unless another field manager owns them
+	for key, path := range owners[manager] {
+		if _, found := appliedFields[key]; found || isOwnedByAnother(owners, manager, key) {
+			continue
+		}
+		unstructured.RemoveNestedField(merged, path...)
+	}
+	delete(applied, "apiVersion")
+	delete(applied, "kind")
+	delete(applied, "status")
+	mergeApplied(merged, applied)
+	owners[manager] = appliedFields
+
+	previousOwners, err := getManagedFields(current)
+	if err != nil {
+		return err
+	}
+	if apiequality.Semantic.DeepEqual(merged, currentContent) && reflect.DeepEqual(compactOwners(owners), compactOwners(previousOwners)) {
+		return fromUnstructured(currentContent, obj)
+	}
+	if err = fromUnstructured(merged, obj); err != nil {
+		return err
+	}
+	if err = setManagedFields(obj, owners); err != nil {
+		return err
+	}
+
+	return c.Client.Update(ctx, obj, updateOptions...)
+}
+
+// patch applies a non-apply patch and records the ownership of the patched fields
+func (c *applyClient) patch(ctx context.Context, obj client.Object, patch client.Patch, patchOptions *client.PatchOptions, opts ...client.PatchOption) error {
+	current, _ := obj.DeepCopyObject().(client.Object)
+	if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil || len(patchOptions.DryRun) > 0 {
+		return err
+	}
+	if err := c.recordUpdate(current, obj, fieldManagerOrDefault(patchOptions.FieldManager)); err != nil {
+		return err
+	}
+
+	return c.Client.Update(ctx, obj)
+}
+
+// recordUpdate gives the ownership of the fields changed between current and obj to manager, in the managedFields of obj
+func (c *applyClient) recordUpdate(current, obj client.Object, manager string) error {
+	currentContent, err := toUnstructured(current)
+	if err != nil {
+		return err
+	}
+	content, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	currentFields := leafFields(currentContent)
+	fields := leafFields(content)
+	owners, err := getManagedFields(current)
+	if err != nil {
+		return err
+	}
+
+	updater := managerKey{manager: manager, operation: metav1.ManagedFieldsOperationUpdate}
+	if owners[updater] == nil {
+		owners[updater] = fieldSet{}
+	}
+	for key, path := range fields {
+		if _, found := currentFields[key]; found && apiequality.Semantic.DeepEqual(fieldValue(currentContent, path), fieldValue(content, path)) {
+			continue
+		}
+		for _, ownedFields := range owners {
+			delete(ownedFields, key)
+		}
+		owners[updater][key] = path
+	}
+	for key := range currentFields {
+		if _, found := fields[key]; !found {
+			for _, ownedFields := range owners {
+				delete(ownedFields, key)
+			}
+		}
+	}
+
+	return setManagedFields(obj, owners)
+}
+
+func isOwnedByAnother(owners map[managerKey]fieldSet, manager managerKey, key string) bool {
+	for owner, fields := range owners {
+		if _, found := fields[key]; found && owner != manager {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeApplied merges the applied fields into dst: maps are merged, other values are replaced
+func mergeApplied(dst, applied map[string]interface{}) {
+	for key, value := range applied {
+		if value == nil {
+			continue
+		}
+		if appliedMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok {
+				mergeApplied(dstMap, appliedMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+// trackedMetadataFields are the metadata fields whose ownership is tracked
+var trackedMetadataFields = map[string]bool{
+	"labels":          true,
+	"annotations":     true,
+	"ownerReferences": true,
+	"finalizers":      true,
+}
+
+// leafFields returns the leaf fields of an object, excluding the type, status and server-set metadata.
+// Lists, scalars and empty maps are leaves.
+func leafFields(content map[string]interface{}) fieldSet {
+	fields := fieldSet{}
+	for key, value := range content {
+		switch key {
+		case "apiVersion", "kind", "status":
+		case "metadata":
+			metadata, _ := value.(map[string]interface{})
+			for metadataKey, metadataValue := range metadata {
+				if trackedMetadataFields[metadataKey] {
+					addLeafFields(fields, []string{key, metadataKey}, metadataValue)
+				}
+			}
+		default:
+			addLeafFields(fields, []string{key}, value)
+		}
+	}
+	return fields
+}
+
+func addLeafFields(fields fieldSet, path []string, value interface{}) {
+	if value == nil {
+		return
+	}
+	if m, ok := value.(map[string]interface{}); ok && len(m) > 0 {
+		for key, child := range m {
+			addLeafFields(fields, append(append([]string{}, path...), key), child)
+		}
+		return
+	}
+	fields[pathKey(path)] = path
+}
+
+func fieldValue(content map[string]interface{}, path []string) interface{} {
+	value, _, _ := unstructured.NestedFieldNoCopy(content, path...)
+	return value
+}
+
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+func formatPath(path []string) string {
+	return "." + strings.Join(path, ".")
+}
+
+// getManagedFields returns the fields owned by each field manager, decoded from the FieldsV1 managedFields
+func getManagedFields(obj client.Object) (map[managerKey]fieldSet, error) {
+	owners := map[managerKey]fieldSet{}
+	for _, entry := range obj.GetManagedFields() {
+		fields := fieldSet{}
+		if entry.FieldsV1 != nil {
+			tree := map[string]interface{}{}
+			if err := json.Unmarshal(entry.FieldsV1.Raw, &tree); err != nil {
+				return nil, err
+			}
+			decodeFieldsV1(fields, nil, tree)
+		}
+		owners[managerKey{manager: entry.Manager, operation: entry.Operation}] = fields
+	}
+	return owners, nil
+}
+
+func decodeFieldsV1(fields fieldSet, path []string, tree map[string]interface{}) {
+	for key, child := range tree {
+		childPath := append(append([]string{}, path...), strings.TrimPrefix(key, "f:"))
+		if childTree, ok := child.(map[string]interface{}); ok && len(childTree) > 0 {
+			decodeFieldsV1(fields, childPath, childTree)
+			continue
+		}
+		fields[pathKey(childPath)] = childPath
+	}
+}
+
+// setManagedFields encodes the fields owned by each field manager in the FieldsV1 managedFields of obj
+func setManagedFields(obj client.Object, owners map[managerKey]fieldSet) error {
+	var entries []metav1.ManagedFieldsEntry
+	for owner, fields := range compactOwners(owners) {
+		tree := map[string]interface{}{}
+		for _, path := range fields {
+			node := tree
+			for _, key := range path {
+				child, ok := node["f:"+key].(map[string]interface{})
+				if !ok {
+					child = map[string]interface{}{}
+					node["f:"+key] = child
+				}
+				node = child
+			}
+		}
+		raw, err := json.Marshal(tree)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, metav1.ManagedFieldsEntry{
+			Manager:    owner.manager,
+			Operation:  owner.operation,
+			APIVersion: obj.GetObjectKind().GroupVersionKind().GroupVersion().String(),
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: raw},
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Manager != entries[j].Manager {
+			return entries[i].Manager < entries[j].Manager
+		}
+		return entries[i].Operation < entries[j].Operation
+	})
+	obj.SetManagedFields(entries)
+
+	return nil
+}
+
+// compactOwners returns the field managers owning at least one field
+func compactOwners(owners map[managerKey]fieldSet) map[managerKey]fieldSet {
+	compacted := map[managerKey]fieldSet{}
+	for owner, fields := range owners {
+		if len(fields) > 0 {
+			compacted[owner] = fields
+		}
+	}
+	return compacted
+}
+
+func fieldManagerOrDefault(manager string) string {
+	if manager == "" {
+		return DefaultFieldManager
+	}
+	return manager
+}
+
+func toUnstructured(obj client.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return runtime.DeepCopyJSON(u.UnstructuredContent()), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+func fromUnstructured(content map[string]interface{}, obj client.Object) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		u.SetUnstructuredContent(runtime.DeepCopyJSON(content))
+		return nil
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, obj)
+}
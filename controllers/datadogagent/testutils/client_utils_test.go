@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package testutils_test
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/DataDog/datadog-operator/pkg/kubernetes"
+)
+
+func newConfigMap(labels, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "bar",
+			Name:      "foo",
+			Labels:    labels,
+		},
+		Data: data,
+	}
+}
+
+func getConfigMap(t *testing.T, c client.Client) *corev1.ConfigMap {
+	cm := &corev1.ConfigMap{}
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "bar", Name: "foo"}, cm))
+	return cm
+}
+
+func Test_applyClient_removesFieldsNoLongerApplied(t *testing.T) {
+	c := NewFakeClient()
+
+	result, err := kubernetes.Apply(context.TODO(), c, newConfigMap(map[string]string{"app": "foo", "team": "web"}, map[string]string{"a": "1", "b": "2"}))
+	assert.NoError(t, err)
+	assert.Equal(t, kubernetes.ApplyOperationCreated, result.Operation)
+
+	result, err = kubernetes.Apply(context.TODO(), c, newConfigMap(map[string]string{"app": "foo"}, map[string]string{"a": "1"}))
+	assert.NoError(t, err)
+	assert.Equal(t, kubernetes.ApplyOperationUpdated, result.Operation)
+
+	cm := getConfigMap(t, c)
+	assert.Equal(t, map[string]string{"app": "foo"}, cm.Labels)
+	assert.Equal(t, map[string]string{"a": "1"}, cm.Data)
+
+	result, err = kubernetes.Apply(context.TODO(), c, newConfigMap(map[string]string{"app": "foo"}, map[string]string{"a": "1"}))
+	assert.NoError(t, err)
+	assert.Equal(t, kubernetes.ApplyOperationNone, result.Operation)
+}
+
+func Test_applyClient_keepsFieldsOfOtherManagers(t *testing.T) {
+	c := NewFakeClient()
+
+	_, err := kubernetes.Apply(context.TODO(), c, newConfigMap(map[string]string{"app": "foo", "shared": "true"}, map[string]string{"a": "1"}))
+	assert.NoError(t, err)
+
+	// Another field manager adds a label and sets a label applied by the operator to the same value
+	cm := getConfigMap(t, c)
+	cm.Labels["mesh"] = "injected"
+	cm.Labels["shared"] = "false"
+	assert.NoError(t, c.Update(context.TODO(), cm, client.FieldOwner("kubectl-label")))
+	cm = getConfigMap(t, c)
+	cm.Labels["shared"] = "true"
+	assert.NoError(t, c.Update(context.TODO(), cm, client.FieldOwner("kubectl-label")))
+
+	result, err := kubernetes.Apply(context.TODO(), c, newConfigMap(map[string]string{"app": "foo"}, map[string]string{"a": "1"}))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+
+	cm = getConfigMap(t, c)
+	assert.Equal(t, map[string]string{"app": "foo", "mesh": "injected", "shared": "true"}, cm.Labels)
+}
+
+func Test_applyClient_conflicts(t *testing.T) {
+	c := NewFakeClient()
+
+	_, err := kubernetes.Apply(context.TODO(), c, newConfigMap(nil, map[string]string{"a": "1", "b": "2"}))
+	assert.NoError(t, err)
+
+	cm := getConfigMap(t, c)
+	cm.Data["a"] = "edited"
+	assert.NoError(t, c.Update(context.TODO(), cm, client.FieldOwner("kubectl-edit")))
+
+	// A non-forced apply fails with the conflicting fields
+	err = c.Patch(context.TODO(), newConfigMap(nil, map[string]string{"a": "1", "b": "2"}), client.Apply, client.FieldOwner(kubernetes.FieldManager))
+	assert.True(t, apierrors.IsConflict(err))
+
+	// Apply reports the conflicts and takes over the fields
+	result, err := kubernetes.Apply(context.TODO(), c, newConfigMap(nil, map[string]string{"a": "1", "b": "2"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`conflict with "kubectl-edit": .data.a`}, result.Conflicts)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, getConfigMap(t, c).Data)
+
+	// The field is owned by the operator again: it is removed when no longer applied
+	result, err = kubernetes.Apply(context.TODO(), c, newConfigMap(nil, map[string]string{"b": "2"}))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+	assert.Equal(t, map[string]string{"b": "2"}, getConfigMap(t, c).Data)
+}
+
+func Test_applyClient_keepsUnownedFields(t *testing.T) {
+	c := NewFakeClient(newConfigMap(map[string]string{"created": "before"}, map[string]string{"a": "0"}))
+
+	result, err := kubernetes.Apply(context.TODO(), c, newConfigMap(nil, map[string]string{"a": "1"}))
+	assert.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+	assert.Equal(t, kubernetes.ApplyOperationUpdated, result.Operation)
+
+	cm := getConfigMap(t, c)
+	assert.Equal(t, map[string]string{"created": "before"}, cm.Labels)
+	assert.Equal(t, map[string]string{"a": "1"}, cm.Data)
+}
@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/pkg/versioncatalog"
+)
+
+const versionCatalogErrorEventReason = "VersionCatalogError"
+
+// reconcileUpdatePolicy selects the Agent and Cluster Agent versions from the version catalog, according to the
+// update policy, and sets them as the image tags rendered by the next reconcile functions.
+// Only the image tags defaulted by the operator are managed.
+func (r *Reconciler) reconcileUpdatePolicy(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus) (reconcile.Result, error) {
+	policy := dda.Spec.UpdatePolicy
+	if policy == nil {
+		newStatus.Versions = nil
+		return reconcile.Result{}, nil
+	}
+
+	catalog, err := r.getVersionCatalog(dda.Namespace, policy.VersionCatalog)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Unable to read the version catalog, the versions are not updated", "configmap", policy.VersionCatalog)
+			r.recorder.Event(dda, corev1.EventTypeWarning, versionCatalogErrorEventReason, err.Error())
+		} else {
+			logger.V(1).Info("Version catalog not found, the versions are not updated", "configmap", policy.VersionCatalog)
+		}
+		catalog = &versioncatalog.Catalog{}
+	}
+
+	now := metav1.NewTime(time.Now())
+	inWindow := isInMaintenanceWindow(policy.MaintenanceWindow, now.Time)
+	if newStatus.Versions == nil {
+		newStatus.Versions = &datadoghqv1alpha1.VersionsStatus{}
+	}
+	override := dda.Status.DefaultOverride
+	if override == nil {
+		override = &datadoghqv1alpha1.DatadogAgentSpec{}
+	}
+
+	if isDefaultedImageTag(override.Agent.Image) {
+		newStatus.Versions.Agent = updateComponentVersion(logger, policy.Channel, newStatus.Versions.Agent, dda.Spec.Agent.Image.Tag, catalog.Agent, inWindow, now)
+		dda.Spec.Agent.Image.Tag = newStatus.Versions.Agent.Current
+		// The Cluster Checks Runner uses the Agent image
+		if isDefaultedImageTag(override.ClusterChecksRunner.Image) {
+			dda.Spec.ClusterChecksRunner.Image.Tag = newStatus.Versions.Agent.Current
+		}
+	} else {
+		newStatus.Versions.Agent = nil
+	}
+
+	if isDefaultedImageTag(override.ClusterAgent.Image) {
+		newStatus.Versions.ClusterAgent = updateComponentVersion(logger, policy.Channel, newStatus.Versions.ClusterAgent, dda.Spec.ClusterAgent.Image.Tag, catalog.ClusterAgent, inWindow, now)
+		dda.Spec.ClusterAgent.Image.Tag = newStatus.Versions.ClusterAgent.Current
+	} else {
+		newStatus.Versions.ClusterAgent = nil
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// getVersionCatalog returns the version catalog stored in a ConfigMap
+func (r *Reconciler) getVersionCatalog(namespace, name string) (*versioncatalog.Catalog, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, cm); err != nil {
+		return nil, err
+	}
+	return versioncatalog.FromConfigMap(cm)
+}
+
+// updateComponentVersion computes the versions of a component, and moves its current version to the target version of
+// the channel when the maintenance window is open. The default version is used if no version was deployed yet.
+func updateComponentVersion(logger logr.Logger, channel datadoghqv1alpha1.UpdateChannel, status *datadoghqv1alpha1.ComponentVersionStatus, defaultVersion string, versions []string, inWindow bool, now metav1.Time) *datadoghqv1alpha1.ComponentVersionStatus {
+	if status == nil {
+		status = &datadoghqv1alpha1.ComponentVersionStatus{}
+	}
+	if status.Current == "" {
+		status.Current = defaultVersion
+		status.LastUpdateTime = &now
+	}
+
+	target, err := versioncatalog.Target(channel, status.Current, versions)
+	if err != nil {
+		logger.Error(err, "Unable to select the target version", "current", status.Current)
+	}
+	status.Target = target
+	status.Available = versioncatalog.Latest(versions)
+
+	if status.Current != status.Target && inWindow {
+		logger.Info("Updating version", "from", status.Current, "to", status.Target, "channel", channel)
+		status.Current = status.Target
+		status.LastUpdateTime = &now
+	}
+	return status
+}
+
+// isInMaintenanceWindow returns true if the maintenance window is open, or not set
+func isInMaintenanceWindow(window *datadoghqv1alpha1.MaintenanceWindow, now time.Time) bool {
+	if window == nil {
+		return true
+	}
+	start, err := time.Parse(datadoghqv1alpha1.MaintenanceWindowStartFormat, window.Start)
+	if err != nil {
+		return false
+	}
+
+	now = now.UTC()
+	// The window may have been opened on one of the previous days
+	maxOffset := int(window.Duration.Duration / (24 * time.Hour))
+	for offset := 0; offset <= maxOffset+1; offset++ {
+		day := now.AddDate(0, 0, -offset)
+		if !isMaintenanceDay(window.Days, day.Weekday()) {
+			continue
+		}
+		windowStart := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+		if !now.Before(windowStart) && now.Before(windowStart.Add(window.Duration.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMaintenanceDay(days []string, weekday time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, day := range days {
+		if wd, found := datadoghqv1alpha1.Weekdays[day]; found && wd == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func isDefaultedImageTag(image *datadoghqv1alpha1.ImageConfig) bool {
+	return image != nil && image.Tag != ""
+}
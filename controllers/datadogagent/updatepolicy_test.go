@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	"github.com/DataDog/datadog-operator/pkg/defaulting"
+	"github.com/DataDog/datadog-operator/pkg/versioncatalog"
+)
+
+func Test_reconcileUpdatePolicy(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+
+	// newDDA returns a DatadogAgent whose Cluster Agent tag, and Agent tag if not set, were defaulted by the operator
+	newDDA := func(agentTag string) *datadoghqv1alpha1.DatadogAgent {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{ClusterAgentEnabled: true})
+		dda.Spec.UpdatePolicy = &datadoghqv1alpha1.UpdatePolicy{Channel: datadoghqv1alpha1.UpdateChannelPatch}
+		datadoghqv1alpha1.DefaultUpdatePolicy(dda.Spec.UpdatePolicy)
+		dda.Spec.ClusterAgent.Image = &datadoghqv1alpha1.ImageConfig{Name: "gcr.io/datadoghq/cluster-agent", Tag: defaulting.ClusterAgentLatestVersion}
+		dda.Spec.Agent.Image = &datadoghqv1alpha1.ImageConfig{Name: "gcr.io/datadoghq/agent", Tag: defaulting.AgentLatestVersion}
+		dda.Status.DefaultOverride = &datadoghqv1alpha1.DatadogAgentSpec{}
+		dda.Status.DefaultOverride.ClusterAgent.Image = &datadoghqv1alpha1.ImageConfig{Tag: defaulting.ClusterAgentLatestVersion}
+		if agentTag == "" {
+			dda.Status.DefaultOverride.Agent.Image = &datadoghqv1alpha1.ImageConfig{Tag: defaulting.AgentLatestVersion}
+		} else {
+			dda.Spec.Agent.Image.Tag = agentTag
+		}
+		return dda
+	}
+	catalog := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: datadoghqv1alpha1.DefaultVersionCatalogName, Namespace: "bar"},
+		Data: map[string]string{
+			versioncatalog.CatalogKey: "agent:\n- 7.33.0\n- 7.33.1\n- 7.34.0\nclusterAgent:\n- 1.17.0\n- 1.18.0\n",
+		},
+	}
+
+	// the current versions are the default versions, the Agent is updated to the latest patch version
+	dda := newDDA("")
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(catalog).Build()
+	r := &Reconciler{client: fakeClient, scheme: scheme.Scheme, recorder: record.NewFakeRecorder(10)}
+
+	newStatus := &datadoghqv1alpha1.DatadogAgentStatus{
+		Versions: &datadoghqv1alpha1.VersionsStatus{
+			Agent: &datadoghqv1alpha1.ComponentVersionStatus{Current: "7.33.0"},
+		},
+	}
+	_, err := r.reconcileUpdatePolicy(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, "7.33.1", newStatus.Versions.Agent.Current)
+	assert.Equal(t, "7.33.1", newStatus.Versions.Agent.Target)
+	assert.Equal(t, "7.34.0", newStatus.Versions.Agent.Available)
+	assert.NotNil(t, newStatus.Versions.Agent.LastUpdateTime)
+	assert.Equal(t, "7.33.1", dda.Spec.Agent.Image.Tag)
+	assert.Equal(t, defaulting.ClusterAgentLatestVersion, newStatus.Versions.ClusterAgent.Current)
+	assert.Equal(t, "1.18.0", newStatus.Versions.ClusterAgent.Available)
+
+	// the maintenance window is closed, the Agent is not updated
+	dda = newDDA("")
+	closedDay := time.Now().UTC().AddDate(0, 0, 3).Weekday().String()
+	dda.Spec.UpdatePolicy.MaintenanceWindow = &datadoghqv1alpha1.MaintenanceWindow{
+		Days:     []string{closedDay},
+		Start:    "00:00",
+		Duration: metav1.Duration{Duration: time.Hour},
+	}
+	newStatus.Versions.Agent = &datadoghqv1alpha1.ComponentVersionStatus{Current: "7.33.0"}
+	_, err = r.reconcileUpdatePolicy(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Equal(t, "7.33.0", newStatus.Versions.Agent.Current)
+	assert.Equal(t, "7.33.1", newStatus.Versions.Agent.Target)
+	assert.Equal(t, "7.33.0", dda.Spec.Agent.Image.Tag)
+
+	// the Agent tag is set by the user, it is not managed
+	dda = newDDA("7.30.0")
+	_, err = r.reconcileUpdatePolicy(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Nil(t, newStatus.Versions.Agent)
+	assert.Equal(t, "7.30.0", dda.Spec.Agent.Image.Tag)
+}
+
+func Test_isInMaintenanceWindow(t *testing.T) {
+	// 2021-01-02 is a Saturday
+	saturday := func(hour, minute int) time.Time {
+		return time.Date(2021, 1, 2, hour, minute, 0, 0, time.UTC)
+	}
+	window := func(days []string, start string, duration time.Duration) *datadoghqv1alpha1.MaintenanceWindow {
+		return &datadoghqv1alpha1.MaintenanceWindow{Days: days, Start: start, Duration: metav1.Duration{Duration: duration}}
+	}
+
+	tests := []struct {
+		name   string
+		window *datadoghqv1alpha1.MaintenanceWindow
+		now    time.Time
+		want   bool
+	}{
+		{
+			name: "no window",
+			now:  saturday(12, 0),
+			want: true,
+		},
+		{
+			name:   "every day, in the window",
+			window: window(nil, "02:00", 2*time.Hour),
+			now:    saturday(3, 30),
+			want:   true,
+		},
+		{
+			name:   "every day, after the window",
+			window: window(nil, "02:00", 2*time.Hour),
+			now:    saturday(4, 0),
+			want:   false,
+		},
+		{
+			name:   "other day",
+			window: window([]string{"Sunday"}, "02:00", 2*time.Hour),
+			now:    saturday(3, 0),
+			want:   false,
+		},
+		{
+			name:   "window opened the previous day",
+			window: window([]string{"Friday"}, "22:00", 4*time.Hour),
+			now:    saturday(1, 0),
+			want:   true,
+		},
+		{
+			name:   "window opened the previous day, closed",
+			window: window([]string{"Friday"}, "22:00", 4*time.Hour),
+			now:    saturday(2, 0),
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isInMaintenanceWindow(tt.window, tt.now))
+		})
+	}
+}
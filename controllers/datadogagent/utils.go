@@ -80,7 +80,7 @@ func newAgentPodTemplate(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent
 		annotations[key] = val
 	}
 
-	image := getImage(dda.Spec.Agent.Image, dda.Spec.Registry)
+	image := mirrorImage(getImage(dda.Spec.Agent.Image, dda.Spec.Registry), dda.Spec.ImagePolicy)
 	containers := []corev1.Container{}
 	agentContainer, err := getAgentContainer(logger, dda, image)
 	if err != nil {
@@ -132,6 +132,13 @@ func newAgentPodTemplate(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent
 		return nil, err
 	}
 
+	// The Agent is only restricted to the Linux nodes when the Windows Agent is enabled, so that the pods of the
+	// existing DatadogAgents are not rolled out
+	var nodeSelector map[string]string
+	if isWindowsAgentEnabled(dda) {
+		nodeSelector = map[string]string{corev1.LabelOSStable: osLinux}
+	}
+
 	return &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: dda.Name,
@@ -145,6 +152,7 @@ func newAgentPodTemplate(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent
 			InitContainers:     initContainers,
 			Containers:         containers,
 			Volumes:            getVolumesForAgent(dda),
+			NodeSelector:       nodeSelector,
 			Tolerations:        dda.Spec.Agent.Config.Tolerations,
 			PriorityClassName:  dda.Spec.Agent.PriorityClassName,
 			HostNetwork:        dda.Spec.Agent.HostNetwork,
@@ -311,9 +319,9 @@ func getAgentContainer(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent,
 		Command:         getDefaultIfEmpty(dda.Spec.Agent.Config.Command, []string{"agent", "run"}),
 		Args:            getDefaultIfEmpty(dda.Spec.Agent.Config.Args, nil),
 		Resources:       *agentSpec.Config.Resources,
-		Ports: []corev1.ContainerPort{
+		Ports: append([]corev1.ContainerPort{
 			udpPort,
-		},
+		}, getOTLPContainerPorts(dda)...),
 		Env:            envVars,
 		VolumeMounts:   getVolumeMountsForAgent(dda),
 		LivenessProbe:  dda.Spec.Agent.Config.LivenessProbe,
@@ -580,6 +588,9 @@ func getEnvVarsForAPMAgent(dda *datadoghqv1alpha1.DatadogAgent) ([]corev1.EnvVar
 		})
 	}
 
+	// OTLP receivers configuration
+	envVars = append(envVars, getEnvVarsForOTLP(dda)...)
+
 	commonEnvVars, err := getEnvVarsCommon(dda, true)
 	if err != nil {
 		return nil, err
@@ -896,6 +907,8 @@ func getEnvVarsForAgent(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent)
 	}
 
 	envVars = append(envVars, prometheusScrapeEnvVars(logger, dda)...)
+	envVars = append(envVars, getEnvVarsForOTLP(dda)...)
+	envVars = append(envVars, getEnvVarsForRemoteConfiguration(dda)...)
 
 	return append(envVars, spec.Agent.Config.Env...), nil
 }
@@ -981,6 +994,7 @@ func getEnvVarsForSecurityAgent(dda *datadoghqv1alpha1.DatadogAgent) ([]corev1.E
 		}
 		envVars = append(envVars, clusterEnv...)
 	}
+	envVars = append(envVars, getEnvVarsForRemoteConfiguration(dda)...)
 	if spec.Agent.Config != nil {
 		envVars = append(envVars, spec.Agent.Config.Env...)
 	}
@@ -1076,6 +1090,16 @@ func getVolumesForAgent(dda *datadoghqv1alpha1.DatadogAgent) []corev1.Volume {
 		volumes = append(volumes, dsdsocketVolume)
 	}
 
+	// OTLP volume
+	if isOTLPUDSEnabled(&dda.Spec) {
+		volumes = append(volumes, getVolumeForOTLPSocket(dda))
+	}
+
+	// Remote Configuration volume
+	if needRemoteConfigurationVolume(dda) {
+		volumes = append(volumes, getVolumeForRemoteConfiguration())
+	}
+
 	runtimeVolume := corev1.Volume{
 		Name: datadoghqv1alpha1.CriSocketVolumeName,
 		VolumeSource: corev1.VolumeSource{
@@ -1197,14 +1221,20 @@ func getVolumesForAgent(dda *datadoghqv1alpha1.DatadogAgent) []corev1.Volume {
 	logConfig := dda.Spec.Features.LogCollection
 	if logConfig != nil && apiutils.BoolValue(logConfig.Enabled) {
 		if logConfig.TempStoragePath != nil {
-			volumes = append(volumes, corev1.Volume{
+			pointerVolume := corev1.Volume{
 				Name: datadoghqv1alpha1.PointerVolumeName,
 				VolumeSource: corev1.VolumeSource{
 					HostPath: &corev1.HostPathVolumeSource{
 						Path: *logConfig.TempStoragePath,
 					},
 				},
-			})
+			}
+			if dda.Spec.Platform == datadoghqv1alpha1.PlatformGKEAutopilot {
+				// The host paths cannot be written on GKE Autopilot, the processed log files are forgotten when
+				// the Agent pod is recreated
+				pointerVolume.VolumeSource = corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+			}
+			volumes = append(volumes, pointerVolume)
 		}
 		if logConfig.PodLogsPath != nil {
 			volumes = append(volumes, corev1.Volume{
@@ -1450,6 +1480,16 @@ func getVolumeMountsForAgent(dda *datadoghqv1alpha1.DatadogAgent) []corev1.Volum
 	// Dogstatsd volume
 	volumeMounts = append(volumeMounts, getVolumeMountDogstatsdSocket(false))
 
+	// OTLP volume
+	if isOTLPUDSEnabled(&dda.Spec) {
+		volumeMounts = append(volumeMounts, getVolumeMountForOTLPSocket())
+	}
+
+	// Remote Configuration volume
+	if needRemoteConfigurationVolume(dda) {
+		volumeMounts = append(volumeMounts, getVolumeMountForRemoteConfiguration())
+	}
+
 	// Log volumes
 	if apiutils.BoolValue(dda.Spec.Features.LogCollection.Enabled) {
 		volumeMounts = append(volumeMounts, []corev1.VolumeMount{
@@ -1979,6 +2019,28 @@ func getDefaultAnnotations(*datadoghqv1alpha1.DatadogAgent) map[string]string {
 	return map[string]string{}
 }
 
+// getTopologySpreadConstraints returns the topology spread constraints of a component. By default, the pods are spread
+// across the zones when possible, so that losing a zone doesn't take down all the replicas.
+func getTopologySpreadConstraints(constraints []corev1.TopologySpreadConstraint, dda *datadoghqv1alpha1.DatadogAgent, component string) []corev1.TopologySpreadConstraint {
+	if constraints != nil {
+		return constraints
+	}
+
+	return []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					datadoghqv1alpha1.AgentDeploymentNameLabelKey:      dda.Name,
+					datadoghqv1alpha1.AgentDeploymentComponentLabelKey: component,
+				},
+			},
+		},
+	}
+}
+
 func mergeAnnotationsLabels(logger logr.Logger, previousVal map[string]string, newVal map[string]string, filter string) map[string]string {
 	var globFilter glob.Glob
 	var err error
@@ -2367,33 +2429,19 @@ func addBoolPointerEnVar(b *bool, varName string, varList []corev1.EnvVar) []cor
 
 // getImage builds the image string based on ImageConfig and the registry configuration.
 func getImage(imageSpec *datadoghqv1alpha1.ImageConfig, registry *string) string {
-	if defaulting.IsImageNameContainsTag(imageSpec.Name) {
-		// The image name corresponds to a full image string
-		return imageSpec.Name
-	}
-
-	img := defaulting.NewImage(imageSpec.Name, imageSpec.Tag, imageSpec.JmxEnabled)
-
-	if registry != nil {
-		defaulting.WithRegistry(defaulting.ContainerRegistry(*registry))(img)
-	}
+	image := imageSpec.Name
+	// Unless the image name corresponds to a full image string
+	if !defaulting.IsImageNameContainsTag(imageSpec.Name) {
+		img := defaulting.NewImage(imageSpec.Name, imageSpec.Tag, imageSpec.JmxEnabled)
 
-	return img.String()
-}
-
-// getReplicas returns the desired replicas of a
-// deployment based on the current and new replica values.
-func getReplicas(currentReplicas, newReplicas *int32) *int32 {
-	if newReplicas == nil {
-		if currentReplicas != nil {
-			// Do not overwrite the current value
-			// It's most likely managed by an autoscaler
-			return apiutils.NewInt32Pointer(*currentReplicas)
+		if registry != nil {
+			defaulting.WithRegistry(defaulting.ContainerRegistry(*registry))(img)
 		}
-
-		// Both new and current are nil
-		return nil
+		image = img.String()
 	}
 
-	return apiutils.NewInt32Pointer(*newReplicas)
+	if imageSpec.Digest != "" && !strings.Contains(image, "@") {
+		image = fmt.Sprintf("%s@%s", image, imageSpec.Digest)
+	}
+	return image
 }
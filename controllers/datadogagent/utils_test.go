@@ -82,6 +82,35 @@ func generateSpec() *datadoghqv1alpha1.DatadogAgent {
 	return dda
 }
 
+func Test_getVolumesForAgent_logsPointerVolume(t *testing.T) {
+	newLogsDatadogAgent := func(platform datadoghqv1alpha1.Platform) *datadoghqv1alpha1.DatadogAgent {
+		dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{})
+		dda.Spec.Platform = platform
+		dda.Spec.Features.LogCollection = &datadoghqv1alpha1.LogCollectionConfig{Enabled: apiutils.NewBoolPointer(true)}
+		datadoghqv1alpha1.DefaultDatadogAgent(dda)
+		return dda
+	}
+	getPointerVolume := func(dda *datadoghqv1alpha1.DatadogAgent) *v1.Volume {
+		for _, volume := range getVolumesForAgent(dda) {
+			if volume.Name == datadoghqv1alpha1.PointerVolumeName {
+				return &volume
+			}
+		}
+		return nil
+	}
+
+	volume := getPointerVolume(newLogsDatadogAgent(""))
+	require.NotNil(t, volume)
+	require.NotNil(t, volume.HostPath)
+	assert.Equal(t, "/var/lib/datadog-agent/logs", volume.HostPath.Path)
+
+	// The host paths cannot be written on GKE Autopilot
+	volume = getPointerVolume(newLogsDatadogAgent(datadoghqv1alpha1.PlatformGKEAutopilot))
+	require.NotNil(t, volume)
+	assert.Nil(t, volume.HostPath)
+	assert.NotNil(t, volume.EmptyDir)
+}
+
 func Test_getLocalFilepath(t *testing.T) {
 	type args struct {
 		filePath  string
@@ -533,56 +562,20 @@ func Test_getImage(t *testing.T) {
 			registry: nil,
 			want:     "gcr.io/datadoghq/agent:latest-jmx",
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.want, getImage(tt.imageSpec, tt.registry))
-		})
-	}
-}
-
-func Test_getReplicas(t *testing.T) {
-	tests := []struct {
-		name    string
-		current *int32
-		new     *int32
-		want    *int32
-	}{
-		{
-			name:    "both not nil",
-			current: apiutils.NewInt32Pointer(2),
-			new:     apiutils.NewInt32Pointer(3),
-			want:    apiutils.NewInt32Pointer(3),
-		},
-		{
-			name:    "new is nil",
-			current: apiutils.NewInt32Pointer(2),
-			new:     nil,
-			want:    apiutils.NewInt32Pointer(2),
-		},
 		{
-			name:    "current is nil",
-			current: nil,
-			new:     apiutils.NewInt32Pointer(3),
-			want:    apiutils.NewInt32Pointer(3),
-		},
-		{
-			name:    "both nil",
-			current: nil,
-			new:     nil,
-			want:    nil,
+			name: "pinned to a digest",
+			imageSpec: &datadoghqv1alpha1.ImageConfig{
+				Name:   "agent",
+				Tag:    "7.33.0",
+				Digest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			},
+			registry: nil,
+			want:     "gcr.io/datadoghq/agent:7.33.0@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getReplicas(tt.current, tt.new)
-			assert.Equal(t, tt.want, got)
-			if got != nil {
-				// Assert the result's address and
-				// the input pointers are not equal
-				assert.NotSame(t, tt.current, got)
-				assert.NotSame(t, tt.new, got)
-			}
+			assert.Equal(t, tt.want, getImage(tt.imageSpec, tt.registry))
 		})
 	}
 }
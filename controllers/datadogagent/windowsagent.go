@@ -0,0 +1,338 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+)
+
+const (
+	osLinux   = "linux"
+	osWindows = "windows"
+	// windowsNodeTaintKey is the taint commonly set on the Windows nodes to keep the Linux pods away
+	windowsNodeTaintKey = "node.kubernetes.io/os"
+)
+
+// windowsUnsupportedEnvVars are the Agent environment variables configuring Linux-only features: Unix sockets,
+// System Probe and the CRI socket mounted from the host
+var windowsUnsupportedEnvVars = map[string]bool{
+	datadoghqv1alpha1.DDDogstatsdSocket:                  true,
+	datadoghqv1alpha1.DDPPMReceiverSocket:                true,
+	datadoghqv1alpha1.DDSystemProbeSocketPath:            true,
+	datadoghqv1alpha1.DDSystemProbeTCPQueueLengthEnabled: true,
+	datadoghqv1alpha1.DDSystemProbeOOMKillEnabled:        true,
+	datadoghqv1alpha1.DDCriSocketPath:                    true,
+	datadoghqv1alpha1.DockerHost:                         true,
+	datadoghqv1alpha1.DDKubeletCAPath:                    true,
+}
+
+func isWindowsAgentEnabled(dda *datadoghqv1alpha1.DatadogAgent) bool {
+	return apiutils.BoolValue(dda.Spec.Agent.Enabled) && dda.Spec.Agent.Windows != nil && apiutils.BoolValue(dda.Spec.Agent.Windows.Enabled)
+}
+
+func windowsDaemonsetName(dda *datadoghqv1alpha1.DatadogAgent) string {
+	return fmt.Sprintf("%s-%s", daemonsetName(dda), osWindows)
+}
+
+func (r *Reconciler) reconcileWindowsAgent(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent, newStatus *datadoghqv1alpha1.DatadogAgentStatus) (reconcile.Result, error) {
+	name := windowsDaemonsetName(dda)
+	enabled := isWindowsAgentEnabled(dda)
+
+	result, err := r.manageResource(logger, dda, managedResource{
+		kind:      daemonSetKind,
+		name:      name,
+		namespace: dda.Namespace,
+		newObject: func() client.Object { return &appsv1.DaemonSet{} },
+		build: func() (client.Object, error) {
+			return newWindowsDaemonSetFromInstance(logger, dda)
+		},
+		ownership: ownedByController,
+		hash:      hashObject,
+		cleanup:   !enabled,
+	})
+	if err != nil || !enabled {
+		newStatus.AgentWindows = nil
+		return result, err
+	}
+
+	ds := &appsv1.DaemonSet{}
+	if err = r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: dda.Namespace}, ds); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+		return result, err
+	}
+	now := metav1.NewTime(time.Now())
+	newStatus.AgentWindows = updateDaemonSetStatus(ds, newStatus.AgentWindows, &now)
+
+	return result, nil
+}
+
+// newWindowsDaemonSetFromInstance returns the Agent DaemonSet of the Windows nodes
+func newWindowsDaemonSetFromInstance(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (*appsv1.DaemonSet, error) {
+	template, err := newWindowsAgentPodTemplate(logger, dda)
+	if err != nil {
+		return nil, err
+	}
+	strategy, err := getAgentDeploymentStrategy(dda)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := getDefaultLabels(dda, datadoghqv1alpha1.DefaultAgentWindowsResourceSuffix, getAgentVersion(dda))
+	labels[datadoghqv1alpha1.AgentDeploymentNameLabelKey] = dda.Name
+	labels[datadoghqv1alpha1.AgentDeploymentComponentLabelKey] = datadoghqv1alpha1.DefaultAgentWindowsResourceSuffix
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        windowsDaemonsetName(dda),
+			Namespace:   dda.Namespace,
+			Labels:      labels,
+			Annotations: getDefaultAnnotations(dda),
+		},
+		Spec: appsv1.DaemonSetSpec{
+			// The selector is immutable, it only contains stable labels
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					datadoghqv1alpha1.AgentDeploymentNameLabelKey:      dda.Name,
+					datadoghqv1alpha1.AgentDeploymentComponentLabelKey: datadoghqv1alpha1.DefaultAgentWindowsResourceSuffix,
+				},
+			},
+			Template: *template,
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type: *strategy.UpdateStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+					MaxUnavailable: strategy.RollingUpdate.MaxUnavailable,
+				},
+			},
+		},
+	}, nil
+}
+
+// newWindowsAgentPodTemplate returns the Agent pod template of the Windows nodes. It runs the Agent and the Trace Agent,
+// the other components are not available on Windows. The Unix sockets are replaced by named pipes.
+func newWindowsAgentPodTemplate(logger logr.Logger, dda *datadoghqv1alpha1.DatadogAgent) (*corev1.PodTemplateSpec, error) {
+	labels := getDefaultLabels(dda, datadoghqv1alpha1.DefaultAgentWindowsResourceSuffix, getAgentVersion(dda))
+	labels[datadoghqv1alpha1.AgentDeploymentNameLabelKey] = dda.Name
+	labels[datadoghqv1alpha1.AgentDeploymentComponentLabelKey] = datadoghqv1alpha1.DefaultAgentWindowsResourceSuffix
+	for key, val := range dda.Spec.Agent.AdditionalLabels {
+		labels[key] = val
+	}
+
+	annotations := getDefaultAnnotations(dda)
+	for key, val := range dda.Spec.Agent.AdditionalAnnotations {
+		annotations[key] = val
+	}
+
+	image, pullPolicy := getWindowsAgentImage(dda)
+	agentContainer, err := getAgentContainer(logger, dda, image)
+	if err != nil {
+		return nil, err
+	}
+	agentContainer.ImagePullPolicy = pullPolicy
+	agentContainer.Env = getEnvVarsForWindows(dda, agentContainer.Env)
+	if isDogstatsdUDSEnabled(&dda.Spec) {
+		agentContainer.Env = append(agentContainer.Env, corev1.EnvVar{
+			Name:  datadoghqv1alpha1.DDDogstatsdPipeName,
+			Value: datadoghqv1alpha1.WindowsDogstatsdPipeName,
+		})
+	}
+	agentContainer.VolumeMounts = getVolumeMountsForWindowsAgent(dda)
+	containers := []corev1.Container{*agentContainer}
+
+	if isAPMEnabled(&dda.Spec) {
+		var apmContainers []corev1.Container
+		apmContainers, err = getAPMAgentContainers(dda, image)
+		if err != nil {
+			return nil, err
+		}
+		for _, apmContainer := range apmContainers {
+			apmContainer.ImagePullPolicy = pullPolicy
+			apmContainer.Command = getDefaultIfEmpty(dda.Spec.Agent.Apm.Command, []string{"trace-agent", "-foreground", fmt.Sprintf("-config=%s", datadoghqv1alpha1.WindowsAgentCustomConfigVolumePath)})
+			apmContainer.Env = getEnvVarsForWindows(dda, apmContainer.Env)
+			if isAPMUDSEnabled(&dda.Spec) {
+				apmContainer.Env = append(apmContainer.Env, corev1.EnvVar{
+					Name:  datadoghqv1alpha1.DDAPMWindowsPipeName,
+					Value: datadoghqv1alpha1.WindowsAPMPipeName,
+				})
+			}
+			apmContainer.VolumeMounts = getVolumeMountsForWindowsAPMAgent(dda)
+			containers = append(containers, apmContainer)
+		}
+	}
+
+	tolerations := append([]corev1.Toleration{}, dda.Spec.Agent.Config.Tolerations...)
+	tolerations = append(tolerations, corev1.Toleration{
+		Key:      windowsNodeTaintKey,
+		Operator: corev1.TolerationOpEqual,
+		Value:    osWindows,
+		Effect:   corev1.TaintEffectNoSchedule,
+	})
+
+	return &corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: dda.Name,
+			Namespace:    dda.Namespace,
+			Labels:       labels,
+			Annotations:  annotations,
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: getAgentServiceAccount(dda),
+			Containers:         containers,
+			Volumes:            getVolumesForWindowsAgent(dda),
+			NodeSelector:       map[string]string{corev1.LabelOSStable: osWindows},
+			Tolerations:        tolerations,
+			PriorityClassName:  dda.Spec.Agent.PriorityClassName,
+			DNSPolicy:          dda.Spec.Agent.DNSPolicy,
+			DNSConfig:          dda.Spec.Agent.DNSConfig,
+			Affinity:           dda.Spec.Agent.Affinity,
+		},
+	}, nil
+}
+
+// getWindowsAgentImage returns the image and the pull policy of the Windows Agent, the Agent image is used by default
+func getWindowsAgentImage(dda *datadoghqv1alpha1.DatadogAgent) (string, corev1.PullPolicy) {
+	imageConfig := dda.Spec.Agent.Image
+	pullPolicy := *imageConfig.PullPolicy
+	if windowsImage := dda.Spec.Agent.Windows.Image; windowsImage != nil && windowsImage.Name != "" {
+		imageConfig = windowsImage
+		if windowsImage.PullPolicy != nil {
+			pullPolicy = *windowsImage.PullPolicy
+		}
+	}
+	return mirrorImage(getImage(imageConfig, dda.Spec.Registry), dda.Spec.ImagePolicy), pullPolicy
+}
+
+// getEnvVarsForWindows removes the Linux-only environment variables and points the Agent to the containerd named pipe
+func getEnvVarsForWindows(dda *datadoghqv1alpha1.DatadogAgent, envVars []corev1.EnvVar) []corev1.EnvVar {
+	windowsEnvVars := make([]corev1.EnvVar, 0, len(envVars)+1)
+	for _, envVar := range envVars {
+		if windowsUnsupportedEnvVars[envVar.Name] {
+			continue
+		}
+		if isOTLPUDSEnabled(&dda.Spec) && (envVar.Name == datadoghqv1alpha1.DDOTLPGRPCEndpoint || envVar.Name == datadoghqv1alpha1.DDOTLPGRPCTransport) {
+			continue
+		}
+		windowsEnvVars = append(windowsEnvVars, envVar)
+	}
+
+	return append(windowsEnvVars, corev1.EnvVar{
+		Name:  datadoghqv1alpha1.DDCriSocketPath,
+		Value: datadoghqv1alpha1.WindowsCriSocketPipePath,
+	})
+}
+
+func getVolumesForWindowsAgent(dda *datadoghqv1alpha1.DatadogAgent) []corev1.Volume {
+	volumes := []corev1.Volume{
+		{
+			Name: datadoghqv1alpha1.InstallInfoVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: getInstallInfoConfigMapName(dda),
+					},
+				},
+			},
+		},
+		{
+			Name: datadoghqv1alpha1.CriSocketVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: datadoghqv1alpha1.WindowsCriSocketPipePath,
+				},
+			},
+		},
+	}
+
+	if dda.Spec.Agent.CustomConfig != nil {
+		volumes = append(volumes, getVolumeFromCustomConfigSpec(dda.Spec.Agent.CustomConfig, getAgentCustomConfigConfigMapName(dda), datadoghqv1alpha1.AgentCustomConfigVolumeName))
+	}
+
+	if apiutils.BoolValue(dda.Spec.Features.LogCollection.Enabled) {
+		volumes = append(volumes,
+			corev1.Volume{
+				Name: datadoghqv1alpha1.LogPodVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: datadoghqv1alpha1.WindowsLogPodVolumePath,
+					},
+				},
+			},
+			corev1.Volume{
+				Name: datadoghqv1alpha1.LogContainerVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: datadoghqv1alpha1.WindowsLogContainerVolumePath,
+					},
+				},
+			},
+		)
+	}
+
+	return volumes
+}
+
+func getVolumeMountsForWindowsAgent(dda *datadoghqv1alpha1.DatadogAgent) []corev1.VolumeMount {
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      datadoghqv1alpha1.InstallInfoVolumeName,
+			SubPath:   datadoghqv1alpha1.InstallInfoVolumeSubPath,
+			MountPath: datadoghqv1alpha1.WindowsInstallInfoVolumePath,
+			ReadOnly:  datadoghqv1alpha1.InstallInfoVolumeReadOnly,
+		},
+		{
+			Name:      datadoghqv1alpha1.CriSocketVolumeName,
+			MountPath: datadoghqv1alpha1.WindowsCriSocketPipePath,
+		},
+	}
+	volumeMounts = append(volumeMounts, getVolumeMountForWindowsConfig(dda)...)
+
+	if apiutils.BoolValue(dda.Spec.Features.LogCollection.Enabled) {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{
+				Name:      datadoghqv1alpha1.LogPodVolumeName,
+				MountPath: datadoghqv1alpha1.WindowsLogPodVolumePath,
+				ReadOnly:  datadoghqv1alpha1.LogPodVolumeReadOnly,
+			},
+			corev1.VolumeMount{
+				Name:      datadoghqv1alpha1.LogContainerVolumeName,
+				MountPath: datadoghqv1alpha1.WindowsLogContainerVolumePath,
+				ReadOnly:  datadoghqv1alpha1.LogContainerVolumeReadOnly,
+			},
+		)
+	}
+
+	return volumeMounts
+}
+
+func getVolumeMountsForWindowsAPMAgent(dda *datadoghqv1alpha1.DatadogAgent) []corev1.VolumeMount {
+	return getVolumeMountForWindowsConfig(dda)
+}
+
+// getVolumeMountForWindowsConfig returns the custom datadog.yaml mount, the configuration directory is not replaced
+// as it contains the default configuration of the Windows image
+func getVolumeMountForWindowsConfig(dda *datadoghqv1alpha1.DatadogAgent) []corev1.VolumeMount {
+	if dda.Spec.Agent.CustomConfig == nil {
+		return nil
+	}
+	return []corev1.VolumeMount{
+		getVolumeMountFromCustomConfigSpec(dda.Spec.Agent.CustomConfig, datadoghqv1alpha1.AgentCustomConfigVolumeName, datadoghqv1alpha1.WindowsAgentCustomConfigVolumePath, datadoghqv1alpha1.AgentCustomConfigVolumeSubPath),
+	}
+}
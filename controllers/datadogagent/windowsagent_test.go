@@ -0,0 +1,165 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogagent
+
+import (
+	"context"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1/test"
+	apiutils "github.com/DataDog/datadog-operator/apis/utils"
+	testutils "github.com/DataDog/datadog-operator/controllers/datadogagent/testutils"
+)
+
+func newWindowsDatadogAgent(options *test.NewDatadogAgentOptions) *datadoghqv1alpha1.DatadogAgent {
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", options)
+	dda.Spec.Agent.Windows = &datadoghqv1alpha1.WindowsAgentConfig{Enabled: apiutils.NewBoolPointer(true)}
+	return dda
+}
+
+func Test_newWindowsDaemonSetFromInstance(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := newWindowsDatadogAgent(&test.NewDatadogAgentOptions{
+		APMEnabled:          true,
+		SystemProbeEnabled:  true,
+		ProcessEnabled:      true,
+		UseEDS:              false,
+		ClusterAgentEnabled: false,
+	})
+
+	ds, err := newWindowsDaemonSetFromInstance(logger, dda)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo-agent-windows", ds.Name)
+	assert.Equal(t, map[string]string{
+		datadoghqv1alpha1.AgentDeploymentNameLabelKey:      "foo",
+		datadoghqv1alpha1.AgentDeploymentComponentLabelKey: "agent-windows",
+	}, ds.Spec.Selector.MatchLabels)
+	for key, value := range ds.Spec.Selector.MatchLabels {
+		assert.Equal(t, value, ds.Spec.Template.Labels[key])
+	}
+
+	podSpec := ds.Spec.Template.Spec
+	assert.Equal(t, map[string]string{corev1.LabelOSStable: "windows"}, podSpec.NodeSelector)
+	assert.Contains(t, podSpec.Tolerations, corev1.Toleration{
+		Key:      "node.kubernetes.io/os",
+		Operator: corev1.TolerationOpEqual,
+		Value:    "windows",
+		Effect:   corev1.TaintEffectNoSchedule,
+	})
+	assert.Nil(t, podSpec.SecurityContext)
+	assert.False(t, podSpec.HostPID)
+	assert.Empty(t, podSpec.InitContainers)
+
+	// Only the Agent and the Trace Agent run on Windows
+	containerNames := []string{}
+	for _, container := range podSpec.Containers {
+		containerNames = append(containerNames, container.Name)
+	}
+	assert.Equal(t, []string{"agent", "trace-agent"}, containerNames)
+
+	for _, container := range podSpec.Containers {
+		envVars := map[string]corev1.EnvVar{}
+		for _, envVar := range container.Env {
+			envVars[envVar.Name] = envVar
+		}
+		assert.Equal(t, datadoghqv1alpha1.WindowsCriSocketPipePath, envVars[datadoghqv1alpha1.DDCriSocketPath].Value)
+		assert.NotContains(t, envVars, datadoghqv1alpha1.DDDogstatsdSocket)
+		assert.NotContains(t, envVars, datadoghqv1alpha1.DDSystemProbeSocketPath)
+	}
+	assert.Equal(t, []string{"trace-agent", "-foreground", "-config=C:/ProgramData/Datadog/datadog.yaml"}, podSpec.Containers[1].Command)
+
+	// No Linux volume is mounted from the host
+	volumeNames := map[string]bool{}
+	for _, volume := range podSpec.Volumes {
+		volumeNames[volume.Name] = true
+		if volume.HostPath != nil {
+			assert.Contains(t, []string{datadoghqv1alpha1.WindowsCriSocketPipePath, datadoghqv1alpha1.WindowsLogPodVolumePath, datadoghqv1alpha1.WindowsLogContainerVolumePath}, volume.HostPath.Path)
+		}
+	}
+	for _, container := range podSpec.Containers {
+		for _, volumeMount := range container.VolumeMounts {
+			assert.True(t, volumeNames[volumeMount.Name], "volume mount %s", volumeMount.Name)
+		}
+	}
+}
+
+func Test_newAgentPodTemplate_LinuxNodeSelector(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+
+	// The Agent pods are left untouched without the Windows Agent
+	dda := test.NewDefaultedDatadogAgent("bar", "foo", &test.NewDatadogAgentOptions{})
+	template, err := newAgentPodTemplate(logger, dda, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, template.Spec.NodeSelector)
+
+	dda = newWindowsDatadogAgent(&test.NewDatadogAgentOptions{})
+	template, err = newAgentPodTemplate(logger, dda, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{corev1.LabelOSStable: "linux"}, template.Spec.NodeSelector)
+}
+
+func Test_newWindowsDaemonSetFromInstance_NamedPipes(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := newWindowsDatadogAgent(&test.NewDatadogAgentOptions{
+		APMEnabled:          true,
+		UseEDS:              false,
+		ClusterAgentEnabled: false,
+	})
+	dda.Spec.Agent.Config.Dogstatsd.UnixDomainSocket.Enabled = apiutils.NewBoolPointer(true)
+	dda.Spec.Agent.Apm.UnixDomainSocket = &datadoghqv1alpha1.APMUnixDomainSocketSpec{
+		Enabled:      apiutils.NewBoolPointer(true),
+		HostFilepath: apiutils.NewStringPointer("/var/run/datadog/apm.socket"),
+	}
+	dda.Spec.Agent.Windows.Image = &datadoghqv1alpha1.ImageConfig{Name: "datadog/agent:7.33.0-servercore"}
+
+	ds, err := newWindowsDaemonSetFromInstance(logger, dda)
+	assert.NoError(t, err)
+
+	containers := ds.Spec.Template.Spec.Containers
+	assert.Equal(t, "datadog/agent:7.33.0-servercore", containers[0].Image)
+	assert.Contains(t, containers[0].Env, corev1.EnvVar{Name: datadoghqv1alpha1.DDDogstatsdPipeName, Value: "datadog-dogstatsd"})
+	assert.Contains(t, containers[1].Env, corev1.EnvVar{Name: datadoghqv1alpha1.DDAPMWindowsPipeName, Value: "datadog-apm"})
+	for _, envVar := range containers[1].Env {
+		assert.NotEqual(t, datadoghqv1alpha1.DDPPMReceiverSocket, envVar.Name)
+	}
+}
+
+func Test_reconcileWindowsAgent(t *testing.T) {
+	logger := logf.Log.WithName(t.Name())
+	dda := newWindowsDatadogAgent(&test.NewDatadogAgentOptions{UseEDS: false, ClusterAgentEnabled: false})
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogAgent{})
+	fakeClient := testutils.WithApplySupport(fake.NewClientBuilder().WithScheme(s).Build())
+	r := &Reconciler{client: fakeClient, scheme: s, recorder: record.NewFakeRecorder(10)}
+	nsName := types.NamespacedName{Name: "foo-agent-windows", Namespace: "bar"}
+
+	newStatus := &datadoghqv1alpha1.DatadogAgentStatus{}
+	_, err := r.reconcileWindowsAgent(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.NoError(t, fakeClient.Get(context.TODO(), nsName, &appsv1.DaemonSet{}))
+	assert.NotNil(t, newStatus.AgentWindows)
+	assert.Equal(t, "foo-agent-windows", newStatus.AgentWindows.DaemonsetName)
+
+	// The DaemonSet is deleted when the Windows Agent is disabled
+	dda.Spec.Agent.Windows.Enabled = apiutils.NewBoolPointer(false)
+	_, err = r.reconcileWindowsAgent(logger, dda, newStatus)
+	assert.NoError(t, err)
+	assert.Nil(t, newStatus.AgentWindows)
+	err = fakeClient.Get(context.TODO(), nsName, &appsv1.DaemonSet{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
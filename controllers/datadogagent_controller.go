@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
@@ -24,6 +25,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1beta1"
@@ -39,6 +41,7 @@ import (
 // DatadogAgentReconciler reconciles a DatadogAgent object.
 type DatadogAgentReconciler struct {
 	client.Client
+	APIReader   client.Reader
 	VersionInfo *version.Info
 	Log         logr.Logger
 	Scheme      *runtime.Scheme
@@ -50,6 +53,8 @@ type DatadogAgentReconciler struct {
 // +kubebuilder:rbac:groups=datadoghq.com,resources=datadogagents,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=datadoghq.com,resources=datadogagents/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=datadoghq.com,resources=datadogagents/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=datadoghq.com,resources=datadogagentprofiles,verbs=get;list;watch
+// +kubebuilder:rbac:groups=datadoghq.com,resources=datadogagentprofiles/status,verbs=get;update;patch
 
 // RBAC Management
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch;create;update;patch;delete
@@ -93,6 +98,10 @@ type DatadogAgentReconciler struct {
 // OpenShift
 // +kubebuilder:rbac:groups=quota.openshift.io,resources=clusterresourcequotas,verbs=get;list
 // +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=restricted,verbs=use
+// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=get;list;watch;create;update;patch;delete
+
+// Pod Security admission
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;patch
 
 // +kubebuilder:rbac:urls=/metrics,verbs=get
 // +kubebuilder:rbac:groups="",resources=componentstatuses,verbs=get;list;watch
@@ -110,6 +119,7 @@ type DatadogAgentReconciler struct {
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
@@ -150,7 +160,7 @@ type DatadogAgentReconciler struct {
 // +kubebuilder:rbac:groups=apps;extensions,resources=daemonsets;deployments;replicasets,verbs=list;watch
 // +kubebuilder:rbac:groups="",resources=replicationcontrollers,verbs=list;watch
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=list;watch
-// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=list;watch
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=list;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=list;watch
 // +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations,verbs=list;watch
@@ -175,6 +185,7 @@ func (r *DatadogAgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&rbacv1.RoleBinding{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&autoscalingv2beta2.HorizontalPodAutoscaler{}).
 		Owns(&networkingv1.NetworkPolicy{})
 
 	// DatadogAgent is namespaced whereas ClusterRole and ClusterRoleBinding are
@@ -184,6 +195,14 @@ func (r *DatadogAgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	builder.Watches(&source.Kind{Type: &rbacv1.ClusterRole{}}, handlerEnqueue)
 	builder.Watches(&source.Kind{Type: &rbacv1.ClusterRoleBinding{}}, handlerEnqueue)
 
+	// A DatadogAgentProfile applies to all the DatadogAgents of its namespace.
+	builder.Watches(&source.Kind{Type: &datadoghqv1alpha1.DatadogAgentProfile{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueDatadogAgentsOfNamespace))
+	// The nodes covered by the profiles depend on the node labels.
+	builder.Watches(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueDatadogAgentsWithProfiles), ctrlbuilder.WithPredicates(predicate.LabelChangedPredicate{}))
+
+	// The version and digest catalogs are ConfigMaps that are not owned by the DatadogAgent.
+	builder.Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueDatadogAgentsOfVersionCatalog))
+
 	if r.Options.SupportExtendedDaemonset {
 		builder = builder.Owns(&edsdatadoghqv1alpha1.ExtendedDaemonSet{})
 	}
@@ -198,6 +217,17 @@ func (r *DatadogAgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		builder = builder.Owns(policy)
 	}
 
+	if r.Options.SupportOpenShiftSCC {
+		// The SecurityContextConstraints is cluster-scoped, its ownership is tracked with labels
+		scc := &unstructured.Unstructured{}
+		scc.SetGroupVersionKind(schema.GroupVersionKind{
+			Group:   "security.openshift.io",
+			Version: "v1",
+			Kind:    "SecurityContextConstraints",
+		})
+		builder.Watches(&source.Kind{Type: scc}, handlerEnqueue)
+	}
+
 	var metricForwarder datadog.MetricForwardersManager
 	if r.Options.OperatorMetricsEnabled {
 		metricForwarder = datadog.NewForwardersManager(r.Client)
@@ -218,7 +248,7 @@ func (r *DatadogAgentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
-	internal, err := datadogagent.NewReconciler(r.Options, r.Client, r.VersionInfo, r.Scheme, r.Log, r.Recorder, metricForwarder)
+	internal, err := datadogagent.NewReconciler(r.Options, r.Client, r.APIReader, r.VersionInfo, r.Scheme, r.Log, r.Recorder, metricForwarder)
 	if err != nil {
 		return err
 	}
@@ -239,3 +269,67 @@ func enqueueIfOwnedByDatadogAgent(obj client.Object) []reconcile.Request {
 
 	return []reconcile.Request{{NamespacedName: owner}}
 }
+
+func (r *DatadogAgentReconciler) enqueueDatadogAgentsOfNamespace(obj client.Object) []reconcile.Request {
+	ddaList := &datadoghqv1alpha1.DatadogAgentList{}
+	if err := r.List(context.TODO(), ddaList, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "Unable to list the DatadogAgents", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(ddaList.Items))
+	for _, dda := range ddaList.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}})
+	}
+	return requests
+}
+
+func (r *DatadogAgentReconciler) enqueueDatadogAgentsWithProfiles(obj client.Object) []reconcile.Request {
+	profileList := &datadoghqv1alpha1.DatadogAgentProfileList{}
+	if err := r.List(context.TODO(), profileList); err != nil {
+		r.Log.Error(err, "Unable to list the DatadogAgentProfiles")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	namespaces := map[string]bool{}
+	for _, profile := range profileList.Items {
+		if namespaces[profile.Namespace] {
+			continue
+		}
+		namespaces[profile.Namespace] = true
+		requests = append(requests, r.enqueueDatadogAgentsOfNamespace(&profile)...)
+	}
+	return requests
+}
+
+func (r *DatadogAgentReconciler) enqueueDatadogAgentsOfVersionCatalog(obj client.Object) []reconcile.Request {
+	ddaList := &datadoghqv1alpha1.DatadogAgentList{}
+	if err := r.List(context.TODO(), ddaList, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "Unable to list the DatadogAgents", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, dda := range ddaList.Items {
+		var catalogs []string
+		if dda.Spec.UpdatePolicy != nil {
+			catalog := dda.Spec.UpdatePolicy.VersionCatalog
+			if catalog == "" {
+				catalog = datadoghqv1alpha1.DefaultVersionCatalogName
+			}
+			catalogs = append(catalogs, catalog)
+		}
+		if dda.Spec.ImagePolicy != nil && dda.Spec.ImagePolicy.DigestCatalog != "" {
+			catalogs = append(catalogs, dda.Spec.ImagePolicy.DigestCatalog)
+		}
+
+		for _, catalog := range catalogs {
+			if catalog == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: dda.Namespace, Name: dda.Name}})
+				break
+			}
+		}
+	}
+	return requests
+}
@@ -37,6 +37,8 @@ const (
 	defaultRequeuePeriod    = 60 * time.Second
 	defaultErrRequeuePeriod = 5 * time.Second
 	maxTriggeredStateGroups = 10
+
+	monitorDeletedEventReason = "MonitorDeleted"
 )
 
 var supportedMonitorTypes = map[string]bool{
@@ -55,25 +57,36 @@ var supportedMonitorTypes = map[string]bool{
 
 // Reconciler reconciles a DatadogMonitor object
 type Reconciler struct {
-	client        client.Client
-	datadogClient *datadogapiclientv1.APIClient
-	datadogAuth   context.Context
-	versionInfo   *version.Info
-	log           logr.Logger
-	scheme        *runtime.Scheme
-	recorder      record.EventRecorder
+	client          client.Client
+	datadogClient   *datadogapiclientv1.APIClient
+	datadogAuth     context.Context
+	versionInfo     *version.Info
+	log             logr.Logger
+	scheme          *runtime.Scheme
+	recorder        record.EventRecorder
+	tagPolicy       TagPolicy
+	recreateDeleted bool
+}
+
+// ReconcilerOptions provides options read from command line
+type ReconcilerOptions struct {
+	TagPolicy TagPolicy
+	// RecreateDeletedMonitors recreates the monitors deleted in Datadog, instead of only reporting them in the status
+	RecreateDeletedMonitors bool
 }
 
 // NewReconciler returns a new Reconciler object
-func NewReconciler(client client.Client, ddClient datadogclient.DatadogClient, versionInfo *version.Info, scheme *runtime.Scheme, log logr.Logger, recorder record.EventRecorder) (*Reconciler, error) {
+func NewReconciler(options ReconcilerOptions, client client.Client, ddClient datadogclient.DatadogClient, versionInfo *version.Info, scheme *runtime.Scheme, log logr.Logger, recorder record.EventRecorder) (*Reconciler, error) {
 	return &Reconciler{
-		client:        client,
-		datadogClient: ddClient.Client,
-		datadogAuth:   ddClient.Auth,
-		versionInfo:   versionInfo,
-		scheme:        scheme,
-		log:           log,
-		recorder:      recorder,
+		client:          client,
+		datadogClient:   ddClient.Client,
+		datadogAuth:     ddClient.Auth,
+		versionInfo:     versionInfo,
+		scheme:          scheme,
+		log:             log,
+		recorder:        recorder,
+		tagPolicy:       options.TagPolicy,
+		recreateDeleted: options.RecreateDeletedMonitors,
 	}, nil
 }
 
@@ -109,6 +122,14 @@ func (r *Reconciler) internalReconcile(ctx context.Context, req reconcile.Reques
 		return result, err
 	}
 
+	// DatadogMonitors generated from an untranslatable source are never synced to Datadog
+	if translationErr, found := instance.Annotations[datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey]; found {
+		err = fmt.Errorf("unable to translate monitor source: %s", translationErr)
+		logger.Error(err, "invalid DatadogMonitor source")
+
+		return r.updateStatusIfNeeded(logger, instance, now, newStatus, err, result)
+	}
+
 	// Validate the DatadogMonitor spec
 	if err = datadoghqv1alpha1.IsValidDatadogMonitor(&instance.Spec); err != nil {
 		logger.Error(err, "invalid DatadogMonitor spec")
@@ -116,7 +137,24 @@ func (r *Reconciler) internalReconcile(ctx context.Context, req reconcile.Reques
 		return r.updateStatusIfNeeded(logger, instance, now, newStatus, err, result)
 	}
 
-	instanceSpecHash, err := comparison.GenerateMD5ForSpec(&instance.Spec)
+	// Render the message template, the rendered message is the one synced to Datadog and hashed,
+	// so that changes to the template or to the namespace metadata trigger an update
+	toSync := instance.DeepCopy()
+	if toSync.Spec.Message, err = r.renderMessage(ctx, instance); err != nil {
+		logger.Error(err, "error rendering message template")
+
+		return r.updateStatusIfNeeded(logger, instance, now, newStatus, err, result)
+	}
+	toSync.Spec.MessageTemplate = nil
+
+	injectedTags, err := r.getInjectedTags(ctx, instance)
+	if err != nil {
+		logger.Error(err, "error getting injected tags")
+
+		return r.updateStatusIfNeeded(logger, instance, now, newStatus, err, result)
+	}
+
+	instanceSpecHash, err := generateHash(&toSync.Spec, injectedTags)
 	if err != nil {
 		logger.Error(err, "error generating hash")
 
@@ -131,12 +169,7 @@ func (r *Reconciler) internalReconcile(ctx context.Context, req reconcile.Reques
 		logger.V(1).Info("Monitor ID is not set; creating monitor in Datadog")
 		// If the monitor ID is 0, then it doesn't exist yet in Datadog. Create the monitor (only metric alerts)
 		if isSupportedMonitorType(instance.Spec.Type) {
-			// Make sure required tags are present
-			if result, err = r.checkRequiredTags(logger, instance); err != nil || result.Requeue {
-				return r.updateStatusIfNeeded(logger, instance, now, newStatus, err, result)
-			}
-
-			if err = r.create(logger, instance, newStatus, now); err != nil {
+			if err = r.create(logger, toSync, injectedTags, newStatus, now); err != nil {
 				logger.Error(err, "error creating monitor")
 			}
 			newStatus.CurrentHash = instanceSpecHash
@@ -149,12 +182,8 @@ func (r *Reconciler) internalReconcile(ctx context.Context, req reconcile.Reques
 	} else {
 		// Check if instance needs to be updated
 		if instanceSpecHash != statusSpecHash {
-			// Make sure required tags are present
-			if result, err = r.checkRequiredTags(logger, instance); err != nil || result.Requeue {
-				return r.updateStatusIfNeeded(logger, instance, now, newStatus, err, result)
-			}
 			// Update action
-			if err = r.update(logger, instance, newStatus, now); err != nil {
+			if err = r.update(logger, toSync, injectedTags, newStatus, now); err != nil {
 				logger.Error(err, "error updating monitor", "Monitor ID", instance.Status.ID)
 			} else {
 				newStatus.CurrentHash = instanceSpecHash
@@ -177,24 +206,42 @@ func (r *Reconciler) internalReconcile(ctx context.Context, req reconcile.Reques
 		}
 	}
 
-	// Requeue
+	if isNotFoundError(err) && instance.Status.ID != 0 {
+		r.handleDeletedMonitor(logger, instance, newStatus)
+	}
+
+	// Requeue, backing off according to the type of error
+	if err != nil {
+		result.RequeueAfter = requeuePeriodForError(err)
+		if isNotFoundError(err) && r.recreateDeleted {
+			result.RequeueAfter = defaultErrRequeuePeriod
+		}
+	}
 	if !result.Requeue && result.RequeueAfter == 0 {
 		result.RequeueAfter = defaultRequeuePeriod
 	}
 
 	// Update the status
-	return r.updateStatusIfNeeded(logger, instance, now, newStatus, err, result)
+	result, statusErr := r.updateStatusIfNeeded(logger, instance, now, newStatus, err, result)
+	if statusErr == nil && getAPIErrorType(err) == apiErrorTypeServer {
+		// Rely on the exponential backoff of the controller for Datadog API server errors
+		return ctrl.Result{}, err
+	}
+
+	return result, statusErr
 }
 
-func (r *Reconciler) create(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.DatadogMonitor, status *datadoghqv1alpha1.DatadogMonitorStatus, now metav1.Time) error {
+func (r *Reconciler) create(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.DatadogMonitor, injectedTags []string, status *datadoghqv1alpha1.DatadogMonitorStatus, now metav1.Time) error {
 	// Validate monitor in Datadog
-	if err := validateMonitor(r.datadogAuth, logger, r.datadogClient, datadogMonitor); err != nil {
+	if err := validateMonitor(r.datadogAuth, logger, r.datadogClient, datadogMonitor, injectedTags); err != nil {
+		status.SyncStatus = syncStatusForError(err, status.SyncStatus)
 		return err
 	}
 
 	// Create monitor in Datadog
-	m, err := createMonitor(r.datadogAuth, logger, r.datadogClient, datadogMonitor)
+	m, err := createMonitor(r.datadogAuth, logger, r.datadogClient, datadogMonitor, injectedTags)
 	if err != nil {
+		status.SyncStatus = syncStatusForError(err, status.SyncStatus)
 		return err
 	}
 	event := buildEventInfo(datadogMonitor.Name, datadogMonitor.Namespace, datadog.CreationEvent)
@@ -216,16 +263,16 @@ func (r *Reconciler) create(logger logr.Logger, datadogMonitor *datadoghqv1alpha
 	return nil
 }
 
-func (r *Reconciler) update(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.DatadogMonitor, status *datadoghqv1alpha1.DatadogMonitorStatus, now metav1.Time) error {
+func (r *Reconciler) update(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.DatadogMonitor, injectedTags []string, status *datadoghqv1alpha1.DatadogMonitorStatus, now metav1.Time) error {
 	// Validate monitor in Datadog
-	if err := validateMonitor(r.datadogAuth, logger, r.datadogClient, datadogMonitor); err != nil {
-		status.SyncStatus = datadoghqv1alpha1.SyncStatusValidateError
+	if err := validateMonitor(r.datadogAuth, logger, r.datadogClient, datadogMonitor, injectedTags); err != nil {
+		status.SyncStatus = syncStatusForError(err, datadoghqv1alpha1.SyncStatusValidateError)
 		return err
 	}
 
 	// Update monitor in Datadog
-	if _, err := updateMonitor(r.datadogAuth, logger, r.datadogClient, datadogMonitor); err != nil {
-		status.SyncStatus = datadoghqv1alpha1.SyncStatusUpdateError
+	if _, err := updateMonitor(r.datadogAuth, logger, r.datadogClient, datadogMonitor, injectedTags); err != nil {
+		status.SyncStatus = syncStatusForError(err, datadoghqv1alpha1.SyncStatusUpdateError)
 		return err
 	}
 
@@ -244,7 +291,7 @@ func (r *Reconciler) get(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.D
 	// Get monitor from Datadog and update resource status if needed
 	m, err := getMonitor(r.datadogAuth, r.datadogClient, datadogMonitor.Status.ID)
 	if err != nil {
-		status.SyncStatus = datadoghqv1alpha1.SyncStatusGetError
+		status.SyncStatus = syncStatusForError(err, datadoghqv1alpha1.SyncStatusGetError)
 		return err
 	}
 
@@ -258,7 +305,7 @@ func (r *Reconciler) get(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.D
 
 func (r *Reconciler) updateStatusIfNeeded(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.DatadogMonitor, now metav1.Time, status *datadoghqv1alpha1.DatadogMonitorStatus, currentErr error, result ctrl.Result) (ctrl.Result, error) {
 	// Update Error and Active conditions
-	condition.SetErrorActiveConditions(status, now, currentErr)
+	condition.SetErrorActiveConditionsWithReason(status, now, currentErr, string(getAPIErrorType(currentErr)))
 
 	if !apiequality.Semantic.DeepEqual(&datadogMonitor.Status, status) {
 		datadogMonitor.Status = *status
@@ -279,49 +326,40 @@ func (r *Reconciler) updateStatusIfNeeded(logger logr.Logger, datadogMonitor *da
 		// not an issue, but if a monitor has many groups and is "flapping", then it can cause a flood of updates to
 		// the Status.TriggeredState and put pressure on the controller. As a safeguard against this, the maximum number
 		// of groups stored in Status.TriggeredState should be conservative.
-		return ctrl.Result{Requeue: true, RequeueAfter: defaultRequeuePeriod}, nil
+		requeueAfter := defaultRequeuePeriod
+		if result.RequeueAfter > 0 {
+			requeueAfter = result.RequeueAfter
+		}
+		return ctrl.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
 	}
 
 	return result, nil
 }
 
-func (r *Reconciler) checkRequiredTags(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.DatadogMonitor) (ctrl.Result, error) {
-	tagsToAdd := []string{}
-	var found bool
-	tags := datadogMonitor.Spec.Tags
-	for _, rT := range getRequiredTags() {
-		found = false
-		for _, t := range tags {
-			if t == rT {
-				found = true
-				break
-			}
-		}
-		if !found {
-			tagsToAdd = append(tagsToAdd, rT)
-		}
-	}
-
-	if len(tagsToAdd) > 0 {
-		tags = append(tags, tagsToAdd...)
-		datadogMonitor.Spec.Tags = tags
-		err := r.client.Update(context.TODO(), datadogMonitor)
-		if err != nil {
-			logger.Error(err, "failed to update DatadogMonitor with required tags")
-
-			return ctrl.Result{Requeue: true, RequeueAfter: defaultErrRequeuePeriod}, err
+// handleDeletedMonitor handles a monitor deleted in Datadog outside of the operator. Depending on the policy, the
+// monitor is either recreated at the next reconcile, or only reported in the status.
+func (r *Reconciler) handleDeletedMonitor(logger logr.Logger, datadogMonitor *datadoghqv1alpha1.DatadogMonitor, status *datadoghqv1alpha1.DatadogMonitorStatus) {
+	if !r.recreateDeleted {
+		if datadogMonitor.Status.SyncStatus != datadoghqv1alpha1.SyncStatusNotFoundError {
+			logger.Info("Monitor was deleted in Datadog", "Monitor ID", datadogMonitor.Status.ID)
+			r.recorder.Event(datadogMonitor, corev1.EventTypeWarning, monitorDeletedEventReason, fmt.Sprintf("Monitor %d was deleted in Datadog", datadogMonitor.Status.ID))
 		}
-		logger.Info("Added required tags", "Monitor Namespace", datadogMonitor.Namespace, "Monitor Name", datadogMonitor.Name, "Monitor ID", datadogMonitor.Status.ID)
-
-		return ctrl.Result{Requeue: true, RequeueAfter: defaultRequeuePeriod}, nil
+		return
 	}
 
-	// Proceed in reconcile loop.
-	return ctrl.Result{}, nil
-}
-
-func getRequiredTags() []string {
-	return []string{"generated:kubernetes"}
+	logger.Info("Monitor was deleted in Datadog, recreating it", "Monitor ID", datadogMonitor.Status.ID)
+	r.recorder.Event(datadogMonitor, corev1.EventTypeWarning, monitorDeletedEventReason, fmt.Sprintf("Monitor %d was deleted in Datadog, recreating it", datadogMonitor.Status.ID))
+
+	// Reset the information of the deleted monitor, so that it is created again
+	status.ID = 0
+	status.Creator = ""
+	status.Created = nil
+	status.Primary = false
+	status.CurrentHash = ""
+	status.MonitorState = ""
+	status.MonitorStateLastTransitionTime = nil
+	status.MonitorStateLastUpdateTime = nil
+	status.TriggeredState = nil
 }
 
 // convertStateToStatus updates status.MonitorState, status.TriggeredState, and status.DowntimeStatus according to the current state of the monitor
@@ -362,6 +400,19 @@ func convertStateToStatus(monitor datadogapiclientv1.Monitor, newStatus *datadog
 	newStatus.DowntimeStatus = datadoghqv1alpha1.DatadogMonitorDowntimeStatus{}
 }
 
+// generateHash returns the hash of the spec synced to Datadog, including the injected tags.
+// Without injected tags, the hash is the one of the spec so that it doesn't change on upgrade.
+func generateHash(spec *datadoghqv1alpha1.DatadogMonitorSpec, injectedTags []string) (string, error) {
+	if len(injectedTags) == 0 {
+		return comparison.GenerateMD5ForSpec(spec)
+	}
+
+	hashed := spec.DeepCopy()
+	hashed.Tags = mergeTags(spec.Tags, injectedTags)
+
+	return comparison.GenerateMD5ForSpec(hashed)
+}
+
 func isSupportedMonitorType(monitorType datadoghqv1alpha1.DatadogMonitorType) bool {
 	return supportedMonitorTypes[string(monitorType)]
 }
@@ -105,7 +105,7 @@ func TestReconcileDatadogMonitor_Reconcile(t *testing.T) {
 			},
 		},
 		{
-			name: "DatadogMonitor exists, check required tags",
+			name: "DatadogMonitor exists, required tags are not added to the spec",
 			args: args{
 				request: newRequest(resourcesNamespace, resourcesName),
 				firstAction: func(c client.Client) {
@@ -119,7 +119,7 @@ func TestReconcileDatadogMonitor_Reconcile(t *testing.T) {
 				if err := c.Get(context.TODO(), types.NamespacedName{Name: resourcesName, Namespace: resourcesNamespace}, dm); err != nil {
 					return err
 				}
-				assert.Contains(t, dm.Spec.Tags, "generated:kubernetes")
+				assert.NotContains(t, dm.Spec.Tags, "generated:kubernetes")
 				return nil
 			},
 		},
@@ -159,7 +159,7 @@ func TestReconcileDatadogMonitor_Reconcile(t *testing.T) {
 					return err
 				}
 				// Make sure status hash is up to date
-				hash, _ := comparison.GenerateMD5ForSpec(dm.Spec)
+				hash, _ := generateHash(&dm.Spec, nil)
 				assert.Equal(t, dm.Status.CurrentHash, hash)
 				return nil
 			},
@@ -397,6 +397,32 @@ func TestReconcileDatadogMonitor_Reconcile(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "DatadogMonitor generated from an untranslatable source",
+			args: args{
+				request: newRequest(resourcesNamespace, resourcesName),
+				firstAction: func(c client.Client) {
+					dm := genericDatadogMonitor()
+					dm.Annotations = map[string]string{
+						datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey: "unsupported function \"absent\"",
+					}
+					_ = c.Create(context.TODO(), dm)
+				},
+				firstReconcileCount: 2,
+			},
+			wantResult: reconcile.Result{Requeue: true, RequeueAfter: defaultRequeuePeriod},
+			wantErr:    false,
+			wantFunc: func(c client.Client) error {
+				dm := &datadoghqv1alpha1.DatadogMonitor{}
+				if err := c.Get(context.TODO(), types.NamespacedName{Name: resourcesName, Namespace: resourcesNamespace}, dm); err != nil {
+					return err
+				}
+				assert.Equal(t, 0, dm.Status.ID)
+				assert.Equal(t, datadoghqv1alpha1.DatadogMonitorConditionTypeError, dm.Status.Conditions[0].Type)
+				assert.Contains(t, dm.Status.Conditions[0].Message, "unsupported function")
+				return nil
+			},
+		},
 		{
 			name: "DatadogMonitor of unsupported type (composite)",
 			args: args{
@@ -507,6 +533,21 @@ func newRequest(ns, name string) reconcile.Request {
 	}
 }
 
+func Test_generateHash(t *testing.T) {
+	spec := &genericDatadogMonitor().Spec
+
+	// Without injected tags, the hash is the one computed before tag injection existed
+	specHash, err := comparison.GenerateMD5ForSpec(spec)
+	assert.NoError(t, err)
+	hash, err := generateHash(spec, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, specHash, hash)
+
+	injectedHash, err := generateHash(spec, []string{"kube_cluster_name:foo"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, specHash, injectedHash)
+}
+
 func Test_convertStateToStatus(t *testing.T) {
 	triggerTs := int64(1612244495)
 	secondTriggerTs := triggerTs + 300
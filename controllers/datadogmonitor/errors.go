@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogmonitor
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+const (
+	authErrRequeuePeriod       = 5 * time.Minute
+	validationErrRequeuePeriod = 10 * time.Minute
+	rateLimitErrRequeuePeriod  = 60 * time.Second
+
+	rateLimitResetHeader = "X-RateLimit-Reset"
+)
+
+// apiErrorType classifies the errors returned by the Datadog API, it is used as the reason of the Error condition
+type apiErrorType string
+
+const (
+	apiErrorTypeAuthentication apiErrorType = "AuthenticationError"
+	apiErrorTypeForbidden      apiErrorType = "Forbidden"
+	apiErrorTypeValidation     apiErrorType = "ValidationError"
+	apiErrorTypeNotFound       apiErrorType = "NotFound"
+	apiErrorTypeRateLimit      apiErrorType = "RateLimited"
+	apiErrorTypeServer         apiErrorType = "ServerError"
+)
+
+// apiError is a Datadog API error classified from the HTTP response
+type apiError struct {
+	errType apiErrorType
+	// retryAfter is the delay before the rate limit is reset, for rate limit errors
+	retryAfter time.Duration
+	err        error
+}
+
+func (e *apiError) Error() string {
+	return e.err.Error()
+}
+
+func (e *apiError) Unwrap() error {
+	return e.err
+}
+
+// newAPIError classifies err according to the status code of the HTTP response. Errors without a
+// response (network errors) or with an unexpected status code are returned unchanged.
+func newAPIError(err error, httpResp *http.Response) error {
+	if err == nil || httpResp == nil {
+		return err
+	}
+
+	e := &apiError{err: err}
+	switch code := httpResp.StatusCode; {
+	case code == http.StatusUnauthorized:
+		e.errType = apiErrorTypeAuthentication
+	case code == http.StatusForbidden:
+		e.errType = apiErrorTypeForbidden
+	case code == http.StatusNotFound:
+		e.errType = apiErrorTypeNotFound
+	case code == http.StatusTooManyRequests:
+		e.errType = apiErrorTypeRateLimit
+		e.retryAfter = rateLimitErrRequeuePeriod
+		if reset, parseErr := strconv.Atoi(httpResp.Header.Get(rateLimitResetHeader)); parseErr == nil && reset > 0 {
+			e.retryAfter = time.Duration(reset) * time.Second
+		}
+	case code == http.StatusBadRequest || code == http.StatusUnprocessableEntity:
+		e.errType = apiErrorTypeValidation
+	case code >= http.StatusInternalServerError:
+		e.errType = apiErrorTypeServer
+	default:
+		return err
+	}
+
+	return e
+}
+
+// getAPIErrorType returns the type of a classified API error, or an empty type
+func getAPIErrorType(err error) apiErrorType {
+	var e *apiError
+	if errors.As(err, &e) {
+		return e.errType
+	}
+
+	return ""
+}
+
+func isNotFoundError(err error) bool {
+	return getAPIErrorType(err) == apiErrorTypeNotFound
+}
+
+// syncStatusForError returns the SyncStatus matching the error type, or defaultStatus for unclassified errors
+func syncStatusForError(err error, defaultStatus datadoghqv1alpha1.SyncStatusMessage) datadoghqv1alpha1.SyncStatusMessage {
+	switch getAPIErrorType(err) {
+	case apiErrorTypeAuthentication:
+		return datadoghqv1alpha1.SyncStatusAuthenticationError
+	case apiErrorTypeForbidden:
+		return datadoghqv1alpha1.SyncStatusForbiddenError
+	case apiErrorTypeValidation:
+		return datadoghqv1alpha1.SyncStatusValidateError
+	case apiErrorTypeNotFound:
+		return datadoghqv1alpha1.SyncStatusNotFoundError
+	case apiErrorTypeRateLimit:
+		return datadoghqv1alpha1.SyncStatusRateLimitError
+	case apiErrorTypeServer:
+		return datadoghqv1alpha1.SyncStatusServerError
+	default:
+		return defaultStatus
+	}
+}
+
+// requeuePeriodForError returns the delay before retrying after err. Authentication and validation errors
+// are not retried aggressively as they require a change of the credentials or of the spec (which triggers a
+// reconcile anyway). Server errors are retried with the exponential backoff of the controller instead.
+func requeuePeriodForError(err error) time.Duration {
+	var e *apiError
+	if !errors.As(err, &e) {
+		return defaultRequeuePeriod
+	}
+
+	switch e.errType {
+	case apiErrorTypeAuthentication, apiErrorTypeForbidden:
+		return authErrRequeuePeriod
+	case apiErrorTypeValidation:
+		return validationErrRequeuePeriod
+	case apiErrorTypeRateLimit:
+		return e.retryAfter
+	default:
+		return defaultRequeuePeriod
+	}
+}
@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogmonitor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadogapiclientv1 "github.com/DataDog/datadog-api-client-go/api/v1/datadog"
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+func Test_newAPIError(t *testing.T) {
+	errGeneric := errors.New("generic error")
+
+	tests := []struct {
+		name           string
+		statusCode     int
+		header         http.Header
+		wantType       apiErrorType
+		wantSyncStatus datadoghqv1alpha1.SyncStatusMessage
+		wantRequeue    time.Duration
+	}{
+		{
+			name:           "authentication error",
+			statusCode:     http.StatusUnauthorized,
+			wantType:       apiErrorTypeAuthentication,
+			wantSyncStatus: datadoghqv1alpha1.SyncStatusAuthenticationError,
+			wantRequeue:    authErrRequeuePeriod,
+		},
+		{
+			name:           "forbidden",
+			statusCode:     http.StatusForbidden,
+			wantType:       apiErrorTypeForbidden,
+			wantSyncStatus: datadoghqv1alpha1.SyncStatusForbiddenError,
+			wantRequeue:    authErrRequeuePeriod,
+		},
+		{
+			name:           "validation error",
+			statusCode:     http.StatusBadRequest,
+			wantType:       apiErrorTypeValidation,
+			wantSyncStatus: datadoghqv1alpha1.SyncStatusValidateError,
+			wantRequeue:    validationErrRequeuePeriod,
+		},
+		{
+			name:           "not found",
+			statusCode:     http.StatusNotFound,
+			wantType:       apiErrorTypeNotFound,
+			wantSyncStatus: datadoghqv1alpha1.SyncStatusNotFoundError,
+			wantRequeue:    defaultRequeuePeriod,
+		},
+		{
+			name:           "rate limit with reset header",
+			statusCode:     http.StatusTooManyRequests,
+			header:         http.Header{http.CanonicalHeaderKey(rateLimitResetHeader): []string{"12"}},
+			wantType:       apiErrorTypeRateLimit,
+			wantSyncStatus: datadoghqv1alpha1.SyncStatusRateLimitError,
+			wantRequeue:    12 * time.Second,
+		},
+		{
+			name:           "rate limit without reset header",
+			statusCode:     http.StatusTooManyRequests,
+			wantType:       apiErrorTypeRateLimit,
+			wantSyncStatus: datadoghqv1alpha1.SyncStatusRateLimitError,
+			wantRequeue:    rateLimitErrRequeuePeriod,
+		},
+		{
+			name:           "server error",
+			statusCode:     http.StatusBadGateway,
+			wantType:       apiErrorTypeServer,
+			wantSyncStatus: datadoghqv1alpha1.SyncStatusServerError,
+			wantRequeue:    defaultRequeuePeriod,
+		},
+		{
+			name:           "unclassified error",
+			statusCode:     http.StatusConflict,
+			wantSyncStatus: datadoghqv1alpha1.SyncStatusGetError,
+			wantRequeue:    defaultRequeuePeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newAPIError(errGeneric, &http.Response{StatusCode: tt.statusCode, Header: tt.header})
+			assert.True(t, errors.Is(err, errGeneric))
+			assert.Equal(t, tt.wantType, getAPIErrorType(err))
+			assert.Equal(t, tt.wantSyncStatus, syncStatusForError(err, datadoghqv1alpha1.SyncStatusGetError))
+			assert.Equal(t, tt.wantRequeue, requeuePeriodForError(err))
+		})
+	}
+
+	assert.Equal(t, errGeneric, newAPIError(errGeneric, nil))
+}
+
+func TestReconcileDatadogMonitor_deletedMonitor(t *testing.T) {
+	eventBroadcaster := record.NewBroadcaster()
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "TestReconcileDatadogMonitor_deletedMonitor"})
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogMonitor{})
+
+	tests := []struct {
+		name            string
+		recreateDeleted bool
+		wantStatus      func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus)
+	}{
+		{
+			name:            "deleted monitor is recreated",
+			recreateDeleted: true,
+			wantStatus: func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus) {
+				assert.Equal(t, 0, status.ID)
+				assert.Empty(t, status.CurrentHash)
+				assert.False(t, status.Primary)
+			},
+		},
+		{
+			name:            "deleted monitor is flagged",
+			recreateDeleted: false,
+			wantStatus: func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus) {
+				assert.Equal(t, 12345, status.ID)
+				assert.True(t, status.Primary)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors": ["Monitor not found"]}`))
+			}))
+			defer httpServer.Close()
+
+			testConfig := datadogapiclientv1.NewConfiguration()
+			testConfig.HTTPClient = httpServer.Client()
+
+			dm := genericDatadogMonitor()
+			dm.Finalizers = []string{datadogMonitorFinalizer}
+			dm.Status = datadoghqv1alpha1.DatadogMonitorStatus{
+				ID:      12345,
+				Primary: true,
+			}
+			dm.Status.CurrentHash, _ = generateHash(&dm.Spec, nil)
+
+			r := &Reconciler{
+				client:          fake.NewFakeClientWithScheme(s, dm),
+				datadogClient:   datadogapiclientv1.NewAPIClient(testConfig),
+				datadogAuth:     setupTestAuth(httpServer.URL),
+				scheme:          s,
+				recorder:        recorder,
+				log:             logf.Log.WithName(tt.name),
+				recreateDeleted: tt.recreateDeleted,
+			}
+
+			_, err := r.Reconcile(context.TODO(), newRequest(resourcesNamespace, resourcesName))
+			assert.NoError(t, err)
+
+			updated := &datadoghqv1alpha1.DatadogMonitor{}
+			assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Namespace: resourcesNamespace, Name: resourcesName}, updated))
+			assert.Equal(t, datadoghqv1alpha1.SyncStatusNotFoundError, updated.Status.SyncStatus)
+			for _, c := range updated.Status.Conditions {
+				if c.Type == datadoghqv1alpha1.DatadogMonitorConditionTypeError {
+					assert.Equal(t, corev1.ConditionTrue, c.Status)
+					assert.Equal(t, string(apiErrorTypeNotFound), c.Reason)
+				}
+			}
+			tt.wantStatus(t, updated.Status)
+		})
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"sort"
 	"strconv"
 
 	"github.com/go-logr/logr"
@@ -19,7 +18,7 @@ import (
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 )
 
-func buildMonitor(logger logr.Logger, dm *datadoghqv1alpha1.DatadogMonitor) (*datadogapiclientv1.Monitor, *datadogapiclientv1.MonitorUpdateRequest) {
+func buildMonitor(logger logr.Logger, dm *datadoghqv1alpha1.DatadogMonitor, injectedTags []string) (*datadogapiclientv1.Monitor, *datadogapiclientv1.MonitorUpdateRequest) {
 	monitorType := datadogapiclientv1.MonitorType(string(dm.Spec.Type))
 	name := dm.Spec.Name
 	msg := dm.Spec.Message
@@ -157,8 +156,7 @@ func buildMonitor(logger logr.Logger, dm *datadoghqv1alpha1.DatadogMonitor) (*da
 		u.SetOptions(o)
 	}
 
-	tags := dm.Spec.Tags
-	sort.Strings(tags)
+	tags := mergeTags(dm.Spec.Tags, injectedTags)
 	m.SetTags(tags)
 	u.SetTags(tags)
 
@@ -170,39 +168,39 @@ func getMonitor(auth context.Context, client *datadogapiclientv1.APIClient, moni
 	optionalParams := datadogapiclientv1.GetMonitorOptionalParameters{
 		GroupStates: &groupStates,
 	}
-	m, _, err := client.MonitorsApi.GetMonitor(auth, int64(monitorID), optionalParams)
+	m, httpResp, err := client.MonitorsApi.GetMonitor(auth, int64(monitorID), optionalParams)
 	if err != nil {
-		return datadogapiclientv1.Monitor{}, translateClientError(err, "error getting monitor")
+		return datadogapiclientv1.Monitor{}, newAPIError(translateClientError(err, "error getting monitor"), httpResp)
 	}
 
 	return m, nil
 }
 
-func validateMonitor(auth context.Context, logger logr.Logger, client *datadogapiclientv1.APIClient, dm *datadoghqv1alpha1.DatadogMonitor) error {
-	m, _ := buildMonitor(logger, dm)
-	if _, _, err := client.MonitorsApi.ValidateMonitor(auth, *m); err != nil {
-		return translateClientError(err, "error validating monitor")
+func validateMonitor(auth context.Context, logger logr.Logger, client *datadogapiclientv1.APIClient, dm *datadoghqv1alpha1.DatadogMonitor, injectedTags []string) error {
+	m, _ := buildMonitor(logger, dm, injectedTags)
+	if _, httpResp, err := client.MonitorsApi.ValidateMonitor(auth, *m); err != nil {
+		return newAPIError(translateClientError(err, "error validating monitor"), httpResp)
 	}
 
 	return nil
 }
 
-func createMonitor(auth context.Context, logger logr.Logger, client *datadogapiclientv1.APIClient, dm *datadoghqv1alpha1.DatadogMonitor) (datadogapiclientv1.Monitor, error) {
-	m, _ := buildMonitor(logger, dm)
-	mCreated, _, err := client.MonitorsApi.CreateMonitor(auth, *m)
+func createMonitor(auth context.Context, logger logr.Logger, client *datadogapiclientv1.APIClient, dm *datadoghqv1alpha1.DatadogMonitor, injectedTags []string) (datadogapiclientv1.Monitor, error) {
+	m, _ := buildMonitor(logger, dm, injectedTags)
+	mCreated, httpResp, err := client.MonitorsApi.CreateMonitor(auth, *m)
 	if err != nil {
-		return datadogapiclientv1.Monitor{}, translateClientError(err, "error creating monitor")
+		return datadogapiclientv1.Monitor{}, newAPIError(translateClientError(err, "error creating monitor"), httpResp)
 	}
 
 	return mCreated, nil
 }
 
-func updateMonitor(auth context.Context, logger logr.Logger, client *datadogapiclientv1.APIClient, dm *datadoghqv1alpha1.DatadogMonitor) (datadogapiclientv1.Monitor, error) {
-	_, u := buildMonitor(logger, dm)
+func updateMonitor(auth context.Context, logger logr.Logger, client *datadogapiclientv1.APIClient, dm *datadoghqv1alpha1.DatadogMonitor, injectedTags []string) (datadogapiclientv1.Monitor, error) {
+	_, u := buildMonitor(logger, dm, injectedTags)
 
-	mUpdated, _, err := client.MonitorsApi.UpdateMonitor(auth, int64(dm.Status.ID), *u)
+	mUpdated, httpResp, err := client.MonitorsApi.UpdateMonitor(auth, int64(dm.Status.ID), *u)
 	if err != nil {
-		return datadogapiclientv1.Monitor{}, translateClientError(err, "error updating monitor")
+		return datadogapiclientv1.Monitor{}, newAPIError(translateClientError(err, "error updating monitor"), httpResp)
 	}
 
 	// TODO additional logic to handle downtimes (and silenced param if needed)
@@ -215,8 +213,8 @@ func deleteMonitor(auth context.Context, client *datadogapiclientv1.APIClient, m
 	optionalParams := datadogapiclientv1.DeleteMonitorOptionalParameters{
 		Force: &force,
 	}
-	if _, _, err := client.MonitorsApi.DeleteMonitor(auth, int64(monitorID), optionalParams); err != nil {
-		return translateClientError(err, "error deleting monitor")
+	if _, httpResp, err := client.MonitorsApi.DeleteMonitor(auth, int64(monitorID), optionalParams); err != nil {
+		return newAPIError(translateClientError(err, "error deleting monitor"), httpResp)
 	}
 
 	return nil
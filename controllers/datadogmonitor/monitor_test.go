@@ -67,7 +67,7 @@ func Test_buildMonitor(t *testing.T) {
 		},
 	}
 
-	monitor, monitorUR := buildMonitor(testLogger, dm)
+	monitor, monitorUR := buildMonitor(testLogger, dm, []string{"kube_namespace:other", "team:web"})
 
 	assert.Equal(t, dm.Spec.Query, monitor.GetQuery(), "discrepancy found in parameter: Query")
 	assert.Equal(t, dm.Spec.Query, monitorUR.GetQuery(), "discrepancy found in parameter: Query")
@@ -84,8 +84,10 @@ func Test_buildMonitor(t *testing.T) {
 	assert.Equal(t, dm.Spec.Priority, monitor.GetPriority(), "discrepancy found in parameter: Priority")
 	assert.Equal(t, dm.Spec.Priority, monitorUR.GetPriority(), "discrepancy found in parameter: Priority")
 
-	assert.Equal(t, dm.Spec.Tags, monitor.GetTags(), "discrepancy found in parameter: Tags")
-	assert.Equal(t, dm.Spec.Tags, monitorUR.GetTags(), "discrepancy found in parameter: Tags")
+	// Required and injected tags are added, spec tags take precedence over injected tags with the same key
+	expectedTags := []string{"env:staging", "generated:kubernetes", "kube_cluster:test.staging", "kube_namespace:test", "team:web"}
+	assert.Equal(t, expectedTags, monitor.GetTags(), "discrepancy found in parameter: Tags")
+	assert.Equal(t, expectedTags, monitorUR.GetTags(), "discrepancy found in parameter: Tags")
 
 	assert.Equal(t, *dm.Spec.Options.EvaluationDelay, monitor.Options.GetEvaluationDelay(), "discrepancy found in parameter: EvaluationDelay")
 	assert.Equal(t, *dm.Spec.Options.EvaluationDelay, monitorUR.Options.GetEvaluationDelay(), "discrepancy found in parameter: EvaluationDelay")
@@ -121,14 +123,8 @@ func Test_buildMonitor(t *testing.T) {
 	assert.Equal(t, warnVal, (&apiMonitorThresholds).GetWarning(), "discrepancy found in parameter: Threshold.Warning")
 	assert.Equal(t, critVal, (&apiMonitorURThresholds).GetCritical(), "discrepancy found in parameter: Threshold.Critical")
 
-	// Also make sure tags are sorted
-	assert.Equal(t, "env:staging", (monitor.GetTags())[0], "tags are not properly sorted")
-	assert.Equal(t, "kube_cluster:test.staging", (monitor.GetTags())[1], "tags are not properly sorted")
-	assert.Equal(t, "kube_namespace:test", (monitor.GetTags())[2], "tags are not properly sorted")
-
-	assert.Equal(t, "env:staging", (monitorUR.GetTags())[0], "tags are not properly sorted")
-	assert.Equal(t, "kube_cluster:test.staging", (monitorUR.GetTags())[1], "tags are not properly sorted")
-	assert.Equal(t, "kube_namespace:test", (monitorUR.GetTags())[2], "tags are not properly sorted")
+	// The DatadogMonitor spec is not modified
+	assert.Equal(t, []string{"env:staging", "kube_namespace:test", "kube_cluster:test.staging"}, dm.Spec.Tags)
 }
 
 func Test_getMonitor(t *testing.T) {
@@ -164,7 +160,7 @@ func Test_validateMonitor(t *testing.T) {
 	client := datadogapiclientv1.NewAPIClient(testConfig)
 	testAuth := setupTestAuth(httpServer.URL)
 
-	err := validateMonitor(testAuth, testLogger, client, dm)
+	err := validateMonitor(testAuth, testLogger, client, dm, nil)
 	assert.Nil(t, err)
 }
 
@@ -198,7 +194,7 @@ func Test_createMonitor(t *testing.T) {
 	client := datadogapiclientv1.NewAPIClient(testConfig)
 	testAuth := setupTestAuth(httpServer.URL)
 
-	monitor, err := createMonitor(testAuth, testLogger, client, dm)
+	monitor, err := createMonitor(testAuth, testLogger, client, dm, nil)
 	assert.Nil(t, err)
 
 	assert.Equal(t, dm.Spec.Query, monitor.GetQuery(), "discrepancy found in parameter: Query")
@@ -241,7 +237,7 @@ func Test_updateMonitor(t *testing.T) {
 	client := datadogapiclientv1.NewAPIClient(testConfig)
 	testAuth := setupTestAuth(httpServer.URL)
 
-	monitor, err := updateMonitor(testAuth, testLogger, client, dm)
+	monitor, err := updateMonitor(testAuth, testLogger, client, dm, nil)
 	assert.Nil(t, err)
 
 	assert.Equal(t, dm.Spec.Query, monitor.GetQuery(), "discrepancy found in parameter: Query")
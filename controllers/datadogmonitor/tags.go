@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogmonitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+const (
+	clusterNameTagKey = "kube_cluster_name"
+	namespaceTagKey   = "kube_namespace"
+)
+
+// TagPolicy defines the tags injected into the monitors sent to Datadog, on top of the required ones.
+// Injected tags are never written back to the DatadogMonitor spec, and a tag key already set in the spec
+// takes precedence over the injected one.
+type TagPolicy struct {
+	// ClusterName is injected as `kube_cluster_name:<ClusterName>` when set
+	ClusterName string
+	// NamespaceTag injects `kube_namespace:<namespace>`
+	NamespaceTag bool
+	// LabelsAsTags maps a label key to a tag key. Labels are read from the DatadogMonitor namespace,
+	// then from the DatadogMonitor itself, which takes precedence.
+	LabelsAsTags map[string]string
+}
+
+// getInjectedTags returns the tags injected into the monitor according to the tag policy
+func (r *Reconciler) getInjectedTags(ctx context.Context, datadogMonitor *datadoghqv1alpha1.DatadogMonitor) ([]string, error) {
+	var namespace *corev1.Namespace
+	if len(r.tagPolicy.LabelsAsTags) > 0 {
+		namespace = &corev1.Namespace{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: datadogMonitor.Namespace}, namespace); err != nil {
+			return nil, fmt.Errorf("unable to get namespace %s: %w", datadogMonitor.Namespace, err)
+		}
+	}
+
+	return r.tagPolicy.tags(datadogMonitor, namespace), nil
+}
+
+// tags returns the injected tags for a DatadogMonitor and its namespace
+func (p *TagPolicy) tags(datadogMonitor *datadoghqv1alpha1.DatadogMonitor, namespace *corev1.Namespace) []string {
+	tags := []string{}
+	if p.ClusterName != "" {
+		tags = append(tags, fmt.Sprintf("%s:%s", clusterNameTagKey, p.ClusterName))
+	}
+	if p.NamespaceTag {
+		tags = append(tags, fmt.Sprintf("%s:%s", namespaceTagKey, datadogMonitor.Namespace))
+	}
+
+	labelTags := map[string]string{}
+	for _, labels := range []map[string]string{namespaceLabels(namespace), datadogMonitor.Labels} {
+		for label, tagKey := range p.LabelsAsTags {
+			if value, found := labels[label]; found && value != "" {
+				labelTags[tagKey] = value
+			}
+		}
+	}
+	for tagKey, value := range labelTags {
+		tags = append(tags, fmt.Sprintf("%s:%s", tagKey, value))
+	}
+
+	return tags
+}
+
+func namespaceLabels(namespace *corev1.Namespace) map[string]string {
+	if namespace == nil {
+		return nil
+	}
+	return namespace.Labels
+}
+
+func getRequiredTags() []string {
+	return []string{"generated:kubernetes"}
+}
+
+// mergeTags returns the sorted spec and required tags, completed by the injected tags whose key is not already set in the spec
+func mergeTags(specTags, injectedTags []string) []string {
+	tags := make([]string, 0, len(specTags)+len(injectedTags)+len(getRequiredTags()))
+	seen := map[string]bool{}
+	keys := map[string]bool{}
+	for _, tag := range append(getRequiredTags(), specTags...) {
+		if !seen[tag] {
+			tags = append(tags, tag)
+			seen[tag] = true
+		}
+		keys[tagKey(tag)] = true
+	}
+	for _, tag := range injectedTags {
+		if seen[tag] || keys[tagKey(tag)] {
+			continue
+		}
+		tags = append(tags, tag)
+		seen[tag] = true
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+func tagKey(tag string) string {
+	return strings.SplitN(tag, ":", 2)[0]
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogmonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+func Test_getInjectedTags(t *testing.T) {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   resourcesNamespace,
+			Labels: map[string]string{"team": "web", "tier": "frontend"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		policy   TagPolicy
+		labels   map[string]string
+		specTags []string
+		want     []string
+	}{
+		{
+			name: "default policy only adds required tags",
+			want: []string{"generated:kubernetes"},
+		},
+		{
+			name:   "cluster and namespace tags",
+			policy: TagPolicy{ClusterName: "prod", NamespaceTag: true},
+			want:   []string{"generated:kubernetes", "kube_cluster_name:prod", "kube_namespace:bar"},
+		},
+		{
+			name:   "labels as tags, monitor labels take precedence",
+			policy: TagPolicy{LabelsAsTags: map[string]string{"team": "team", "tier": "tier", "missing": "missing"}},
+			labels: map[string]string{"team": "api"},
+			want:   []string{"generated:kubernetes", "team:api", "tier:frontend"},
+		},
+		{
+			name:     "spec tags take precedence",
+			policy:   TagPolicy{NamespaceTag: true, LabelsAsTags: map[string]string{"team": "team"}},
+			specTags: []string{"team:sre", "generated:kubernetes"},
+			want:     []string{"generated:kubernetes", "kube_namespace:bar", "team:sre"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{
+				client:    fake.NewFakeClient(namespace),
+				tagPolicy: tt.policy,
+			}
+			dm := &datadoghqv1alpha1.DatadogMonitor{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourcesName,
+					Namespace: resourcesNamespace,
+					Labels:    tt.labels,
+				},
+			}
+
+			injectedTags, err := r.getInjectedTags(context.TODO(), dm)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, mergeTags(tt.specTags, injectedTags))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogmonitor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+const (
+	// Delimiters used by message templates, as `{{` `}}` are reserved for Datadog template variables
+	templateLeftDelim  = "[["
+	templateRightDelim = "]]"
+)
+
+// templateObject holds the metadata of an object exposed to message templates
+type templateObject struct {
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// templateData is the data passed to message templates
+type templateData struct {
+	Monitor   templateObject
+	Namespace templateObject
+}
+
+// renderMessage returns the message to sync to Datadog: spec.Message followed by the rendered message template, if any.
+func (r *Reconciler) renderMessage(ctx context.Context, datadogMonitor *datadoghqv1alpha1.DatadogMonitor) (string, error) {
+	if datadogMonitor.Spec.MessageTemplate == nil {
+		return datadogMonitor.Spec.Message, nil
+	}
+
+	tpl, err := r.getMessageTemplate(ctx, datadogMonitor)
+	if err != nil {
+		return "", err
+	}
+
+	namespace := &corev1.Namespace{}
+	if err = r.client.Get(ctx, types.NamespacedName{Name: datadogMonitor.Namespace}, namespace); err != nil {
+		return "", fmt.Errorf("unable to get namespace %s: %w", datadogMonitor.Namespace, err)
+	}
+
+	data := templateData{
+		Monitor: templateObject{
+			Name:        datadogMonitor.Name,
+			Namespace:   datadogMonitor.Namespace,
+			Labels:      datadogMonitor.Labels,
+			Annotations: datadogMonitor.Annotations,
+		},
+		Namespace: templateObject{
+			Name:        namespace.Name,
+			Labels:      namespace.Labels,
+			Annotations: namespace.Annotations,
+		},
+	}
+
+	rendered, err := renderTemplate(tpl, data)
+	if err != nil {
+		return "", err
+	}
+
+	if datadogMonitor.Spec.Message == "" {
+		return rendered, nil
+	}
+
+	return fmt.Sprintf("%s\n\n%s", datadogMonitor.Spec.Message, rendered), nil
+}
+
+// getMessageTemplate returns the raw template referenced by the DatadogMonitor
+func (r *Reconciler) getMessageTemplate(ctx context.Context, datadogMonitor *datadoghqv1alpha1.DatadogMonitor) (string, error) {
+	ref := datadogMonitor.Spec.MessageTemplate
+	if ref.ConfigMapKeyRef != nil {
+		cm := &corev1.ConfigMap{}
+		if err := r.client.Get(ctx, types.NamespacedName{Namespace: datadogMonitor.Namespace, Name: ref.ConfigMapKeyRef.Name}, cm); err != nil {
+			return "", fmt.Errorf("unable to get message template configmap %s: %w", ref.ConfigMapKeyRef.Name, err)
+		}
+		tpl, found := cm.Data[ref.ConfigMapKeyRef.Key]
+		if !found {
+			return "", fmt.Errorf("key %s not found in message template configmap %s", ref.ConfigMapKeyRef.Key, ref.ConfigMapKeyRef.Name)
+		}
+		return tpl, nil
+	}
+
+	monitorTemplate := &datadoghqv1alpha1.DatadogMonitorTemplate{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: ref.TemplateName}, monitorTemplate); err != nil {
+		return "", fmt.Errorf("unable to get DatadogMonitorTemplate %s: %w", ref.TemplateName, err)
+	}
+
+	return monitorTemplate.Spec.Message, nil
+}
+
+// UsesConfigMapTemplate returns true if the message template of the DatadogMonitor is stored in the given ConfigMap of its namespace
+func UsesConfigMapTemplate(datadogMonitor *datadoghqv1alpha1.DatadogMonitor, configMapName string) bool {
+	ref := datadogMonitor.Spec.MessageTemplate
+	return ref != nil && ref.ConfigMapKeyRef != nil && ref.ConfigMapKeyRef.Name == configMapName
+}
+
+// UsesClusterTemplate returns true if the message template of the DatadogMonitor is the given DatadogMonitorTemplate
+func UsesClusterTemplate(datadogMonitor *datadoghqv1alpha1.DatadogMonitor, templateName string) bool {
+	ref := datadogMonitor.Spec.MessageTemplate
+	return ref != nil && ref.ConfigMapKeyRef == nil && ref.TemplateName == templateName
+}
+
+func renderTemplate(tpl string, data templateData) (string, error) {
+	t, err := template.New("message").Delims(templateLeftDelim, templateRightDelim).Option("missingkey=error").Parse(tpl)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse message template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("unable to render message template: %w", err)
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogmonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+func Test_renderMessage(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogMonitor{}, &datadoghqv1alpha1.DatadogMonitorTemplate{})
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        resourcesNamespace,
+			Labels:      map[string]string{"team": "web"},
+			Annotations: map[string]string{"datadoghq.com/notify": "@slack-web"},
+		},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "templates",
+			Namespace: resourcesNamespace,
+		},
+		Data: map[string]string{
+			"default": `Team [[ index .Namespace.Labels "team" ]] [[ index .Namespace.Annotations "datadoghq.com/notify" ]]`,
+			"invalid": `[[ .Unknown ]]`,
+		},
+	}
+	monitorTemplate := &datadoghqv1alpha1.DatadogMonitorTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cluster",
+		},
+		Spec: datadoghqv1alpha1.DatadogMonitorTemplateSpec{
+			Message: "Monitor [[ .Monitor.Namespace ]]/[[ .Monitor.Name ]] {{#is_alert}}@pagerduty{{/is_alert}}",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		message  string
+		template *datadoghqv1alpha1.DatadogMonitorMessageTemplate
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:    "no template",
+			message: "Something is wrong",
+			want:    "Something is wrong",
+		},
+		{
+			name:     "configmap template appended to message",
+			message:  "Something is wrong",
+			template: &datadoghqv1alpha1.DatadogMonitorMessageTemplate{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "templates"}, Key: "default"}},
+			want:     "Something is wrong\n\nTeam web @slack-web",
+		},
+		{
+			name:     "cluster template keeps Datadog template variables",
+			template: &datadoghqv1alpha1.DatadogMonitorMessageTemplate{TemplateName: "cluster"},
+			want:     "Monitor bar/foo {{#is_alert}}@pagerduty{{/is_alert}}",
+		},
+		{
+			name:     "missing configmap key",
+			template: &datadoghqv1alpha1.DatadogMonitorMessageTemplate{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "templates"}, Key: "missing"}},
+			wantErr:  true,
+		},
+		{
+			name:     "missing cluster template",
+			template: &datadoghqv1alpha1.DatadogMonitorMessageTemplate{TemplateName: "missing"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid template variable",
+			template: &datadoghqv1alpha1.DatadogMonitorMessageTemplate{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "templates"}, Key: "invalid"}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{
+				client: fake.NewFakeClientWithScheme(s, []runtime.Object{namespace, configMap, monitorTemplate}...),
+			}
+			dm := &datadoghqv1alpha1.DatadogMonitor{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      resourcesName,
+					Namespace: resourcesNamespace,
+				},
+				Spec: datadoghqv1alpha1.DatadogMonitorSpec{
+					Message:         tt.message,
+					MessageTemplate: tt.template,
+				},
+			}
+
+			got, err := r.renderMessage(context.TODO(), dm)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_UsesTemplate(t *testing.T) {
+	configMapRef := &datadoghqv1alpha1.DatadogMonitor{Spec: datadoghqv1alpha1.DatadogMonitorSpec{
+		MessageTemplate: &datadoghqv1alpha1.DatadogMonitorMessageTemplate{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "templates"}, Key: "default"}},
+	}}
+	clusterRef := &datadoghqv1alpha1.DatadogMonitor{Spec: datadoghqv1alpha1.DatadogMonitorSpec{
+		MessageTemplate: &datadoghqv1alpha1.DatadogMonitorMessageTemplate{TemplateName: "templates"},
+	}}
+	noRef := &datadoghqv1alpha1.DatadogMonitor{}
+
+	assert.True(t, UsesConfigMapTemplate(configMapRef, "templates"))
+	assert.False(t, UsesConfigMapTemplate(configMapRef, "other"))
+	assert.False(t, UsesConfigMapTemplate(clusterRef, "templates"))
+	assert.False(t, UsesConfigMapTemplate(noRef, "templates"))
+
+	assert.True(t, UsesClusterTemplate(clusterRef, "templates"))
+	assert.False(t, UsesClusterTemplate(clusterRef, "other"))
+	assert.False(t, UsesClusterTemplate(configMapRef, "templates"))
+	assert.False(t, UsesClusterTemplate(noRef, "templates"))
+}
@@ -0,0 +1,353 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogmonitor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+const (
+	// WebhookPath is the path on which the webhook receiver accepts notifications
+	WebhookPath = "/monitors/webhook"
+	// WebhookSecretHeader contains the shared secret configured in the Datadog webhook integration
+	WebhookSecretHeader = "X-Datadog-Webhook-Secret"
+	// WebhookSignatureHeader contains the hex encoded HMAC-SHA256 of `<timestamp>.<request body>`, signed with the shared secret
+	WebhookSignatureHeader = "X-Datadog-Webhook-Signature"
+	// WebhookTimestampHeader contains the epoch of the signature, in seconds
+	WebhookTimestampHeader = "X-Datadog-Webhook-Timestamp"
+
+	maxWebhookPayloadSize = 1 << 20
+	webhookShutdownDelay  = 5 * time.Second
+	// maxWebhookPayloadAge is the maximum difference between the date of a notification, or of its signature,
+	// and its reception. Older notifications are rejected, and the ones received within this window are rejected
+	// when they were already received, so that a captured request cannot be replayed.
+	maxWebhookPayloadAge = 10 * time.Minute
+)
+
+var errMonitorNotFound = errors.New("no DatadogMonitor found for this monitor ID")
+
+// replayCache remembers the notifications received within the accepted window
+type replayCache struct {
+	mutex sync.Mutex
+	seen  map[string]time.Time
+}
+
+// add records a notification, it returns false if the notification was already received
+func (c *replayCache) add(key string, now time.Time) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for k, receivedAt := range c.seen {
+		if now.Sub(receivedAt) > 2*maxWebhookPayloadAge {
+			delete(c.seen, k)
+		}
+	}
+	if _, found := c.seen[key]; found {
+		return false
+	}
+	c.seen[key] = now
+
+	return true
+}
+
+// remove forgets a notification, so that it can be retried
+func (c *replayCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.seen, key)
+}
+
+// webhookPayload is the payload expected from the Datadog webhook integration, configured as
+// `{"monitor_id": "$ALERT_ID", "transition": "$ALERT_TRANSITION", "scope": "$ALERT_SCOPE", "date": "$DATE"}`
+type webhookPayload struct {
+	MonitorID  json.Number `json:"monitor_id"`
+	Transition string      `json:"transition"`
+	Scope      string      `json:"scope"`
+	// Date is the epoch of the transition, in milliseconds
+	Date json.Number `json:"date"`
+}
+
+// WebhookReceiver receives Datadog webhook notifications and updates the state of the matching DatadogMonitors
+// without waiting for the next poll. Polling remains the source of truth and fixes any missed notification.
+type WebhookReceiver struct {
+	client   client.Client
+	addr     string
+	certFile string
+	keyFile  string
+	secret   []byte
+	log      logr.Logger
+	now      func() time.Time
+	replays  *replayCache
+}
+
+// NewWebhookReceiver returns a new WebhookReceiver serving HTTPS on addr with the certificate and key files
+func NewWebhookReceiver(client client.Client, addr, certFile, keyFile, secret string, log logr.Logger) (*WebhookReceiver, error) {
+	if secret == "" {
+		return nil, errors.New("a shared secret is required to run the monitor webhook receiver")
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("a TLS certificate and key are required to run the monitor webhook receiver")
+	}
+
+	return &WebhookReceiver{
+		client:   client,
+		addr:     addr,
+		certFile: certFile,
+		keyFile:  keyFile,
+		secret:   []byte(secret),
+		log:      log,
+		now:      time.Now,
+		replays:  &replayCache{seen: map[string]time.Time{}},
+	}, nil
+}
+
+// NeedLeaderElection returns false so that every replica serves notifications, it implements manager.LeaderElectionRunnable
+func (w *WebhookReceiver) NeedLeaderElection() bool {
+	return false
+}
+
+// Start runs the HTTPS server until the context is done, it implements manager.Runnable
+func (w *WebhookReceiver) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(WebhookPath, w)
+	server := &http.Server{Addr: w.addr, Handler: mux}
+
+	errChan := make(chan error, 1)
+	go func() {
+		w.log.Info("Starting monitor webhook receiver", "address", w.addr, "path", WebhookPath)
+		errChan <- server.ListenAndServeTLS(w.certFile, w.keyFile)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownDelay)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// ServeHTTP handles a webhook notification
+func (w *WebhookReceiver) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, maxWebhookPayloadSize))
+	if err != nil {
+		http.Error(rw, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	replayKey, authenticated := w.authenticate(req, body)
+	if !authenticated {
+		http.Error(rw, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	payload := webhookPayload{}
+	if err = json.Unmarshal(body, &payload); err != nil {
+		http.Error(rw, fmt.Sprintf("invalid payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	date, err := payload.Date.Int64()
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("invalid date: %v", err), http.StatusBadRequest)
+		return
+	}
+	transitionTime := metav1.NewTime(time.Unix(0, date*int64(time.Millisecond)))
+	if !w.isWithinAcceptedWindow(transitionTime.Time) {
+		http.Error(rw, "notification date outside of the accepted window", http.StatusUnauthorized)
+		return
+	}
+
+	if !w.replays.add(replayKey, w.now()) {
+		http.Error(rw, "notification already received", http.StatusUnauthorized)
+		return
+	}
+
+	monitorID, err := payload.MonitorID.Int64()
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("invalid monitor_id: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	state, found := transitionToState(payload.Transition)
+	if !found {
+		// Renotifications and unknown transitions do not change the monitor state
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	logger := w.log.WithValues("Monitor ID", monitorID, "transition", payload.Transition, "scope", payload.Scope)
+	if err = w.updateMonitorState(req.Context(), int(monitorID), payload.Scope, state, transitionTime); err != nil {
+		if errors.Is(err, errMonitorNotFound) {
+			// The webhook can be shared by monitors that are not managed by the Operator
+			logger.V(1).Info("Ignoring notification of a monitor not managed by a DatadogMonitor")
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		logger.Error(err, "unable to update DatadogMonitor state")
+		w.replays.remove(replayKey)
+		http.Error(rw, "unable to update DatadogMonitor state", http.StatusInternalServerError)
+		return
+	}
+	logger.V(1).Info("Updated DatadogMonitor state from webhook notification")
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// authenticate checks either the HMAC signature of the timestamp and body, or the shared secret header.
+// It returns the key identifying the notification in the replay cache.
+func (w *WebhookReceiver) authenticate(req *http.Request, body []byte) (string, bool) {
+	if signature := req.Header.Get(WebhookSignatureHeader); signature != "" {
+		timestamp := req.Header.Get(WebhookTimestampHeader)
+		epoch, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || !w.isWithinAcceptedWindow(time.Unix(epoch, 0)) {
+			return "", false
+		}
+		expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+		if err != nil {
+			return "", false
+		}
+		mac := hmac.New(sha256.New, w.secret)
+		_, _ = mac.Write([]byte(timestamp + "."))
+		_, _ = mac.Write(body)
+		if !hmac.Equal(mac.Sum(nil), expected) {
+			return "", false
+		}
+		return hex.EncodeToString(expected), true
+	}
+
+	if subtle.ConstantTimeCompare([]byte(req.Header.Get(WebhookSecretHeader)), w.secret) != 1 {
+		return "", false
+	}
+	// The shared secret is the same for every notification, the body identifies them
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), true
+}
+
+func (w *WebhookReceiver) isWithinAcceptedWindow(date time.Time) bool {
+	age := w.now().Sub(date)
+	return age <= maxWebhookPayloadAge && age >= -maxWebhookPayloadAge
+}
+
+func (w *WebhookReceiver) updateMonitorState(ctx context.Context, monitorID int, scope string, state datadoghqv1alpha1.DatadogMonitorState, transitionTime metav1.Time) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		monitors := &datadoghqv1alpha1.DatadogMonitorList{}
+		if err := w.client.List(ctx, monitors); err != nil {
+			return err
+		}
+
+		var datadogMonitor *datadoghqv1alpha1.DatadogMonitor
+		for i := range monitors.Items {
+			if monitors.Items[i].Status.ID == monitorID {
+				datadogMonitor = &monitors.Items[i]
+				break
+			}
+		}
+		if datadogMonitor == nil {
+			return errMonitorNotFound
+		}
+
+		applyTransition(&datadogMonitor.Status, scope, state, transitionTime)
+
+		return w.client.Status().Update(ctx, datadogMonitor)
+	})
+}
+
+// applyTransition updates status.TriggeredState with the group transition and recomputes status.MonitorState
+func applyTransition(status *datadoghqv1alpha1.DatadogMonitorStatus, scope string, state datadoghqv1alpha1.DatadogMonitorState, transitionTime metav1.Time) {
+	group := scope
+	if group == "" {
+		group = "*"
+	}
+
+	triggeredStates := []datadoghqv1alpha1.DatadogMonitorTriggeredState{}
+	for _, triggeredState := range status.TriggeredState {
+		if triggeredState.MonitorGroup != group {
+			triggeredStates = append(triggeredStates, triggeredState)
+		}
+	}
+	if isTriggered(string(state)) {
+		triggeredStates = append(triggeredStates, datadoghqv1alpha1.DatadogMonitorTriggeredState{
+			MonitorGroup:       group,
+			State:              state,
+			LastTransitionTime: transitionTime,
+		})
+	}
+	sort.SliceStable(triggeredStates, func(i, j int) bool { return triggeredStates[i].MonitorGroup < triggeredStates[j].MonitorGroup })
+	if len(triggeredStates) > maxTriggeredStateGroups {
+		triggeredStates = triggeredStates[0:maxTriggeredStateGroups]
+	}
+	status.TriggeredState = triggeredStates
+
+	// The overall state is the most severe state of the triggered groups
+	overallState := datadoghqv1alpha1.DatadogMonitorStateOK
+	for _, triggeredState := range triggeredStates {
+		if stateSeverity(triggeredState.State) > stateSeverity(overallState) {
+			overallState = triggeredState.State
+		}
+	}
+	if status.MonitorState != overallState {
+		status.MonitorState = overallState
+		status.MonitorStateLastTransitionTime = &transitionTime
+	}
+}
+
+// transitionToState maps the $ALERT_TRANSITION webhook variable to a monitor state
+func transitionToState(transition string) (datadoghqv1alpha1.DatadogMonitorState, bool) {
+	switch strings.TrimPrefix(transition, "Re-") {
+	case "Triggered":
+		return datadoghqv1alpha1.DatadogMonitorStateAlert, true
+	case "Warn":
+		return datadoghqv1alpha1.DatadogMonitorStateWarn, true
+	case "No Data":
+		return datadoghqv1alpha1.DatadogMonitorStateNoData, true
+	case "Recovered":
+		return datadoghqv1alpha1.DatadogMonitorStateOK, true
+	default:
+		return "", false
+	}
+}
+
+func stateSeverity(state datadoghqv1alpha1.DatadogMonitorState) int {
+	switch state {
+	case datadoghqv1alpha1.DatadogMonitorStateAlert:
+		return 3
+	case datadoghqv1alpha1.DatadogMonitorStateNoData:
+		return 2
+	case datadoghqv1alpha1.DatadogMonitorStateWarn:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package datadogmonitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+const testWebhookSecret = "s3cr3t"
+
+func TestWebhookReceiver_ServeHTTP(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogMonitor{}, &datadoghqv1alpha1.DatadogMonitorList{})
+
+	alertTime := metav1.Unix(1600000000, 0)
+
+	tests := []struct {
+		name       string
+		body       string
+		headers    func(body string) map[string]string
+		wantCode   int
+		wantStatus func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus)
+	}{
+		{
+			name:     "shared secret, triggered group",
+			body:     `{"monitor_id": "12345", "transition": "Triggered", "scope": "host:foo", "date": "1600000000000"}`,
+			headers:  secretHeader(testWebhookSecret),
+			wantCode: http.StatusNoContent,
+			wantStatus: func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus) {
+				assert.Equal(t, datadoghqv1alpha1.DatadogMonitorStateAlert, status.MonitorState)
+				assert.Equal(t, []datadoghqv1alpha1.DatadogMonitorTriggeredState{
+					{MonitorGroup: "host:bar", State: datadoghqv1alpha1.DatadogMonitorStateWarn, LastTransitionTime: alertTime},
+					{MonitorGroup: "host:foo", State: datadoghqv1alpha1.DatadogMonitorStateAlert, LastTransitionTime: alertTime},
+				}, status.TriggeredState)
+			},
+		},
+		{
+			name:     "hmac signature, recovered group",
+			body:     `{"monitor_id": 12345, "transition": "Recovered", "scope": "host:bar", "date": "1600000000000"}`,
+			headers:  signatureHeader(testWebhookSecret),
+			wantCode: http.StatusNoContent,
+			wantStatus: func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus) {
+				assert.Equal(t, datadoghqv1alpha1.DatadogMonitorStateOK, status.MonitorState)
+				assert.Empty(t, status.TriggeredState)
+				assert.Equal(t, alertTime.Unix(), status.MonitorStateLastTransitionTime.Unix())
+			},
+		},
+		{
+			name:     "invalid secret",
+			body:     `{"monitor_id": "12345", "transition": "Triggered", "scope": "host:foo", "date": "1600000000000"}`,
+			headers:  secretHeader("wrong"),
+			wantCode: http.StatusUnauthorized,
+			wantStatus: func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus) {
+				assert.Equal(t, datadoghqv1alpha1.DatadogMonitorStateWarn, status.MonitorState)
+			},
+		},
+		{
+			name:     "invalid signature",
+			body:     `{"monitor_id": "12345", "transition": "Triggered", "scope": "host:foo", "date": "1600000000000"}`,
+			headers:  signatureHeader("wrong"),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "unknown monitor",
+			body:     `{"monitor_id": "1", "transition": "Triggered", "scope": "host:foo", "date": "1600000000000"}`,
+			headers:  secretHeader(testWebhookSecret),
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "renotification is ignored",
+			body:     `{"monitor_id": "12345", "transition": "Renotify", "scope": "host:bar", "date": "1600000000000"}`,
+			headers:  secretHeader(testWebhookSecret),
+			wantCode: http.StatusNoContent,
+			wantStatus: func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus) {
+				assert.Equal(t, datadoghqv1alpha1.DatadogMonitorStateWarn, status.MonitorState)
+				assert.Len(t, status.TriggeredState, 1)
+			},
+		},
+		{
+			name:     "expired signature",
+			body:     `{"monitor_id": 12345, "transition": "Recovered", "scope": "host:bar", "date": "1600000000000"}`,
+			headers:  signatureHeaderAt(testWebhookSecret, alertTime.Add(-time.Hour)),
+			wantCode: http.StatusUnauthorized,
+			wantStatus: func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus) {
+				assert.Equal(t, datadoghqv1alpha1.DatadogMonitorStateWarn, status.MonitorState)
+			},
+		},
+		{
+			name:     "signature without timestamp",
+			body:     `{"monitor_id": 12345, "transition": "Recovered", "scope": "host:bar", "date": "1600000000000"}`,
+			headers:  unsignedTimestampHeader(testWebhookSecret),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "old notification",
+			body:     `{"monitor_id": "12345", "transition": "Triggered", "scope": "host:foo", "date": "1599990000000"}`,
+			headers:  signatureHeader(testWebhookSecret),
+			wantCode: http.StatusUnauthorized,
+			wantStatus: func(t *testing.T, status datadoghqv1alpha1.DatadogMonitorStatus) {
+				assert.Equal(t, datadoghqv1alpha1.DatadogMonitorStateWarn, status.MonitorState)
+			},
+		},
+		{
+			name:     "missing date",
+			body:     `{"monitor_id": "12345", "transition": "Triggered", "scope": "host:foo"}`,
+			headers:  secretHeader(testWebhookSecret),
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "invalid payload",
+			body:     `{"monitor_id": "abc"}`,
+			headers:  secretHeader(testWebhookSecret),
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := genericDatadogMonitor()
+			dm.Status = datadoghqv1alpha1.DatadogMonitorStatus{
+				ID:           12345,
+				MonitorState: datadoghqv1alpha1.DatadogMonitorStateWarn,
+				TriggeredState: []datadoghqv1alpha1.DatadogMonitorTriggeredState{
+					{MonitorGroup: "host:bar", State: datadoghqv1alpha1.DatadogMonitorStateWarn, LastTransitionTime: alertTime},
+				},
+			}
+			k8sClient := fake.NewFakeClientWithScheme(s, dm)
+
+			receiver, err := NewWebhookReceiver(k8sClient, "", "tls.crt", "tls.key", testWebhookSecret, logf.Log.WithName(tt.name))
+			assert.NoError(t, err)
+			receiver.now = func() time.Time { return alertTime.Add(time.Minute) }
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+WebhookPath, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+			for key, value := range tt.headers(tt.body) {
+				req.Header.Set(key, value)
+			}
+			resp, err := server.Client().Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantStatus != nil {
+				updated := &datadoghqv1alpha1.DatadogMonitor{}
+				assert.NoError(t, k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: resourcesNamespace, Name: resourcesName}, updated))
+				tt.wantStatus(t, updated.Status)
+			}
+		})
+	}
+}
+
+func TestNewWebhookReceiver_requiresSecret(t *testing.T) {
+	_, err := NewWebhookReceiver(fake.NewFakeClient(), ":8443", "tls.crt", "tls.key", "", logf.Log)
+	assert.Error(t, err)
+}
+
+func TestNewWebhookReceiver_requiresTLS(t *testing.T) {
+	_, err := NewWebhookReceiver(fake.NewFakeClient(), ":8443", "", "", testWebhookSecret, logf.Log)
+	assert.Error(t, err)
+}
+
+func secretHeader(secret string) func(string) map[string]string {
+	return func(string) map[string]string {
+		return map[string]string{WebhookSecretHeader: secret}
+	}
+}
+
+func signatureHeader(secret string) func(string) map[string]string {
+	return signatureHeaderAt(secret, time.Unix(1600000000, 0))
+}
+
+func signatureHeaderAt(secret string, timestamp time.Time) func(string) map[string]string {
+	return func(body string) map[string]string {
+		epoch := strconv.FormatInt(timestamp.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write([]byte(epoch + "." + body))
+		return map[string]string{
+			WebhookSignatureHeader: "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+			WebhookTimestampHeader: epoch,
+		}
+	}
+}
+
+// unsignedTimestampHeader signs the body only, as the timestamp is missing
+func unsignedTimestampHeader(secret string) func(string) map[string]string {
+	return func(body string) map[string]string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		_, _ = mac.Write([]byte(body))
+		return map[string]string{WebhookSignatureHeader: "sha256=" + hex.EncodeToString(mac.Sum(nil))}
+	}
+}
+
+func TestWebhookReceiver_rejectsReplays(t *testing.T) {
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogMonitor{}, &datadoghqv1alpha1.DatadogMonitorList{})
+
+	alertTime := metav1.Unix(1600000000, 0)
+	body := `{"monitor_id": "12345", "transition": "Triggered", "scope": "host:foo", "date": "1600000000000"}`
+
+	tests := []struct {
+		name    string
+		headers func(body string) map[string]string
+	}{
+		{
+			name:    "shared secret",
+			headers: secretHeader(testWebhookSecret),
+		},
+		{
+			name:    "hmac signature",
+			headers: signatureHeader(testWebhookSecret),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := genericDatadogMonitor()
+			dm.Status.ID = 12345
+			receiver, err := NewWebhookReceiver(fake.NewFakeClientWithScheme(s, dm), "", "tls.crt", "tls.key", testWebhookSecret, logf.Log.WithName(tt.name))
+			assert.NoError(t, err)
+			receiver.now = func() time.Time { return alertTime.Add(time.Minute) }
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			for _, wantCode := range []int{http.StatusNoContent, http.StatusUnauthorized} {
+				req, err := http.NewRequest(http.MethodPost, server.URL+WebhookPath, bytes.NewBufferString(body))
+				assert.NoError(t, err)
+				for key, value := range tt.headers(body) {
+					req.Header.Set(key, value)
+				}
+				resp, err := server.Client().Do(req)
+				assert.NoError(t, err)
+				resp.Body.Close()
+				assert.Equal(t, wantCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestWebhookReceiver_NeedLeaderElection(t *testing.T) {
+	receiver, err := NewWebhookReceiver(fake.NewFakeClient(), ":8443", "tls.crt", "tls.key", testWebhookSecret, logf.Log)
+	assert.NoError(t, err)
+	assert.False(t, receiver.NeedLeaderElection())
+}
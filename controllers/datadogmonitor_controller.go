@@ -9,11 +9,16 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
 	"github.com/DataDog/datadog-operator/controllers/datadogmonitor"
@@ -28,12 +33,16 @@ type DatadogMonitorReconciler struct {
 	Log         logr.Logger
 	Scheme      *runtime.Scheme
 	Recorder    record.EventRecorder
+	Options     datadogmonitor.ReconcilerOptions
 	internal    *datadogmonitor.Reconciler
 }
 
 // +kubebuilder:rbac:groups=datadoghq.com,resources=datadogmonitors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=datadoghq.com,resources=datadogmonitors/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=datadoghq.com,resources=datadogmonitors/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=datadoghq.com,resources=datadogmonitortemplates,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 
 // Reconcile loop for DatadogMonitor.
 func (r *DatadogMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -42,7 +51,7 @@ func (r *DatadogMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 // SetupWithManager creates a new DatadogMonitor controller.
 func (r *DatadogMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	internal, err := datadogmonitor.NewReconciler(r.Client, r.DDClient, r.VersionInfo, r.Scheme, r.Log, r.Recorder)
+	internal, err := datadogmonitor.NewReconciler(r.Options, r.Client, r.DDClient, r.VersionInfo, r.Scheme, r.Log, r.Recorder)
 	if err != nil {
 		return err
 	}
@@ -51,6 +60,10 @@ func (r *DatadogMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&datadoghqv1alpha1.DatadogMonitor{})
 
+	// The message templates are not owned by the DatadogMonitors that reference them.
+	builder.Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueDatadogMonitorsOfConfigMap))
+	builder.Watches(&source.Kind{Type: &datadoghqv1alpha1.DatadogMonitorTemplate{}}, handler.EnqueueRequestsFromMapFunc(r.enqueueDatadogMonitorsOfTemplate))
+
 	err = builder.Complete(r)
 	if err != nil {
 		return err
@@ -58,3 +71,35 @@ func (r *DatadogMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	return nil
 }
+
+func (r *DatadogMonitorReconciler) enqueueDatadogMonitorsOfConfigMap(obj client.Object) []reconcile.Request {
+	dmList := &datadoghqv1alpha1.DatadogMonitorList{}
+	if err := r.Client.List(context.TODO(), dmList, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "Unable to list the DatadogMonitors", "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range dmList.Items {
+		if datadogmonitor.UsesConfigMapTemplate(&dmList.Items[i], obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: dmList.Items[i].Namespace, Name: dmList.Items[i].Name}})
+		}
+	}
+	return requests
+}
+
+func (r *DatadogMonitorReconciler) enqueueDatadogMonitorsOfTemplate(obj client.Object) []reconcile.Request {
+	dmList := &datadoghqv1alpha1.DatadogMonitorList{}
+	if err := r.Client.List(context.TODO(), dmList); err != nil {
+		r.Log.Error(err, "Unable to list the DatadogMonitors")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range dmList.Items {
+		if datadogmonitor.UsesClusterTemplate(&dmList.Items[i], obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: dmList.Items[i].Namespace, Name: dmList.Items[i].Name}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,269 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package prometheusrule
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	"github.com/DataDog/datadog-operator/controllers/utils"
+	"github.com/DataDog/datadog-operator/pkg/controller/utils/datadog"
+	prometheus "github.com/DataDog/datadog-operator/pkg/prometheus/v1"
+)
+
+const (
+	defaultErrRequeuePeriod = 5 * time.Second
+	datadogMonitorKind      = "DatadogMonitor"
+	maxMonitorNameLength    = 253
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Reconciler reconciles a PrometheusRule object into DatadogMonitor objects.
+// It never calls the Datadog API: the generated DatadogMonitors are synced by the DatadogMonitor controller.
+type Reconciler struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	log      logr.Logger
+	recorder record.EventRecorder
+}
+
+// NewReconciler returns a new Reconciler object
+func NewReconciler(client client.Client, scheme *runtime.Scheme, log logr.Logger, recorder record.EventRecorder) (*Reconciler, error) {
+	return &Reconciler{
+		client:   client,
+		scheme:   scheme,
+		log:      log,
+		recorder: recorder,
+	}, nil
+}
+
+// Reconcile is similar to reconciler.Reconcile interface, but taking a context
+func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	return r.internalReconcile(ctx, request)
+}
+
+func (r *Reconciler) internalReconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	logger := r.log.WithValues("prometheusrule", req.NamespacedName)
+	logger.Info("Reconciling PrometheusRule")
+
+	instance := prometheus.EmptyUnstructuredPrometheusRule()
+	if err := r.client.Get(ctx, req.NamespacedName, instance); err != nil {
+		if apierrors.IsNotFound(err) {
+			// Generated DatadogMonitors are garbage collected through their owner reference
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{RequeueAfter: defaultErrRequeuePeriod}, err
+	}
+
+	if instance.GetDeletionTimestamp() != nil {
+		return reconcile.Result{}, nil
+	}
+
+	rule := &prometheus.PrometheusRule{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(instance.UnstructuredContent(), rule); err != nil {
+		logger.Error(err, "unable to decode PrometheusRule")
+		return reconcile.Result{}, nil
+	}
+
+	desired, err := r.buildDatadogMonitors(instance, rule)
+	if err != nil {
+		return reconcile.Result{RequeueAfter: defaultErrRequeuePeriod}, err
+	}
+
+	existingList := &datadoghqv1alpha1.DatadogMonitorList{}
+	if err = r.client.List(ctx, existingList, client.InNamespace(rule.Namespace), client.MatchingLabels{datadoghqv1alpha1.PrometheusRuleNameLabelKey: prometheusRuleLabelValue(rule.Name)}); err != nil {
+		return reconcile.Result{RequeueAfter: defaultErrRequeuePeriod}, err
+	}
+	existing := map[string]*datadoghqv1alpha1.DatadogMonitor{}
+	for i := range existingList.Items {
+		existing[existingList.Items[i].Name] = &existingList.Items[i]
+	}
+
+	for _, dm := range desired {
+		current, found := existing[dm.Name]
+		delete(existing, dm.Name)
+		if !found {
+			if err = r.createDatadogMonitor(logger, instance, dm); err != nil {
+				return reconcile.Result{RequeueAfter: defaultErrRequeuePeriod}, err
+			}
+			continue
+		}
+		if err = r.updateDatadogMonitorIfNeeded(logger, instance, current, dm); err != nil {
+			return reconcile.Result{RequeueAfter: defaultErrRequeuePeriod}, err
+		}
+	}
+
+	// Remaining monitors belong to alerts that were removed from the PrometheusRule
+	for _, dm := range existing {
+		if !metav1.IsControlledBy(dm, instance) {
+			continue
+		}
+		logger.Info("Deleting DatadogMonitor of removed alert", "datadogmonitor", dm.Name)
+		if err = r.client.Delete(ctx, dm); err != nil && !apierrors.IsNotFound(err) {
+			return reconcile.Result{RequeueAfter: defaultErrRequeuePeriod}, err
+		}
+		r.recordEvent(instance, utils.BuildEventInfo(dm.Name, dm.Namespace, datadogMonitorKind, datadog.DeletionEvent))
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// buildDatadogMonitors returns one DatadogMonitor per alerting rule. Rules that cannot be translated
+// produce a DatadogMonitor without query, annotated with the translation error.
+func (r *Reconciler) buildDatadogMonitors(owner *unstructured.Unstructured, rule *prometheus.PrometheusRule) ([]*datadoghqv1alpha1.DatadogMonitor, error) {
+	monitors := []*datadoghqv1alpha1.DatadogMonitor{}
+	names := map[string]bool{}
+
+	for _, group := range rule.Spec.Groups {
+		for i := range group.Rules {
+			alert := &group.Rules[i]
+			if alert.Alert == "" {
+				// Recording rule
+				continue
+			}
+
+			name := monitorName(rule.Name, group.Name, alert)
+			if names[name] {
+				name = fmt.Sprintf("%s-%d", name, i)
+			}
+			names[name] = true
+
+			dm := &datadoghqv1alpha1.DatadogMonitor{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: rule.Namespace,
+					Labels: map[string]string{
+						datadoghqv1alpha1.PrometheusRuleNameLabelKey: prometheusRuleLabelValue(rule.Name),
+					},
+					Annotations: map[string]string{
+						datadoghqv1alpha1.PrometheusRuleNameAnnotationKey: rule.Name,
+					},
+				},
+			}
+
+			spec, err := translateRule(rule.Name, group.Name, alert)
+			if err != nil {
+				dm.Annotations[datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey] = fmt.Sprintf("alert %s: %s: %v", alert.Alert, alert.Expr.String(), err)
+				dm.Spec = datadoghqv1alpha1.DatadogMonitorSpec{
+					Name: alert.Alert,
+					Type: datadoghqv1alpha1.DatadogMonitorTypeQuery,
+					Tags: buildTags(rule.Name, group.Name, alert),
+				}
+			} else {
+				dm.Spec = *spec
+			}
+
+			if err = controllerutil.SetControllerReference(owner, dm, r.scheme); err != nil {
+				return nil, err
+			}
+			monitors = append(monitors, dm)
+		}
+	}
+
+	return monitors, nil
+}
+
+func (r *Reconciler) createDatadogMonitor(logger logr.Logger, owner *unstructured.Unstructured, dm *datadoghqv1alpha1.DatadogMonitor) error {
+	logger.Info("Creating DatadogMonitor", "datadogmonitor", dm.Name)
+	if err := r.client.Create(context.TODO(), dm); err != nil {
+		return err
+	}
+	r.recordEvent(owner, utils.BuildEventInfo(dm.Name, dm.Namespace, datadogMonitorKind, datadog.CreationEvent))
+
+	return nil
+}
+
+func (r *Reconciler) updateDatadogMonitorIfNeeded(logger logr.Logger, owner *unstructured.Unstructured, current, desired *datadoghqv1alpha1.DatadogMonitor) error {
+	if !metav1.IsControlledBy(current, owner) {
+		return fmt.Errorf("DatadogMonitor %s/%s already exists and is not owned by PrometheusRule %s", current.Namespace, current.Name, owner.GetName())
+	}
+
+	currentError := current.Annotations[datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey]
+	desiredError := desired.Annotations[datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey]
+	ruleName := desired.Annotations[datadoghqv1alpha1.PrometheusRuleNameAnnotationKey]
+	if apiequality.Semantic.DeepEqual(current.Spec, desired.Spec) && currentError == desiredError && current.Annotations[datadoghqv1alpha1.PrometheusRuleNameAnnotationKey] == ruleName {
+		return nil
+	}
+
+	updated := current.DeepCopy()
+	updated.Spec = desired.Spec
+	if updated.Annotations == nil {
+		updated.Annotations = map[string]string{}
+	}
+	updated.Annotations[datadoghqv1alpha1.PrometheusRuleNameAnnotationKey] = ruleName
+	if desiredError != "" {
+		updated.Annotations[datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey] = desiredError
+	} else {
+		delete(updated.Annotations, datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey)
+	}
+
+	logger.Info("Updating DatadogMonitor", "datadogmonitor", current.Name)
+	if err := r.client.Update(context.TODO(), updated); err != nil {
+		return err
+	}
+	r.recordEvent(owner, utils.BuildEventInfo(current.Name, current.Namespace, datadogMonitorKind, datadog.UpdateEvent))
+
+	return nil
+}
+
+// prometheusRuleLabelValue returns the value of the PrometheusRuleNameLabelKey label. The PrometheusRule names can
+// be longer than a label value, they are then truncated and suffixed with their hash.
+func prometheusRuleLabelValue(name string) string {
+	if len(name) <= validation.LabelValueMaxLength {
+		return name
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	suffix := fmt.Sprintf("%08x", h.Sum32())
+	prefix := strings.TrimRight(name[:validation.LabelValueMaxLength-len(suffix)-1], "-.")
+
+	return fmt.Sprintf("%s-%s", prefix, suffix)
+}
+
+// recordEvent wraps the manager event recorder
+func (r *Reconciler) recordEvent(owner runtime.Object, info utils.EventInfo) {
+	r.recorder.Event(owner, corev1.EventTypeNormal, info.GetReason(), info.GetMessage())
+}
+
+// monitorName returns a stable DatadogMonitor name for an alerting rule. The hash keeps alerts sharing
+// the same name (usually one per severity) apart.
+func monitorName(ruleName, groupName string, alert *prometheus.Rule) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(groupName))
+	_, _ = h.Write([]byte(alert.Alert))
+	for _, tag := range buildTags(ruleName, groupName, alert) {
+		_, _ = h.Write([]byte(tag))
+	}
+	suffix := fmt.Sprintf("%08x", h.Sum32())
+
+	base := invalidNameChars.ReplaceAllString(strings.ToLower(fmt.Sprintf("%s-%s", ruleName, alert.Alert)), "-")
+	base = strings.Trim(base, "-")
+	if len(base) > maxMonitorNameLength-len(suffix)-1 {
+		base = base[:maxMonitorNameLength-len(suffix)-1]
+	}
+
+	return fmt.Sprintf("%s-%s", base, suffix)
+}
@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package prometheusrule
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	prometheus "github.com/DataDog/datadog-operator/pkg/prometheus/v1"
+)
+
+const (
+	resourcesName      = "foo"
+	resourcesNamespace = "bar"
+)
+
+func TestReconcilePrometheusRule_Reconcile(t *testing.T) {
+	eventBroadcaster := record.NewBroadcaster()
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "TestReconcilePrometheusRule_Reconcile"})
+
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	s := scheme.Scheme
+	s.AddKnownTypes(datadoghqv1alpha1.GroupVersion, &datadoghqv1alpha1.DatadogMonitor{}, &datadoghqv1alpha1.DatadogMonitorList{})
+
+	tests := []struct {
+		name         string
+		rules        []prometheus.Rule
+		secondRules  []prometheus.Rule
+		wantMonitors func(t *testing.T, monitors []datadoghqv1alpha1.DatadogMonitor)
+	}{
+		{
+			name: "alert rules are translated, recording rules are ignored",
+			rules: []prometheus.Rule{
+				{Alert: "HighLoad", Expr: intstr.FromString(`avg(load1) by (host) > 4`), Labels: map[string]string{"severity": "warning"}},
+				{Alert: "HighLoad", Expr: intstr.FromString(`avg(load1) by (host) > 8`), Labels: map[string]string{"severity": "critical"}},
+				{Record: "job:load:avg", Expr: intstr.FromString(`avg(load1) by (job)`)},
+			},
+			wantMonitors: func(t *testing.T, monitors []datadoghqv1alpha1.DatadogMonitor) {
+				assert.Len(t, monitors, 2)
+				for _, dm := range monitors {
+					assert.Equal(t, "HighLoad", dm.Spec.Name)
+					assert.NotContains(t, dm.Annotations, datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey)
+					assert.Len(t, dm.OwnerReferences, 1)
+					assert.Equal(t, resourcesName, dm.OwnerReferences[0].Name)
+				}
+			},
+		},
+		{
+			name: "untranslatable expression is reported on the DatadogMonitor",
+			rules: []prometheus.Rule{
+				{Alert: "Absent", Expr: intstr.FromString(`absent(up{job="api"})`)},
+			},
+			wantMonitors: func(t *testing.T, monitors []datadoghqv1alpha1.DatadogMonitor) {
+				assert.Len(t, monitors, 1)
+				assert.Empty(t, monitors[0].Spec.Query)
+				assert.Contains(t, monitors[0].Annotations[datadoghqv1alpha1.DatadogMonitorTranslationErrorAnnotationKey], "unsupported function")
+			},
+		},
+		{
+			name: "removed and updated alerts are propagated",
+			rules: []prometheus.Rule{
+				{Alert: "HighLoad", Expr: intstr.FromString(`avg(load1) > 4`)},
+				{Alert: "LowDisk", Expr: intstr.FromString(`avg(disk_free) < 10`)},
+			},
+			secondRules: []prometheus.Rule{
+				{Alert: "HighLoad", Expr: intstr.FromString(`avg(load1) > 6`)},
+			},
+			wantMonitors: func(t *testing.T, monitors []datadoghqv1alpha1.DatadogMonitor) {
+				assert.Len(t, monitors, 1)
+				assert.Equal(t, "avg(last_5m):avg:load1{*} > 6", monitors[0].Spec.Query)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Reconciler{
+				client:   fake.NewFakeClient(),
+				scheme:   s,
+				recorder: recorder,
+				log:      logf.Log.WithName(tt.name),
+			}
+
+			rule := newPrometheusRule(t, tt.rules)
+			assert.NoError(t, r.client.Create(context.TODO(), rule))
+			_, err := r.Reconcile(context.TODO(), newRequest())
+			assert.NoError(t, err)
+
+			if tt.secondRules != nil {
+				rule = newPrometheusRule(t, tt.secondRules)
+				current := prometheus.EmptyUnstructuredPrometheusRule()
+				assert.NoError(t, r.client.Get(context.TODO(), types.NamespacedName{Namespace: resourcesNamespace, Name: resourcesName}, current))
+				rule.SetResourceVersion(current.GetResourceVersion())
+				rule.SetUID(current.GetUID())
+				assert.NoError(t, r.client.Update(context.TODO(), rule))
+				_, err = r.Reconcile(context.TODO(), newRequest())
+				assert.NoError(t, err)
+			}
+
+			monitors := &datadoghqv1alpha1.DatadogMonitorList{}
+			assert.NoError(t, r.client.List(context.TODO(), monitors, client.InNamespace(resourcesNamespace)))
+			tt.wantMonitors(t, monitors.Items)
+		})
+	}
+}
+
+func Test_monitorName(t *testing.T) {
+	warning := &prometheus.Rule{Alert: "High_Load", Labels: map[string]string{"severity": "warning"}}
+	critical := &prometheus.Rule{Alert: "High_Load", Labels: map[string]string{"severity": "critical"}}
+
+	assert.Equal(t, monitorName("node.rules", "node", warning), monitorName("node.rules", "node", warning))
+	assert.NotEqual(t, monitorName("node.rules", "node", warning), monitorName("node.rules", "node", critical))
+	assert.Regexp(t, "^node-rules-high-load-[0-9a-f]{8}$", monitorName("node.rules", "node", warning))
+}
+
+func Test_prometheusRuleLabelValue(t *testing.T) {
+	assert.Equal(t, "node-rules", prometheusRuleLabelValue("node-rules"))
+
+	long := strings.Repeat("a", 60) + "-" + strings.Repeat("b", 100)
+	value := prometheusRuleLabelValue(long)
+	assert.LessOrEqual(t, len(value), validation.LabelValueMaxLength)
+	assert.Empty(t, validation.IsValidLabelValue(value))
+	assert.Regexp(t, "^a+-[0-9a-f]{8}$", value)
+	assert.Equal(t, value, prometheusRuleLabelValue(long))
+	assert.NotEqual(t, value, prometheusRuleLabelValue(long+"c"))
+}
+
+func newPrometheusRule(t *testing.T, rules []prometheus.Rule) *unstructured.Unstructured {
+	rule := &prometheus.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: resourcesNamespace,
+			Name:      resourcesName,
+			UID:       "uid",
+		},
+		Spec: prometheus.PrometheusRuleSpec{
+			Groups: []prometheus.RuleGroup{{Name: "group", Rules: rules}},
+		},
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(rule)
+	assert.NoError(t, err)
+	u := &unstructured.Unstructured{Object: obj}
+	u.SetGroupVersionKind(prometheus.PrometheusRuleGroupVersionKind())
+
+	return u
+}
+
+func newRequest() reconcile.Request {
+	return reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Namespace: resourcesNamespace,
+			Name:      resourcesName,
+		},
+	}
+}
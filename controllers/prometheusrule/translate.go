@@ -0,0 +1,567 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package prometheusrule
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	prometheus "github.com/DataDog/datadog-operator/pkg/prometheus/v1"
+)
+
+const (
+	defaultEvaluationWindow = 5 * time.Minute
+	severityLabel           = "severity"
+)
+
+// supportedWindows are the evaluation windows accepted by Datadog query alerts
+var supportedWindows = []struct {
+	duration time.Duration
+	name     string
+}{
+	{time.Minute, "last_1m"},
+	{5 * time.Minute, "last_5m"},
+	{10 * time.Minute, "last_10m"},
+	{15 * time.Minute, "last_15m"},
+	{30 * time.Minute, "last_30m"},
+	{time.Hour, "last_1h"},
+	{2 * time.Hour, "last_2h"},
+	{4 * time.Hour, "last_4h"},
+	{24 * time.Hour, "last_1d"},
+}
+
+// severityPriorities maps the usual alert severity labels to a Datadog monitor priority
+var severityPriorities = map[string]int64{
+	"critical": 1,
+	"high":     2,
+	"error":    2,
+	"warning":  3,
+	"info":     4,
+	"none":     5,
+}
+
+var (
+	promValueTemplate = regexp.MustCompile(`{{\s*\$value\s*}}`)
+	promLabelTemplate = regexp.MustCompile(`{{\s*\$labels\.([a-zA-Z_][a-zA-Z0-9_]*)\s*}}`)
+)
+
+// translateRule converts a Prometheus alerting rule into a DatadogMonitorSpec
+func translateRule(ruleName, groupName string, rule *prometheus.Rule) (*datadoghqv1alpha1.DatadogMonitorSpec, error) {
+	expr, err := parseAlertExpr(rule.Expr.String())
+	if err != nil {
+		return nil, err
+	}
+
+	window := defaultEvaluationWindow
+	if rule.For != "" {
+		if window, err = parseDuration(rule.For); err != nil {
+			return nil, fmt.Errorf("invalid for duration %q: %w", rule.For, err)
+		}
+	} else if expr.rangeDuration > 0 {
+		window = expr.rangeDuration
+	}
+
+	query := fmt.Sprintf("avg(%s):%s %s %s", evaluationWindow(window), expr.datadogQuery(), expr.comparator, expr.threshold)
+
+	spec := &datadoghqv1alpha1.DatadogMonitorSpec{
+		Name:     rule.Alert,
+		Message:  buildMessage(rule.Annotations),
+		Query:    query,
+		Type:     datadoghqv1alpha1.DatadogMonitorTypeQuery,
+		Priority: severityPriorities[rule.Labels[severityLabel]],
+		Tags:     buildTags(ruleName, groupName, rule),
+		Options: datadoghqv1alpha1.DatadogMonitorOptions{
+			Thresholds: &datadoghqv1alpha1.DatadogMonitorOptionsThresholds{
+				Critical: &expr.threshold,
+			},
+		},
+	}
+
+	return spec, nil
+}
+
+// evaluationWindow returns the smallest supported Datadog window that covers the duration
+func evaluationWindow(d time.Duration) string {
+	for _, w := range supportedWindows {
+		if d <= w.duration {
+			return w.name
+		}
+	}
+
+	return supportedWindows[len(supportedWindows)-1].name
+}
+
+func buildMessage(annotations map[string]string) string {
+	parts := []string{}
+	for _, key := range []string{"summary", "description", "message"} {
+		if v := annotations[key]; v != "" {
+			parts = append(parts, translateTemplate(v))
+		}
+	}
+	if runbook := annotations["runbook_url"]; runbook != "" {
+		parts = append(parts, fmt.Sprintf("Runbook: %s", runbook))
+	}
+	if len(parts) == 0 {
+		return "Generated from a PrometheusRule alert"
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// translateTemplate rewrites the common Prometheus template variables into Datadog template variables
+func translateTemplate(in string) string {
+	out := promValueTemplate.ReplaceAllString(in, "{{value}}")
+	return promLabelTemplate.ReplaceAllString(out, "{{$1.name}}")
+}
+
+func buildTags(ruleName, groupName string, rule *prometheus.Rule) []string {
+	tags := []string{
+		"generated:kubernetes",
+		fmt.Sprintf("alertname:%s", rule.Alert),
+		fmt.Sprintf("prometheus_rule:%s", ruleName),
+	}
+	if groupName != "" {
+		tags = append(tags, fmt.Sprintf("prometheus_rule_group:%s", groupName))
+	}
+	for k, v := range rule.Labels {
+		tags = append(tags, fmt.Sprintf("%s:%s", k, v))
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// alertExpr is the subset of PromQL that can be expressed as a Datadog query alert:
+// [aggregation [by (labels)]] ( [rate|irate] ( metric{matchers}[range] ) ) [by (labels)] comparator threshold
+type alertExpr struct {
+	aggregation   string
+	groupBy       []string
+	function      string
+	metric        string
+	matchers      []labelMatcher
+	rangeDuration time.Duration
+	comparator    string
+	threshold     string
+}
+
+type labelMatcher struct {
+	name     string
+	negative bool
+	value    string
+}
+
+var datadogSpaceAggregations = map[string]bool{
+	"sum": true,
+	"avg": true,
+	"min": true,
+	"max": true,
+}
+
+var rateFunctions = map[string]bool{
+	"rate":  true,
+	"irate": true,
+}
+
+var flippedComparators = map[string]string{
+	">":  "<",
+	">=": "<=",
+	"<":  ">",
+	"<=": ">=",
+}
+
+func (e *alertExpr) datadogQuery() string {
+	aggregation := e.aggregation
+	if aggregation == "" {
+		aggregation = "avg"
+	}
+
+	scope := "*"
+	if len(e.matchers) > 0 {
+		parts := make([]string, 0, len(e.matchers))
+		for _, m := range e.matchers {
+			prefix := ""
+			if m.negative {
+				prefix = "!"
+			}
+			parts = append(parts, fmt.Sprintf("%s%s:%s", prefix, m.name, m.value))
+		}
+		scope = strings.Join(parts, ",")
+	}
+
+	query := fmt.Sprintf("%s:%s{%s}", aggregation, e.metric, scope)
+	if len(e.groupBy) > 0 {
+		query = fmt.Sprintf("%s by {%s}", query, strings.Join(e.groupBy, ","))
+	}
+	if e.function != "" {
+		query = fmt.Sprintf("per_second(%s)", query)
+	}
+
+	return query
+}
+
+// parseAlertExpr parses a PromQL alerting expression, returning an error when it uses
+// constructs that have no Datadog equivalent
+func parseAlertExpr(expr string) (*alertExpr, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	result := &alertExpr{}
+
+	if p.peek().kind == tokenNumber {
+		// Threshold on the left side: `0.5 < metric`
+		result.threshold = p.next().value
+		op := p.next()
+		flipped, ok := flippedComparators[op.value]
+		if !ok {
+			return nil, fmt.Errorf("unsupported comparison operator %q", op.value)
+		}
+		result.comparator = flipped
+		if err = p.parseVector(result); err != nil {
+			return nil, err
+		}
+	} else {
+		if err = p.parseVector(result); err != nil {
+			return nil, err
+		}
+		op := p.next()
+		if _, ok := flippedComparators[op.value]; !ok {
+			if op.kind == tokenEOF {
+				return nil, fmt.Errorf("expression has no threshold comparison")
+			}
+			return nil, fmt.Errorf("unsupported operator %q", op.value)
+		}
+		result.comparator = op.value
+		sign := ""
+		if p.peek().value == "-" {
+			sign = p.next().value
+		}
+		threshold := p.next()
+		if threshold.kind != tokenNumber {
+			return nil, fmt.Errorf("threshold must be a number, got %q", threshold.value)
+		}
+		result.threshold = sign + threshold.value
+	}
+
+	if tok := p.next(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unsupported expression after threshold: %q", tok.value)
+	}
+
+	return result, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenDuration
+	tokenOperator
+	tokenPunct
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenEOF}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	tok := p.peek()
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) expect(value string) error {
+	if tok := p.next(); tok.value != value {
+		return fmt.Errorf("expected %q, got %q", value, tok.value)
+	}
+	return nil
+}
+
+func (p *exprParser) parseVector(result *alertExpr) error {
+	tok := p.peek()
+	if tok.kind != tokenIdent {
+		return fmt.Errorf("unexpected token %q", tok.value)
+	}
+
+	if datadogSpaceAggregations[tok.value] {
+		p.next()
+		result.aggregation = tok.value
+		if err := p.parseGrouping(result); err != nil {
+			return err
+		}
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		if err := p.parseInner(result); err != nil {
+			return err
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		return p.parseGrouping(result)
+	}
+
+	if p.isCall() {
+		return p.parseInner(result)
+	}
+
+	return p.parseSelector(result, false)
+}
+
+func (p *exprParser) isCall() bool {
+	return p.pos+1 < len(p.tokens) && p.tokens[p.pos+1].value == "("
+}
+
+func (p *exprParser) parseGrouping(result *alertExpr) error {
+	tok := p.peek()
+	if tok.kind != tokenIdent {
+		return nil
+	}
+	switch tok.value {
+	case "by":
+		p.next()
+	case "without":
+		return fmt.Errorf("aggregation 'without' is not supported")
+	default:
+		return nil
+	}
+	if len(result.groupBy) > 0 {
+		return fmt.Errorf("grouping is defined twice")
+	}
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for {
+		label := p.next()
+		if label.kind != tokenIdent {
+			return fmt.Errorf("invalid grouping label %q", label.value)
+		}
+		result.groupBy = append(result.groupBy, label.value)
+		sep := p.next()
+		if sep.value == ")" {
+			return nil
+		}
+		if sep.value != "," {
+			return fmt.Errorf("expected ',' or ')', got %q", sep.value)
+		}
+	}
+}
+
+func (p *exprParser) parseInner(result *alertExpr) error {
+	tok := p.peek()
+	if tok.kind == tokenIdent && p.isCall() {
+		if !rateFunctions[tok.value] {
+			return fmt.Errorf("unsupported function %q", tok.value)
+		}
+		p.next()
+		result.function = tok.value
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		if err := p.parseSelector(result, true); err != nil {
+			return err
+		}
+		return p.expect(")")
+	}
+
+	return p.parseSelector(result, false)
+}
+
+func (p *exprParser) parseSelector(result *alertExpr, withRange bool) error {
+	name := p.next()
+	if name.kind != tokenIdent {
+		return fmt.Errorf("expected a metric name, got %q", name.value)
+	}
+	result.metric = name.value
+
+	if p.peek().value == "{" {
+		p.next()
+		for p.peek().value != "}" {
+			label := p.next()
+			if label.kind != tokenIdent {
+				return fmt.Errorf("invalid label matcher %q", label.value)
+			}
+			op := p.next()
+			if op.value != "=" && op.value != "!=" {
+				return fmt.Errorf("unsupported label matcher operator %q", op.value)
+			}
+			value := p.next()
+			if value.kind != tokenString {
+				return fmt.Errorf("invalid label matcher value %q", value.value)
+			}
+			result.matchers = append(result.matchers, labelMatcher{name: label.value, negative: op.value == "!=", value: value.value})
+			if p.peek().value == "," {
+				p.next()
+			}
+		}
+		p.next()
+	}
+
+	if p.peek().value == "[" {
+		if !withRange {
+			return fmt.Errorf("range vector is only supported inside rate() or irate()")
+		}
+		p.next()
+		d := p.next()
+		if d.kind != tokenDuration {
+			return fmt.Errorf("invalid range %q", d.value)
+		}
+		duration, err := parseDuration(d.value)
+		if err != nil {
+			return err
+		}
+		result.rangeDuration = duration
+		return p.expect("]")
+	} else if withRange {
+		return fmt.Errorf("%s() requires a range vector", result.function)
+	}
+
+	if p.peek().value == "offset" {
+		return fmt.Errorf("offset modifier is not supported")
+	}
+
+	return nil
+}
+
+func tokenize(expr string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated string in expression")
+			}
+			raw := string(runes[i+1 : end])
+			if r == '\'' {
+				raw = strings.ReplaceAll(strings.ReplaceAll(raw, `\'`, `'`), `"`, `\"`)
+			}
+			unquoted, err := strconv.Unquote(`"` + raw + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string in expression: %w", err)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: unquoted})
+			i = end + 1
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			end := i
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.' || runes[end] == 'e' || runes[end] == 'E') {
+				end++
+			}
+			kind := tokenNumber
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				kind = tokenDuration
+				end++
+			}
+			tokens = append(tokens, token{kind: kind, value: string(runes[i:end])})
+			i = end
+		case unicode.IsLetter(r) || r == '_' || r == ':':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_' || runes[end] == ':') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[i:end])})
+			i = end
+		case strings.ContainsRune("(){}[],", r):
+			tokens = append(tokens, token{kind: tokenPunct, value: string(r)})
+			i++
+		default:
+			end := i
+			for end < len(runes) && strings.ContainsRune("<>=!~+-*/%^", runes[end]) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("unexpected character %q in expression", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, value: string(runes[i:end])})
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+// parseDuration parses a Prometheus duration such as 30s, 5m, 1h30m or 1d
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	var total time.Duration
+	rest := s
+	for rest != "" {
+		i := 0
+		for i < len(rest) && unicode.IsDigit(rune(rest[i])) {
+			i++
+		}
+		if i == 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		value, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		j := i
+		for j < len(rest) && unicode.IsLetter(rune(rest[j])) {
+			j++
+		}
+		var unit time.Duration
+		switch rest[i:j] {
+		case "ms":
+			unit = time.Millisecond
+		case "s":
+			unit = time.Second
+		case "m":
+			unit = time.Minute
+		case "h":
+			unit = time.Hour
+		case "d":
+			unit = 24 * time.Hour
+		case "w":
+			unit = 7 * 24 * time.Hour
+		case "y":
+			unit = 365 * 24 * time.Hour
+		default:
+			return 0, fmt.Errorf("invalid duration unit in %q", s)
+		}
+		total += time.Duration(value) * unit
+		rest = rest[j:]
+	}
+
+	return total, nil
+}
@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package prometheusrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+	prometheus "github.com/DataDog/datadog-operator/pkg/prometheus/v1"
+)
+
+func Test_translateRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      prometheus.Rule
+		wantQuery string
+		wantErr   bool
+	}{
+		{
+			name: "plain selector",
+			rule: prometheus.Rule{
+				Alert: "DiskFull",
+				Expr:  intstr.FromString(`node_filesystem_usage{mountpoint="/",job!="test"} > 0.9`),
+			},
+			wantQuery: "avg(last_5m):avg:node_filesystem_usage{mountpoint:/,!job:test} > 0.9",
+		},
+		{
+			name: "aggregation with grouping and for",
+			rule: prometheus.Rule{
+				Alert: "HighErrorRate",
+				Expr:  intstr.FromString(`sum by (service) (rate(http_requests_errors_total{code="500"}[5m])) >= 10`),
+				For:   "12m",
+			},
+			wantQuery: "avg(last_15m):per_second(sum:http_requests_errors_total{code:500} by {service}) >= 10",
+		},
+		{
+			name: "trailing grouping and range window",
+			rule: prometheus.Rule{
+				Alert: "LowThroughput",
+				Expr:  intstr.FromString(`max(irate(requests_total[10m])) by (host, env) < 1`),
+			},
+			wantQuery: "avg(last_10m):per_second(max:requests_total{*} by {host,env}) < 1",
+		},
+		{
+			name: "threshold on the left side",
+			rule: prometheus.Rule{
+				Alert: "QueueTooLong",
+				Expr:  intstr.FromString(`100 < queue_length`),
+			},
+			wantQuery: "avg(last_5m):avg:queue_length{*} > 100",
+		},
+		{
+			name: "negative threshold",
+			rule: prometheus.Rule{
+				Alert: "Negative",
+				Expr:  intstr.FromString(`temperature < -10`),
+			},
+			wantQuery: "avg(last_5m):avg:temperature{*} < -10",
+		},
+		{
+			name: "unsupported function",
+			rule: prometheus.Rule{
+				Alert: "Absent",
+				Expr:  intstr.FromString(`absent(up{job="api"}) == 1`),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported regex matcher",
+			rule: prometheus.Rule{
+				Alert: "Regex",
+				Expr:  intstr.FromString(`up{job=~"api.*"} < 1`),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported binary operator",
+			rule: prometheus.Rule{
+				Alert: "And",
+				Expr:  intstr.FromString(`up < 1 and foo > 2`),
+			},
+			wantErr: true,
+		},
+		{
+			name: "no threshold",
+			rule: prometheus.Rule{
+				Alert: "NoThreshold",
+				Expr:  intstr.FromString(`up`),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := translateRule("rules", "group", &tt.rule)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, spec.Query)
+			assert.Equal(t, datadoghqv1alpha1.DatadogMonitorTypeQuery, spec.Type)
+			assert.Equal(t, tt.rule.Alert, spec.Name)
+			assert.NoError(t, datadoghqv1alpha1.IsValidDatadogMonitor(spec))
+		})
+	}
+}
+
+func Test_translateRule_metadata(t *testing.T) {
+	rule := &prometheus.Rule{
+		Alert: "HighLatency",
+		Expr:  intstr.FromString(`avg(latency_seconds) by (service) > 0.5`),
+		Labels: map[string]string{
+			"severity": "critical",
+			"team":     "web",
+		},
+		Annotations: map[string]string{
+			"summary":     "Latency is {{ $value }} on {{ $labels.service }}",
+			"runbook_url": "https://runbooks/latency",
+		},
+	}
+
+	spec, err := translateRule("latency", "api", rule)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), spec.Priority)
+	assert.Equal(t, "0.5", *spec.Options.Thresholds.Critical)
+	assert.Equal(t, "Latency is {{value}} on {{service.name}}\n\nRunbook: https://runbooks/latency", spec.Message)
+	assert.Equal(t, []string{
+		"alertname:HighLatency",
+		"generated:kubernetes",
+		"prometheus_rule:latency",
+		"prometheus_rule_group:api",
+		"severity:critical",
+		"team:web",
+	}, spec.Tags)
+}
+
+func Test_parseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "30s", want: 30 * time.Second},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "1d", want: 24 * time.Hour},
+		{in: "5", wantErr: true},
+		{in: "m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDuration(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
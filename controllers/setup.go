@@ -10,10 +10,13 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/DataDog/datadog-operator/controllers/datadogagent"
+	"github.com/DataDog/datadog-operator/controllers/datadogmonitor"
 	"github.com/DataDog/datadog-operator/pkg/config"
 	"github.com/DataDog/datadog-operator/pkg/datadogclient"
+	prometheus "github.com/DataDog/datadog-operator/pkg/prometheus/v1"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/version"
@@ -22,24 +25,40 @@ import (
 )
 
 const (
-	agentControllerName   = "DatadogAgent"
-	monitorControllerName = "DatadogMonitor"
+	agentControllerName          = "DatadogAgent"
+	monitorControllerName        = "DatadogMonitor"
+	prometheusRuleControllerName = "PrometheusRule"
 )
 
 // SetupOptions defines options for setting up controllers to ease testing
 type SetupOptions struct {
-	SupportExtendedDaemonset bool
-	SupportCilium            bool
-	Creds                    config.Creds
-	DatadogMonitorEnabled    bool
-	OperatorMetricsEnabled   bool
+	SupportExtendedDaemonset  bool
+	SupportCilium             bool
+	SupportOpenShiftSCC       bool
+	PodSecurityLabelNamespace bool
+	OrphanCollectionEnabled   bool
+	OrphanCollectionDryRun    bool
+	Creds                     config.Creds
+	DatadogMonitorEnabled     bool
+	PrometheusRuleEnabled     bool
+	OperatorMetricsEnabled    bool
+	SidecarInjectionEnabled   bool
+	MonitorClusterName        string
+	MonitorNamespaceTag       bool
+	MonitorLabelsAsTags       map[string]string
+	MonitorWebhookAddr        string
+	MonitorWebhookCertFile    string
+	MonitorWebhookKeyFile     string
+	MonitorWebhookSecret      string
+	MonitorRecreateDeleted    bool
 }
 
 type starterFunc func(logr.Logger, manager.Manager, *version.Info, SetupOptions) error
 
 var controllerStarters = map[string]starterFunc{
-	agentControllerName:   startDatadogAgent,
-	monitorControllerName: startDatadogMonitor,
+	agentControllerName:          startDatadogAgent,
+	monitorControllerName:        startDatadogMonitor,
+	prometheusRuleControllerName: startPrometheusRule,
 }
 
 // SetupControllers starts all controllers (also used by e2e tests)
@@ -67,16 +86,29 @@ func SetupControllers(logger logr.Logger, mgr manager.Manager, options SetupOpti
 }
 
 func startDatadogAgent(logger logr.Logger, mgr manager.Manager, vInfo *version.Info, options SetupOptions) error {
+	if options.SidecarInjectionEnabled {
+		injector, err := datadogagent.NewSidecarInjector(mgr.GetClient(), mgr.GetAPIReader(), mgr.GetScheme(), ctrl.Log.WithName("webhooks").WithName("AgentSidecar"))
+		if err != nil {
+			return fmt.Errorf("unable to create the Agent sidecar injector: %w", err)
+		}
+		mgr.GetWebhookServer().Register(datadogagent.SidecarInjectionWebhookPath, &webhook.Admission{Handler: injector})
+	}
+
 	return (&DatadogAgentReconciler{
 		Client:      mgr.GetClient(),
+		APIReader:   mgr.GetAPIReader(),
 		VersionInfo: vInfo,
 		Log:         ctrl.Log.WithName("controllers").WithName(agentControllerName),
 		Scheme:      mgr.GetScheme(),
 		Recorder:    mgr.GetEventRecorderFor(agentControllerName),
 		Options: datadogagent.ReconcilerOptions{
-			SupportExtendedDaemonset: options.SupportExtendedDaemonset,
-			SupportCilium:            options.SupportCilium,
-			OperatorMetricsEnabled:   options.OperatorMetricsEnabled,
+			SupportExtendedDaemonset:  options.SupportExtendedDaemonset,
+			SupportCilium:             options.SupportCilium,
+			SupportOpenShiftSCC:       options.SupportOpenShiftSCC,
+			PodSecurityLabelNamespace: options.PodSecurityLabelNamespace,
+			OrphanCollectionEnabled:   options.OrphanCollectionEnabled,
+			OrphanCollectionDryRun:    options.OrphanCollectionDryRun,
+			OperatorMetricsEnabled:    options.OperatorMetricsEnabled,
 		},
 	}).SetupWithManager(mgr)
 }
@@ -93,6 +125,16 @@ func startDatadogMonitor(logger logr.Logger, mgr manager.Manager, vInfo *version
 		return fmt.Errorf("unable to create Datadog API Client: %w", err)
 	}
 
+	if options.MonitorWebhookAddr != "" {
+		receiver, err := datadogmonitor.NewWebhookReceiver(mgr.GetClient(), options.MonitorWebhookAddr, options.MonitorWebhookCertFile, options.MonitorWebhookKeyFile, options.MonitorWebhookSecret, ctrl.Log.WithName("controllers").WithName(monitorControllerName).WithName("webhook"))
+		if err != nil {
+			return fmt.Errorf("unable to create the monitor webhook receiver: %w", err)
+		}
+		if err = mgr.Add(receiver); err != nil {
+			return fmt.Errorf("unable to add the monitor webhook receiver: %w", err)
+		}
+	}
+
 	return (&DatadogMonitorReconciler{
 		Client:      mgr.GetClient(),
 		DDClient:    ddClient,
@@ -100,5 +142,37 @@ func startDatadogMonitor(logger logr.Logger, mgr manager.Manager, vInfo *version
 		Log:         ctrl.Log.WithName("controllers").WithName(monitorControllerName),
 		Scheme:      mgr.GetScheme(),
 		Recorder:    mgr.GetEventRecorderFor(monitorControllerName),
+		Options: datadogmonitor.ReconcilerOptions{
+			TagPolicy: datadogmonitor.TagPolicy{
+				ClusterName:  options.MonitorClusterName,
+				NamespaceTag: options.MonitorNamespaceTag,
+				LabelsAsTags: options.MonitorLabelsAsTags,
+			},
+			RecreateDeletedMonitors: options.MonitorRecreateDeleted,
+		},
+	}).SetupWithManager(mgr)
+}
+
+func startPrometheusRule(logger logr.Logger, mgr manager.Manager, vInfo *version.Info, options SetupOptions) error {
+	if !options.PrometheusRuleEnabled {
+		logger.Info("Feature disabled, not starting the controller", "controller", prometheusRuleControllerName)
+
+		return nil
+	}
+
+	if !options.DatadogMonitorEnabled {
+		return fmt.Errorf("the %s controller requires the %s controller to be enabled", prometheusRuleControllerName, monitorControllerName)
+	}
+
+	gvk := prometheus.PrometheusRuleGroupVersionKind()
+	if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		return fmt.Errorf("unable to find the PrometheusRule resource: %w", err)
+	}
+
+	return (&PrometheusRuleReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName(prometheusRuleControllerName),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor(prometheusRuleControllerName),
 	}).SetupWithManager(mgr)
 }
@@ -86,9 +86,9 @@ func main() {
 	flag.DurationVar(&leaderElectionLeaseDuration, "leader-election-lease-duration", 60*time.Second, "Define LeaseDuration as well as RenewDeadline (leaseDuration / 2) and RetryPeriod (leaseDuration / 4)")
 
 	// Custom flags
-	var printVersion, pprofActive, supportExtendedDaemonset, supportCilium, datadogMonitorEnabled, operatorMetricsEnabled bool
-	var logEncoder, secretBackendCommand string
-	var secretBackendArgs stringSlice
+	var printVersion, pprofActive, supportExtendedDaemonset, supportCilium, supportOpenShiftSCC, podSecurityLabelNamespace, orphanCollectionEnabled, orphanCollectionDryRun, datadogMonitorEnabled, prometheusRuleEnabled, operatorMetricsEnabled, monitorNamespaceTag, monitorRecreateDeleted, sidecarInjectionEnabled bool
+	var logEncoder, secretBackendCommand, monitorClusterName, monitorWebhookAddr, monitorWebhookCertFile, monitorWebhookKeyFile string
+	var secretBackendArgs, monitorLabelsAsTags stringSlice
 	flag.StringVar(&logEncoder, "logEncoder", "json", "log encoding ('json' or 'console')")
 	flag.StringVar(&secretBackendCommand, "secretBackendCommand", "", "Secret backend command")
 	flag.Var(&secretBackendArgs, "secretBackendArgs", "Space separated arguments of the secret backend command")
@@ -97,8 +97,21 @@ func main() {
 	flag.BoolVar(&pprofActive, "pprof", false, "Enable pprof endpoint")
 	flag.BoolVar(&supportExtendedDaemonset, "supportExtendedDaemonset", false, "Support usage of Datadog ExtendedDaemonset CRD.")
 	flag.BoolVar(&supportCilium, "supportCilium", false, "Support usage of Cilium network policies.")
+	flag.BoolVar(&supportOpenShiftSCC, "supportOpenShiftSCC", false, "Support the creation of OpenShift SecurityContextConstraints for the Agent.")
+	flag.BoolVar(&podSecurityLabelNamespace, "podSecurityLabelNamespace", false, "Label the namespace of a DatadogAgent with the Pod Security level admitting its pods, when the enforced level rejects them")
+	flag.BoolVar(&orphanCollectionEnabled, "orphanCollectionEnabled", false, "Periodically delete the resources labeled as part of a DatadogAgent that it doesn't need anymore")
+	flag.BoolVar(&orphanCollectionDryRun, "orphanCollectionDryRun", false, "Only log the orphaned resources that would be deleted, requires orphanCollectionEnabled")
 	flag.BoolVar(&datadogMonitorEnabled, "datadogMonitorEnabled", false, "Enable the DatadogMonitor controller")
+	flag.BoolVar(&prometheusRuleEnabled, "prometheusRuleEnabled", false, "Enable the PrometheusRule controller (translates PrometheusRule alerts into DatadogMonitors, requires datadogMonitorEnabled)")
 	flag.BoolVar(&operatorMetricsEnabled, "operatorMetricsEnabled", true, "Enable sending operator metrics to Datadog")
+	flag.BoolVar(&sidecarInjectionEnabled, "sidecarInjectionEnabled", false, "Serve the admission webhook injecting the Agent sidecar into the pods running on serverless nodes")
+	flag.StringVar(&monitorClusterName, "monitorClusterName", "", "Cluster name added as kube_cluster_name tag to the DatadogMonitors")
+	flag.BoolVar(&monitorNamespaceTag, "monitorNamespaceTag", false, "Add the kube_namespace tag to the DatadogMonitors")
+	flag.BoolVar(&monitorRecreateDeleted, "monitorRecreateDeleted", true, "Recreate the monitors deleted in Datadog, instead of only reporting them in the DatadogMonitor status")
+	flag.StringVar(&monitorWebhookAddr, "monitorWebhookAddr", "", "The address the DatadogMonitor webhook receiver binds to, disabled if empty (the shared secret is read from DD_MONITOR_WEBHOOK_SECRET)")
+	flag.StringVar(&monitorWebhookCertFile, "monitorWebhookCertFile", "", "The TLS certificate file of the DatadogMonitor webhook receiver, required with monitorWebhookAddr")
+	flag.StringVar(&monitorWebhookKeyFile, "monitorWebhookKeyFile", "", "The TLS key file of the DatadogMonitor webhook receiver, required with monitorWebhookAddr")
+	flag.Var(&monitorLabelsAsTags, "monitorLabelsAsTags", "Space separated <label>=<tag> pairs of namespace or DatadogMonitor labels added as tags to the DatadogMonitors")
 
 	// Parsing flags
 	flag.Parse()
@@ -150,12 +163,32 @@ func main() {
 		os.Exit(1)
 	}
 
+	labelsAsTags, err := parseLabelsAsTags(monitorLabelsAsTags)
+	if err != nil {
+		setupLog.Error(err, "Invalid monitorLabelsAsTags")
+		os.Exit(1)
+	}
+
 	options := controllers.SetupOptions{
-		SupportExtendedDaemonset: supportExtendedDaemonset,
-		SupportCilium:            supportCilium,
-		Creds:                    creds,
-		DatadogMonitorEnabled:    datadogMonitorEnabled,
-		OperatorMetricsEnabled:   operatorMetricsEnabled,
+		SupportExtendedDaemonset:  supportExtendedDaemonset,
+		SupportCilium:             supportCilium,
+		SupportOpenShiftSCC:       supportOpenShiftSCC,
+		PodSecurityLabelNamespace: podSecurityLabelNamespace,
+		OrphanCollectionEnabled:   orphanCollectionEnabled,
+		OrphanCollectionDryRun:    orphanCollectionDryRun,
+		Creds:                     creds,
+		DatadogMonitorEnabled:     datadogMonitorEnabled,
+		PrometheusRuleEnabled:     prometheusRuleEnabled,
+		OperatorMetricsEnabled:    operatorMetricsEnabled,
+		SidecarInjectionEnabled:   sidecarInjectionEnabled,
+		MonitorClusterName:        monitorClusterName,
+		MonitorNamespaceTag:       monitorNamespaceTag,
+		MonitorLabelsAsTags:       labelsAsTags,
+		MonitorWebhookAddr:        monitorWebhookAddr,
+		MonitorWebhookCertFile:    monitorWebhookCertFile,
+		MonitorWebhookKeyFile:     monitorWebhookKeyFile,
+		MonitorWebhookSecret:      os.Getenv("DD_MONITOR_WEBHOOK_SECRET"),
+		MonitorRecreateDeleted:    monitorRecreateDeleted,
 	}
 
 	if err := controllers.SetupControllers(setupLog, mgr, options); err != nil {
@@ -172,6 +205,23 @@ func main() {
 	}
 }
 
+// parseLabelsAsTags parses <label>=<tag> pairs
+func parseLabelsAsTags(pairs []string) (map[string]string, error) {
+	labelsAsTags := map[string]string{}
+	for _, pair := range pairs {
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid label to tag mapping %q, expected <label>=<tag>", pair)
+		}
+		labelsAsTags[parts[0]] = parts[1]
+	}
+
+	return labelsAsTags, nil
+}
+
 func customSetupLogging(logLevel zapcore.Level, logEncoder string) error {
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
@@ -29,6 +29,15 @@ func SetErrorActiveConditions(status *datadoghqv1alpha1.DatadogMonitorStatus, no
 	}
 }
 
+// SetErrorActiveConditionsWithReason is SetErrorActiveConditions, also setting the reason of the Error condition
+func SetErrorActiveConditionsWithReason(status *datadoghqv1alpha1.DatadogMonitorStatus, now metav1.Time, err error, reason string) {
+	SetErrorActiveConditions(status, now, err)
+
+	if conditionIndex := getIndexForDatadogMonitorConditionType(status, datadoghqv1alpha1.DatadogMonitorConditionTypeError); conditionIndex > -1 {
+		status.Conditions[conditionIndex].Reason = reason
+	}
+}
+
 // UpdateDatadogMonitorConditions is used to update a DatadogMonitorConditionType in conditions
 func UpdateDatadogMonitorConditions(status *datadoghqv1alpha1.DatadogMonitorStatus, now metav1.Time, t datadoghqv1alpha1.DatadogMonitorConditionType, conditionStatus corev1.ConditionStatus, desc string) {
 	conditionIndex := getIndexForDatadogMonitorConditionType(status, t)
@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// FieldManager is the field manager used by the operator to apply the resources it manages
+const FieldManager = "datadog-operator"
+
+// ApplyOperation is the operation performed by Apply
+type ApplyOperation string
+
+const (
+	// ApplyOperationNone the resource was already up to date
+	ApplyOperationNone ApplyOperation = "unchanged"
+	// ApplyOperationCreated the resource was created
+	ApplyOperationCreated ApplyOperation = "created"
+	// ApplyOperationUpdated the resource was updated
+	ApplyOperationUpdated ApplyOperation = "updated"
+)
+
+// ApplyResult contains the outcome of Apply
+type ApplyResult struct {
+	Operation ApplyOperation
+	// Conflicts contains the fields that were managed by another field manager with a different value,
+	// and that were taken over by the operator.
+	Conflicts []string
+}
+
+// Apply creates or updates obj with server-side apply, using FieldManager as field manager.
+// obj must only contain the fields managed by the operator; it is updated with the object returned by the API server.
+// Fields set to a different value by another field manager are reported in the result, and the operator forces
+// their ownership: it remains the source of truth of the resources it manages.
+func Apply(ctx context.Context, c client.Client, obj client.Object) (ApplyResult, error) {
+	result := ApplyResult{}
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return result, err
+	}
+	// The apply patch is the serialized object, it requires the apiVersion and kind
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	previousVersion := ""
+	current, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return result, errors.New("unable to copy the object to apply")
+	}
+	if err = c.Get(ctx, client.ObjectKeyFromObject(obj), current); err == nil {
+		previousVersion = current.GetResourceVersion()
+	} else if !apierrors.IsNotFound(err) {
+		return result, err
+	}
+
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	err = c.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager))
+	if result.Conflicts = applyConflicts(err); len(result.Conflicts) > 0 {
+		err = c.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+	}
+	if err != nil {
+		return result, err
+	}
+	// The decoded response doesn't always contain the type information
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	switch {
+	case previousVersion == "":
+		result.Operation = ApplyOperationCreated
+	case obj.GetResourceVersion() != previousVersion:
+		result.Operation = ApplyOperationUpdated
+	default:
+		result.Operation = ApplyOperationNone
+	}
+
+	return result, nil
+}
+
+// applyConflicts returns the field manager conflicts contained in a server-side apply error
+func applyConflicts(err error) []string {
+	if err == nil || !apierrors.IsConflict(err) {
+		return nil
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return nil
+	}
+
+	var conflicts []string
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, cause.Message)
+		}
+	}
+
+	return conflicts
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kubernetes
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func Test_applyConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "no error",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "not a conflict",
+			err:  apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "foo"),
+			want: nil,
+		},
+		{
+			name: "not an API error",
+			err:  errors.New("conflict"),
+			want: nil,
+		},
+		{
+			name: "field manager conflicts",
+			err: apierrors.NewApplyConflict([]metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "kubectl": .data.foo`,
+					Field:   ".data.foo",
+				},
+				{
+					Type:    metav1.CauseTypeFieldValueInvalid,
+					Message: "invalid value",
+				},
+			}, "Apply failed with 1 conflict"),
+			want: []string{`conflict with "kubectl": .data.foo`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyConflicts(tt.err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyConflicts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
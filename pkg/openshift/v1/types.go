@@ -0,0 +1,127 @@
+package openshift
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FSType is a volume plugin allowed by a SecurityContextConstraints
+type FSType string
+
+const (
+	// FSTypeHostPath allows hostPath volumes
+	FSTypeHostPath FSType = "hostPath"
+	// FSTypeEmptyDir allows emptyDir volumes
+	FSTypeEmptyDir FSType = "emptyDir"
+	// FSTypeConfigMap allows configMap volumes
+	FSTypeConfigMap FSType = "configMap"
+	// FSTypeSecret allows secret volumes
+	FSTypeSecret FSType = "secret"
+	// FSTypeProjected allows projected volumes
+	FSTypeProjected FSType = "projected"
+	// FSTypeDownwardAPI allows downwardAPI volumes
+	FSTypeDownwardAPI FSType = "downwardAPI"
+	// FSTypePersistentVolumeClaim allows persistentVolumeClaim volumes
+	FSTypePersistentVolumeClaim FSType = "persistentVolumeClaim"
+	// FSTypeCSI allows CSI volumes
+	FSTypeCSI FSType = "csi"
+	// FSTypeEphemeral allows ephemeral volumes
+	FSTypeEphemeral FSType = "ephemeral"
+	// FSTypeAll allows all the volume plugins
+	FSTypeAll FSType = "*"
+)
+
+// SELinuxContextStrategyType is the strategy used to validate the SELinux context of the pods
+type SELinuxContextStrategyType string
+
+const (
+	// SELinuxStrategyMustRunAs requires the SELinux options of the strategy
+	SELinuxStrategyMustRunAs SELinuxContextStrategyType = "MustRunAs"
+	// SELinuxStrategyRunAsAny allows any SELinux context
+	SELinuxStrategyRunAsAny SELinuxContextStrategyType = "RunAsAny"
+)
+
+// RunAsUserStrategyType is the strategy used to validate the user of the containers
+type RunAsUserStrategyType string
+
+const (
+	// RunAsUserStrategyRunAsAny allows any user
+	RunAsUserStrategyRunAsAny RunAsUserStrategyType = "RunAsAny"
+	// RunAsUserStrategyMustRunAs requires the containers to run with the UID
+	RunAsUserStrategyMustRunAs RunAsUserStrategyType = "MustRunAs"
+	// RunAsUserStrategyMustRunAsRange requires the containers to run with a UID in the range
+	RunAsUserStrategyMustRunAsRange RunAsUserStrategyType = "MustRunAsRange"
+)
+
+// SupplementalGroupsStrategyType is the strategy used to validate the supplemental groups of the pods
+type SupplementalGroupsStrategyType string
+
+const (
+	// SupplementalGroupsStrategyRunAsAny allows any supplemental group
+	SupplementalGroupsStrategyRunAsAny SupplementalGroupsStrategyType = "RunAsAny"
+)
+
+// FSGroupStrategyType is the strategy used to validate the fsGroup of the pods
+type FSGroupStrategyType string
+
+const (
+	// FSGroupStrategyRunAsAny allows any fsGroup
+	FSGroupStrategyRunAsAny FSGroupStrategyType = "RunAsAny"
+)
+
+// SecurityContextConstraints is an OpenShift SecurityContextConstraints, only the fields used by the
+// operator are defined
+type SecurityContextConstraints struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Priority *int32 `json:"priority"`
+
+	AllowPrivilegedContainer bool  `json:"allowPrivilegedContainer"`
+	AllowHostDirVolumePlugin bool  `json:"allowHostDirVolumePlugin"`
+	AllowHostNetwork         bool  `json:"allowHostNetwork"`
+	AllowHostPorts           bool  `json:"allowHostPorts"`
+	AllowHostPID             bool  `json:"allowHostPID"`
+	AllowHostIPC             bool  `json:"allowHostIPC"`
+	AllowPrivilegeEscalation *bool `json:"allowPrivilegeEscalation,omitempty"`
+	ReadOnlyRootFilesystem   bool  `json:"readOnlyRootFilesystem"`
+
+	DefaultAddCapabilities   []corev1.Capability `json:"defaultAddCapabilities"`
+	RequiredDropCapabilities []corev1.Capability `json:"requiredDropCapabilities"`
+	AllowedCapabilities      []corev1.Capability `json:"allowedCapabilities"`
+
+	Volumes         []FSType `json:"volumes"`
+	SeccompProfiles []string `json:"seccompProfiles,omitempty"`
+
+	SELinuxContext     SELinuxContextStrategyOptions     `json:"seLinuxContext,omitempty"`
+	RunAsUser          RunAsUserStrategyOptions          `json:"runAsUser,omitempty"`
+	SupplementalGroups SupplementalGroupsStrategyOptions `json:"supplementalGroups,omitempty"`
+	FSGroup            FSGroupStrategyOptions            `json:"fsGroup,omitempty"`
+
+	Users  []string `json:"users"`
+	Groups []string `json:"groups"`
+}
+
+// SELinuxContextStrategyOptions defines the SELinux context strategy
+type SELinuxContextStrategyOptions struct {
+	Type           SELinuxContextStrategyType `json:"type,omitempty"`
+	SELinuxOptions *corev1.SELinuxOptions     `json:"seLinuxOptions,omitempty"`
+}
+
+// RunAsUserStrategyOptions defines the user strategy
+type RunAsUserStrategyOptions struct {
+	Type        RunAsUserStrategyType `json:"type,omitempty"`
+	UID         *int64                `json:"uid,omitempty"`
+	UIDRangeMin *int64                `json:"uidRangeMin,omitempty"`
+	UIDRangeMax *int64                `json:"uidRangeMax,omitempty"`
+}
+
+// SupplementalGroupsStrategyOptions defines the supplemental groups strategy
+type SupplementalGroupsStrategyOptions struct {
+	Type SupplementalGroupsStrategyType `json:"type,omitempty"`
+}
+
+// FSGroupStrategyOptions defines the fsGroup strategy
+type FSGroupStrategyOptions struct {
+	Type FSGroupStrategyType `json:"type,omitempty"`
+}
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package versioncatalog selects the Agent versions to deploy, and their image digests, from a catalog of available versions.
+package versioncatalog
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+// CatalogKey is the key of the catalog in the data of its ConfigMap
+const CatalogKey = "catalog.yaml"
+
+var digestRegexp = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// Catalog lists the available versions of the Agent and of the Cluster Agent,
+// and the digests of the images by reference, for example gcr.io/datadoghq/agent:7.33.0
+type Catalog struct {
+	Agent        []string          `json:"agent,omitempty"`
+	ClusterAgent []string          `json:"clusterAgent,omitempty"`
+	Digests      map[string]string `json:"digests,omitempty"`
+}
+
+// Parse parses a YAML catalog, and checks that all the versions are valid semantic versions
+func Parse(data []byte) (*Catalog, error) {
+	catalog := &Catalog{}
+	if err := yaml.UnmarshalStrict(data, catalog); err != nil {
+		return nil, fmt.Errorf("unable to parse the version catalog: %w", err)
+	}
+
+	for _, versions := range [][]string{catalog.Agent, catalog.ClusterAgent} {
+		for _, version := range versions {
+			if _, err := semver.NewVersion(version); err != nil {
+				return nil, fmt.Errorf("invalid version %q in the version catalog: %w", version, err)
+			}
+		}
+	}
+
+	for image, digest := range catalog.Digests {
+		if !digestRegexp.MatchString(digest) {
+			return nil, fmt.Errorf("invalid digest %q of the image %s in the version catalog", digest, image)
+		}
+	}
+	return catalog, nil
+}
+
+// FromConfigMap parses the catalog stored in a ConfigMap
+func FromConfigMap(cm *corev1.ConfigMap) (*Catalog, error) {
+	data, found := cm.Data[CatalogKey]
+	if !found {
+		return nil, fmt.Errorf("the ConfigMap %s/%s has no %s key", cm.Namespace, cm.Name, CatalogKey)
+	}
+	return Parse([]byte(data))
+}
+
+// Latest returns the latest stable version, or an empty string if there is none
+func Latest(versions []string) string {
+	return latestMatching(versions, nil)
+}
+
+// Digest returns the digest of an image reference, or an empty string if the catalog doesn't list it
+func (c *Catalog) Digest(image string) string {
+	return c.Digests[image]
+}
+
+// Target returns the version the channel updates the current version to: the current version for the pinned channel,
+// the latest patch version of its minor version for the patch channel, and the latest minor version of its major version
+// for the minor channel. The current version is returned if no newer version matches the channel.
+func Target(channel datadoghqv1alpha1.UpdateChannel, current string, versions []string) (string, error) {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return current, fmt.Errorf("the current version %q is not a semantic version: %w", current, err)
+	}
+
+	var upperBound semver.Version
+	switch channel {
+	case datadoghqv1alpha1.UpdateChannelPinned:
+		return current, nil
+	case datadoghqv1alpha1.UpdateChannelPatch:
+		upperBound = currentVersion.IncMinor()
+	case datadoghqv1alpha1.UpdateChannelMinor:
+		upperBound = currentVersion.IncMajor()
+	default:
+		return current, fmt.Errorf("unknown update channel %q", channel)
+	}
+
+	constraint, err := semver.NewConstraint(fmt.Sprintf("> %s, < %s", currentVersion, &upperBound))
+	if err != nil {
+		return current, err
+	}
+	if target := latestMatching(versions, constraint); target != "" {
+		return target, nil
+	}
+	return current, nil
+}
+
+func latestMatching(versions []string, constraint *semver.Constraints) string {
+	var latest *semver.Version
+	var latestStr string
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil || v.Prerelease() != "" {
+			continue
+		}
+		if constraint != nil && !constraint.Check(v) {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+			latestStr = version
+		}
+	}
+	return latestStr
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package versioncatalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	datadoghqv1alpha1 "github.com/DataDog/datadog-operator/apis/datadoghq/v1alpha1"
+)
+
+func TestParse(t *testing.T) {
+	catalog, err := Parse([]byte("agent:\n- 7.33.0\n- 7.34.0\nclusterAgent:\n- 1.17.0\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, &Catalog{Agent: []string{"7.33.0", "7.34.0"}, ClusterAgent: []string{"1.17.0"}}, catalog)
+
+	_, err = Parse([]byte("agent:\n- latest\n"))
+	assert.Error(t, err)
+
+	_, err = Parse([]byte("agents:\n- 7.33.0\n"))
+	assert.Error(t, err)
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+	catalog, err = Parse([]byte("digests:\n  gcr.io/datadoghq/agent:7.33.0: " + digest + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, digest, catalog.Digest("gcr.io/datadoghq/agent:7.33.0"))
+	assert.Equal(t, "", catalog.Digest("gcr.io/datadoghq/agent:7.34.0"))
+
+	_, err = Parse([]byte("digests:\n  gcr.io/datadoghq/agent:7.33.0: sha256:1234\n"))
+	assert.Error(t, err)
+}
+
+func TestTarget(t *testing.T) {
+	versions := []string{"7.33.0", "7.33.1", "7.33.2", "7.34.0", "7.35.0-rc.1", "7.35.1", "8.0.0"}
+
+	testCases := []struct {
+		channel datadoghqv1alpha1.UpdateChannel
+		current string
+		want    string
+		wantErr bool
+	}{
+		{channel: datadoghqv1alpha1.UpdateChannelPinned, current: "7.33.0", want: "7.33.0"},
+		{channel: datadoghqv1alpha1.UpdateChannelPatch, current: "7.33.0", want: "7.33.2"},
+		{channel: datadoghqv1alpha1.UpdateChannelPatch, current: "7.34.0", want: "7.34.0"},
+		{channel: datadoghqv1alpha1.UpdateChannelMinor, current: "7.33.0", want: "7.35.1"},
+		{channel: datadoghqv1alpha1.UpdateChannelMinor, current: "7.36.0", want: "7.36.0"},
+		{channel: datadoghqv1alpha1.UpdateChannelMinor, current: "latest", want: "latest", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.channel)+"/"+tc.current, func(t *testing.T) {
+			got, err := Target(tc.channel, tc.current, versions)
+			assert.Equal(t, tc.wantErr, err != nil)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLatest(t *testing.T) {
+	assert.Equal(t, "8.0.0", Latest([]string{"7.33.0", "8.0.0", "8.1.0-rc.1"}))
+	assert.Equal(t, "", Latest(nil))
+}